@@ -0,0 +1,58 @@
+package codecs
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Adapter converts a value into its wire representation (such as a time.Time
+// into an RFC 3339 string), which every codec then marshals.
+type Adapter func(value interface{}) (interface{}, error)
+
+var (
+	// adapters holds the map[reflect.Type]Adapter of registered adapters,
+	// which is replaced rather than changed so that it can be read without
+	// locking.
+	adapters     atomic.Value
+	adaptersLock sync.Mutex
+)
+
+// RegisterAdapter makes the adapter convert values of the object's type (or
+// the type it points to) in public data, so that every codec represents them
+// the same way rather than as their underlying libraries do.  A nil adapter
+// removes the type's adapter.
+func RegisterAdapter(object interface{}, adapter Adapter) {
+
+	if object == nil {
+		panic("codecs: RegisterAdapter object is nil.")
+	}
+
+	objectType := reflect.TypeOf(object)
+	for objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	adaptersLock.Lock()
+	defer adaptersLock.Unlock()
+
+	registered := registeredAdapters()
+	copied := make(map[reflect.Type]Adapter, len(registered)+1)
+	for registeredType, registeredAdapter := range registered {
+		copied[registeredType] = registeredAdapter
+	}
+
+	if adapter == nil {
+		delete(copied, objectType)
+	} else {
+		copied[objectType] = adapter
+	}
+
+	adapters.Store(copied)
+}
+
+// registeredAdapters gets the registered adapters, by type.
+func registeredAdapters() map[reflect.Type]Adapter {
+	registered, _ := adapters.Load().(map[reflect.Type]Adapter)
+	return registered
+}
@@ -0,0 +1,107 @@
+package codecs
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// celsius is a temperature, adapted into text in tests.
+type celsius float64
+
+// reading holds values with adapters.
+type reading struct {
+	Taken       time.Time `json:"taken"`
+	Temperature celsius   `json:"temperature"`
+	Previous    *celsius  `json:"previous"`
+	History     []celsius `json:"history"`
+}
+
+func TestRegisterAdapter(t *testing.T) {
+
+	RegisterAdapter(time.Time{}, func(value interface{}) (interface{}, error) {
+		return value.(time.Time).Format(time.RFC3339), nil
+	})
+	defer RegisterAdapter(time.Time{}, nil)
+
+	RegisterAdapter(new(celsius), func(value interface{}) (interface{}, error) {
+		return fmt.Sprintf("%.1fC", float64(value.(celsius))), nil
+	})
+
+	taken := time.Date(2014, 1, 2, 3, 4, 5, 0, time.UTC)
+	object := &reading{Taken: taken, Temperature: 21.5, History: []celsius{20, 19.5}}
+
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"taken":       "2014-01-02T03:04:05Z",
+			"temperature": "21.5C",
+			"previous":    nil,
+			"history":     []interface{}{"20.0C", "19.5C"},
+		}, public)
+	}
+
+	// adapters are also applied to values given directly
+	public, err = PublicData(taken, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2014-01-02T03:04:05Z", public)
+	}
+
+	// errors from adapters are returned
+	RegisterAdapter(celsius(0), func(value interface{}) (interface{}, error) {
+		return nil, errors.New("too cold")
+	})
+	_, err = PublicData(object, nil)
+	assert.EqualError(t, err, "too cold")
+
+	// removed adapters are no longer applied
+	RegisterAdapter(celsius(0), nil)
+	public, err = PublicData(celsius(3), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, celsius(3), public)
+	}
+
+	assert.Panics(t, func() {
+		RegisterAdapter(nil, nil)
+	})
+}
+
+// station has xml tags, which are the codecs' to follow.
+type station struct {
+	XMLName xml.Name `xml:"station"`
+	Name    string   `xml:"name,attr"`
+	Reading *reading `xml:"reading,omitempty"`
+}
+
+func TestRegisterAdapter_LeavesOtherValues(t *testing.T) {
+
+	RegisterAdapter(new(celsius), func(value interface{}) (interface{}, error) {
+		return fmt.Sprintf("%.1fC", float64(value.(celsius))), nil
+	})
+	defer RegisterAdapter(celsius(0), nil)
+
+	// values without adapted ones are left to the codecs
+	object := &station{Name: "North"}
+
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, object, public)
+
+		data, err := xml.Marshal(public)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `<station name="North"></station>`, string(data))
+		}
+	}
+
+	// while those holding them are adapted
+	object.Reading = &reading{Temperature: 21.5}
+
+	public, err = PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "21.5C", public.(map[string]interface{})["Reading"].(map[string]interface{})["temperature"])
+	}
+
+}
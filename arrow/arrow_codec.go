@@ -0,0 +1,156 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"io"
+	"reflect"
+)
+
+// OptionKeyBatchSize is the option key for the number of rows in each record
+// batch.  Slices are written as a single batch unless it is set, and channels
+// are written in batches of DefaultChannelBatchSize.
+const OptionKeyBatchSize = "arrow.batchsize"
+
+// DefaultChannelBatchSize is the number of rows in each record batch when
+// encoding a channel, unless OptionKeyBatchSize says otherwise.
+const DefaultChannelBatchSize = 1024
+
+// ArrowCodec converts slices of structs or maps to and from Arrow IPC
+// streams.
+type ArrowCodec struct{}
+
+func init() {
+	codecs.Register(new(ArrowCodec))
+}
+
+// Marshal converts a slice of structs (or maps) to an Arrow IPC stream.
+func (c *ArrowCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var buffer bytes.Buffer
+	if err := c.Encode(&buffer, object, options); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Encode writes the object to w as an Arrow IPC stream, a batch at a time.
+// The object can be a slice (or array) of structs or maps, or a channel of
+// them, in which case batches are written as rows are received until the
+// channel is closed.
+func (c *ArrowCodec) Encode(w io.Writer, object interface{}, options map[string]interface{}) error {
+
+	writer := NewStreamWriter(w)
+	v := reflect.ValueOf(object)
+	batchSize, _ := options[OptionKeyBatchSize].(int)
+
+	if v.IsValid() && v.Kind() == reflect.Chan {
+
+		if batchSize <= 0 {
+			batchSize = DefaultChannelBatchSize
+		}
+
+		batch := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, batchSize)
+		for {
+			row, ok := v.Recv()
+			if ok {
+				batch = reflect.Append(batch, row)
+			}
+			if (!ok && batch.Len() > 0) || batch.Len() == batchSize {
+				if err := writer.Write(batch.Interface()); err != nil {
+					return err
+				}
+				batch = batch.Slice(0, 0)
+			}
+			if !ok {
+				break
+			}
+		}
+
+		return writer.Close()
+	}
+
+	if v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && batchSize > 0 {
+
+		if v.Kind() == reflect.Array {
+			array := v
+			v = reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), v.Len(), v.Len())
+			reflect.Copy(v, array)
+		}
+
+		for start := 0; start < v.Len() || start == 0; start += batchSize {
+			end := start + batchSize
+			if end > v.Len() {
+				end = v.Len()
+			}
+			if err := writer.Write(v.Slice(start, end).Interface()); err != nil {
+				return err
+			}
+		}
+
+		return writer.Close()
+	}
+
+	if err := writer.Write(object); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Unmarshal converts an Arrow IPC stream into an object.
+func (c *ArrowCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	rows, err := unmarshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	// generic values can be set directly
+	switch target := obj.(type) {
+	case *interface{}:
+		*target = rows
+		return nil
+	case *[]map[string]interface{}:
+		*target = rows
+		return nil
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := json.Marshal(rows)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *ArrowCodec) ContentType() string {
+	return constants.ContentTypeArrow
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ArrowCodec) FileExtension() string {
+	return constants.FileExtensionArrow
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ArrowCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// Binary returns whether this codec's representation is binary.
+func (c *ArrowCodec) Binary() bool {
+	return true
+}
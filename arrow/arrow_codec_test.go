@@ -0,0 +1,306 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var arrowCodec ArrowCodec
+
+type resultRow struct {
+	ID      int64     `arrow:"id"`
+	Name    string    `json:"name"`
+	Score   float64   `arrow:"score"`
+	Ratio   float32   `arrow:"ratio"`
+	Small   int8      `arrow:"small"`
+	Count   uint32    `arrow:"count"`
+	Active  bool      `arrow:"active"`
+	Data    []byte    `arrow:"data"`
+	Created time.Time `arrow:"created"`
+	Note    *string   `arrow:"note"`
+	Secret  string    `arrow:"-"`
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(ArrowCodec), "ArrowCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeArrow, arrowCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionArrow, arrowCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, arrowCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal_Messages(t *testing.T) {
+
+	data, err := arrowCodec.Marshal([]resultRow{{ID: 1, Name: "Mat"}}, nil)
+
+	if assert.NoError(t, err) {
+
+		// schema message
+		assert.Equal(t, continuation, binary.LittleEndian.Uint32(data))
+		length := int(binary.LittleEndian.Uint32(data[4:]))
+		assert.Equal(t, 0, length%8)
+		schemaMessage := fbRoot(data[8 : 8+length])
+		assert.Equal(t, metadataVersionV5, schemaMessage.int16(0))
+		assert.Equal(t, headerSchema, schemaMessage.uint8(1))
+		assert.Equal(t, int64(0), schemaMessage.int64(3))
+
+		schema, _ := schemaMessage.table(2)
+		fields, err := readSchema(schema)
+		if assert.NoError(t, err) && assert.Equal(t, 10, len(fields)) {
+			assert.Equal(t, field{"id", dataType{id: typeInt, bitWidth: 64, signed: true}}, fields[0])
+			assert.Equal(t, field{"name", dataType{id: typeUtf8}}, fields[1])
+			assert.Equal(t, field{"ratio", dataType{id: typeFloatingPoint, precision: precisionSingle}}, fields[3])
+			assert.Equal(t, field{"count", dataType{id: typeInt, bitWidth: 32}}, fields[5])
+			assert.Equal(t, field{"created", dataType{id: typeTimestamp, unit: unitMicrosecond}}, fields[8])
+		}
+
+		// record batch message
+		pos := 8 + length
+		assert.Equal(t, continuation, binary.LittleEndian.Uint32(data[pos:]))
+		length = int(binary.LittleEndian.Uint32(data[pos+4:]))
+		batchMessage := fbRoot(data[pos+8 : pos+8+length])
+		assert.Equal(t, headerRecordBatch, batchMessage.uint8(1))
+		assert.Equal(t, 0, int(batchMessage.int64(3))%8)
+
+		// end of stream
+		assert.True(t, bytes.HasSuffix(data, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}))
+	}
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	note := "hello"
+	rows := []resultRow{
+		{ID: 1, Name: "Mat", Score: 1.5, Ratio: 0.25, Small: -3, Count: 7, Active: true, Data: []byte{1, 2},
+			Created: time.Date(2013, 4, 1, 12, 0, 0, 0, time.UTC), Note: &note, Secret: "s"},
+		{ID: 2, Name: "Tyler"},
+		{ID: 3, Name: "", Active: true, Note: &note},
+	}
+
+	data, err := arrowCodec.Marshal(rows, nil)
+
+	if assert.NoError(t, err) {
+
+		var generic interface{}
+		if assert.NoError(t, arrowCodec.Unmarshal(data, &generic)) {
+			back := generic.([]map[string]interface{})
+			if assert.Equal(t, 3, len(back)) {
+				assert.Equal(t, map[string]interface{}{
+					"id": int64(1), "name": "Mat", "score": 1.5, "ratio": float32(0.25), "small": int8(-3),
+					"count": uint32(7), "active": true, "data": []byte{1, 2},
+					"created": time.Date(2013, 4, 1, 12, 0, 0, 0, time.UTC), "note": "hello",
+				}, back[0])
+				assert.Nil(t, back[1]["note"])
+				assert.Equal(t, "", back[2]["name"])
+				assert.Equal(t, "hello", back[2]["note"])
+			}
+		}
+
+		var typed []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		if assert.NoError(t, arrowCodec.Unmarshal(data, &typed)) {
+			assert.Equal(t, int64(2), typed[1].ID)
+			assert.Equal(t, "Tyler", typed[1].Name)
+		}
+	}
+
+}
+
+func TestRoundTrip_Maps(t *testing.T) {
+
+	rows := []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30},
+		map[string]interface{}{"name": "Tyler", "score": 1.5},
+	}
+
+	data, err := arrowCodec.Marshal(rows, nil)
+
+	if assert.NoError(t, err) {
+		var back []map[string]interface{}
+		if assert.NoError(t, arrowCodec.Unmarshal(data, &back)) {
+			assert.Equal(t, []map[string]interface{}{
+				{"age": int64(30), "name": "Mat", "score": nil},
+				{"age": nil, "name": "Tyler", "score": 1.5},
+			}, back)
+		}
+	}
+
+}
+
+func TestEncode_Batches(t *testing.T) {
+
+	rows := make([]resultRow, 5)
+	for i := range rows {
+		rows[i].ID = int64(i)
+	}
+
+	data, err := arrowCodec.Marshal(rows, map[string]interface{}{OptionKeyBatchSize: 2})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, countBatches(data))
+		var back []resultRow
+		if assert.NoError(t, arrowCodec.Unmarshal(data, &back)) && assert.Equal(t, 5, len(back)) {
+			assert.Equal(t, int64(4), back[4].ID)
+		}
+	}
+
+}
+
+func TestEncode_Channel(t *testing.T) {
+
+	rows := make(chan *resultRow)
+	go func() {
+		for i := 0; i < 3; i++ {
+			rows <- &resultRow{ID: int64(i), Name: "row"}
+		}
+		close(rows)
+	}()
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, arrowCodec.Encode(&buffer, rows, map[string]interface{}{OptionKeyBatchSize: 2})) {
+		assert.Equal(t, 2, countBatches(buffer.Bytes()))
+		var back []map[string]interface{}
+		if assert.NoError(t, arrowCodec.Unmarshal(buffer.Bytes(), &back)) && assert.Equal(t, 3, len(back)) {
+			assert.Equal(t, int64(2), back[2]["id"])
+		}
+	}
+
+}
+
+func TestStreamWriter(t *testing.T) {
+
+	var buffer bytes.Buffer
+	writer := NewStreamWriter(&buffer)
+
+	assert.NoError(t, writer.Write([]map[string]interface{}{{"a": 1}}))
+	assert.NoError(t, writer.Write([]map[string]interface{}{}))
+	assert.NoError(t, writer.Write([]map[string]interface{}{{"a": 2}}))
+	assert.Equal(t, ErrorSchemaChanged, writer.Write([]map[string]interface{}{{"b": 2}}))
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, ErrorStreamClosed, writer.Write([]map[string]interface{}{{"a": 3}}))
+
+	var back []map[string]interface{}
+	if assert.NoError(t, arrowCodec.Unmarshal(buffer.Bytes(), &back)) {
+		assert.Equal(t, []map[string]interface{}{{"a": int64(1)}, {"a": int64(2)}}, back)
+	}
+
+}
+
+func TestMarshal_Empty(t *testing.T) {
+
+	data, err := arrowCodec.Marshal([]map[string]interface{}{}, nil)
+
+	if assert.NoError(t, err) {
+		var back []map[string]interface{}
+		if assert.NoError(t, arrowCodec.Unmarshal(data, &back)) {
+			assert.Equal(t, 0, len(back))
+		}
+	}
+
+}
+
+func TestMarshal_Errors(t *testing.T) {
+
+	_, err := arrowCodec.Marshal("nope", nil)
+	assert.Equal(t, ErrorNotTabular, err)
+
+	_, err = arrowCodec.Marshal([]map[string]interface{}{{"a": 1}, {"a": "one"}}, nil)
+	assert.Error(t, err)
+
+	_, err = arrowCodec.Marshal([]struct{ Tags []string }{{}}, nil)
+	assert.Error(t, err)
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var back []map[string]interface{}
+	assert.Equal(t, ErrorInvalidData, arrowCodec.Unmarshal([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x40, 0, 0, 0, 1, 2}, &back))
+	assert.Equal(t, ErrorInvalidData, arrowCodec.Unmarshal(nil, &back))
+
+	_, isInvalid := arrowCodec.Unmarshal(nil, back).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
+
+// countBatches counts the record batch messages in the stream.
+func countBatches(data []byte) int {
+
+	batches := 0
+	for pos := 0; pos+8 <= len(data); {
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if length == 0 {
+			break
+		}
+		message := fbRoot(data[pos+8 : pos+8+length])
+		if message.uint8(1) == headerRecordBatch {
+			batches++
+		}
+		pos += 8 + length + int(message.int64(3))
+	}
+
+	return batches
+}
+
+func TestUnmarshal_ImpossibleLengths(t *testing.T) {
+
+	data, err := arrowCodec.Marshal([]resultRow{{ID: 1, Name: "Mat"}}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// find the record batch header
+	pos := 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+	message := fbRoot(data[pos+8 : pos+8+length])
+	header, _ := message.table(2)
+
+	// a batch claiming more rows than its body can hold
+	rows := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(rows[pos+8+header.offset(0):], 1<<40)
+
+	var back []map[string]interface{}
+	assert.Equal(t, ErrorInvalidData, arrowCodec.Unmarshal(rows, &back))
+
+	// a buffer reaching past the body
+	buffersStart, _ := header.vector(2)
+	buffers := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(buffers[pos+8+buffersStart+8:], 1<<62)
+	assert.Equal(t, ErrorInvalidData, arrowCodec.Unmarshal(buffers, &back))
+
+	// a body longer than the stream
+	body := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(body[pos+8+message.offset(3):], 1<<62)
+	assert.Equal(t, ErrorInvalidData, arrowCodec.Unmarshal(body, &back))
+
+}
+
+func TestCheckLimits(t *testing.T) {
+
+	data, err := arrowCodec.Marshal([]resultRow{{ID: 1, Name: "Mat"}, {ID: 2, Name: "Tyler"}}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, arrowCodec.CheckLimits(data, codecs.DecodeLimits{MaxElements: 100, MaxDepth: 2, MaxStringLength: 5}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitElements, Max: 20}, arrowCodec.CheckLimits(data, codecs.DecodeLimits{MaxElements: 20}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 1}, arrowCodec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 1}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitStringLength, Max: 4}, arrowCodec.CheckLimits(data, codecs.DecodeLimits{MaxStringLength: 4}))
+
+}
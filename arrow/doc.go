@@ -0,0 +1,32 @@
+// A codec for handling the Apache Arrow IPC stream format.
+//
+// Marshal writes a schema message followed by record batches (and the end of
+// stream marker) from a slice of structs or maps.  Struct columns are named by
+// the `arrow` struct tag, falling back on the `json` tag and then the field
+// name, and pointer fields are nullable.  Slices of maps (such as facade
+// public data) use the sorted keys as nullable columns typed by their values.
+//
+//	bool                         Bool
+//	int and uint types           Int (of the same width and signedness)
+//	float32, float64             FloatingPoint (SINGLE, DOUBLE)
+//	string                       Utf8
+//	[]byte                       Binary
+//	time.Time                    Timestamp (MICROSECOND, UTC)
+//
+// Large or open ended results can be written without holding them all in
+// memory using Encode (which also accepts a channel of rows) or a
+// StreamWriter, which writes a record batch per call:
+//
+//	writer := arrow.NewStreamWriter(response)
+//	for rows := range results {
+//		if err := writer.Write(rows); err != nil {
+//			return err
+//		}
+//	}
+//	return writer.Close()
+//
+// Unmarshal reads streams using the types above into a
+// []map[string]interface{} when unmarshalling into an interface{}; any other
+// target uses encoding/json's rules.  Dictionary batches and compressed bodies
+// are not supported.
+package arrow
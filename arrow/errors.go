@@ -0,0 +1,39 @@
+package arrow
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrorNotTabular is returned when marshalling something other than a
+	// slice of structs or maps.
+	ErrorNotTabular = errors.New("codecs: arrow: Only slices of structs or maps can be marshalled")
+
+	// ErrorSchemaChanged is returned when a batch written to a StreamWriter
+	// has different columns to the batches before it.
+	ErrorSchemaChanged = errors.New("codecs: arrow: Batch columns do not match the stream's schema")
+
+	// ErrorStreamClosed is returned when writing to a closed StreamWriter.
+	ErrorStreamClosed = errors.New("codecs: arrow: Stream is closed")
+
+	// ErrorInvalidData is returned when the data is not a valid Arrow stream.
+	ErrorInvalidData = errors.New("codecs: arrow: Data is not a valid Arrow stream")
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: arrow: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: arrow: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: arrow: Unmarshal(nil " + e.Type.String() + ")"
+}
@@ -0,0 +1,297 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Arrow's IPC metadata is stored as flatbuffers.  Only the parts of the format
+// needed for Arrow messages are implemented here.
+
+// fbTable is a table being written.
+type fbTable struct {
+	fields []fbField
+}
+
+// fbField is a table field.  The value can be a bool, uint8, int16, int32,
+// int64, string, *fbTable, []*fbTable or fbStructs.
+type fbField struct {
+	slot  int
+	value interface{}
+}
+
+// fbStructs is a vector of structs, already laid out.
+type fbStructs struct {
+	align int
+	count int
+	data  []byte
+}
+
+// add sets the value of the field in the slot.
+func (t *fbTable) add(slot int, value interface{}) *fbTable {
+	t.fields = append(t.fields, fbField{slot, value})
+	return t
+}
+
+// buildFlatbuffer lays out the table as a flatbuffer.  Unlike the usual
+// builders this writes front to back, with each table followed by the
+// objects it refers to, which keeps every offset pointing forward.
+func buildFlatbuffer(root *fbTable) []byte {
+
+	b := new(fbBuilder)
+	at := b.placeholder()
+	b.patch(at, b.table(root))
+	b.pad(8)
+
+	return b.buf
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// placeholder reserves space for an offset to be patched later.
+func (b *fbBuilder) placeholder() int {
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	return len(b.buf) - 4
+}
+
+// patch points the offset at the target.
+func (b *fbBuilder) patch(at, target int) {
+	binary.LittleEndian.PutUint32(b.buf[at:], uint32(target-at))
+}
+
+func fbSize(value interface{}) int {
+	switch value.(type) {
+	case bool, uint8:
+		return 1
+	case int16:
+		return 2
+	case int64:
+		return 8
+	}
+	// int32 and offsets
+	return 4
+}
+
+// table writes the table's vtable, the table and then its children,
+// returning the position of the table.
+func (b *fbBuilder) table(t *fbTable) int {
+
+	fields := make([]fbField, len(t.fields))
+	copy(fields, t.fields)
+	sort.SliceStable(fields, func(i, j int) bool { return fbSize(fields[i].value) > fbSize(fields[j].value) })
+
+	// lay the fields out after the vtable offset
+	offsets := make([]int, len(fields))
+	size, slots := 4, 0
+	for i, f := range fields {
+		n := fbSize(f.value)
+		for size%n != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += n
+		if f.slot+1 > slots {
+			slots = f.slot + 1
+		}
+	}
+
+	b.pad(2)
+	vtable := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+2*slots)...)
+	binary.LittleEndian.PutUint16(b.buf[vtable:], uint16(4+2*slots))
+	binary.LittleEndian.PutUint16(b.buf[vtable+2:], uint16(size))
+	for i, f := range fields {
+		binary.LittleEndian.PutUint16(b.buf[vtable+4+2*f.slot:], uint16(offsets[i]))
+	}
+
+	b.pad(8)
+	table := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[table:], uint32(int32(table-vtable)))
+
+	var references []int
+	for i, f := range fields {
+		at := b.buf[table+offsets[i]:]
+		switch v := f.value.(type) {
+		case bool:
+			if v {
+				at[0] = 1
+			}
+		case uint8:
+			at[0] = v
+		case int16:
+			binary.LittleEndian.PutUint16(at, uint16(v))
+		case int32:
+			binary.LittleEndian.PutUint32(at, uint32(v))
+		case int64:
+			binary.LittleEndian.PutUint64(at, uint64(v))
+		default:
+			references = append(references, i)
+		}
+	}
+
+	for _, i := range references {
+		at := table + offsets[i]
+		switch v := fields[i].value.(type) {
+		case string:
+			b.patch(at, b.str(v))
+		case *fbTable:
+			b.patch(at, b.table(v))
+		case []*fbTable:
+			b.patch(at, b.tables(v))
+		case fbStructs:
+			b.patch(at, b.structs(v))
+		}
+	}
+
+	return table
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (b *fbBuilder) str(s string) int {
+	b.pad(4)
+	at := len(b.buf)
+	b.buf = appendUint32(b.buf, uint32(len(s)))
+	b.buf = append(append(b.buf, s...), 0)
+	return at
+}
+
+func (b *fbBuilder) tables(tables []*fbTable) int {
+
+	b.pad(4)
+	at := len(b.buf)
+	b.buf = appendUint32(b.buf, uint32(len(tables)))
+	for range tables {
+		b.placeholder()
+	}
+
+	for i, t := range tables {
+		b.patch(at+4+4*i, b.table(t))
+	}
+
+	return at
+}
+
+func (b *fbBuilder) structs(s fbStructs) int {
+
+	// the structs (rather than the length) need to be aligned
+	b.pad(4)
+	for (len(b.buf)+4)%s.align != 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+
+	at := len(b.buf)
+	b.buf = appendUint32(b.buf, uint32(s.count))
+	b.buf = append(b.buf, s.data...)
+
+	return at
+}
+
+// fbRef refers to a table in a flatbuffer being read.  Reads outside the
+// buffer panic, so callers are expected to recover.
+type fbRef struct {
+	buf []byte
+	pos int
+}
+
+// fbRoot gets the root table of the flatbuffer.
+func fbRoot(buf []byte) fbRef {
+	return fbRef{buf, int(binary.LittleEndian.Uint32(buf))}
+}
+
+// offset gets the position of the field in the slot, or 0 if it isn't
+// present.
+func (t fbRef) offset(slot int) int {
+
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	entry := 4 + 2*slot
+
+	if entry+2 > int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0
+	}
+
+	if o := int(binary.LittleEndian.Uint16(t.buf[vtable+entry:])); o != 0 {
+		return t.pos + o
+	}
+
+	return 0
+}
+
+func (t fbRef) uint8(slot int) uint8 {
+	if at := t.offset(slot); at != 0 {
+		return t.buf[at]
+	}
+	return 0
+}
+
+func (t fbRef) bool(slot int) bool {
+	return t.uint8(slot) != 0
+}
+
+func (t fbRef) int16(slot int) int16 {
+	if at := t.offset(slot); at != 0 {
+		return int16(binary.LittleEndian.Uint16(t.buf[at:]))
+	}
+	return 0
+}
+
+func (t fbRef) int32(slot int) int32 {
+	if at := t.offset(slot); at != 0 {
+		return int32(binary.LittleEndian.Uint32(t.buf[at:]))
+	}
+	return 0
+}
+
+func (t fbRef) int64(slot int) int64 {
+	if at := t.offset(slot); at != 0 {
+		return int64(binary.LittleEndian.Uint64(t.buf[at:]))
+	}
+	return 0
+}
+
+// follow gets the position the offset at the position refers to.
+func (t fbRef) follow(at int) int {
+	return at + int(binary.LittleEndian.Uint32(t.buf[at:]))
+}
+
+func (t fbRef) table(slot int) (fbRef, bool) {
+	if at := t.offset(slot); at != 0 {
+		return fbRef{t.buf, t.follow(at)}, true
+	}
+	return fbRef{}, false
+}
+
+func (t fbRef) str(slot int) string {
+	if at := t.offset(slot); at != 0 {
+		s := t.follow(at)
+		length := int(binary.LittleEndian.Uint32(t.buf[s:]))
+		return string(t.buf[s+4 : s+4+length])
+	}
+	return ""
+}
+
+// vector gets the position of the first element of the vector and its
+// length.
+func (t fbRef) vector(slot int) (int, int) {
+	if at := t.offset(slot); at != 0 {
+		v := t.follow(at)
+		return v + 4, int(binary.LittleEndian.Uint32(t.buf[v:]))
+	}
+	return 0, 0
+}
+
+// tableAt gets the table in a vector of tables.
+func (t fbRef) tableAt(start, i int) fbRef {
+	return fbRef{t.buf, t.follow(start + 4*i)}
+}
@@ -0,0 +1,61 @@
+package arrow
+
+import (
+	"github.com/stretchr/codecs"
+)
+
+// CheckLimits checks that the Arrow stream doesn't exceed the limits, reading
+// the sizes of its record batches without decoding their rows.  The rows form
+// an array of objects, each holding a value for every column, and utf8 and
+// binary values count towards the string length limit.
+func (c *ArrowCodec) CheckLimits(data []byte, limits codecs.DecodeLimits) error {
+
+	checker := codecs.LimitChecker{Limits: limits}
+
+	// the array of rows
+	elements := int64(1)
+
+	err := readStream(data, func(header fbRef, body []byte, fields []field) error {
+
+		length, columns, err := readColumns(header, body, fields)
+		if err != nil {
+			return err
+		}
+
+		if length == 0 {
+			return nil
+		}
+
+		// each row, and the key and value of each of its columns
+		elements += int64(length) * int64(1+2*len(fields))
+		if limits.MaxElements > 0 && elements > int64(limits.MaxElements) {
+			return &codecs.DecodeLimitError{Limit: codecs.LimitElements, Max: limits.MaxElements}
+		}
+
+		// rows are objects in the array
+		if limits.MaxDepth > 0 && limits.MaxDepth < 2 {
+			return &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: limits.MaxDepth}
+		}
+
+		for _, column := range columns {
+			if column.offsets == nil {
+				continue
+			}
+			for i := 0; i < length; i++ {
+				if start, end := column.span(i); column.valid(i) {
+					if err := checker.String(end - start); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+
+	// malformed data is left for Unmarshal to report
+	if _, exceeded := err.(*codecs.DecodeLimitError); exceeded {
+		return err
+	}
+	return nil
+}
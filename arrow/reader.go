@@ -0,0 +1,334 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// field describes a column being read.
+type field struct {
+	name     string
+	dataType dataType
+}
+
+// unmarshal reads the rows in the Arrow stream.
+func unmarshal(data []byte) ([]map[string]interface{}, error) {
+
+	rows := []map[string]interface{}{}
+
+	err := readStream(data, func(header fbRef, body []byte, fields []field) error {
+		batch, err := readBatch(header, body, fields)
+		rows = append(rows, batch...)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// readStream reads the messages in the Arrow stream, calling batch with the
+// header and body of each record batch and the fields of the schema before
+// it.
+func readStream(data []byte, batch func(header fbRef, body []byte, fields []field) error) (err error) {
+
+	// the flatbuffer reads panic when the offsets don't make sense
+	defer func() {
+		if recover() != nil {
+			err = ErrorInvalidData
+		}
+	}()
+
+	var fields []field
+	pos := 0
+
+	for pos+4 <= len(data) {
+
+		marker := binary.LittleEndian.Uint32(data[pos:])
+		pos += 4
+
+		// streams from before 0.15 don't have the continuation marker
+		if marker == continuation {
+			if pos+4 > len(data) {
+				return ErrorInvalidData
+			}
+			marker = binary.LittleEndian.Uint32(data[pos:])
+			pos += 4
+		}
+		length := int(marker)
+
+		if length == 0 {
+			// end of stream
+			break
+		}
+
+		if length > len(data)-pos {
+			return ErrorInvalidData
+		}
+		message := fbRoot(data[pos : pos+length])
+		pos += length
+
+		bodyLength := message.int64(3)
+		if bodyLength < 0 || bodyLength > int64(len(data)-pos) {
+			return ErrorInvalidData
+		}
+		body := data[pos : pos+int(bodyLength)]
+		pos += int(bodyLength)
+
+		header, ok := message.table(2)
+		if !ok {
+			return ErrorInvalidData
+		}
+
+		switch message.uint8(1) {
+		case headerSchema:
+			if fields, err = readSchema(header); err != nil {
+				return err
+			}
+		case headerRecordBatch:
+			if fields == nil {
+				return ErrorInvalidData
+			}
+			if err := batch(header, body, fields); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("codecs: arrow: Message type %d is not supported", message.uint8(1))
+		}
+	}
+
+	if fields == nil {
+		return ErrorInvalidData
+	}
+
+	return nil
+}
+
+func readSchema(schema fbRef) ([]field, error) {
+
+	fields := []field{}
+	start, count := schema.vector(1)
+
+	for i := 0; i < count; i++ {
+
+		f := schema.tableAt(start, i)
+		if _, children := f.vector(5); children > 0 {
+			return nil, fmt.Errorf("codecs: arrow: Nested fields are not supported")
+		}
+		if _, dictionary := f.table(4); dictionary {
+			return nil, fmt.Errorf("codecs: arrow: Dictionary encoded fields are not supported")
+		}
+
+		typeTable, _ := f.table(3)
+		dt := dataType{id: f.uint8(2)}
+		switch dt.id {
+		case typeInt:
+			dt.bitWidth = typeTable.int32(0)
+			dt.signed = typeTable.bool(1)
+		case typeFloatingPoint:
+			dt.precision = typeTable.int16(0)
+			if dt.precision != precisionSingle && dt.precision != precisionDouble {
+				return nil, fmt.Errorf("codecs: arrow: Half precision floats are not supported")
+			}
+		case typeTimestamp:
+			dt.unit = typeTable.int16(0)
+		case typeBool, typeUtf8, typeBinary:
+		default:
+			return nil, fmt.Errorf("codecs: arrow: Type %d of field %q is not supported", dt.id, f.str(0))
+		}
+
+		fields = append(fields, field{f.str(0), dt})
+	}
+
+	return fields, nil
+}
+
+// batchColumn holds the buffers of a column of a record batch.
+type batchColumn struct {
+	field
+
+	// nulls is the number of null values, validity their bitmap, offsets
+	// the offsets of variable width values and values the values.
+	nulls                     uint64
+	validity, offsets, values []byte
+}
+
+// valid gets whether the value in the row isn't null.
+func (c *batchColumn) valid(i int) bool {
+	return c.nulls == 0 || len(c.validity) == 0 || c.validity[i/8]&(1<<uint(i%8)) != 0
+}
+
+// span gets the start and end of the variable width value in the row.
+func (c *batchColumn) span(i int) (int, int) {
+	return int(binary.LittleEndian.Uint32(c.offsets[4*i:])), int(binary.LittleEndian.Uint32(c.offsets[4*i+4:]))
+}
+
+// readColumns reads the number of rows in the record batch and the buffers of
+// its columns, checking that they fit in the body and hold as many values as
+// there are rows, so that nothing is allocated for rows the body can't hold.
+func readColumns(batch fbRef, body []byte, fields []field) (int, []batchColumn, error) {
+
+	if _, compressed := batch.table(3); compressed {
+		return 0, nil, fmt.Errorf("codecs: arrow: Compressed record batches are not supported")
+	}
+
+	// even a column of bools needs a bit for each row
+	length := batch.int64(0)
+	if length < 0 || length > 8*int64(len(body)) {
+		return 0, nil, ErrorInvalidData
+	}
+	rows := int(length)
+
+	nodesStart, nodeCount := batch.vector(1)
+	buffersStart, bufferCount := batch.vector(2)
+
+	if nodeCount != len(fields) {
+		return 0, nil, ErrorInvalidData
+	}
+
+	// buffer gets the body of the next buffer, if it fits in the body
+	next := 0
+	buffer := func() ([]byte, bool) {
+		if next >= bufferCount {
+			return nil, false
+		}
+		at := buffersStart + 16*next
+		next++
+		offset := binary.LittleEndian.Uint64(batch.buf[at:])
+		size := binary.LittleEndian.Uint64(batch.buf[at+8:])
+		if offset > uint64(len(body)) || size > uint64(len(body))-offset {
+			return nil, false
+		}
+		return body[offset : offset+size], true
+	}
+
+	bitmap := (rows + 7) / 8
+	columns := make([]batchColumn, len(fields))
+
+	for n, f := range fields {
+
+		column := &columns[n]
+		column.field = f
+		column.nulls = binary.LittleEndian.Uint64(batch.buf[nodesStart+16*n+8:])
+
+		var ok bool
+		if column.validity, ok = buffer(); !ok || (column.nulls != 0 && len(column.validity) != 0 && len(column.validity) < bitmap) {
+			return 0, nil, ErrorInvalidData
+		}
+
+		if f.dataType.id == typeUtf8 || f.dataType.id == typeBinary {
+			if column.offsets, ok = buffer(); !ok || (rows > 0 && len(column.offsets) < 4*(rows+1)) {
+				return 0, nil, ErrorInvalidData
+			}
+		}
+
+		if column.values, ok = buffer(); !ok {
+			return 0, nil, ErrorInvalidData
+		}
+
+		switch f.dataType.id {
+		case typeBool:
+			if len(column.values) < bitmap {
+				return 0, nil, ErrorInvalidData
+			}
+		case typeUtf8, typeBinary:
+			for i := 0; i < rows; i++ {
+				if start, end := column.span(i); start > end || end > len(column.values) {
+					return 0, nil, ErrorInvalidData
+				}
+			}
+		default:
+			if len(column.values) < rows*f.dataType.width() {
+				return 0, nil, ErrorInvalidData
+			}
+		}
+	}
+
+	return rows, columns, nil
+}
+
+func readBatch(batch fbRef, body []byte, fields []field) ([]map[string]interface{}, error) {
+
+	length, columns, err := readColumns(batch, body, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]interface{}, length)
+	for i := range rows {
+		rows[i] = make(map[string]interface{}, len(fields))
+	}
+
+	for _, c := range columns {
+
+		for i := 0; i < length; i++ {
+
+			if !c.valid(i) {
+				rows[i][c.name] = nil
+				continue
+			}
+
+			switch c.dataType.id {
+			case typeBool:
+				rows[i][c.name] = c.values[i/8]&(1<<uint(i%8)) != 0
+			case typeUtf8, typeBinary:
+				start, end := c.span(i)
+				b := make([]byte, end-start)
+				copy(b, c.values[start:end])
+				if c.dataType.id == typeUtf8 {
+					rows[i][c.name] = string(b)
+				} else {
+					rows[i][c.name] = b
+				}
+			default:
+				width := c.dataType.width()
+				rows[i][c.name] = readValue(c.values[i*width:(i+1)*width], c.dataType)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// readValue reads the fixed width value.
+func readValue(at []byte, dt dataType) interface{} {
+
+	var bits uint64
+	for i := range at {
+		bits |= uint64(at[i]) << uint(8*i)
+	}
+
+	switch dt.id {
+	case typeFloatingPoint:
+		if dt.precision == precisionSingle {
+			return math.Float32frombits(uint32(bits))
+		}
+		return math.Float64frombits(bits)
+	case typeTimestamp:
+		units := map[int16]time.Duration{unitSecond: time.Second, unitMillisecond: time.Millisecond, unitMicrosecond: time.Microsecond, unitNanosecond: time.Nanosecond}
+		return time.Unix(0, int64(bits)*int64(units[dt.unit])).UTC()
+	}
+
+	switch {
+	case dt.signed && dt.bitWidth == 8:
+		return int8(bits)
+	case dt.signed && dt.bitWidth == 16:
+		return int16(bits)
+	case dt.signed && dt.bitWidth == 32:
+		return int32(bits)
+	case dt.signed:
+		return int64(bits)
+	case dt.bitWidth == 8:
+		return uint8(bits)
+	case dt.bitWidth == 16:
+		return uint16(bits)
+	case dt.bitWidth == 32:
+		return uint32(bits)
+	}
+
+	return bits
+}
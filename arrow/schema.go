@@ -0,0 +1,340 @@
+package arrow
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Arrow type ids (the Type union in Schema.fbs).
+const (
+	typeInt           uint8 = 2
+	typeFloatingPoint uint8 = 3
+	typeBinary        uint8 = 4
+	typeUtf8          uint8 = 5
+	typeBool          uint8 = 6
+	typeTimestamp     uint8 = 10
+)
+
+// Floating point precisions and time units.
+const (
+	precisionSingle int16 = 1
+	precisionDouble int16 = 2
+	unitSecond      int16 = 0
+	unitMillisecond int16 = 1
+	unitMicrosecond int16 = 2
+	unitNanosecond  int16 = 3
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// dataType describes an Arrow type.
+type dataType struct {
+	id        uint8
+	bitWidth  int32
+	signed    bool
+	precision int16
+	unit      int16
+}
+
+// width gets the size in bytes of the type's values, or 0 for bools and
+// variable width types.
+func (t dataType) width() int {
+	switch t.id {
+	case typeInt:
+		return int(t.bitWidth / 8)
+	case typeFloatingPoint:
+		if t.precision == precisionSingle {
+			return 4
+		}
+		return 8
+	case typeTimestamp:
+		return 8
+	}
+	return 0
+}
+
+// column describes a column being written.
+type column struct {
+	name     string
+	dataType dataType
+	nullable bool
+
+	// value gets the column's value from the row, or nil if it is null.
+	// Values are bool, int64, uint64, float64, string or []byte.
+	value func(row reflect.Value) (interface{}, error)
+}
+
+// sameColumns gets whether the columns describe the same schema.
+func sameColumns(a, b []column) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].name != b[i].name || a[i].dataType != b[i].dataType || a[i].nullable != b[i].nullable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// typeOf gets the Arrow type for values of the Go type.  Maps widen their
+// values to 64 bits so mixed rows still agree.
+func typeOf(t reflect.Type, widen bool) (dataType, bool) {
+
+	if t == timeType {
+		return dataType{id: typeTimestamp, unit: unitMicrosecond}, true
+	}
+
+	bits := int32(t.Size() * 8)
+	if widen {
+		bits = 64
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return dataType{id: typeBool}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return dataType{id: typeInt, bitWidth: bits, signed: true}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return dataType{id: typeInt, bitWidth: bits}, true
+	case reflect.Float32:
+		if !widen {
+			return dataType{id: typeFloatingPoint, precision: precisionSingle}, true
+		}
+		return dataType{id: typeFloatingPoint, precision: precisionDouble}, true
+	case reflect.Float64:
+		return dataType{id: typeFloatingPoint, precision: precisionDouble}, true
+	case reflect.String:
+		return dataType{id: typeUtf8}, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return dataType{id: typeBinary}, true
+		}
+	}
+
+	return dataType{}, false
+}
+
+// normalize gets the value to write.
+func normalize(v reflect.Value) interface{} {
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UnixNano() / int64(time.Microsecond)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+
+	return v.Bytes()
+}
+
+// fieldName gets the column name for the struct field, or "-" if it should
+// be skipped.
+func fieldName(sf reflect.StructField) string {
+
+	tag := sf.Tag.Get("arrow")
+	if len(tag) == 0 {
+		tag = sf.Tag.Get("json")
+	}
+
+	if name := strings.Split(tag, ",")[0]; len(name) > 0 {
+		return name
+	}
+
+	return sf.Name
+}
+
+// structColumns gets the columns for rows of the struct type.
+func structColumns(t reflect.Type) ([]column, error) {
+
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		name := fieldName(sf)
+		if len(sf.PkgPath) > 0 || name == "-" {
+			continue
+		}
+
+		fieldType := sf.Type
+		nullable := fieldType.Kind() == reflect.Ptr
+		if nullable {
+			fieldType = fieldType.Elem()
+		}
+
+		dt, ok := typeOf(fieldType, false)
+		if !ok {
+			return nil, fmt.Errorf("codecs: arrow: Cannot write field %s of type %s", sf.Name, sf.Type)
+		}
+
+		index := i
+		columns = append(columns, column{
+			name:     name,
+			dataType: dt,
+			nullable: nullable,
+			value: func(row reflect.Value) (interface{}, error) {
+				v := row.Field(index)
+				if v.Kind() == reflect.Ptr {
+					if v.IsNil() {
+						return nil, nil
+					}
+					v = v.Elem()
+				}
+				return normalize(v), nil
+			},
+		})
+	}
+
+	return columns, nil
+}
+
+// mapColumns gets the (nullable) columns for rows of maps, with the types
+// taken from the values.
+func mapColumns(rows []reflect.Value) ([]column, error) {
+
+	types := map[string]reflect.Type{}
+	var names []string
+	for _, row := range rows {
+		for _, key := range row.MapKeys() {
+
+			name := key.String()
+			if _, seen := types[name]; !seen {
+				names = append(names, name)
+				types[name] = nil
+			}
+
+			if value := indirect(row.MapIndex(key)); value.IsValid() && types[name] == nil {
+				types[name] = value.Type()
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	var columns []column
+	for _, name := range names {
+
+		dt := dataType{id: typeUtf8}
+		if t := types[name]; t != nil {
+			var ok bool
+			if dt, ok = typeOf(t, true); !ok {
+				return nil, fmt.Errorf("codecs: arrow: Cannot write %q values of type %s", name, t)
+			}
+		}
+
+		key := reflect.ValueOf(name)
+		columnName, columnType := name, dt
+		columns = append(columns, column{
+			name:     name,
+			dataType: dt,
+			nullable: true,
+			value: func(row reflect.Value) (interface{}, error) {
+				v := indirect(row.MapIndex(key.Convert(row.Type().Key())))
+				if !v.IsValid() {
+					return nil, nil
+				}
+				if dt, _ := typeOf(v.Type(), true); dt != columnType {
+					return nil, fmt.Errorf("codecs: arrow: Column %q has mixed types", columnName)
+				}
+				return normalize(v), nil
+			},
+		})
+	}
+
+	return columns, nil
+}
+
+// indirect follows pointers and interfaces, returning an invalid value for
+// nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// tabulate gets the rows of the object, along with the columns for its
+// element type.
+func tabulate(object interface{}) ([]reflect.Value, []column, error) {
+
+	v := indirect(reflect.ValueOf(object))
+
+	if !v.IsValid() {
+		return nil, nil, ErrorNotTabular
+	}
+
+	var rows []reflect.Value
+	var elementType reflect.Type
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		rows = []reflect.Value{v}
+		elementType = v.Type()
+	case reflect.Slice, reflect.Array:
+		elementType = v.Type().Elem()
+		for elementType.Kind() == reflect.Ptr {
+			elementType = elementType.Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			row := indirect(v.Index(i))
+			if !row.IsValid() {
+				return nil, nil, ErrorNotTabular
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, nil, ErrorNotTabular
+	}
+
+	// interface{} elements get their type from the rows
+	if elementType.Kind() == reflect.Interface && len(rows) > 0 {
+		elementType = rows[0].Type()
+	}
+
+	for _, row := range rows {
+		if row.Type() != elementType && (row.Kind() != reflect.Map || elementType.Kind() != reflect.Map) {
+			return nil, nil, ErrorNotTabular
+		}
+	}
+
+	switch elementType.Kind() {
+	case reflect.Struct:
+		columns, err := structColumns(elementType)
+		return rows, columns, err
+	case reflect.Map:
+		for _, row := range rows {
+			if row.Type().Key().Kind() != reflect.String {
+				return nil, nil, ErrorNotTabular
+			}
+		}
+		if len(rows) == 0 {
+			// the columns can't be known yet
+			return rows, nil, nil
+		}
+		columns, err := mapColumns(rows)
+		return rows, columns, err
+	case reflect.Interface:
+		return rows, nil, nil
+	}
+
+	return nil, nil, ErrorNotTabular
+}
@@ -0,0 +1,255 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Arrow message header types and the metadata version written.
+const (
+	headerSchema      uint8 = 1
+	headerRecordBatch uint8 = 3
+	metadataVersionV5 int16 = 4
+)
+
+// continuation starts every encapsulated message.
+const continuation uint32 = 0xFFFFFFFF
+
+// StreamWriter writes rows to an Arrow IPC stream, one record batch per call
+// to Write.  The schema is taken from the first batch, and every batch after
+// it must have the same columns.
+type StreamWriter struct {
+	w       io.Writer
+	columns []column
+	started bool
+	closed  bool
+}
+
+// NewStreamWriter makes a new StreamWriter that writes to w.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// Write writes the rows (a slice of structs or maps) as a record batch,
+// writing the schema first if this is the first batch.
+func (s *StreamWriter) Write(rows interface{}) error {
+
+	if s.closed {
+		return ErrorStreamClosed
+	}
+
+	values, columns, err := tabulate(rows)
+
+	if err != nil {
+		return err
+	}
+
+	if columns == nil && len(values) == 0 {
+		// nothing to write, and nothing learnt about the schema
+		return nil
+	}
+
+	if !s.started {
+		if err := s.writeSchema(columns); err != nil {
+			return err
+		}
+	} else if !sameColumns(s.columns, columns) {
+		return ErrorSchemaChanged
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return s.writeBatch(values)
+}
+
+// Close finishes the stream, writing an (empty) schema if no batches were
+// written.  It does not close the underlying writer.
+func (s *StreamWriter) Close() error {
+
+	if s.closed {
+		return nil
+	}
+
+	if !s.started {
+		if err := s.writeSchema(nil); err != nil {
+			return err
+		}
+	}
+
+	s.closed = true
+
+	return writeUint32s(s.w, continuation, 0)
+}
+
+func (s *StreamWriter) writeSchema(columns []column) error {
+
+	fields := []*fbTable{}
+	for _, c := range columns {
+
+		var typeTable *fbTable
+		switch c.dataType.id {
+		case typeInt:
+			typeTable = new(fbTable).add(0, c.dataType.bitWidth).add(1, c.dataType.signed)
+		case typeFloatingPoint:
+			typeTable = new(fbTable).add(0, c.dataType.precision)
+		case typeTimestamp:
+			typeTable = new(fbTable).add(0, c.dataType.unit).add(1, "UTC")
+		default:
+			typeTable = new(fbTable)
+		}
+
+		fields = append(fields, new(fbTable).
+			add(0, c.name).
+			add(1, c.nullable).
+			add(2, c.dataType.id).
+			add(3, typeTable).
+			add(5, []*fbTable{}))
+	}
+
+	s.columns = columns
+	s.started = true
+
+	return writeMessage(s.w, headerSchema, new(fbTable).add(0, int16(0)).add(1, fields), nil)
+}
+
+func (s *StreamWriter) writeBatch(rows []reflect.Value) error {
+
+	var body, nodes, buffers []byte
+
+	// addBuffer adds a buffer to the body, padded to 8 bytes.
+	addBuffer := func(data []byte) {
+		buffers = appendUint64s(buffers, uint64(len(body)), uint64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	for _, c := range s.columns {
+
+		values := make([]interface{}, len(rows))
+		validity := make([]byte, (len(rows)+7)/8)
+		nulls := 0
+		for i, row := range rows {
+			value, err := c.value(row)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				nulls++
+				continue
+			}
+			values[i] = value
+			validity[i/8] |= 1 << uint(i%8)
+		}
+
+		nodes = appendUint64s(nodes, uint64(len(rows)), uint64(nulls))
+		if nulls > 0 {
+			addBuffer(validity)
+		} else {
+			addBuffer(nil)
+		}
+
+		switch c.dataType.id {
+		case typeBool:
+			bits := make([]byte, (len(rows)+7)/8)
+			for i, value := range values {
+				if value == true {
+					bits[i/8] |= 1 << uint(i%8)
+				}
+			}
+			addBuffer(bits)
+		case typeUtf8, typeBinary:
+			offsets := make([]byte, 0, 4*(len(rows)+1))
+			var data []byte
+			offsets = appendUint32(offsets, 0)
+			for _, value := range values {
+				switch v := value.(type) {
+				case string:
+					data = append(data, v...)
+				case []byte:
+					data = append(data, v...)
+				}
+				offsets = appendUint32(offsets, uint32(len(data)))
+			}
+			addBuffer(offsets)
+			addBuffer(data)
+		default:
+			width := c.dataType.width()
+			data := make([]byte, width*len(rows))
+			for i, value := range values {
+				putValue(data[i*width:(i+1)*width], c.dataType, value)
+			}
+			addBuffer(data)
+		}
+	}
+
+	header := new(fbTable).
+		add(0, int64(len(rows))).
+		add(1, fbStructs{align: 8, count: len(nodes) / 16, data: nodes}).
+		add(2, fbStructs{align: 8, count: len(buffers) / 16, data: buffers})
+
+	return writeMessage(s.w, headerRecordBatch, header, body)
+}
+
+// putValue writes the fixed width value.
+func putValue(at []byte, dt dataType, value interface{}) {
+
+	var bits uint64
+	switch v := value.(type) {
+	case nil:
+		return
+	case int64:
+		bits = uint64(v)
+	case uint64:
+		bits = v
+	case float64:
+		if dt.precision == precisionSingle {
+			bits = uint64(math.Float32bits(float32(v)))
+		} else {
+			bits = math.Float64bits(v)
+		}
+	}
+
+	for i := range at {
+		at[i] = byte(bits >> uint(8*i))
+	}
+
+}
+
+// writeMessage writes an encapsulated message.
+func writeMessage(w io.Writer, headerType uint8, header *fbTable, body []byte) error {
+
+	metadata := buildFlatbuffer(new(fbTable).
+		add(0, metadataVersionV5).
+		add(1, headerType).
+		add(2, header).
+		add(3, int64(len(body))))
+
+	if err := writeUint32s(w, continuation, uint32(len(metadata))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(metadata); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body)
+
+	return err
+}
+
+func writeUint32s(w io.Writer, values ...uint32) error {
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+func appendUint64s(buf []byte, values ...uint64) []byte {
+	for _, v := range values {
+		buf = appendUint32(appendUint32(buf, uint32(v)), uint32(v>>32))
+	}
+	return buf
+}
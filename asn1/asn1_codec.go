@@ -0,0 +1,143 @@
+package asn1
+
+import (
+	asn1Encoding "encoding/asn1"
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// OptionKeyStructure is the option key for the name of the registered
+// structure to marshal maps as, overriding the codec's Structure.
+const OptionKeyStructure = "asn1.structure"
+
+// Asn1Codec converts objects to and from DER encoded ASN.1.
+type Asn1Codec struct {
+
+	// Structure is the name of the registered structure this codec expects.
+	Structure string
+}
+
+func init() {
+	codecs.Register(new(Asn1Codec))
+}
+
+// Marshal converts an object to DER.  Maps are converted to the selected
+// structure first.
+func (c *Asn1Codec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	name, ok := options[OptionKeyStructure].(string)
+	if !ok {
+		name = c.Structure
+	}
+
+	v := reflect.ValueOf(object)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.IsValid() && v.Kind() == reflect.Map && len(name) > 0 {
+
+		t, err := structure(name)
+		if err != nil {
+			return nil, err
+		}
+
+		jsonData, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+
+		value := reflect.New(t)
+		if err := json.Unmarshal(jsonData, value.Interface()); err != nil {
+			return nil, err
+		}
+		v = value.Elem()
+	}
+
+	if !v.IsValid() {
+		return asn1Encoding.Marshal(asn1Encoding.NullRawValue)
+	}
+
+	return asn1Encoding.Marshal(v.Interface())
+}
+
+// Unmarshal converts DER into an object.  An interface{} is given a pointer to
+// a new value of the codec's Structure, or the asn1.RawValue if it has none.
+func (c *Asn1Codec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	target, ok := obj.(*interface{})
+	if !ok {
+		return unmarshal(data, obj)
+	}
+
+	if len(c.Structure) == 0 {
+		var raw asn1Encoding.RawValue
+		if err := unmarshal(data, &raw); err != nil {
+			return err
+		}
+		*target = raw
+		return nil
+	}
+
+	t, err := structure(c.Structure)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.New(t)
+	if err := unmarshal(data, value.Interface()); err != nil {
+		return err
+	}
+
+	*target = value.Interface()
+
+	return nil
+}
+
+// unmarshal decodes the DER, which must hold a single value.
+func unmarshal(data []byte, obj interface{}) error {
+
+	rest, err := asn1Encoding.Unmarshal(data, obj)
+
+	if err != nil {
+		return err
+	}
+
+	if len(rest) > 0 {
+		return ErrorTrailingData
+	}
+
+	return nil
+}
+
+// ContentType returns the content type for this codec.
+func (c *Asn1Codec) ContentType() string {
+	return constants.ContentTypeDER
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *Asn1Codec) FileExtension() string {
+	return constants.FileExtensionDER
+}
+
+// Suffixes returns the structured syntax suffixes this codec can handle.
+func (c *Asn1Codec) Suffixes() []string {
+	return []string{"der"}
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *Asn1Codec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// Binary returns whether this codec's representation is binary.
+func (c *Asn1Codec) Binary() bool {
+	return true
+}
@@ -0,0 +1,86 @@
+package asn1
+
+import (
+	asn1Encoding "encoding/asn1"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var asn1Codec Asn1Codec
+
+type serial struct {
+	Version int    `json:"version"`
+	Issuer  string `json:"issuer" asn1:"utf8"`
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(Asn1Codec), "Asn1Codec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeDER, asn1Codec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionDER, asn1Codec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, asn1Codec.CanMarshalWithCallback())
+}
+
+func TestMarshal_Struct(t *testing.T) {
+
+	bytes, err := asn1Codec.Marshal(&serial{2, "CA"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x30, 0x07, 0x02, 0x01, 0x02, 0x0c, 0x02, 'C', 'A'}, bytes)
+
+		var back serial
+		if assert.NoError(t, asn1Codec.Unmarshal(bytes, &back)) {
+			assert.Equal(t, serial{2, "CA"}, back)
+		}
+	}
+
+}
+
+func TestMarshal_MapWithStructure(t *testing.T) {
+
+	RegisterStructure("serial", &serial{})
+	defer RegisterStructure("serial", nil)
+
+	codec := &Asn1Codec{Structure: "serial"}
+	object := map[string]interface{}{"version": 2, "issuer": "CA"}
+
+	bytes, err := codec.Marshal(object, nil)
+
+	if assert.NoError(t, err) {
+
+		var back interface{}
+		if assert.NoError(t, codec.Unmarshal(bytes, &back)) {
+			assert.Equal(t, &serial{2, "CA"}, back)
+		}
+
+		var raw interface{}
+		if assert.NoError(t, asn1Codec.Unmarshal(bytes, &raw)) {
+			assert.Equal(t, asn1Encoding.TagSequence, raw.(asn1Encoding.RawValue).Tag)
+		}
+	}
+
+	_, err = asn1Codec.Marshal(object, map[string]interface{}{OptionKeyStructure: "unknown"})
+	assert.Equal(t, ErrorUnknownStructure, err)
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var s serial
+	assert.Equal(t, ErrorTrailingData, asn1Codec.Unmarshal([]byte{0x30, 0x07, 0x02, 0x01, 0x02, 0x0c, 0x02, 'C', 'A', 0}, &s))
+	assert.Error(t, asn1Codec.Unmarshal([]byte{0x30, 0x07}, &s))
+
+	_, isInvalid := asn1Codec.Unmarshal(nil, s).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
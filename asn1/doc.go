@@ -0,0 +1,19 @@
+// A codec for DER encoded ASN.1 structures, using encoding/asn1.
+//
+// Structs are marshalled and unmarshalled using encoding/asn1's rules (and its
+// `asn1` struct tags).  Public data and other generic values don't describe
+// their ASN.1 structure, so the structures expected by an endpoint are
+// registered by name:
+//
+//	asn1.RegisterStructure("validity", Validity{})
+//
+// and selected by the codec's Structure field (or the OptionKeyStructure
+// option when marshalling):
+//
+//	codec := &asn1.Asn1Codec{Structure: "validity"}
+//
+// Maps are converted into the selected structure using encoding/json's rules
+// before being marshalled, and unmarshalling into an interface{} gives a
+// pointer to a new value of the structure.  Without a structure, unmarshalling
+// into an interface{} gives the asn1.RawValue.
+package asn1
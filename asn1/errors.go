@@ -0,0 +1,33 @@
+package asn1
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrorUnknownStructure is returned when the selected structure has not
+	// been registered.
+	ErrorUnknownStructure = errors.New("codecs: asn1: Structure is not registered")
+
+	// ErrorTrailingData is returned when there is data after the DER encoded
+	// value.
+	ErrorTrailingData = errors.New("codecs: asn1: Unexpected data after the DER encoded value")
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: asn1: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: asn1: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: asn1: Unmarshal(nil " + e.Type.String() + ")"
+}
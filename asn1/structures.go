@@ -0,0 +1,44 @@
+package asn1
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	structuresLock sync.RWMutex
+	structures     = map[string]reflect.Type{}
+)
+
+// RegisterStructure registers the type of the prototype (following pointers)
+// as the structure with the given name.  Registering a nil prototype removes
+// the name.
+func RegisterStructure(name string, prototype interface{}) {
+
+	structuresLock.Lock()
+	defer structuresLock.Unlock()
+
+	if prototype == nil {
+		delete(structures, name)
+		return
+	}
+
+	t := reflect.TypeOf(prototype)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	structures[name] = t
+}
+
+// structure gets the type registered with the name.
+func structure(name string) (reflect.Type, error) {
+
+	structuresLock.RLock()
+	defer structuresLock.RUnlock()
+
+	t, ok := structures[name]
+	if !ok {
+		return nil, ErrorUnknownStructure
+	}
+	return t, nil
+}
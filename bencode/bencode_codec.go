@@ -0,0 +1,69 @@
+package bencode
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// BencodeCodec converts objects to and from bencode.
+type BencodeCodec struct{}
+
+func init() {
+	codecs.Register(new(BencodeCodec))
+}
+
+// Marshal converts an object to bencode.
+func (c *BencodeCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var buffer bytes.Buffer
+	if err := encode(&buffer, reflect.ValueOf(object)); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal converts bencode into an object.
+func (c *BencodeCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	d := &decoder{data: data}
+	value, err := d.value()
+
+	if err != nil {
+		return err
+	}
+
+	if d.pos != len(data) {
+		return fmt.Errorf("codecs: bencode: Unexpected data after offset %d", d.pos)
+	}
+
+	return assign(rv.Elem(), value)
+}
+
+// ContentType returns the content type for this codec.
+func (c *BencodeCodec) ContentType() string {
+	return constants.ContentTypeBencode
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *BencodeCodec) FileExtension() string {
+	return constants.FileExtensionBencode
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *BencodeCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// Binary returns whether this codec's representation is binary.
+func (c *BencodeCodec) Binary() bool {
+	return true
+}
@@ -0,0 +1,140 @@
+package bencode
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var bencodeCodec BencodeCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(BencodeCodec), "BencodeCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeBencode, bencodeCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionBencode, bencodeCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, bencodeCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"spam":     []interface{}{"a", 42, -3},
+		"announce": "http://tracker/",
+		"private":  true,
+		"pieces":   []byte{0, 1},
+	}
+
+	bytes, err := bencodeCodec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "d8:announce15:http://tracker/6:pieces2:\x00\x017:privatei1e4:spaml1:ai42ei-3eee", string(bytes))
+	}
+
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+
+	_, err := bencodeCodec.Marshal(map[string]interface{}{"ratio": 1.5}, nil)
+	assert.Error(t, err)
+
+	_, err = bencodeCodec.Marshal(nil, nil)
+	assert.Error(t, err)
+
+}
+
+// node is a struct that can hold itself.
+type node struct {
+	Name string
+	Next *node `bencode:",omitempty"`
+}
+
+func TestMarshal_Cycles(t *testing.T) {
+
+	cyclic := &node{Name: "a"}
+	cyclic.Next = cyclic
+
+	_, err := bencodeCodec.Marshal(cyclic, nil)
+	assert.IsType(t, &UnsupportedValueError{}, err)
+
+	m := map[string]interface{}{}
+	m["self"] = m
+
+	_, err = bencodeCodec.Marshal(m, nil)
+	assert.IsType(t, &UnsupportedValueError{}, err)
+
+	// values held more than once are fine when they don't hold themselves
+	shared := &node{Name: "b"}
+	bytes, err := bencodeCodec.Marshal([]interface{}{shared, shared}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ld4:Name1:bed4:Name1:bee", string(bytes))
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	var obj interface{}
+	if assert.NoError(t, bencodeCodec.Unmarshal([]byte("d4:infod6:lengthi1024e4:name5:a.txte4:listl0:i-1eee"), &obj)) {
+		assert.Equal(t, map[string]interface{}{
+			"info": map[string]interface{}{"length": int64(1024), "name": "a.txt"},
+			"list": []interface{}{"", int64(-1)},
+		}, obj)
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	type file struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	}
+	type torrent struct {
+		Announce string   `bencode:"announce"`
+		Comment  string   `bencode:"comment,omitempty"`
+		Files    []file   `bencode:"files"`
+		Pieces   []byte   `bencode:"pieces"`
+		Ignored  string   `bencode:"-"`
+		Private  bool     `bencode:"private"`
+		Tiers    []string `bencode:"tiers,omitempty"`
+	}
+
+	in := torrent{Announce: "udp://t/", Files: []file{{Length: 5, Path: []string{"a", "b"}}}, Pieces: []byte{9, 8}, Private: true}
+	bytes, err := bencodeCodec.Marshal(in, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "d8:announce8:udp://t/5:filesld6:lengthi5e4:pathl1:a1:beee6:pieces2:\x09\x087:privatei1ee", string(bytes))
+
+		var out torrent
+		if assert.NoError(t, bencodeCodec.Unmarshal(bytes, &out)) {
+			assert.Equal(t, in, out)
+		}
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorUnexpectedEnd, bencodeCodec.Unmarshal([]byte("d3:key"), &obj))
+	assert.Equal(t, ErrorUnexpectedEnd, bencodeCodec.Unmarshal([]byte("10:short"), &obj))
+	assert.Error(t, bencodeCodec.Unmarshal([]byte("i1x2e"), &obj))
+	assert.Error(t, bencodeCodec.Unmarshal([]byte("i1ei2e"), &obj))
+
+	var s string
+	assert.Error(t, bencodeCodec.Unmarshal([]byte("i1e"), &s))
+
+	_, isInvalid := bencodeCodec.Unmarshal([]byte("i1e"), obj).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
@@ -0,0 +1,196 @@
+package bencode
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// decoder parses bencoded data into generic values.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) value() (interface{}, error) {
+
+	if d.pos >= len(d.data) {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		d.pos++
+		raw, err := d.until('e')
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("codecs: bencode: Invalid integer %q", raw)
+		}
+		return i, nil
+	case c == 'l':
+		d.pos++
+		list := []interface{}{}
+		for {
+			if d.pos >= len(d.data) {
+				return nil, ErrorUnexpectedEnd
+			}
+			if d.data[d.pos] == 'e' {
+				d.pos++
+				return list, nil
+			}
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case c == 'd':
+		d.pos++
+		dict := map[string]interface{}{}
+		for {
+			if d.pos >= len(d.data) {
+				return nil, ErrorUnexpectedEnd
+			}
+			if d.data[d.pos] == 'e' {
+				d.pos++
+				return dict, nil
+			}
+			key, err := d.str()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+		}
+	case c >= '0' && c <= '9':
+		return d.str()
+	default:
+		return nil, fmt.Errorf("codecs: bencode: Unexpected %q at offset %d", c, d.pos)
+	}
+
+}
+
+// str reads a length prefixed byte string.
+func (d *decoder) str() (string, error) {
+
+	raw, err := d.until(':')
+
+	if err != nil {
+		return "", err
+	}
+
+	length, err := strconv.Atoi(raw)
+
+	if err != nil || length < 0 {
+		return "", fmt.Errorf("codecs: bencode: Invalid string length %q", raw)
+	}
+
+	if d.pos+length > len(d.data) {
+		return "", ErrorUnexpectedEnd
+	}
+
+	s := string(d.data[d.pos : d.pos+length])
+	d.pos += length
+
+	return s, nil
+}
+
+// until reads up to (and consumes) the terminator.
+func (d *decoder) until(terminator byte) (string, error) {
+
+	for i := d.pos; i < len(d.data); i++ {
+		if d.data[i] == terminator {
+			s := string(d.data[d.pos:i])
+			d.pos = i + 1
+			return s, nil
+		}
+	}
+
+	return "", ErrorUnexpectedEnd
+}
+
+// assign stores the generic value in the target, converting it as
+// necessary.
+func assign(target reflect.Value, value interface{}) error {
+
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return assign(target.Elem(), value)
+	}
+
+	switch v := value.(type) {
+	case int64:
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			target.SetInt(v)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			target.SetUint(uint64(v))
+			return nil
+		case reflect.Bool:
+			target.SetBool(v != 0)
+			return nil
+		}
+	case string:
+		switch {
+		case target.Kind() == reflect.String:
+			target.SetString(v)
+			return nil
+		case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+			target.SetBytes([]byte(v))
+			return nil
+		}
+	case []interface{}:
+		if target.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(target.Type(), len(v), len(v))
+			for i, item := range v {
+				if err := assign(slice.Index(i), item); err != nil {
+					return err
+				}
+			}
+			target.Set(slice)
+			return nil
+		}
+	case map[string]interface{}:
+		switch target.Kind() {
+		case reflect.Map:
+			if target.Type().Key().Kind() != reflect.String {
+				break
+			}
+			m := reflect.MakeMap(target.Type())
+			for key, item := range v {
+				element := reflect.New(target.Type().Elem()).Elem()
+				if err := assign(element, item); err != nil {
+					return err
+				}
+				m.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), element)
+			}
+			target.Set(m)
+			return nil
+		case reflect.Struct:
+			for _, f := range structFields(target.Type()) {
+				if item, ok := v[f.name]; ok {
+					if err := assign(target.Field(f.index), item); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("codecs: bencode: Cannot unmarshal %T into %s", value, target.Type())
+}
@@ -0,0 +1,19 @@
+// A codec for handling bencode (the BitTorrent encoding) encoding and decoding.
+//
+// Go values are mapped to bencode types as follows:
+//
+//	int and uint types    integers (i42e)
+//	bool                  integers (1 or 0)
+//	string, []byte        byte strings (4:spam)
+//	arrays, slices        lists (l...e)
+//	maps, structs         dictionaries (d...e), with keys sorted as bencode requires
+//
+// Structs use the `bencode` struct tag (falling back on the field name), which
+// supports ",omitempty" and "-" like encoding/json.  Floating point numbers and
+// nil values cannot be represented and cause an error.
+//
+// When unmarshalling into an interface{}, integers become int64, byte strings
+// become string, lists become []interface{} and dictionaries become
+// map[string]interface{}.  Unmarshalling into structs, maps, slices and basic
+// types is also supported.
+package bencode
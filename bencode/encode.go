@@ -0,0 +1,185 @@
+package bencode
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// encode writes the bencoded value to the buffer.
+func encode(buffer *bytes.Buffer, v reflect.Value) error {
+	return encodeValue(buffer, v, map[interface{}]bool{})
+}
+
+// sliceKey identifies a slice being encoded by its array and length, as
+// slices of the same array can hold each other.
+type sliceKey struct {
+	ptr uintptr
+	len int
+}
+
+// encodeValue writes the bencoded value to the buffer, refusing the pointers,
+// maps and slices it is already inside (given by visiting), which would
+// otherwise recurse forever.
+func encodeValue(buffer *bytes.Buffer, v reflect.Value, visiting map[interface{}]bool) error {
+
+	if !v.IsValid() {
+		return &UnsupportedTypeError{nil}
+	}
+
+	var key interface{}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if !v.IsNil() {
+			key = v.Pointer()
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			key = sliceKey{ptr: v.Pointer(), len: v.Len()}
+		}
+	}
+	if key != nil {
+		if visiting[key] {
+			return &UnsupportedValueError{Value: v, Str: "encountered a cycle via " + v.Type().String()}
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return &UnsupportedTypeError{nil}
+		}
+		return encodeValue(buffer, v.Elem(), visiting)
+	case reflect.Bool:
+		if v.Bool() {
+			buffer.WriteString("i1e")
+		} else {
+			buffer.WriteString("i0e")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buffer.WriteString("i" + strconv.FormatInt(v.Int(), 10) + "e")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buffer.WriteString("i" + strconv.FormatUint(v.Uint(), 10) + "e")
+	case reflect.String:
+		writeString(buffer, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			writeString(buffer, string(b))
+			return nil
+		}
+		buffer.WriteString("l")
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeValue(buffer, v.Index(i), visiting); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("e")
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return &UnsupportedTypeError{v.Type()}
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		// bencode requires keys sorted as raw strings
+		sort.Strings(keys)
+		buffer.WriteString("d")
+		for _, key := range keys {
+			writeString(buffer, key)
+			if err := encodeValue(buffer, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())), visiting); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("e")
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		buffer.WriteString("d")
+		for _, f := range fields {
+			value := v.Field(f.index)
+			if f.omitEmpty && isEmptyValue(value) {
+				continue
+			}
+			writeString(buffer, f.name)
+			if err := encodeValue(buffer, value, visiting); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("e")
+	default:
+		return &UnsupportedTypeError{v.Type()}
+	}
+
+	return nil
+}
+
+func writeString(buffer *bytes.Buffer, s string) {
+	buffer.WriteString(strconv.Itoa(len(s)))
+	buffer.WriteString(":")
+	buffer.WriteString(s)
+}
+
+// field describes a struct field.
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields gets the fields of the struct type sorted by their bencode
+// names.
+func structFields(t reflect.Type) []field {
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+
+		f := field{name: sf.Name, index: i}
+		if tag := sf.Tag.Get("bencode"); len(tag) > 0 {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if len(parts[0]) > 0 {
+				f.name = parts[0]
+			}
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					f.omitEmpty = true
+				}
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
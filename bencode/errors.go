@@ -0,0 +1,50 @@
+package bencode
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorUnexpectedEnd is returned when the data ends part way through a value.
+var ErrorUnexpectedEnd = errors.New("codecs: bencode: Unexpected end of data")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: bencode: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: bencode: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: bencode: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnsupportedTypeError is returned when marshalling a value that bencode
+// cannot represent.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "codecs: bencode: Cannot encode nil"
+	}
+	return "codecs: bencode: Cannot encode " + e.Type.String()
+}
+
+// An UnsupportedValueError is returned when marshalling a value that bencode
+// cannot represent, such as one that holds itself.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return "codecs: bencode: Unsupported value: " + e.Str
+}
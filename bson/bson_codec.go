@@ -1,6 +1,7 @@
 package bson
 
 import (
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"labix.org/v2/mgo/bson"
 )
@@ -8,6 +9,10 @@ import (
 // BsonCodec converts objects to and from BSON.
 type BsonCodec struct{}
 
+func init() {
+	codecs.Register(new(BsonCodec))
+}
+
 // Marshal converts an object to BSON.
 func (b *BsonCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 	return bson.Marshal(object)
@@ -32,3 +37,8 @@ func (b *BsonCodec) FileExtension() string {
 func (b *BsonCodec) CanMarshalWithCallback() bool {
 	return false
 }
+
+// Binary returns whether this codec's representation is binary.
+func (b *BsonCodec) Binary() bool {
+	return true
+}
@@ -0,0 +1,51 @@
+package codecs
+
+// Capabilities describes what a codec can do, so that code built on codecs
+// can decide how to respond (such as whether to send a charset, or to stream
+// the body) without knowing the concrete codec types.
+type Capabilities struct {
+
+	// StreamEncode is whether the codec can write objects straight to a writer
+	// (see StreamEncoder).
+	StreamEncode bool
+
+	// StreamDecode is whether the codec can read objects straight from a
+	// reader (see StreamDecoder).
+	StreamDecode bool
+
+	// Context is whether the codec takes a context.Context (see ContextCodec).
+	Context bool
+
+	// Binary is whether the codec's representation is binary rather than text
+	// (see BinaryCodec).
+	Binary bool
+
+	// Callback is whether the codec can marshal with a callback, as with
+	// JSONP.
+	Callback bool
+
+	// Unmarshal is whether the codec can unmarshal (see
+	// UnmarshalCapableCodec).
+	Unmarshal bool
+}
+
+// CapabilitiesOf gets the capabilities of the codec from the interfaces it
+// conforms to.
+func CapabilitiesOf(codec Codec) Capabilities {
+
+	capabilities := Capabilities{Callback: codec.CanMarshalWithCallback(), Unmarshal: true}
+
+	_, capabilities.StreamEncode = codec.(StreamEncoder)
+	_, capabilities.StreamDecode = codec.(StreamDecoder)
+	_, capabilities.Context = codec.(ContextCodec)
+
+	if binaryCodec, ok := codec.(BinaryCodec); ok {
+		capabilities.Binary = binaryCodec.Binary()
+	}
+
+	if unmarshalCapableCodec, ok := codec.(UnmarshalCapableCodec); ok {
+		capabilities.Unmarshal = unmarshalCapableCodec.CanUnmarshal()
+	}
+
+	return capabilities
+}
@@ -0,0 +1,34 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// binaryTestCodec is a marshal only, binary codec.
+type binaryTestCodec struct {
+	test.TestCodec
+}
+
+func (c *binaryTestCodec) Binary() bool {
+	return true
+}
+
+func (c *binaryTestCodec) CanUnmarshal() bool {
+	return false
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+
+	codec := new(test.TestCodec)
+	codec.On("CanMarshalWithCallback").Return(true)
+
+	assert.Equal(t, Capabilities{Callback: true, Unmarshal: true}, CapabilitiesOf(codec))
+
+	binaryCodec := new(binaryTestCodec)
+	binaryCodec.On("CanMarshalWithCallback").Return(false)
+
+	assert.Equal(t, Capabilities{Binary: true}, CapabilitiesOf(binaryCodec))
+
+}
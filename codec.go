@@ -1,5 +1,10 @@
 package codecs
 
+import (
+	"context"
+	"io"
+)
+
 // Codec is the interface to which a codec must conform.
 type Codec interface {
 
@@ -20,3 +25,203 @@ type Codec interface {
 	// a callback parameter.
 	CanMarshalWithCallback() bool
 }
+
+// UnmarshalOptionsCodec is the interface to which a codec can also conform to
+// take options when unmarshalling, as it does when marshalling, for settings
+// (such as strictness) that vary from request to request.  Use
+// UnmarshalWithOptions to unmarshal with any codec.
+type UnmarshalOptionsCodec interface {
+	Codec
+
+	// UnmarshalWithOptions converts a []byte representation into an object,
+	// customised by the options.
+	UnmarshalWithOptions(data []byte, obj interface{}, options map[string]interface{}) error
+}
+
+// UnmarshalWithOptions unmarshals the data into the object with the codec,
+// passing the options to codecs implementing UnmarshalOptionsCodec.  Other
+// codecs unmarshal as usual, without the options.
+func UnmarshalWithOptions(codec Codec, data []byte, obj interface{}, options map[string]interface{}) error {
+	if optionsCodec, ok := codec.(UnmarshalOptionsCodec); ok {
+		return optionsCodec.UnmarshalWithOptions(data, obj, options)
+	}
+	return codec.Unmarshal(data, obj)
+}
+
+// BatchMode says how several objects are marshalled into one body.
+type BatchMode int
+
+const (
+	// BatchArray marshals the objects as one array.
+	BatchArray BatchMode = iota
+
+	// BatchDocuments marshals the objects one after another, separated by
+	// the codec's DocumentSeparator (as with NDJSON or YAML streams).
+	BatchDocuments
+
+	// BatchMultipart marshals each object into its own part of a
+	// multipart/mixed body.
+	BatchMultipart
+)
+
+// BatchCodec is the interface to which a codec can also conform to say how
+// several objects are marshalled into one body.  Codecs that don't conform
+// marshal them as an array (BatchArray), unless they are binary (see
+// BinaryCodec), in which case each gets a part of a multipart body
+// (BatchMultipart).
+type BatchCodec interface {
+	Codec
+
+	// BatchMode gets how the codec marshals several objects into one body.
+	BatchMode() BatchMode
+}
+
+// DocumentCodec is the interface to which codecs using BatchDocuments conform
+// to give the separator written between documents.
+type DocumentCodec interface {
+	BatchCodec
+
+	// DocumentSeparator gets the bytes written between documents.
+	DocumentSeparator() []byte
+}
+
+// StreamEncoder is the interface to which a codec can also conform to write
+// objects straight to a writer, rather than building the whole []byte
+// representation in memory first.
+type StreamEncoder interface {
+	Codec
+
+	// Encode writes the object to w.
+	Encode(w io.Writer, object interface{}, options map[string]interface{}) error
+}
+
+// SequenceEncoder is the interface to which a codec can also conform to write
+// a Sequence (the public data of a channel or iterator function, see
+// PublicDataSequence) to a writer element by element, so that the elements
+// needn't all be held in memory.
+type SequenceEncoder interface {
+	Codec
+
+	// EncodeSequence writes the elements of the sequence to w as they come.
+	EncodeSequence(w io.Writer, sequence Sequence, options map[string]interface{}) error
+}
+
+// StreamDecoder is the interface to which a codec can also conform to read
+// objects straight from a reader, rather than needing all of the data in
+// memory first.
+type StreamDecoder interface {
+	Codec
+
+	// Decode reads an object from r into obj.
+	Decode(r io.Reader, obj interface{}) error
+}
+
+// SequenceDecoder is the interface to which a codec can also conform to read
+// a sequence of elements (such as the elements of a JSON array) from a reader
+// one at a time, so that large documents needn't be decoded into memory whole.
+type SequenceDecoder interface {
+	Codec
+
+	// DecodeSequence calls fn for each element read from r, in order.  fn
+	// decodes the element into an object of its choosing with decode, or
+	// skips it by not calling decode; an error it returns stops the decoding
+	// and is returned.
+	DecodeSequence(r io.Reader, fn func(decode func(obj interface{}) error) error) error
+}
+
+// StreamingCodec is the interface for codecs that can both encode to writers
+// and decode from readers.
+type StreamingCodec interface {
+	Codec
+
+	// Encode writes the object to w.
+	Encode(w io.Writer, object interface{}, options map[string]interface{}) error
+
+	// Decode reads an object from r into obj.
+	Decode(r io.Reader, obj interface{}) error
+}
+
+// ContextCodec is the interface to which a codec can also conform to take a
+// context.Context when marshalling and unmarshalling, so that long running work
+// can be cancelled and deadlines and request scoped values (such as trace IDs)
+// reach it.
+type ContextCodec interface {
+	Codec
+
+	// MarshalContext converts an object to a []byte representation, giving up
+	// with the context's error if it is done first.
+	MarshalContext(ctx context.Context, object interface{}, options map[string]interface{}) ([]byte, error)
+
+	// UnmarshalContext converts a []byte representation into an object,
+	// giving up with the context's error if it is done first.
+	UnmarshalContext(ctx context.Context, data []byte, obj interface{}) error
+}
+
+// SuffixCodec is the interface to which a codec can also conform to declare the
+// structured syntax suffixes (RFC 6839) it can handle, so that media types such as
+// application/vnd.example+json are negotiated to it when no codec has that exact
+// content type.
+type SuffixCodec interface {
+	Codec
+
+	// Suffixes gets the structured syntax suffixes (without the leading +)
+	// the codec can handle.
+	Suffixes() []string
+}
+
+// AliasCodec is the interface to which a codec can also conform to declare other
+// content types it answers to (such as application/xml alongside text/xml), so
+// that requests and Accept headers using them are handled by it too.
+type AliasCodec interface {
+	Codec
+
+	// Aliases gets the content types the codec handles besides its ContentType.
+	Aliases() []string
+}
+
+// Parameter describes a media type parameter (such as version=2) a codec
+// understands.
+type Parameter struct {
+
+	// Name is the lower case name of the parameter.
+	Name string
+
+	// Values are the values the codec supports, or empty if it supports any.
+	Values []string
+
+	// Required is whether the parameter must be given for the codec to match.
+	Required bool
+
+	// Default is the value used when the parameter isn't given.
+	Default string
+}
+
+// ParameterCodec is the interface to which a codec can also conform to declare the
+// media type parameters it understands, so that Accept headers asking for
+// unsupported values (or missing required parameters) are not negotiated to it.
+type ParameterCodec interface {
+	Codec
+
+	// Parameters gets the media type parameters the codec understands.
+	Parameters() []Parameter
+}
+
+// BinaryCodec is the interface to which a codec can also conform to declare
+// that its representation is binary rather than text, so that no charset
+// applies to it.  Codecs that don't conform are taken to be text.
+type BinaryCodec interface {
+	Codec
+
+	// Binary gets whether the codec's representation is binary.
+	Binary() bool
+}
+
+// UnmarshalCapableCodec is the interface to which a codec can also conform to
+// declare whether it can unmarshal at all, for codecs (such as JSONP) that
+// only marshal.  Codecs that don't conform are taken to unmarshal.
+type UnmarshalCapableCodec interface {
+	Codec
+
+	// CanUnmarshal gets whether the codec can unmarshal.
+	CanUnmarshal() bool
+}
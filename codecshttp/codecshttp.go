@@ -0,0 +1,174 @@
+package codecshttp
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/services"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Service is the codec service Respond and Bind use.
+var Service = services.NewWebCodecService()
+
+// CallbackParameter is the query parameter giving the callback to respond
+// with (as with JSONP), ContextParameter the one giving the client context to
+// pass back to it, FieldsParameter the one giving the comma separated fields
+// to respond with (such as "id,name,author.name"), and PrettyParameter the one
+// asking for indented output (as in ?pretty or ?pretty=true).
+var (
+	CallbackParameter = "callback"
+	ContextParameter  = "context"
+	FieldsParameter   = "fields"
+	PrettyParameter   = "pretty"
+)
+
+// Respond responds to the request with the object, using Service.
+func Respond(w http.ResponseWriter, r *http.Request, status int, object interface{}) error {
+	return RespondWith(Service, w, r, status, object)
+}
+
+// RespondWith responds to the request with the object with the status,
+// negotiating the codec from the Accept header, the extension of the path and
+// the callback parameter, the charset from the Accept header's charset
+// parameter, the content coding from Accept-Encoding and the language from
+// Accept-Language.  Nothing is written if there is an error, so that the
+// caller can respond with it (see StatusForError).
+func RespondWith(service *services.WebCodecService, w http.ResponseWriter, r *http.Request, status int, object interface{}) error {
+	return NewNegotiatedWriterWith(service, w, r).WriteObject(status, object)
+}
+
+// withCharset gets the content type with its charset parameter set to the
+// charset, if there is one.
+func withCharset(contentType, charset string) string {
+
+	if len(charset) == 0 {
+		return contentType
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return contentType
+	}
+
+	params["charset"] = strings.ToLower(charset)
+
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// BadRequestError is the error BindWith returns when the request's body can't
+// be decompressed or unmarshalled, for responding with 400 Bad Request.
+type BadRequestError struct {
+	// Err is the error decompressing or unmarshalling the body.
+	Err error
+}
+
+func (e *BadRequestError) Error() string {
+	return e.Err.Error()
+}
+
+// StatusCode gets the HTTP status to respond with, 400 Bad Request.
+func (e *BadRequestError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Unwrap gets the error decompressing or unmarshalling the body.
+func (e *BadRequestError) Unwrap() error {
+	return e.Err
+}
+
+// badRequest gets the error from reading the request's body as a
+// *BadRequestError, unless it already has a status other than 500 Internal
+// Server Error (see StatusForError).
+func badRequest(err error) error {
+	if StatusForError(err) != http.StatusInternalServerError {
+		return err
+	}
+	return &BadRequestError{Err: err}
+}
+
+// Bind unmarshals the body of the request into the target, using Service.
+func Bind(r *http.Request, target interface{}) error {
+	return BindWith(Service, r, target)
+}
+
+// BindWith unmarshals the body of the request into the target with the codec
+// for the request's Content-Type, decompressing it from its Content-Encoding
+// and transcoding it from the Content-Type's charset, and then validates the
+// target (see WebCodecService.Validate).  A
+// *services.UnsupportedMediaTypeError is returned if no codec handles the
+// Content-Type, a *BadRequestError if the body can't be decompressed or
+// unmarshalled, and a *services.ValidationError (which can be responded with)
+// if the target is invalid.
+func BindWith(service *services.WebCodecService, r *http.Request, target interface{}) error {
+
+	contentType := r.Header.Get("Content-Type")
+
+	decompressed, err := services.DecompressReader(r.Body, r.Header.Get("Content-Encoding"))
+
+	if err != nil {
+		return badRequest(err)
+	}
+	defer decompressed.Close()
+
+	// don't decompress past the codec's payload limit, or the service's if
+	// the codec is only known once the data is read
+	var limitCodec codecs.Codec
+	if len(contentType) > 0 {
+		limitCodec, _ = service.GetCodec(contentType)
+	}
+
+	data, err := ioutil.ReadAll(service.LimitReader(decompressed, limitCodec))
+
+	if err != nil {
+		return badRequest(err)
+	}
+
+	codec, err := service.GetCodecForData(contentType, data)
+
+	if err != nil {
+		return err
+	}
+
+	if err := service.UnmarshalWithCodecAndContentType(codec, data, contentType, target); err != nil {
+		return badRequest(err)
+	}
+
+	return service.Validate(target)
+}
+
+// StatusForError gets the status to respond with for an error from Respond or
+// Bind: the status of errors with a StatusCode method (such as
+// *services.NotAcceptableError, *services.UnsupportedMediaTypeError and
+// *BadRequestError), 406
+// Not Acceptable when no content coding is acceptable, 415 Unsupported Media
+// Type when the request's charset or content coding isn't supported, 413
+// Payload Too Large when the request's body is over the service's size or
+// decode limits, 422 Unprocessable Entity when the request's object is
+// invalid, and 500 Internal Server Error otherwise.
+func StatusForError(err error) int {
+
+	if statusError, ok := err.(interface {
+		StatusCode() int
+	}); ok {
+		return statusError.StatusCode()
+	}
+
+	switch err.(type) {
+	case *services.PayloadTooLargeError, *codecs.DecodeLimitError:
+		return http.StatusRequestEntityTooLarge
+	case *services.ValidationError:
+		return http.StatusUnprocessableEntity
+	}
+
+	switch err {
+	case services.ErrorContentEncodingNotAcceptable:
+		return http.StatusNotAcceptable
+	case services.ErrorContentTypeNotSupported, services.ErrorCharsetNotSupported, services.ErrorContentEncodingNotSupported:
+		return http.StatusUnsupportedMediaType
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,210 @@
+package codecshttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/people/1", nil)
+	request.Header.Set("Accept", "application/json")
+	recorder := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(recorder, request, http.StatusCreated, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept, Accept-Encoding", recorder.Header().Get("Vary"))
+		assert.Equal(t, `{"name":"Mat"}`, recorder.Body.String())
+	}
+
+}
+
+func TestRespond_WithExtensionAndCallback(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/people/1.xml", nil)
+	recorder := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(recorder, request, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Contains(t, recorder.Header().Get("Content-Type"), constants.ContentTypeXML)
+	}
+
+	request = httptest.NewRequest("GET", "/people/1?callback=show&context=1", nil)
+	recorder = httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(recorder, request, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, `/**/show({"name":"Mat"},"1");`, recorder.Body.String())
+	}
+
+}
+
+func TestRespond_WithContentEncoding(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/people/1", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(recorder, request, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+
+		reader, err := gzip.NewReader(recorder.Body)
+		if assert.NoError(t, err) {
+			data, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, `{"name":"Mat"}`, string(data))
+		}
+	}
+
+}
+
+func TestRespond_NotAcceptable(t *testing.T) {
+
+	service := services.NewWebCodecService(services.WithStrictNegotiation())
+
+	request := httptest.NewRequest("GET", "/people/1", nil)
+	request.Header.Set("Accept", "image/png")
+	recorder := httptest.NewRecorder()
+
+	err := RespondWith(service, recorder, request, http.StatusOK, map[string]interface{}{"name": "Mat"})
+	if assert.Error(t, err) {
+		assert.Equal(t, http.StatusNotAcceptable, StatusForError(err))
+		assert.Equal(t, 0, recorder.Body.Len(), "nothing should be written")
+	}
+
+}
+
+func TestBind(t *testing.T) {
+
+	request := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat"}`))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var person map[string]interface{}
+	if assert.NoError(t, Bind(request, &person)) {
+		assert.Equal(t, "Mat", person["name"])
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(`{"name":"Tyler"}`))
+	writer.Close()
+
+	request = httptest.NewRequest("POST", "/people", &compressed)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Encoding", "gzip")
+
+	person = nil
+	if assert.NoError(t, Bind(request, &person)) {
+		assert.Equal(t, "Tyler", person["name"])
+	}
+
+	request = httptest.NewRequest("POST", "/people", strings.NewReader(`name: Mat`))
+	request.Header.Set("Content-Type", "application/yaml")
+
+	err := Bind(request, &person)
+	if unsupported, ok := err.(*services.UnsupportedMediaTypeError); assert.True(t, ok) {
+		assert.Equal(t, "application/yaml", unsupported.ContentType)
+		assert.Contains(t, unsupported.Supported, constants.ContentTypeJSON)
+	}
+	assert.Equal(t, http.StatusUnsupportedMediaType, StatusForError(err))
+
+}
+
+type boundPerson struct {
+	Name string
+}
+
+func (p *boundPerson) Validate() error {
+	if len(p.Name) == 0 {
+		return services.FieldError{Field: "Name", Message: "is required"}
+	}
+	return nil
+}
+
+func TestBind_Validates(t *testing.T) {
+
+	request := httptest.NewRequest("POST", "/people", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	err := Bind(request, new(boundPerson))
+	if assert.IsType(t, &services.ValidationError{}, err) {
+		assert.Equal(t, http.StatusUnprocessableEntity, StatusForError(err))
+
+		// the error can be responded with
+		request = httptest.NewRequest("POST", "/people", nil)
+		request.Header.Set("Accept", "application/json")
+		recorder := httptest.NewRecorder()
+
+		if assert.NoError(t, Respond(recorder, request, StatusForError(err), err)) {
+			assert.Equal(t, `{"errors":[{"field":"Name","message":"is required"}]}`, recorder.Body.String())
+		}
+	}
+
+}
+
+func TestBind_BadRequest(t *testing.T) {
+
+	request := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":`))
+	request.Header.Set("Content-Type", "application/json")
+
+	err := Bind(request, new(boundPerson))
+	if assert.IsType(t, &BadRequestError{}, err) {
+		assert.Equal(t, http.StatusBadRequest, StatusForError(err))
+	}
+
+	// bodies that aren't in their Content-Encoding are the client's fault too
+	request = httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Encoding", "gzip")
+
+	err = Bind(request, new(boundPerson))
+	if assert.IsType(t, &BadRequestError{}, err) {
+		assert.Equal(t, http.StatusBadRequest, StatusForError(err))
+	}
+
+	// errors that already have a status keep it
+	request = httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Encoding", "snappy")
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, StatusForError(Bind(request, new(boundPerson))))
+
+}
+
+func TestBind_PayloadTooLarge(t *testing.T) {
+
+	service := services.NewWebCodecService(services.WithMaxPayloadSize(8))
+
+	request := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat"}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	err := BindWith(service, request, new(boundPerson))
+	if assert.IsType(t, &services.PayloadTooLargeError{}, err) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, StatusForError(err))
+	}
+
+	// the limit is on the decompressed body
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(bytes.Repeat([]byte(" "), 1<<20))
+	writer.Close()
+
+	request = httptest.NewRequest("POST", "/people", &compressed)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Content-Encoding", "gzip")
+
+	assert.IsType(t, &services.PayloadTooLargeError{}, BindWith(service, request, new(boundPerson)))
+
+	// and the service's limit is kept without a Content-Type
+	request = httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat"}`))
+
+	assert.IsType(t, &services.PayloadTooLargeError{}, BindWith(service, request, new(boundPerson)))
+
+}
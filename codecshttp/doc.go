@@ -0,0 +1,29 @@
+// Helpers for responding to and reading net/http requests with codecs.
+//
+// Respond negotiates the codec (and charset, content coding and language) to
+// respond with from the request, marshals the object with it and writes the
+// response:
+//
+//	func getPerson(w http.ResponseWriter, r *http.Request) {
+//		if err := codecshttp.Respond(w, r, http.StatusOK, person); err != nil {
+//			http.Error(w, err.Error(), codecshttp.StatusForError(err))
+//		}
+//	}
+//
+// Bind reads the request body with the codec for its Content-Type:
+//
+//	var person Person
+//	if err := codecshttp.Bind(r, &person); err != nil {
+//		http.Error(w, err.Error(), codecshttp.StatusForError(err))
+//		return
+//	}
+//
+// Both use Service, or RespondWith and BindWith take the service to use.
+//
+// Handlers wrapped by Handler are given a *NegotiatedWriter, which negotiates
+// when it is first asked to write an object:
+//
+//	http.Handle("/people/", codecshttp.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		w.(*codecshttp.NegotiatedWriter).WriteObject(http.StatusOK, people)
+//	})))
+package codecshttp
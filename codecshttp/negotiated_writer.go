@@ -0,0 +1,176 @@
+package codecshttp
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/services"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+)
+
+// NegotiatedWriter is an http.ResponseWriter that negotiates how to respond to
+// its request, so that handlers can respond with objects without touching
+// codecs.  Negotiation is put off until it is needed, and then remembered.
+type NegotiatedWriter struct {
+	http.ResponseWriter
+
+	// Request is the request being responded to.
+	Request *http.Request
+
+	// Service is the codec service negotiating and marshalling.
+	Service *services.WebCodecService
+
+	once        sync.Once
+	negotiation *services.Negotiation
+	err         error
+}
+
+// NewNegotiatedWriter makes a NegotiatedWriter responding to the request with
+// w, using Service.
+func NewNegotiatedWriter(w http.ResponseWriter, r *http.Request) *NegotiatedWriter {
+	return NewNegotiatedWriterWith(Service, w, r)
+}
+
+// NewNegotiatedWriterWith makes a NegotiatedWriter responding to the request
+// with w, using the service.
+func NewNegotiatedWriterWith(service *services.WebCodecService, w http.ResponseWriter, r *http.Request) *NegotiatedWriter {
+	return &NegotiatedWriter{ResponseWriter: w, Request: r, Service: service}
+}
+
+// Handler wraps the handler so that it is given a *NegotiatedWriter as its
+// http.ResponseWriter, using Service.
+func Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(NewNegotiatedWriter(w, r), r)
+	})
+}
+
+// Negotiation gets the outcome of negotiating the codec to respond with from
+// the request's Accept header, the extension of its path and its callback
+// parameter.
+func (w *NegotiatedWriter) Negotiation() (*services.Negotiation, error) {
+
+	w.once.Do(func() {
+		w.negotiation, w.err = w.Service.GetNegotiationForResponding(w.Request.Header.Get("Accept"), path.Ext(w.Request.URL.Path), len(w.callback()) > 0)
+	})
+
+	return w.negotiation, w.err
+}
+
+// callback gets the callback the request asks for, if any.
+func (w *NegotiatedWriter) callback() string {
+	return w.Request.URL.Query().Get(CallbackParameter)
+}
+
+// pretty gets whether the request's query asks for indented output, by giving
+// the pretty parameter without a value or with a true one.
+func (w *NegotiatedWriter) pretty() bool {
+
+	values, ok := w.Request.URL.Query()[PrettyParameter]
+	if !ok {
+		return false
+	}
+
+	if len(values[0]) == 0 {
+		return true
+	}
+
+	pretty, _ := strconv.ParseBool(values[0])
+	return pretty
+}
+
+// WriteObject writes the object with the status, marshalled with the
+// negotiated codec in the charset, content coding and language negotiated
+// from the request's Accept, Accept-Encoding and Accept-Language headers.
+// The Content-Type, Content-Length, Content-Encoding and Content-Language
+// headers are set to match, and the Vary header lists the headers negotiated
+// on.  Responses to HEAD requests get the headers without the body.  The
+// request's context is passed to objects implementing codecs.FacadeWithContext,
+// and the negotiated version to those implementing codecs.VersionedFacade.
+// Nothing is written if there is an error, so that the caller can respond with
+// it (see StatusForError).
+func (w *NegotiatedWriter) WriteObject(status int, object interface{}) error {
+
+	header, data, err := w.marshal(object)
+
+	if err != nil {
+		return err
+	}
+
+	for name, values := range header {
+		if name == "Vary" {
+			for _, value := range values {
+				w.Header().Add(name, value)
+			}
+		} else {
+			w.Header()[name] = values
+		}
+	}
+	w.WriteHeader(status)
+
+	if w.Request.Method == http.MethodHead {
+		return nil
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// marshal marshals the object as negotiated, returning the headers to respond
+// with along with the data.
+func (w *NegotiatedWriter) marshal(object interface{}) (http.Header, []byte, error) {
+
+	negotiation, err := w.Negotiation()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contentEncoding, err := w.Service.GetContentEncodingForResponding(w.Request.Header.Get("Accept-Encoding"))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := map[string]interface{}{}
+	if callback := w.callback(); len(callback) > 0 {
+		options[constants.OptionKeyClientCallback] = callback
+		if context := w.Request.URL.Query().Get(ContextParameter); len(context) > 0 {
+			options[constants.OptionKeyClientContext] = context
+		}
+	}
+	if fields := w.Request.URL.Query().Get(FieldsParameter); len(fields) > 0 {
+		options[constants.OptionKeyFields] = fields
+	}
+	if w.pretty() {
+		options[constants.OptionKeyPretty] = true
+	}
+
+	header := http.Header{}
+	header.Set("Vary", "Accept, Accept-Encoding")
+
+	if language := w.Service.GetLanguageForResponding(w.Request.Header.Get("Accept-Language")); len(language) > 0 {
+		options[constants.OptionKeyClientLanguage] = language
+		header.Set("Content-Language", language)
+		header.Set("Vary", "Accept, Accept-Encoding, Accept-Language")
+	}
+
+	data, charset, err := w.Service.MarshalWithCodecInCharsetContext(w.Request.Context(), negotiation.Codec, object, negotiation.Options(options), negotiation.Params["charset"])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data, err = services.Compress(data, contentEncoding); err != nil {
+		return nil, nil, err
+	}
+
+	header.Set("Content-Type", withCharset(negotiation.ContentType, charset))
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+	if len(contentEncoding) > 0 {
+		header.Set("Content-Encoding", contentEncoding)
+	}
+
+	return header, data, nil
+}
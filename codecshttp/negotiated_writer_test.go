@@ -0,0 +1,100 @@
+package codecshttp
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestNegotiatedWriter_WriteObject(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/people/1", nil)
+	request.Header.Set("Accept", constants.ContentTypeXML)
+	recorder := httptest.NewRecorder()
+	recorder.Header().Set("Vary", "Origin")
+
+	w := NewNegotiatedWriter(recorder, request)
+
+	negotiation, err := w.Negotiation()
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeXML, negotiation.Codec.ContentType())
+	}
+
+	if assert.NoError(t, w.WriteObject(http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Contains(t, recorder.Header().Get("Content-Type"), constants.ContentTypeXML)
+		assert.Equal(t, []string{"Origin", "Accept, Accept-Encoding"}, recorder.Header()["Vary"])
+		assert.Equal(t, strconv.Itoa(recorder.Body.Len()), recorder.Header().Get("Content-Length"))
+	}
+
+}
+
+func TestNegotiatedWriter_WriteObject_Head(t *testing.T) {
+
+	request := httptest.NewRequest("HEAD", "/people/1", nil)
+	request.Header.Set("Accept", constants.ContentTypeJSON)
+	recorder := httptest.NewRecorder()
+
+	if assert.NoError(t, NewNegotiatedWriter(recorder, request).WriteObject(http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, strconv.Itoa(len(`{"name":"Mat"}`)), recorder.Header().Get("Content-Length"))
+		assert.Equal(t, 0, recorder.Body.Len())
+	}
+
+}
+
+func TestNegotiatedWriter_WriteObject_Fields(t *testing.T) {
+
+	request := httptest.NewRequest("GET", "/posts/1.json?fields=title,author.name", nil)
+	recorder := httptest.NewRecorder()
+
+	object := map[string]interface{}{
+		"title":  "Hello",
+		"body":   "...",
+		"author": map[string]interface{}{"name": "Mat", "email": "mat@example.com"},
+	}
+
+	if assert.NoError(t, NewNegotiatedWriter(recorder, request).WriteObject(http.StatusOK, object)) {
+		assert.Equal(t, `{"author":{"name":"Mat"},"title":"Hello"}`, recorder.Body.String())
+	}
+
+}
+
+func TestNegotiatedWriter_WriteObject_Pretty(t *testing.T) {
+
+	for _, query := range []string{"?pretty", "?pretty=true", "?pretty=1"} {
+
+		request := httptest.NewRequest("GET", "/people/1.json"+query, nil)
+		recorder := httptest.NewRecorder()
+
+		if assert.NoError(t, NewNegotiatedWriter(recorder, request).WriteObject(http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+			assert.Equal(t, "{\n  \"name\": \"Mat\"\n}", recorder.Body.String(), query)
+		}
+	}
+
+	request := httptest.NewRequest("GET", "/people/1.json?pretty=false", nil)
+	recorder := httptest.NewRecorder()
+
+	if assert.NoError(t, NewNegotiatedWriter(recorder, request).WriteObject(http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, `{"name":"Mat"}`, recorder.Body.String())
+	}
+
+}
+
+func TestHandler(t *testing.T) {
+
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(*NegotiatedWriter).WriteObject(http.StatusAccepted, map[string]interface{}{"name": "Mat"})
+	}))
+
+	request := httptest.NewRequest("GET", "/people/1.json", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, `{"name":"Mat"}`, recorder.Body.String())
+
+}
@@ -0,0 +1,124 @@
+package codecs
+
+import (
+	"github.com/stretchr/stew/objects"
+	"reflect"
+	"sync"
+)
+
+// ComputeFunc computes the value of a computed field from the object it is
+// added to, which is given as a pointer to the struct.
+type ComputeFunc func(object interface{}) interface{}
+
+// computedField is a field registered with RegisterComputedField.
+type computedField struct {
+	name    string
+	compute ComputeFunc
+}
+
+var (
+	computedFields     = map[reflect.Type][]computedField{}
+	computedFieldsLock sync.RWMutex
+)
+
+// RegisterComputedField adds a field with the name to the public data of
+// objects of the object's type (or the type it points to), computed by the
+// function when they are marshalled (such as a display name, links or derived
+// totals).  Computed fields are added to structs, and to the public data of
+// Facade objects if it is a map.  Registering a name again replaces its
+// function, and a nil function removes the field.
+func RegisterComputedField(object interface{}, name string, compute ComputeFunc) {
+
+	if object == nil {
+		panic("codecs: RegisterComputedField object is nil.")
+	}
+
+	objectType := reflect.TypeOf(object)
+	for objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	computedFieldsLock.Lock()
+	defer computedFieldsLock.Unlock()
+
+	var fields []computedField
+	for _, field := range computedFields[objectType] {
+		if field.name != name {
+			fields = append(fields, field)
+		}
+	}
+	if compute != nil {
+		fields = append(fields, computedField{name, compute})
+	}
+
+	if len(fields) == 0 {
+		delete(computedFields, objectType)
+		return
+	}
+	computedFields[objectType] = fields
+}
+
+// computedFieldsFor gets the computed fields registered for the type (or the
+// type it points to).
+func computedFieldsFor(t reflect.Type) []computedField {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	computedFieldsLock.RLock()
+	defer computedFieldsLock.RUnlock()
+
+	return computedFields[t]
+}
+
+// compute adds the computed fields of the object (a struct or pointer to
+// one) to the fields.
+func compute(fields []computedField, object reflect.Value, into map[string]interface{}) {
+
+	if object.Kind() != reflect.Ptr {
+		if object.CanAddr() {
+			object = object.Addr()
+		} else {
+			copied := reflect.New(object.Type())
+			copied.Elem().Set(object)
+			object = copied
+		}
+	}
+
+	for _, field := range fields {
+		into[field.name] = field.compute(object.Interface())
+	}
+}
+
+// addComputedFields copies the public data of the Facade object with its
+// computed fields added, if it has any and the public data is a map.
+func addComputedFields(object interface{}, public interface{}) interface{} {
+
+	objectValue := reflect.ValueOf(object)
+	fields := computedFieldsFor(objectValue.Type())
+	if len(fields) == 0 || isNil(objectValue) {
+		return public
+	}
+
+	var publicMap map[string]interface{}
+	switch public := public.(type) {
+	case objects.Map:
+		publicMap = public
+	case map[string]interface{}:
+		publicMap = public
+	default:
+		return public
+	}
+
+	copied := make(map[string]interface{}, len(publicMap)+len(fields))
+	for key, value := range publicMap {
+		copied[key] = value
+	}
+	compute(fields, objectValue, copied)
+
+	if _, ok := public.(objects.Map); ok {
+		return objects.Map(copied)
+	}
+	return copied
+}
@@ -0,0 +1,57 @@
+package codecs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// order is given computed fields.
+type order struct {
+	ID    int       `json:"id"`
+	Items []float64 `json:"items"`
+}
+
+func TestRegisterComputedField(t *testing.T) {
+
+	RegisterComputedField(order{}, "total", func(object interface{}) interface{} {
+		total := 0.0
+		for _, item := range object.(*order).Items {
+			total += item
+		}
+		return total
+	})
+	RegisterComputedField(&order{}, "links", func(object interface{}) interface{} {
+		return map[string]interface{}{"self": "/orders/1"}
+	})
+	defer RegisterComputedField(order{}, "total", nil)
+	defer RegisterComputedField(order{}, "links", nil)
+
+	public, err := PublicData([]order{{ID: 1, Items: []float64{1.5, 2}}}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"id":    1,
+			"items": []float64{1.5, 2},
+			"total": 3.5,
+			"links": map[string]interface{}{"self": "/orders/1"},
+		}}, public)
+	}
+
+	// fields are added to the public data of Facade objects too
+	RegisterComputedField(author{}, "display_name", func(object interface{}) interface{} {
+		return "@" + object.(*author).Name
+	})
+	defer RegisterComputedField(author{}, "display_name", nil)
+
+	public, err = PublicData(&author{Name: "Mat", Email: "mat@example.com"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"name": "Mat", "display_name": "@Mat"}, public)
+	}
+
+	// removing a field
+	RegisterComputedField(order{}, "links", nil)
+	public, err = PublicData(&order{ID: 2}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"id": 2, "items": []float64(nil), "total": 0.0}, public)
+	}
+
+}
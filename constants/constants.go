@@ -5,21 +5,92 @@ package constants
 */
 
 const (
-	ContentTypeJSON      string = "application/json"
-	FileExtensionJSON    string = ".json"
-	ContentTypeJSONP     string = "text/javascript"
-	FileExtensionJSONP   string = ".js"
-	ContentTypeBSON      string = "application/bson"
-	FileExtensionBSON    string = ".bson"
-	ContentTypeMsgpack   string = "application/x-msgpack"
-	FileExtensionMsgpack string = ".msgpack"
-	ContentTypeCSV       string = "text/csv"
-	FileExtensionCSV     string = ".csv"
-	ContentTypeXML       string = "text/xml"
-	FileExtensionXML     string = ".xml"
+	ContentTypeJSON       string = "application/json"
+	FileExtensionJSON     string = ".json"
+	ContentTypeJSONP      string = "text/javascript"
+	FileExtensionJSONP    string = ".js"
+	ContentTypeBSON       string = "application/bson"
+	FileExtensionBSON     string = ".bson"
+	ContentTypeMsgpack    string = "application/x-msgpack"
+	FileExtensionMsgpack  string = ".msgpack"
+	ContentTypeCSV        string = "text/csv"
+	FileExtensionCSV      string = ".csv"
+	ContentTypeXML        string = "text/xml"
+	FileExtensionXML      string = ".xml"
+	ContentTypeAtom       string = "application/atom+xml"
+	FileExtensionAtom     string = ".atom"
+	ContentTypeRSS        string = "application/rss+xml"
+	FileExtensionRSS      string = ".rss"
+	ContentTypeICal       string = "text/calendar"
+	FileExtensionICal     string = ".ics"
+	ContentTypeVCard      string = "text/vcard"
+	FileExtensionVCard    string = ".vcf"
+	ContentTypeSOAP       string = "application/soap+xml"
+	FileExtensionSOAP     string = ".soap"
+	FileExtensionXMLRPC   string = ".xmlrpc"
+	ContentTypeSmile      string = "application/x-jackson-smile"
+	FileExtensionSmile    string = ".smile"
+	ContentTypeBencode    string = "application/x-bittorrent"
+	FileExtensionBencode  string = ".torrent"
+	ContentTypeEDN        string = "application/edn"
+	FileExtensionEDN      string = ".edn"
+	ContentTypeJSON5      string = "application/json5"
+	FileExtensionJSON5    string = ".json5"
+	ContentTypeParquet    string = "application/vnd.apache.parquet"
+	FileExtensionParquet  string = ".parquet"
+	ContentTypeArrow      string = "application/vnd.apache.arrow.stream"
+	FileExtensionArrow    string = ".arrows"
+	ContentTypeMarkdown   string = "text/markdown"
+	FileExtensionMarkdown string = ".md"
+	ContentTypeHTML       string = "text/html"
+	FileExtensionHTML     string = ".html"
+	ContentTypeSSE        string = "text/event-stream"
+	FileExtensionSSE      string = ".sse"
+	ContentTypeDER        string = "application/x-der"
+	FileExtensionDER      string = ".der"
+	ContentTypeTextPB     string = "text/x-protobuf"
+	FileExtensionTextPB   string = ".txtpb"
 )
 
+// Other content types that codecs also answer to.
 const (
-	OptionKeyClientCallback string = "options.client.callback"
-	OptionKeyClientContext  string = "options.client.context"
+	ContentTypeXMLAlias     string = "application/xml"
+	ContentTypeMsgpackAlias string = "application/msgpack"
+	ContentTypeJSONPAlias   string = "application/javascript"
+)
+
+const (
+	OptionKeyClientCallback  string = "options.client.callback"
+	OptionKeyClientContext   string = "options.client.context"
+	OptionKeyClientLanguage  string = "options.client.language"
+	OptionKeyIndent          string = "options.indent"
+	OptionKeyPretty          string = "options.pretty"
+	OptionKeyCanonical       string = "options.canonical"
+	OptionKeyNoHTMLEscape    string = "options.nohtmlescape"
+	OptionKeyFields          string = "options.fields"
+	OptionKeyCharset         string = "options.charset"
+	OptionKeyStrict          string = "options.strict"
+	OptionKeyNoDuplicateKeys string = "options.noduplicatekeys"
+	OptionKeyRoles           string = "options.roles"
+	OptionKeyVersion         string = "options.version"
+	OptionKeyStructTags      string = "options.structtags"
+	OptionKeyEmpty           string = "options.empty"
+	OptionKeyKeyCase         string = "options.keycase"
+	OptionKeyFacadeErrors    string = "options.facadeerrors"
+	OptionKeyElementErrors   string = "options.elementerrors"
+	OptionKeyFieldNames      string = "options.fieldnames"
+	OptionKeyTextMarshalers  string = "options.textmarshalers"
+	OptionKeySortKeys        string = "options.sortkeys"
+	OptionKeyTimeLayout      string = "options.timelayout"
+	OptionKeyTimeUTC         string = "options.timeutc"
+	OptionKeyTimeUnix        string = "options.timeunix"
+	OptionKeyNumbers         string = "options.numbers"
+	OptionKeyIntsAsStrings   string = "options.intsasstrings"
+	OptionKeyEnvelope        string = "options.envelope"
+	OptionKeyStatus          string = "options.status"
+	OptionKeyHeaders         string = "options.headers"
+	OptionKeyBinBytes        string = "options.binbytes"
+	OptionKeyTimestamps      string = "options.timestamps"
+	OptionKeyStructsAsArrays string = "options.structsasarrays"
+	OptionKeyMessageType     string = "options.messagetype"
 )
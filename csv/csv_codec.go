@@ -5,14 +5,23 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"reflect"
 	"strings"
 )
 
+// OptionKeyHeader is the option giving the field names ([]string) of CSV data
+// without a header line, for UnmarshalWithOptions.
+const OptionKeyHeader = "csv.header"
+
 // CsvCodec converts objects to and from CSV format.
 type CsvCodec struct{}
 
+func init() {
+	codecs.Register(new(CsvCodec))
+}
+
 // Converts an object to CSV data.
 func (c *CsvCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 
@@ -101,6 +110,13 @@ func (c *CsvCodec) Marshal(object interface{}, options map[string]interface{}) (
 
 // Unmarshal converts CSV data into an object.
 func (c *CsvCodec) Unmarshal(data []byte, obj interface{}) error {
+	return c.UnmarshalWithOptions(data, obj, nil)
+}
+
+// UnmarshalWithOptions converts CSV data into an object.  The first line is
+// taken to be the header naming the fields, unless the OptionKeyHeader option
+// gives the field names instead.
+func (c *CsvCodec) UnmarshalWithOptions(data []byte, obj interface{}, options map[string]interface{}) error {
 
 	// check the value
 	rv := reflect.ValueOf(obj)
@@ -115,6 +131,10 @@ func (c *CsvCodec) Unmarshal(data []byte, obj interface{}) error {
 		return readErr
 	}
 
+	if header, ok := options[OptionKeyHeader].([]string); ok {
+		records = append([][]string{header}, records...)
+	}
+
 	lenRecords := len(records)
 
 	if lenRecords == 0 {
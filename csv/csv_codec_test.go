@@ -123,6 +123,22 @@ func TestUnmarshal_SingleObject(t *testing.T) {
 
 }
 
+func TestUnmarshalWithOptions_Header(t *testing.T) {
+
+	raw := "row1a,row1b\nrow2a,row2b\n"
+
+	var obj interface{}
+	err := new(CsvCodec).UnmarshalWithOptions([]byte(raw), &obj, map[string]interface{}{OptionKeyHeader: []string{"field_a", "field_b"}})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []map[string]interface{}{
+			{"field_a": "row1a", "field_b": "row1b"},
+			{"field_a": "row2a", "field_b": "row2b"},
+		}, obj)
+	}
+
+}
+
 func TestUnmarshal_MultipleObjects(t *testing.T) {
 
 	raw := "field_a,field_b,field_c\nrow1a,row1b,row1c\nrow2a,row2b,row2c\nrow3a,row3b,row3c"
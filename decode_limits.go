@@ -0,0 +1,102 @@
+package codecs
+
+import (
+	"fmt"
+)
+
+// Names of the decode limits, as given by DecodeLimitError.
+const (
+	LimitDepth        = "depth"
+	LimitElements     = "elements"
+	LimitStringLength = "string length"
+)
+
+// DecodeLimits bounds the structure of data to be unmarshalled, so that small
+// but pathological payloads (such as deeply nested arrays) can be refused
+// before they are decoded.  Limits of 0 aren't enforced.
+type DecodeLimits struct {
+
+	// MaxDepth is the deepest objects, arrays and elements may be nested.
+	MaxDepth int
+
+	// MaxElements is the most values (including object keys and XML
+	// elements) the data may hold.
+	MaxElements int
+
+	// MaxStringLength is the longest (in bytes) a string may be.
+	MaxStringLength int
+}
+
+// DecodeLimitError is the error for when data to unmarshal exceeds one of its
+// DecodeLimits.
+type DecodeLimitError struct {
+	// Limit is the name of the limit exceeded (one of the Limit constants).
+	Limit string
+
+	// Max is the value of the limit.
+	Max int
+}
+
+func (e *DecodeLimitError) Error() string {
+	return fmt.Sprintf("codecs: Data exceeds the %s limit of %d.", e.Limit, e.Max)
+}
+
+// LimitedCodec is the interface to which a codec can also conform to check data
+// against DecodeLimits before unmarshalling it.  Use CheckLimits to check data
+// for any codec.
+type LimitedCodec interface {
+	Codec
+
+	// CheckLimits gets a *DecodeLimitError if the data exceeds the limits.
+	// Malformed data is left for Unmarshal to report.
+	CheckLimits(data []byte, limits DecodeLimits) error
+}
+
+// CheckLimits checks the data against the limits with codecs implementing
+// LimitedCodec.  Data for other codecs isn't checked.
+func CheckLimits(codec Codec, data []byte, limits DecodeLimits) error {
+	if limitedCodec, ok := codec.(LimitedCodec); ok && limits != (DecodeLimits{}) {
+		return limitedCodec.CheckLimits(data, limits)
+	}
+	return nil
+}
+
+// LimitChecker keeps count of the structure of data as a codec scans it,
+// returning a *DecodeLimitError once a limit is exceeded.
+type LimitChecker struct {
+	// Limits are the limits to enforce.
+	Limits DecodeLimits
+
+	depth, elements int
+}
+
+// Enter records entering an object, array or element.
+func (c *LimitChecker) Enter() error {
+	c.depth++
+	if c.Limits.MaxDepth > 0 && c.depth > c.Limits.MaxDepth {
+		return &DecodeLimitError{Limit: LimitDepth, Max: c.Limits.MaxDepth}
+	}
+	return nil
+}
+
+// Leave records leaving an object, array or element.
+func (c *LimitChecker) Leave() {
+	c.depth--
+}
+
+// Element records a value.
+func (c *LimitChecker) Element() error {
+	c.elements++
+	if c.Limits.MaxElements > 0 && c.elements > c.Limits.MaxElements {
+		return &DecodeLimitError{Limit: LimitElements, Max: c.Limits.MaxElements}
+	}
+	return nil
+}
+
+// String records a string of the length (in bytes).
+func (c *LimitChecker) String(length int) error {
+	if c.Limits.MaxStringLength > 0 && length > c.Limits.MaxStringLength {
+		return &DecodeLimitError{Limit: LimitStringLength, Max: c.Limits.MaxStringLength}
+	}
+	return nil
+}
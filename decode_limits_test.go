@@ -0,0 +1,53 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// limitedTestCodec is a LimitedCodec counting the bytes of its data as
+// elements.
+type limitedTestCodec struct {
+	test.TestCodec
+}
+
+func (c *limitedTestCodec) CheckLimits(data []byte, limits DecodeLimits) error {
+	checker := LimitChecker{Limits: limits}
+	for range data {
+		if err := checker.Element(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCheckLimits(t *testing.T) {
+
+	assert.NoError(t, CheckLimits(new(limitedTestCodec), []byte("abc"), DecodeLimits{}))
+	assert.NoError(t, CheckLimits(new(limitedTestCodec), []byte("abc"), DecodeLimits{MaxElements: 3}))
+	assert.Equal(t, &DecodeLimitError{Limit: LimitElements, Max: 2}, CheckLimits(new(limitedTestCodec), []byte("abc"), DecodeLimits{MaxElements: 2}))
+
+	// codecs that can't check limits aren't checked
+	assert.NoError(t, CheckLimits(new(test.TestCodec), []byte("abc"), DecodeLimits{MaxElements: 2}))
+
+}
+
+func TestLimitChecker(t *testing.T) {
+
+	checker := LimitChecker{Limits: DecodeLimits{MaxDepth: 2, MaxStringLength: 3}}
+
+	assert.NoError(t, checker.Enter())
+	assert.NoError(t, checker.Enter())
+	assert.Equal(t, &DecodeLimitError{Limit: LimitDepth, Max: 2}, checker.Enter())
+	checker.Leave()
+	checker.Leave()
+	assert.NoError(t, checker.Enter())
+
+	assert.NoError(t, checker.String(3))
+	assert.Equal(t, &DecodeLimitError{Limit: LimitStringLength, Max: 3}, checker.String(4))
+	assert.NoError(t, checker.Element())
+
+	assert.Equal(t, "codecs: Data exceeds the depth limit of 2.", (&DecodeLimitError{Limit: LimitDepth, Max: 2}).Error())
+
+}
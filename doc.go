@@ -5,6 +5,11 @@
 // To write a custom codec service, simply create a type that conforms to the CodecService interface.
 //
 // To write a custom codec, simply create a type that conforms to the Codec interface.
+// Codecs that can handle a structured syntax suffix (such as +json) should also conform to
+// the SuffixCodec interface, so vendor media types are negotiated to them.  Codecs answering to
+// more than one content type should conform to the AliasCodec interface, and VersionedCodec
+// installs a codec for one version of a media type.  Codecs that can write to an io.Writer
+// or read from an io.Reader as they go should conform to StreamEncoder and StreamDecoder.
 //
 // If you wish to customize what is encoded, also conform to the Facade interface.
 // This interface allows you to provide custom data to be encoded, rather than having your object encoded directly.
@@ -0,0 +1,390 @@
+package edn
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// characters gets the runes for the named characters.
+var characters = map[string]rune{
+	"newline": '\n',
+	"return":  '\r',
+	"space":   ' ',
+	"tab":     '\t',
+}
+
+// decoder parses EDN data into generic values.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// unmarshal decodes a single EDN value.
+func unmarshal(data []byte) (interface{}, error) {
+
+	d := &decoder{data: data}
+	value, err := d.value()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if d.skip(); d.pos != len(d.data) {
+		return nil, fmt.Errorf("codecs: edn: Unexpected data after offset %d", d.pos)
+	}
+
+	return value, nil
+}
+
+// skip moves past whitespace (including commas), comments and discarded
+// (#_) forms.
+func (d *decoder) skip() error {
+
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			d.pos++
+		case c == ';':
+			for d.pos < len(d.data) && d.data[d.pos] != '\n' {
+				d.pos++
+			}
+		case c == '#' && d.pos+1 < len(d.data) && d.data[d.pos+1] == '_':
+			d.pos += 2
+			if _, err := d.value(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) value() (interface{}, error) {
+
+	if err := d.skip(); err != nil {
+		return nil, err
+	}
+
+	if d.pos >= len(d.data) {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	switch c := d.data[d.pos]; c {
+	case '"':
+		return d.str()
+	case '\\':
+		return d.char()
+	case ':':
+		d.pos++
+		token := d.token()
+		if len(token) == 0 {
+			return nil, fmt.Errorf("codecs: edn: Empty keyword at offset %d", d.pos)
+		}
+		return Keyword(token), nil
+	case '(':
+		d.pos++
+		return d.collection(')')
+	case '[':
+		d.pos++
+		return d.collection(']')
+	case '{':
+		d.pos++
+		return d.mapping()
+	case '#':
+		d.pos++
+		if d.pos < len(d.data) && d.data[d.pos] == '{' {
+			d.pos++
+			return d.collection('}')
+		}
+		return d.tagged()
+	case ')', ']', '}':
+		return nil, fmt.Errorf("codecs: edn: Unexpected %q at offset %d", c, d.pos)
+	}
+
+	start := d.pos
+	token := d.token()
+
+	switch token {
+	case "":
+		return nil, fmt.Errorf("codecs: edn: Unexpected %q at offset %d", d.data[start], start)
+	case "nil":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	if isNumber(token) {
+		return number(token)
+	}
+
+	return Symbol(token), nil
+}
+
+// token reads a symbol-like run of characters.
+func (d *decoder) token() string {
+
+	start := d.pos
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || strings.IndexByte("()[]{}\";", c) >= 0 {
+			break
+		}
+		d.pos++
+	}
+
+	return string(d.data[start:d.pos])
+}
+
+func (d *decoder) collection(end byte) ([]interface{}, error) {
+
+	items := []interface{}{}
+	for {
+
+		if err := d.skip(); err != nil {
+			return nil, err
+		}
+		if d.pos >= len(d.data) {
+			return nil, ErrorUnexpectedEnd
+		}
+		if d.data[d.pos] == end {
+			d.pos++
+			return items, nil
+		}
+
+		item, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (d *decoder) mapping() (interface{}, error) {
+
+	items, err := d.collection('}')
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items)%2 != 0 {
+		return nil, fmt.Errorf("codecs: edn: Map literal must contain an even number of forms")
+	}
+
+	// use string keys when we can, as that's what everything else expects
+	stringKeys := true
+	for i := 0; i < len(items); i += 2 {
+		switch items[i].(type) {
+		case string, Keyword:
+		default:
+			stringKeys = false
+		}
+	}
+
+	if stringKeys {
+		m := make(map[string]interface{}, len(items)/2)
+		for i := 0; i < len(items); i += 2 {
+			switch key := items[i].(type) {
+			case string:
+				m[key] = items[i+1]
+			case Keyword:
+				m[string(key)] = items[i+1]
+			}
+		}
+		return m, nil
+	}
+
+	m := make(map[interface{}]interface{}, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		if !hashable(reflect.ValueOf(items[i])) {
+			return nil, fmt.Errorf("codecs: edn: Collections cannot be used as map keys")
+		}
+		m[items[i]] = items[i+1]
+	}
+	return m, nil
+}
+
+// hashable gets whether the value can be used as a key in a Go map, looking
+// inside the values it holds (such as a Tagged value's Value), since a type
+// like Tagged is comparable but panics as a key when it holds a collection.
+func hashable(v reflect.Value) bool {
+
+	if !v.IsValid() {
+		return true
+	}
+
+	if !v.Type().Comparable() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return hashable(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (d *decoder) tagged() (interface{}, error) {
+
+	tag := d.token()
+
+	if len(tag) == 0 {
+		return nil, fmt.Errorf("codecs: edn: Missing tag at offset %d", d.pos)
+	}
+
+	value, err := d.value()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reader, ok := tagReader(tag); ok {
+		return reader(value)
+	}
+
+	return Tagged{Tag: tag, Value: value}, nil
+}
+
+func (d *decoder) str() (string, error) {
+
+	d.pos++
+	var s strings.Builder
+
+	for d.pos < len(d.data) {
+
+		c := d.data[d.pos]
+		switch c {
+		case '"':
+			d.pos++
+			return s.String(), nil
+		case '\\':
+			if d.pos+1 >= len(d.data) {
+				return "", ErrorUnexpectedEnd
+			}
+			d.pos += 2
+			switch escaped := d.data[d.pos-1]; escaped {
+			case 't':
+				s.WriteByte('\t')
+			case 'r':
+				s.WriteByte('\r')
+			case 'n':
+				s.WriteByte('\n')
+			case '\\', '"':
+				s.WriteByte(escaped)
+			case 'u':
+				if d.pos+4 > len(d.data) {
+					return "", ErrorUnexpectedEnd
+				}
+				r, err := strconv.ParseUint(string(d.data[d.pos:d.pos+4]), 16, 32)
+				if err != nil {
+					return "", fmt.Errorf("codecs: edn: Invalid unicode escape at offset %d", d.pos)
+				}
+				s.WriteRune(rune(r))
+				d.pos += 4
+			default:
+				return "", fmt.Errorf("codecs: edn: Invalid escape %q at offset %d", escaped, d.pos-1)
+			}
+		default:
+			s.WriteByte(c)
+			d.pos++
+		}
+	}
+
+	return "", ErrorUnexpectedEnd
+}
+
+func (d *decoder) char() (rune, error) {
+
+	d.pos++
+	if d.pos >= len(d.data) {
+		return 0, ErrorUnexpectedEnd
+	}
+
+	// the first character is always part of it, even if it's a delimiter
+	r, size := utf8.DecodeRune(d.data[d.pos:])
+	d.pos += size
+	rest := d.token()
+
+	if len(rest) == 0 {
+		return r, nil
+	}
+
+	name := string(r) + rest
+	if named, ok := characters[name]; ok {
+		return named, nil
+	}
+	if r == 'u' && len(rest) == 4 {
+		if code, err := strconv.ParseUint(rest, 16, 32); err == nil {
+			return rune(code), nil
+		}
+	}
+
+	return 0, fmt.Errorf("codecs: edn: Invalid character \\%s", name)
+}
+
+// isNumber gets whether the token starts like a number.
+func isNumber(token string) bool {
+
+	if token[0] == '-' || token[0] == '+' {
+		token = token[1:]
+	}
+
+	return len(token) > 0 && token[0] >= '0' && token[0] <= '9'
+}
+
+func number(token string) (interface{}, error) {
+
+	switch {
+	case strings.HasSuffix(token, "M"):
+		f, _, err := big.ParseFloat(strings.TrimSuffix(token, "M"), 10, 0, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("codecs: edn: Invalid number %q", token)
+		}
+		return f, nil
+	case strings.HasSuffix(token, "N"):
+		i, ok := new(big.Int).SetString(strings.TrimSuffix(strings.TrimPrefix(token, "+"), "N"), 10)
+		if !ok {
+			return nil, fmt.Errorf("codecs: edn: Invalid number %q", token)
+		}
+		return i, nil
+	case strings.ContainsAny(token, ".eE"):
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("codecs: edn: Invalid number %q", token)
+		}
+		return f, nil
+	}
+
+	i, err := strconv.ParseInt(token, 10, 64)
+
+	if err != nil {
+		// fall back on a big integer if it's just too big
+		if big, ok := new(big.Int).SetString(strings.TrimPrefix(token, "+"), 10); ok {
+			return big, nil
+		}
+		return nil, fmt.Errorf("codecs: edn: Invalid number %q", token)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,25 @@
+// A codec for handling EDN (extensible data notation) encoding and decoding.
+//
+// Go values are written as their natural EDN counterparts: maps and structs
+// become maps (with string keys written as keywords), slices become vectors,
+// time.Time becomes an #inst and the Keyword, Symbol and Tagged types can be
+// used to write keywords, symbols and tagged literals explicitly.  Structs use
+// the `edn` struct tag, falling back on the `json` tag and then the field name.
+//
+// When unmarshalling into an interface{}, the values become:
+//
+//	nil, true, false      nil, bool
+//	integers              int64 (or *big.Int for N suffixed or oversized integers)
+//	floats                float64 (or *big.Float for M suffixed decimals)
+//	strings               string
+//	characters            rune
+//	keywords, symbols     Keyword, Symbol
+//	lists, vectors, sets  []interface{}
+//	maps                  map[string]interface{} if every key is a string or
+//	                      keyword, otherwise map[interface{}]interface{}
+//	tagged literals       the result of the registered TagReader, or Tagged
+//
+// #inst and #uuid are understood out of the box, and further tags can be
+// handled with RegisterTagReader.  Unmarshalling into any other type uses
+// encoding/json's rules on the generic values.
+package edn
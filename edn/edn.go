@@ -0,0 +1,84 @@
+package edn
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Keyword is an EDN keyword, held without its leading colon.
+type Keyword string
+
+// String gets the keyword as it is written in EDN.
+func (k Keyword) String() string {
+	return ":" + string(k)
+}
+
+// Symbol is an EDN symbol.
+type Symbol string
+
+// Tagged is a tagged literal (#tag value) that has no registered TagReader.
+// It can also be marshalled to write arbitrary tagged literals.
+type Tagged struct {
+	Tag   string
+	Value interface{}
+}
+
+// TagReader converts the value of a tagged literal into a Go value.
+type TagReader func(value interface{}) (interface{}, error)
+
+var (
+	tagReadersLock sync.RWMutex
+	tagReaders     = map[string]TagReader{
+		"inst": readInst,
+		"uuid": readUUID,
+	}
+)
+
+// RegisterTagReader registers the reader for tagged literals with the given
+// tag (without the leading #).  Registering a nil reader removes the tag, so
+// its literals are unmarshalled as Tagged values.
+func RegisterTagReader(tag string, reader TagReader) {
+
+	tagReadersLock.Lock()
+	defer tagReadersLock.Unlock()
+
+	if reader == nil {
+		delete(tagReaders, tag)
+		return
+	}
+	tagReaders[tag] = reader
+}
+
+// tagReader gets the reader registered for the tag.
+func tagReader(tag string) (TagReader, bool) {
+
+	tagReadersLock.RLock()
+	defer tagReadersLock.RUnlock()
+
+	reader, ok := tagReaders[tag]
+	return reader, ok
+}
+
+func readInst(value interface{}) (interface{}, error) {
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("codecs: edn: #inst expects a string, not %T", value)
+	}
+
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func readUUID(value interface{}) (interface{}, error) {
+
+	s, ok := value.(string)
+	if !ok || !uuidPattern.MatchString(s) {
+		return nil, fmt.Errorf("codecs: edn: Invalid #uuid %v", value)
+	}
+
+	return s, nil
+}
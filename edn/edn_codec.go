@@ -0,0 +1,65 @@
+package edn
+
+import (
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// EdnCodec converts objects to and from EDN.
+type EdnCodec struct{}
+
+func init() {
+	codecs.Register(new(EdnCodec))
+}
+
+// Marshal converts an object to EDN.
+func (c *EdnCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return marshal(object)
+}
+
+// Unmarshal converts EDN into an object.
+func (c *EdnCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	value, err := unmarshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	// generic values can be set directly
+	if target, ok := obj.(*interface{}); ok {
+		*target = value
+		return nil
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *EdnCodec) ContentType() string {
+	return constants.ContentTypeEDN
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *EdnCodec) FileExtension() string {
+	return constants.FileExtensionEDN
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *EdnCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,215 @@
+package edn
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var ednCodec EdnCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(EdnCodec), "EdnCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeEDN, ednCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionEDN, ednCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, ednCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name":    "Mat \"Stretchr\"",
+		"age":     30,
+		"score":   2.0,
+		"admin":   false,
+		"missing": nil,
+		"role":    Keyword("owner"),
+		"handler": Symbol("my.ns/handle"),
+		"tags":    []string{"a", "b"},
+		"joined":  time.Date(2013, 4, 1, 12, 30, 0, 0, time.UTC),
+		"point":   Tagged{Tag: "geo/point", Value: []float64{1.5, -2}},
+	}
+
+	bytes, err := ednCodec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{:admin false, :age 30, :handler my.ns/handle, :joined #inst "2013-04-01T12:30:00Z", :missing nil, :name "Mat \"Stretchr\"", :point #geo/point [1.5 -2.0], :role :owner, :score 2.0, :tags ["a" "b"]}`, string(bytes))
+	}
+
+}
+
+func TestMarshal_Struct(t *testing.T) {
+
+	type person struct {
+		Name    string `edn:"name"`
+		Email   string `json:"email,omitempty"`
+		Age     int
+		private string
+		Ignored bool `edn:"-"`
+	}
+
+	bytes, err := ednCodec.Marshal(&person{Name: "Tyler", Age: 12}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{:name "Tyler", :Age 12}`, string(bytes))
+	}
+
+}
+
+func TestMarshal_NonStringKeys(t *testing.T) {
+
+	bytes, err := ednCodec.Marshal(map[int]string{2: "b", 1: "a"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{1 "a", 2 "b"}`, string(bytes))
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	data := `
+	; a person
+	{:name "Mat\n" :age 30, :ratio 0.5 :big 12345678901234567890
+	 :exact 1.25M :admin true :nothing nil
+	 :role :owner :handler my.ns/handle
+	 :tags #{"a" "b"} :list (1 2) #_ :discarded #_ 1
+	 :chars [\a \newline \A \(]
+	 "plain" 1}`
+
+	var obj interface{}
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(data), &obj)) {
+
+		m := obj.(map[string]interface{})
+		assert.Equal(t, "Mat\n", m["name"])
+		assert.Equal(t, int64(30), m["age"])
+		assert.Equal(t, 0.5, m["ratio"])
+		expectedBig, _ := new(big.Int).SetString("12345678901234567890", 10)
+		assert.Equal(t, 0, expectedBig.Cmp(m["big"].(*big.Int)))
+		assert.Equal(t, "1.25", m["exact"].(*big.Float).Text('f', 2))
+		assert.Equal(t, true, m["admin"])
+		assert.Nil(t, m["nothing"])
+		assert.Equal(t, Keyword("owner"), m["role"])
+		assert.Equal(t, Symbol("my.ns/handle"), m["handler"])
+		assert.Equal(t, []interface{}{"a", "b"}, m["tags"])
+		assert.Equal(t, []interface{}{int64(1), int64(2)}, m["list"])
+		assert.Equal(t, []interface{}{'a', '\n', 'A', '('}, m["chars"])
+		assert.Equal(t, int64(1), m["plain"])
+		_, discarded := m["discarded"]
+		assert.False(t, discarded)
+		assert.Equal(t, 13, len(m))
+
+	}
+
+}
+
+func TestUnmarshal_NonStringKeys(t *testing.T) {
+
+	var obj interface{}
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(`{1 "a" sym "b"}`), &obj)) {
+		assert.Equal(t, map[interface{}]interface{}{int64(1): "a", Symbol("sym"): "b"}, obj)
+	}
+
+}
+
+func TestUnmarshal_TaggedLiterals(t *testing.T) {
+
+	var obj interface{}
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(`[#inst "2013-04-01T12:30:00Z" #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" #my/thing {:a 1}]`), &obj)) {
+		items := obj.([]interface{})
+		assert.Equal(t, time.Date(2013, 4, 1, 12, 30, 0, 0, time.UTC), items[0])
+		assert.Equal(t, "f81d4fae-7dec-11d0-a765-00a0c91e6bf6", items[1])
+		assert.Equal(t, Tagged{Tag: "my/thing", Value: map[string]interface{}{"a": int64(1)}}, items[2])
+	}
+
+	assert.Error(t, ednCodec.Unmarshal([]byte(`#uuid "nope"`), &obj))
+
+}
+
+func TestRegisterTagReader(t *testing.T) {
+
+	RegisterTagReader("my/upper", func(value interface{}) (interface{}, error) {
+		return Keyword(value.(string) + "!"), nil
+	})
+	defer RegisterTagReader("my/upper", nil)
+
+	var obj interface{}
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(`#my/upper "hi"`), &obj)) {
+		assert.Equal(t, Keyword("hi!"), obj)
+	}
+
+	// tag readers returning collections can't make map keys
+	RegisterTagReader("my/list", func(value interface{}) (interface{}, error) {
+		return []interface{}{value}, nil
+	})
+	defer RegisterTagReader("my/list", nil)
+	assert.EqualError(t, ednCodec.Unmarshal([]byte(`{#my/list 1 2}`), &obj), "codecs: edn: Collections cannot be used as map keys")
+
+	RegisterTagReader("my/upper", nil)
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(`#my/upper "hi"`), &obj)) {
+		assert.Equal(t, Tagged{Tag: "my/upper", Value: "hi"}, obj)
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	type person struct {
+		Name string   `json:"name"`
+		Age  int      `json:"age"`
+		Tags []string `json:"tags"`
+	}
+
+	var p person
+	if assert.NoError(t, ednCodec.Unmarshal([]byte(`{:name "Tyler" :age 12 :tags [:a "b"]}`), &p)) {
+		assert.Equal(t, person{Name: "Tyler", Age: 12, Tags: []string{"a", "b"}}, p)
+	}
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name":  "Mat",
+		"count": int64(2),
+		"items": []interface{}{1.5, Keyword("k"), Symbol("s"), nil},
+	}
+
+	bytes, err := ednCodec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		var back interface{}
+		if assert.NoError(t, ednCodec.Unmarshal(bytes, &back)) {
+			assert.Equal(t, obj, back)
+		}
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorUnexpectedEnd, ednCodec.Unmarshal([]byte(`{:a 1`), &obj))
+	assert.Equal(t, ErrorUnexpectedEnd, ednCodec.Unmarshal([]byte(`"open`), &obj))
+	assert.Error(t, ednCodec.Unmarshal([]byte(`{:a}`), &obj))
+	assert.Error(t, ednCodec.Unmarshal([]byte(`]`), &obj))
+	assert.Error(t, ednCodec.Unmarshal([]byte(`1 2`), &obj))
+	assert.Error(t, ednCodec.Unmarshal([]byte(`{[1] 2}`), &obj))
+	assert.EqualError(t, ednCodec.Unmarshal([]byte(`{#foo [1 2] 1}`), &obj), "codecs: edn: Collections cannot be used as map keys")
+
+	_, isInvalid := ednCodec.Unmarshal([]byte(`1`), obj).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
@@ -0,0 +1,285 @@
+package edn
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	taggedType        = reflect.TypeOf(Tagged{})
+	bigIntType        = reflect.TypeOf(big.Int{})
+	bigFloatType      = reflect.TypeOf(big.Float{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encoder writes EDN data.
+type encoder struct {
+	buffer bytes.Buffer
+}
+
+// marshal encodes the object as EDN.
+func marshal(object interface{}) ([]byte, error) {
+
+	e := new(encoder)
+
+	if err := e.encode(reflect.ValueOf(object)); err != nil {
+		return nil, err
+	}
+
+	return e.buffer.Bytes(), nil
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+
+	if !v.IsValid() {
+		e.buffer.WriteString("nil")
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		e.buffer.WriteString("#inst ")
+		e.writeString(v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	case taggedType:
+		tagged := v.Interface().(Tagged)
+		e.buffer.WriteString("#" + tagged.Tag + " ")
+		return e.encode(reflect.ValueOf(tagged.Value))
+	case bigIntType:
+		i := v.Interface().(big.Int)
+		e.buffer.WriteString(i.String() + "N")
+		return nil
+	case bigFloatType:
+		f := v.Interface().(big.Float)
+		e.buffer.WriteString(f.Text('g', -1) + "M")
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buffer.WriteString("nil")
+			return nil
+		}
+		return e.encode(v.Elem())
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.writeString(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.buffer.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buffer.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buffer.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return e.writeFloat(v.Float())
+	case reflect.String:
+		switch v.Interface().(type) {
+		case Keyword:
+			e.buffer.WriteString(":" + v.String())
+		case Symbol:
+			e.buffer.WriteString(v.String())
+		default:
+			e.writeString(v.String())
+		}
+	case reflect.Slice, reflect.Array:
+		e.buffer.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buffer.WriteString(" ")
+			}
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buffer.WriteString("]")
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		e.buffer.WriteString("{")
+		first := true
+		for _, f := range structFields(v.Type()) {
+			value := v.Field(f.index)
+			if f.omitEmpty && isEmptyValue(value) {
+				continue
+			}
+			if !first {
+				e.buffer.WriteString(", ")
+			}
+			first = false
+			e.buffer.WriteString(":" + f.name + " ")
+			if err := e.encode(value); err != nil {
+				return err
+			}
+		}
+		e.buffer.WriteString("}")
+	default:
+		return fmt.Errorf("codecs: edn: Cannot encode %s", v.Type())
+	}
+
+	return nil
+}
+
+// encodeMap writes the map with its entries sorted by key, so the output is
+// stable.
+func (e *encoder) encodeMap(v reflect.Value) error {
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	for _, key := range v.MapKeys() {
+
+		// string keys are written as keywords
+		if key.Kind() == reflect.String && key.Type() != reflect.TypeOf(Symbol("")) {
+			entries = append(entries, entry{":" + key.String(), v.MapIndex(key)})
+			continue
+		}
+
+		keyEncoder := new(encoder)
+		if err := keyEncoder.encode(key); err != nil {
+			return err
+		}
+		entries = append(entries, entry{keyEncoder.buffer.String(), v.MapIndex(key)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	e.buffer.WriteString("{")
+	for i, entry := range entries {
+		if i > 0 {
+			e.buffer.WriteString(", ")
+		}
+		e.buffer.WriteString(entry.key + " ")
+		if err := e.encode(entry.value); err != nil {
+			return err
+		}
+	}
+	e.buffer.WriteString("}")
+
+	return nil
+}
+
+func (e *encoder) writeFloat(f float64) error {
+
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("codecs: edn: Cannot encode %v", f)
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	// make sure it reads back as a float rather than an integer
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	e.buffer.WriteString(s)
+
+	return nil
+}
+
+func (e *encoder) writeString(s string) {
+
+	e.buffer.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			e.buffer.WriteString(`\"`)
+		case '\\':
+			e.buffer.WriteString(`\\`)
+		case '\n':
+			e.buffer.WriteString(`\n`)
+		case '\r':
+			e.buffer.WriteString(`\r`)
+		case '\t':
+			e.buffer.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&e.buffer, `\u%04x`, r)
+			} else {
+				e.buffer.WriteRune(r)
+			}
+		}
+	}
+	e.buffer.WriteByte('"')
+
+}
+
+// field describes a struct field.
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields gets the exported fields of the struct type.
+func structFields(t reflect.Type) []field {
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+
+		tag := sf.Tag.Get("edn")
+		if len(tag) == 0 {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		f := field{name: sf.Name, index: i}
+		parts := strings.Split(tag, ",")
+		if len(parts[0]) > 0 {
+			f.name = parts[0]
+		}
+		for _, option := range parts[1:] {
+			if option == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
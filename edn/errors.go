@@ -0,0 +1,26 @@
+package edn
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorUnexpectedEnd is returned when the data ends part way through a value.
+var ErrorUnexpectedEnd = errors.New("codecs: edn: Unexpected end of data")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: edn: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: edn: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: edn: Unmarshal(nil " + e.Type.String() + ")"
+}
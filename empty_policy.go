@@ -0,0 +1,49 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// EmptyPolicy says what public data does with nil and empty values, so that
+// every codec treats them the same way.  Give it with the
+// constants.OptionKeyEmpty option.
+type EmptyPolicy int
+
+const (
+	// EmptyAsNull leaves nil and empty values as they are, so that nil
+	// pointers, slices and maps are marshalled as null.  It is the default.
+	EmptyAsNull EmptyPolicy = iota
+
+	// EmptyOmit leaves nil pointers, empty slices and maps, and zero values
+	// out of maps and structs.
+	EmptyOmit
+
+	// EmptyAsCollection makes nil slices and maps empty, so that they are
+	// marshalled as [] and {} rather than null.
+	EmptyAsCollection
+)
+
+// emptyPolicy gets the policy given by the constants.OptionKeyEmpty option.
+func emptyPolicy(options map[string]interface{}) EmptyPolicy {
+	policy, _ := options[constants.OptionKeyEmpty].(EmptyPolicy)
+	return policy
+}
+
+// isEmpty gets whether the value is nil, an empty collection or string, or a
+// zero value.
+func isEmpty(value reflect.Value) bool {
+
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	}
+
+	return value.IsZero()
+}
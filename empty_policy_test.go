@@ -0,0 +1,54 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// profile has nil and empty fields.
+type profile struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Tags    []string          `json:"tags"`
+	Links   map[string]string `json:"links"`
+	Manager *profile          `json:"manager"`
+	Joined  time.Time         `json:"joined"`
+}
+
+func TestPublicData_EmptyPolicy(t *testing.T) {
+
+	object := &profile{Name: "Mat"}
+
+	// nil values are left as they are by default
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(object))
+	}
+
+	public, err = PublicData(object, Options{Empty: EmptyOmit}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"name": "Mat"}, public)
+	}
+
+	public, err = PublicData(object, map[string]interface{}{constants.OptionKeyEmpty: EmptyAsCollection})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"name":    "Mat",
+			"age":     0,
+			"tags":    []interface{}{},
+			"links":   map[string]interface{}{},
+			"manager": nil,
+			"joined":  time.Time{},
+		}, public)
+	}
+
+	public, err = PublicData(map[string]interface{}{"people": []*profile{object}, "none": []string(nil)}, Options{Empty: EmptyAsCollection}.Map())
+	if assert.NoError(t, err) {
+		people := public.(map[string]interface{})["people"].([]interface{})
+		assert.Equal(t, []interface{}{}, people[0].(map[string]interface{})["tags"])
+		assert.Equal(t, []interface{}{}, public.(map[string]interface{})["none"])
+	}
+
+}
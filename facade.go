@@ -1,10 +1,13 @@
 package codecs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/stew/objects"
 	"reflect"
+	"strings"
 )
 
 const (
@@ -47,6 +50,30 @@ type Facade interface {
 	PublicData(options map[string]interface{}) (publicData interface{}, err error)
 }
 
+// FacadeWithContext is the interface objects can implement (instead of, or as
+// well as, Facade) if their public data depends on the request, such as on the
+// requesting user, locale or feature flags carried by the context.
+type FacadeWithContext interface {
+
+	// PublicDataContext should return an object containing the data to be
+	// marshalled, as Facade's PublicData does.  The context is the one given
+	// to PublicDataContext (or context.Background() when PublicData is
+	// used).
+	PublicDataContext(ctx context.Context, options map[string]interface{}) (publicData interface{}, err error)
+}
+
+// VersionedFacade is the interface objects can implement (instead of, or as
+// well as, Facade) if they have a public representation for each version of
+// an API.  The version is given by the constants.OptionKeyVersion option,
+// which services set from the version negotiated (see
+// codecs.VersionedCodec), and is empty if none was.
+type VersionedFacade interface {
+
+	// PublicDataV should return an object containing the data to be
+	// marshalled for the version, as Facade's PublicData does.
+	PublicDataV(version string, options map[string]interface{}) (publicData interface{}, err error)
+}
+
 // PublicData gets the data that is considered public for the specified object.
 // If the object implements the Facade interface, its PublicData method is called
 // until the returning object no longer implements the Facade interface at which point
@@ -54,7 +81,44 @@ type Facade interface {
 //
 // If the object passed in is an array or slice, PublicData is called on each object
 // to build up an array of public versions of the objects, and an array will be
-// returned.
+// returned.  The same goes for channels (which are received from until they are
+// closed) and iterator functions (see IsSequence).
+//
+// The public data is resolved recursively, so Facade objects held in map values
+// and struct fields are replaced by their public data too.  Maps and structs
+// holding no Facade objects are returned as they are; structs that do are
+// converted into map[string]interface{}s keyed by the fields' json tag names (or
+// field names), leaving out unexported fields and those tagged "-".
+//
+// Struct fields can be hidden from some roles by tagging them with the roles that
+// may see them (such as `public:"admin,owner"`) or by registering a
+// VisibilityPolicy for the struct (see RegisterVisibilityPolicy).  The roles are
+// given by the constants.OptionKeyRoles option, and structs with hidden fields
+// are always converted into map[string]interface{}s.
+//
+// If the constants.OptionKeyStructTags option is true, structs with codec tags
+// are always converted too, naming their fields by the tags (as in
+// `codec:"name,omitempty"`, or `codec:"-"` to leave a field out) so that they
+// are named the same way by every codec.
+//
+// The constants.OptionKeyEmpty option gives the EmptyPolicy saying whether nil
+// and empty values are left as they are, left out, or made empty collections,
+// and the constants.OptionKeyKeyCase option the KeyCase to convert keys into.
+// Times are given as the constants.OptionKeyTimeLayout,
+// constants.OptionKeyTimeUTC and constants.OptionKeyTimeUnix options say, if
+// any are given, and integers too large for JavaScript numbers are given as
+// strings if the constants.OptionKeyIntsAsStrings option is true.  Values of
+// types with adapters (see RegisterAdapter) are replaced by what their
+// adapters convert them into.  If the constants.OptionKeyTextMarshalers option
+// is true, values implementing encoding.TextMarshaler are replaced by their
+// text (and others implementing encoding.BinaryMarshaler by their bytes), so
+// that every codec represents them as encoding/json does.
+//
+// The constants.OptionKeyFieldNames option gives FieldNames renaming the fields
+// of structs, which wins over their tags.
+//
+// Fields registered with RegisterComputedField are added to the public data of
+// structs, and of Facade objects whose public data is a map.
 //
 // If the resulting object is not of the appropriate type, the PublicDataDidNotFindMap error will
 // be returned.
@@ -62,15 +126,23 @@ type Facade interface {
 // If one of the PublicData methods returns itself (or another object already in the path)
 // thus resulting in too much recursion, the PublicDataTooMuchRecursion error is returned.
 //
-// If any of the objects' PublicData() method returns an error, that is directly returned.
+// If any of the objects' PublicData() method returns an error, that is directly returned,
+// unless the constants.OptionKeyFacadeErrors option gives another FacadeErrorPolicy
+// for the elements of arrays and slices.
 func PublicData(object interface{}, options map[string]interface{}) (interface{}, error) {
-	return publicData(object, 0, options)
+	return resolvePublicData(context.Background(), object, options)
+}
+
+// PublicDataContext gets the public data of the object as PublicData does,
+// passing the context to objects implementing FacadeWithContext.
+func PublicDataContext(ctx context.Context, object interface{}, options map[string]interface{}) (interface{}, error) {
+	return resolvePublicData(ctx, object, options)
 }
 
 // PublicDataMap calls PublicData and returns the result after type asserting to objects.Map
 func PublicDataMap(object interface{}, options map[string]interface{}) (objects.Map, error) {
 
-	data, err := publicData(object, 0, options)
+	data, err := resolvePublicData(context.Background(), object, options)
 
 	if err != nil {
 		return nil, err
@@ -101,7 +173,7 @@ func PublicDataMap(object interface{}, options map[string]interface{}) (objects.
 
 // publicData performs the work of PublicData keeping track of the level in order
 // to ensure the code doesn't recurse too much.
-func publicData(object interface{}, level int, options map[string]interface{}) (interface{}, error) {
+func publicData(ctx context.Context, object interface{}, level int, options map[string]interface{}) (interface{}, error) {
 
 	// make sure we don't end up with too much recusrion
 	if level > facadeMaxRecursionLevel {
@@ -120,7 +192,7 @@ func publicData(object interface{}, level int, options map[string]interface{}) (
 
 		// make an array to hold the items
 		length := objectValue.Len()
-		arr := make([]interface{}, length)
+		arr := make([]interface{}, 0, length)
 
 		// get the public data for each item
 		for subObjIndex := 0; subObjIndex < length; subObjIndex++ {
@@ -129,15 +201,22 @@ func publicData(object interface{}, level int, options map[string]interface{}) (
 			subObj := objectValue.Index(subObjIndex).Interface()
 
 			// ask for the object's public data
-			subPublic, subPublicErr := publicData(subObj, level+1, options)
+			subPublic, subPublicErr := publicData(ctx, subObj, level+1, options)
 
-			// throw an error if there is one
+			// throw an error if there is one, unless the policy says
+			// otherwise
 			if subPublicErr != nil {
-				return nil, subPublicErr
+				var keep bool
+				if subPublic, keep, subPublicErr = handleElementError(subObjIndex, subPublicErr, options); subPublicErr != nil {
+					return nil, subPublicErr
+				}
+				if !keep {
+					continue
+				}
 			}
 
 			// add the item to the array
-			arr[subObjIndex] = subPublic
+			arr = append(arr, subPublic)
 
 		}
 
@@ -146,10 +225,16 @@ func publicData(object interface{}, level int, options map[string]interface{}) (
 
 	}
 
+	// handle channels and iterator functions, whose elements are gathered
+	// into an array (see PublicDataSequence for getting them as they come)
+	if isSequence(objectValue) {
+		return sequenceSlice(ctx, objectValue, level, options)
+	}
+
 	// cast the object
-	if objectWithFacade, ok := object.(Facade); ok {
+	if isFacade(object) {
 
-		publicObject, err := objectWithFacade.PublicData(options)
+		publicObject, err := facadePublicData(ctx, object, options)
 
 		// return the public data error if there was one
 		if err != nil {
@@ -158,9 +243,271 @@ func publicData(object interface{}, level int, options map[string]interface{}) (
 
 		// recursivly call publicData until the object no longer
 		// implements the Facade interface.
-		return publicData(publicObject, level+1, options)
+		public, err := publicData(ctx, publicObject, level+1, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return addComputedFields(object, public), nil
+	}
+
+	// resolve any Facade objects in maps and structs
+	if resolved, changed, err := nestedPublicData(ctx, objectValue, level, options); err != nil || changed {
+		return resolved, err
 	}
 
 	// we can't do anything - so just return the object back
 	return object, nil
 }
+
+// nestedPublicData resolves the public data of the Facade objects held in the
+// map, struct, array or slice (or pointer to one), getting whether anything
+// changed.
+func nestedPublicData(ctx context.Context, value reflect.Value, level int, options map[string]interface{}) (interface{}, bool, error) {
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil, false, nil
+		}
+		return nestedPublicData(ctx, value.Elem(), level+1, options)
+
+	case reflect.Array, reflect.Slice:
+		var resolved []interface{}
+		for i := 0; i < value.Len(); i++ {
+
+			public, changed, err := nestedItemPublicData(ctx, value.Index(i), level, options)
+			if err != nil {
+				return nil, false, err
+			}
+
+			// copy the items the first time one changes
+			if changed && resolved == nil {
+				resolved = make([]interface{}, value.Len())
+				for j := 0; j < i; j++ {
+					resolved[j] = value.Index(j).Interface()
+				}
+			}
+			if resolved != nil {
+				resolved[i] = public
+			}
+		}
+		return resolved, resolved != nil, nil
+
+	case reflect.Map:
+		var resolved reflect.Value
+		for _, key := range value.MapKeys() {
+
+			public, changed, err := nestedItemPublicData(ctx, value.MapIndex(key), level, options)
+			if err != nil {
+				return nil, false, err
+			}
+			if !changed {
+				continue
+			}
+
+			// copy the map the first time a value changes, keeping its type
+			// if its values can hold the public data
+			if !resolved.IsValid() {
+				mapType := value.Type()
+				if mapType.Elem() != interfaceType {
+					mapType = reflect.MapOf(mapType.Key(), interfaceType)
+				}
+				resolved = reflect.MakeMapWithSize(mapType, value.Len())
+				for _, copyKey := range value.MapKeys() {
+					resolved.SetMapIndex(copyKey, value.MapIndex(copyKey))
+				}
+			}
+			resolved.SetMapIndex(key, reflectValueOf(public))
+		}
+		if !resolved.IsValid() {
+			return nil, false, nil
+		}
+		return resolved.Interface(), true, nil
+
+	case reflect.Struct:
+
+		// structs with fields hidden from some roles, named by codec tags or
+		// FieldNames, or computed are always converted
+		info := typeInfoFor(value.Type())
+		policy := visibilityPolicy(value.Type())
+		tagged := info.codecTags && structTags(options)
+		computed := computedFieldsFor(value.Type())
+		renamed := fieldNamesFor(value.Type(), options)
+		if policy == nil && !tagged && len(computed) == 0 && renamed == nil && !info.fieldsMayHoldFacade {
+			return nil, false, nil
+		}
+
+		changedAny := policy != nil || tagged || len(computed) > 0 || renamed != nil
+		clientRoles := roles(options)
+		fields := make(map[string]interface{}, len(info.fields))
+		for _, field := range info.fields {
+
+			name, omitEmpty := field.name, false
+			if tagged {
+				name, omitEmpty = field.codecName, field.omitEmpty
+			}
+			if rename, ok := renamed[field.Name]; ok {
+				name = rename
+			}
+			if len(name) == 0 {
+				continue
+			}
+			if policy != nil && !policy(field.StructField, clientRoles) {
+				continue
+			}
+
+			// without codec tags, fields are written as encoding/json
+			// writes them
+			fieldValue, ok := field.jsonValue(value)
+			if tagged {
+				fieldValue, ok = field.fieldValue(value)
+			}
+			if !ok || (omitEmpty && fieldValue.IsZero()) {
+				continue
+			}
+
+			public, changed, err := nestedItemPublicData(ctx, fieldValue, level, options)
+			if err != nil {
+				return nil, false, err
+			}
+
+			changedAny = changedAny || changed
+			fields[name] = public
+		}
+		compute(computed, value, fields)
+		if !changedAny {
+			return nil, false, nil
+		}
+		return fields, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// nestedItemPublicData gets the public data of a value held in a map, struct,
+// array or slice, and whether it differs from the value.
+func nestedItemPublicData(ctx context.Context, item reflect.Value, level int, options map[string]interface{}) (interface{}, bool, error) {
+
+	// make sure we don't end up with too much recursion
+	if level+1 > facadeMaxRecursionLevel {
+		return nil, false, PublicDataTooMuchRecursion
+	}
+
+	for item.Kind() == reflect.Interface && !item.IsNil() {
+		item = item.Elem()
+	}
+
+	object := item.Interface()
+
+	if !mayHoldFacade(item.Type()) || isNil(item) {
+		return object, false, nil
+	}
+
+	if isFacade(object) {
+		public, err := publicData(ctx, object, level+1, options)
+		return public, true, err
+	}
+
+	public, changed, err := nestedPublicData(ctx, item, level+1, options)
+	if !changed {
+		return object, false, err
+	}
+	return public, true, err
+}
+
+// facadeType, facadeWithContextType and versionedFacadeType are the types of
+// the Facade, FacadeWithContext and VersionedFacade interfaces.
+var (
+	facadeType            = reflect.TypeOf((*Facade)(nil)).Elem()
+	facadeWithContextType = reflect.TypeOf((*FacadeWithContext)(nil)).Elem()
+	versionedFacadeType   = reflect.TypeOf((*VersionedFacade)(nil)).Elem()
+)
+
+// isFacade gets whether the object implements Facade, FacadeWithContext or
+// VersionedFacade.
+func isFacade(object interface{}) bool {
+	switch object.(type) {
+	case Facade, FacadeWithContext, VersionedFacade:
+		return true
+	}
+	return false
+}
+
+// facadePublicData gets the public data of the Facade, FacadeWithContext or
+// VersionedFacade object, preferring PublicDataV and then PublicDataContext.
+func facadePublicData(ctx context.Context, object interface{}, options map[string]interface{}) (interface{}, error) {
+	if versioned, ok := object.(VersionedFacade); ok {
+		version, _ := options[constants.OptionKeyVersion].(string)
+		return versioned.PublicDataV(version, options)
+	}
+	if objectWithContext, ok := object.(FacadeWithContext); ok {
+		return objectWithContext.PublicDataContext(ctx, options)
+	}
+	return object.(Facade).PublicData(options)
+}
+
+// interfaceType is the type of interface{}.
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// mayHoldFacade gets whether values of the type may be, or hold, Facade
+// objects, so that values that can't (such as []byte) needn't be walked.
+func mayHoldFacade(t reflect.Type) bool {
+	return typeInfoFor(t).mayHoldFacade
+}
+
+// computeMayHoldFacade works out mayHoldFacade for the type.  It doesn't use
+// the cache, since it is called while the cache is being filled in (and stops
+// at structs, so recursive types are fine).
+func computeMayHoldFacade(t reflect.Type) bool {
+
+	if t.Implements(facadeType) || t.Implements(facadeWithContextType) || t.Implements(versionedFacadeType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Array, reflect.Slice, reflect.Map:
+		return computeMayHoldFacade(t.Elem())
+	}
+
+	return false
+}
+
+// isNil gets whether the value is a nil pointer, interface, map or slice.
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return value.IsNil()
+	}
+	return false
+}
+
+// reflectValueOf gets the value of the object, or the zero interface{} value
+// for nil.
+func reflectValueOf(object interface{}) reflect.Value {
+	if object == nil {
+		return reflect.Zero(interfaceType)
+	}
+	return reflect.ValueOf(object)
+}
+
+// publicFieldName gets the name of the struct field in public data, which is
+// its json tag name (or its name), or empty if it is tagged "-".
+func publicFieldName(field reflect.StructField) string {
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+
+	if comma := strings.Index(tag, ","); comma >= 0 {
+		tag = tag[:comma]
+	}
+	if len(tag) > 0 {
+		return tag
+	}
+
+	return field.Name
+}
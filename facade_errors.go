@@ -0,0 +1,101 @@
+package codecs
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs/constants"
+	"sync"
+)
+
+// FacadeErrorPolicy says what PublicData does when getting the public data of
+// an element of an array or slice fails.  Give it with the
+// constants.OptionKeyFacadeErrors option.
+type FacadeErrorPolicy int
+
+const (
+	// FacadeErrorFail fails the whole of the public data with the error.
+	// It is the default.
+	FacadeErrorFail FacadeErrorPolicy = iota
+
+	// FacadeErrorSkip leaves the element out.
+	FacadeErrorSkip
+
+	// FacadeErrorPlaceholder puts an ElementErrorPlaceholder in place of the
+	// element.
+	FacadeErrorPlaceholder
+)
+
+// ElementErrorPlaceholder is the public data put in place of elements whose
+// public data can't be got under the FacadeErrorPlaceholder policy.
+var ElementErrorPlaceholder = map[string]interface{}{"error": "unavailable"}
+
+// ElementError is the error getting the public data of the element at the
+// index of an array or slice.
+type ElementError struct {
+	Index int
+	Err   error
+}
+
+// Error gets the error message.
+func (e ElementError) Error() string {
+	return fmt.Sprintf("codecs: Element %d: %s", e.Index, e.Err)
+}
+
+// ElementErrors collects the errors of the elements skipped or replaced by
+// placeholders under the FacadeErrorSkip and FacadeErrorPlaceholder policies.
+// Give a *ElementErrors with the constants.OptionKeyElementErrors option to
+// find out which elements they were.  The index of each is its index in the
+// array or slice holding it.
+type ElementErrors struct {
+	lock   sync.Mutex
+	errors []ElementError
+}
+
+// Errors gets the errors collected, in the order they happened in.
+func (e *ElementErrors) Errors() []ElementError {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]ElementError(nil), e.errors...)
+}
+
+// Indexes gets the indexes of the elements the errors were for.
+func (e *ElementErrors) Indexes() []int {
+	var indexes []int
+	for _, err := range e.Errors() {
+		indexes = append(indexes, err.Index)
+	}
+	return indexes
+}
+
+// add collects the error.
+func (e *ElementErrors) add(err ElementError) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.errors = append(e.errors, err)
+}
+
+// handleElementError applies the FacadeErrorPolicy given by the options to the
+// error getting the public data of the element at the index, getting the
+// public data to use instead and whether to keep the element, or the error to
+// fail with.
+func handleElementError(index int, err error, options map[string]interface{}) (interface{}, bool, error) {
+
+	policy, _ := options[constants.OptionKeyFacadeErrors].(FacadeErrorPolicy)
+	if policy == FacadeErrorFail || err == PublicDataTooMuchRecursion {
+		return nil, false, err
+	}
+
+	if collected, ok := options[constants.OptionKeyElementErrors].(*ElementErrors); ok {
+		collected.add(ElementError{Index: index, Err: err})
+	}
+
+	if policy == FacadeErrorSkip {
+		return nil, false, nil
+	}
+
+	placeholder := make(map[string]interface{}, len(ElementErrorPlaceholder))
+	for key, value := range ElementErrorPlaceholder {
+		placeholder[key] = value
+	}
+
+	return placeholder, true, nil
+}
@@ -0,0 +1,48 @@
+package codecs
+
+import (
+	"errors"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// flaky fails to get its public data if it is broken.
+type flaky struct {
+	ID     int
+	Broken bool
+}
+
+func (f *flaky) PublicData(options map[string]interface{}) (interface{}, error) {
+	if f.Broken {
+		return nil, errors.New("broken")
+	}
+	return map[string]interface{}{"id": f.ID}, nil
+}
+
+func TestPublicData_FacadeErrors(t *testing.T) {
+
+	items := []*flaky{{ID: 1}, {ID: 2, Broken: true}, {ID: 3}, {ID: 4, Broken: true}}
+
+	_, err := PublicData(items, nil)
+	assert.EqualError(t, err, "broken")
+
+	collected := new(ElementErrors)
+	public, err := PublicData(items, Options{FacadeErrors: FacadeErrorSkip, ElementErrors: collected}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 3}}, public)
+		assert.Equal(t, []int{1, 3}, collected.Indexes())
+		assert.EqualError(t, collected.Errors()[0], "codecs: Element 1: broken")
+	}
+
+	public, err = PublicData(items, map[string]interface{}{constants.OptionKeyFacadeErrors: FacadeErrorPlaceholder})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"error": "unavailable"},
+			map[string]interface{}{"id": 3},
+			map[string]interface{}{"error": "unavailable"},
+		}, public)
+	}
+
+}
@@ -1,6 +1,7 @@
 package codecs
 
 import (
+	"context"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/codecs/test"
 	"github.com/stretchr/stew/objects"
@@ -171,3 +172,191 @@ func TestPublicData_WithRecursion_WithObjects(t *testing.T) {
 	mock.AssertExpectationsForObjects(t, o.Mock, o1.Mock, o2.Mock)
 
 }
+
+// author is a Facade hiding its email address.
+type author struct {
+	Name, Email string
+}
+
+func (a *author) PublicData(options map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"name": a.Name}, nil
+}
+
+// post holds a Facade in a field.
+type post struct {
+	Title    string   `json:"title"`
+	Author   *author  `json:"author"`
+	Tags     []string `json:"tags,omitempty"`
+	Internal string   `json:"-"`
+	secret   string
+}
+
+func TestPublicData_Nested(t *testing.T) {
+
+	mat := &author{Name: "Mat", Email: "mat@example.com"}
+
+	// struct fields
+	public, err := PublicData(&post{Title: "Hello", Author: mat, Tags: []string{"go"}, Internal: "x", secret: "y"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"title": "Hello", "author": map[string]interface{}{"name": "Mat"}, "tags": []string{"go"}}, public)
+	}
+
+	// map values, keeping the map's type
+	public, err = PublicData(objects.Map{"author": mat, "count": 1}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, objects.Map{"author": map[string]interface{}{"name": "Mat"}, "count": 1}, public)
+	}
+
+	public, err = PublicData(map[string]*author{"author": mat}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"author": map[string]interface{}{"name": "Mat"}}, public)
+	}
+
+	// slices in maps
+	public, err = PublicData(map[string]interface{}{"authors": []*author{mat}}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"authors": []interface{}{map[string]interface{}{"name": "Mat"}}}, public)
+	}
+
+	// data without Facade objects is left as it is
+	unchanged := &post{Title: "Hello", Tags: []string{"go"}}
+	public, err = PublicData(unchanged, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(unchanged))
+	}
+
+}
+
+// timestamps is embedded in article, so its fields are promoted.
+type timestamps struct {
+	Created int64 `json:"created,string"`
+	Updated int64 `json:"updated,omitempty"`
+}
+
+// article holds a Facade in a field, and embeds timestamps.
+type article struct {
+	timestamps
+	*post
+	Title  string  `json:"title"`
+	Author *author `json:"author"`
+	Views  int     `json:"views,omitempty"`
+}
+
+func TestPublicData_Nested_JSONTags(t *testing.T) {
+
+	mat := &author{Name: "Mat", Email: "mat@example.com"}
+
+	// empty fields tagged omitempty are left out, fields tagged string are
+	// quoted, embedded struct fields are promoted (though not the ones they
+	// share names with) and nil embedded struct pointers are skipped
+	public, err := PublicData(&article{timestamps: timestamps{Created: 1}, Title: "Hello", Author: mat}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"created": "1", "title": "Hello", "author": map[string]interface{}{"name": "Mat"}}, public)
+	}
+
+	public, err = PublicData(&article{post: &post{Title: "Hidden", Tags: []string{"go"}}, Author: mat, Views: 2}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"created": "0", "title": "", "author": map[string]interface{}{"name": "Mat"}, "tags": []string{"go"}, "views": 2}, public)
+	}
+
+}
+
+// cycle refers to itself.
+type cycle struct {
+	Next *cycle
+}
+
+func TestPublicData_Nested_WithRecursion(t *testing.T) {
+
+	c := new(cycle)
+	c.Next = c
+
+	_, err := PublicData(c, nil)
+	assert.Equal(t, PublicDataTooMuchRecursion, err)
+
+}
+
+// contextKey is the type of the context keys used in tests.
+type contextKey string
+
+// localizedGreeting is a FacadeWithContext greeting in the context's locale.
+type localizedGreeting struct{}
+
+func (g *localizedGreeting) PublicDataContext(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	if locale, _ := ctx.Value(contextKey("locale")).(string); locale == "fr" {
+		return map[string]interface{}{"greeting": "Bonjour"}, nil
+	}
+	return map[string]interface{}{"greeting": "Hello"}, nil
+}
+
+func TestPublicDataContext(t *testing.T) {
+
+	ctx := context.WithValue(context.Background(), contextKey("locale"), "fr")
+
+	public, err := PublicDataContext(ctx, &localizedGreeting{}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"greeting": "Bonjour"}, public)
+	}
+
+	// nested objects get the context too
+	public, err = PublicDataContext(ctx, map[string]interface{}{"message": &localizedGreeting{}}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"message": map[string]interface{}{"greeting": "Bonjour"}}, public)
+	}
+
+	// PublicData uses the background context
+	public, err = PublicData(&localizedGreeting{}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"greeting": "Hello"}, public)
+	}
+
+}
+
+// account has fields hidden from some roles.
+type account struct {
+	Name  string `json:"name"`
+	Email string `json:"email" public:"admin,owner"`
+	Notes string `json:"notes" public:"admin"`
+}
+
+// badge is given a VisibilityPolicy.
+type badge struct {
+	Label  string `json:"label"`
+	Secret string `json:"secret"`
+}
+
+func TestPublicData_Roles(t *testing.T) {
+
+	a := &account{Name: "Mat", Email: "mat@example.com", Notes: "VIP"}
+
+	public, err := PublicData(a, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"name": "Mat"}, public)
+	}
+
+	public, err = PublicData(a, map[string]interface{}{constants.OptionKeyRoles: "owner"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"name": "Mat", "email": "mat@example.com"}, public)
+	}
+
+	public, err = PublicData([]*account{a}, Options{Roles: []string{"admin"}}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Mat", "email": "mat@example.com", "notes": "VIP"}}, public)
+	}
+
+	RegisterVisibilityPolicy(&badge{}, func(field reflect.StructField, roles []string) bool {
+		return field.Name != "Secret" || len(roles) > 0
+	})
+	defer RegisterVisibilityPolicy(&badge{}, nil)
+
+	public, err = PublicData(badge{Label: "gold", Secret: "x"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"label": "gold"}, public)
+	}
+
+	public, err = PublicData(badge{Label: "gold", Secret: "x"}, Options{Roles: []string{"staff"}}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"label": "gold", "secret": "x"}, public)
+	}
+
+}
@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"time"
+)
+
+// atomNamespace is the XML namespace of Atom documents.
+const atomNamespace string = "http://www.w3.org/2005/Atom"
+
+// AtomCodec converts objects to and from Atom feeds.
+type AtomCodec struct{}
+
+func init() {
+	codecs.Register(new(AtomCodec))
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Links     []atomLink  `xml:"link"`
+	Author    *atomPerson `xml:"author"`
+	Summary   *atomText   `xml:"summary"`
+	Content   *atomText   `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+// Marshal converts an object to an Atom feed.
+func (c *AtomCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	f, err := FeedFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc := atomFeed{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Subtitle,
+		Updated:  atomTime(f.Updated),
+		Links:    atomLinks(f.Link),
+		Author:   atomAuthor(f.Author),
+	}
+
+	for _, entry := range f.Entries {
+		e := atomEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Updated: atomTime(entry.updated()),
+			Links:   atomLinks(entry.Link),
+			Author:  atomAuthor(entry.Author),
+		}
+		if len(e.ID) == 0 {
+			e.ID = entry.Link
+		}
+		if !entry.Published.IsZero() {
+			e.Published = atomTime(entry.Published)
+		}
+		if len(entry.Summary) > 0 {
+			e.Summary = &atomText{Body: entry.Summary}
+		}
+		if len(entry.Content) > 0 {
+			e.Content = &atomText{Type: "html", Body: entry.Content}
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	bytes, err := xml.Marshal(doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), bytes...), nil
+}
+
+// Unmarshal converts an Atom feed into a *Feed.  The obj must be a *Feed,
+// **Feed or *interface{}.
+func (c *AtomCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	var doc atomFeed
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	f := &Feed{
+		ID:       doc.ID,
+		Title:    doc.Title,
+		Subtitle: doc.Subtitle,
+		Link:     atomHref(doc.Links),
+		Updated:  parseTime(doc.Updated, time.RFC3339),
+	}
+	if doc.Author != nil {
+		f.Author = doc.Author.Name
+	}
+
+	for _, e := range doc.Entries {
+		entry := &Entry{
+			ID:        e.ID,
+			Title:     e.Title,
+			Link:      atomHref(e.Links),
+			Published: parseTime(e.Published, time.RFC3339),
+			Updated:   parseTime(e.Updated, time.RFC3339),
+		}
+		if e.Author != nil {
+			entry.Author = e.Author.Name
+		}
+		if e.Summary != nil {
+			entry.Summary = e.Summary.Body
+		}
+		if e.Content != nil {
+			entry.Content = e.Content.Body
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	return setFeed(obj, f)
+}
+
+// ContentType returns the content type for this codec.
+func (c *AtomCodec) ContentType() string {
+	return constants.ContentTypeAtom
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *AtomCodec) FileExtension() string {
+	return constants.FileExtensionAtom
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *AtomCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func atomLinks(href string) []atomLink {
+	if len(href) == 0 {
+		return nil
+	}
+	return []atomLink{{Href: href, Rel: "alternate"}}
+}
+
+// atomHref gets the alternate link from the links.
+func atomHref(links []atomLink) string {
+	for _, link := range links {
+		if len(link.Rel) == 0 || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+func atomAuthor(name string) *atomPerson {
+	if len(name) == 0 {
+		return nil
+	}
+	return &atomPerson{Name: name}
+}
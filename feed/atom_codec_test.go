@@ -0,0 +1,83 @@
+package feed
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var atomCodec AtomCodec
+
+func TestAtomInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(AtomCodec), "AtomCodec")
+}
+
+func TestAtomContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeAtom, atomCodec.ContentType())
+}
+
+func TestAtomFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionAtom, atomCodec.FileExtension())
+}
+
+func TestAtomCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, atomCodec.CanMarshalWithCallback())
+}
+
+func TestAtomMarshal(t *testing.T) {
+
+	f := &Feed{
+		ID:      "urn:feed",
+		Title:   "Posts",
+		Updated: time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC),
+		Entries: []*Entry{{ID: "urn:1", Title: "One", Link: "http://x/1", Summary: "First"}},
+	}
+
+	bytes, err := atomCodec.Marshal(f, nil)
+
+	if assert.NoError(t, err) {
+		out := string(bytes)
+		assert.Contains(t, out, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+		assert.Contains(t, out, `<id>urn:feed</id>`)
+		assert.Contains(t, out, `<updated>2013-01-02T03:04:05Z</updated>`)
+		assert.Contains(t, out, `<link href="http://x/1" rel="alternate"></link>`)
+		assert.Contains(t, out, `<summary>First</summary>`)
+	}
+
+}
+
+func TestAtomMarshalAndUnmarshal(t *testing.T) {
+
+	published := time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC)
+	bytes, err := atomCodec.Marshal([]Entry{{ID: "urn:1", Title: "One", Author: "Mat", Content: "<p>Hi</p>", Published: published}}, map[string]interface{}{OptionKeyFeedTitle: "Posts", OptionKeyFeedID: "urn:feed"})
+
+	if assert.NoError(t, err) {
+
+		var f Feed
+		if assert.NoError(t, atomCodec.Unmarshal(bytes, &f)) {
+			assert.Equal(t, "urn:feed", f.ID)
+			assert.Equal(t, "Posts", f.Title)
+			assert.Equal(t, published, f.Updated)
+			if assert.Equal(t, 1, len(f.Entries)) {
+				assert.Equal(t, "One", f.Entries[0].Title)
+				assert.Equal(t, "Mat", f.Entries[0].Author)
+				assert.Equal(t, "<p>Hi</p>", f.Entries[0].Content)
+				assert.Equal(t, published, f.Entries[0].Published)
+			}
+		}
+
+	}
+
+}
+
+func TestAtomUnmarshal_InvalidTarget(t *testing.T) {
+
+	bytes, _ := atomCodec.Marshal(&Feed{ID: "urn:feed"}, nil)
+
+	var m map[string]interface{}
+	_, ok := atomCodec.Unmarshal(bytes, &m).(*InvalidUnmarshalError)
+	assert.True(t, ok)
+
+}
@@ -0,0 +1,19 @@
+// Codecs for serving syndication feeds as Atom (application/atom+xml) and
+// RSS 2.0 (application/rss+xml).
+//
+// Both codecs work on the Feed and Entry types.  Objects can be marshalled
+// directly if they are (or contain) Feed and Entry values, or if they implement
+// the FeedProvider or EntryProvider interfaces:
+//
+//	func (p *Post) FeedEntry(options map[string]interface{}) (*feed.Entry, error) {
+//	  return &feed.Entry{ID: p.URL, Title: p.Title, Summary: p.Intro, Updated: p.Modified}, nil
+//	}
+//
+// A slice of such objects is marshalled as a feed containing one entry per
+// item, which means list endpoints can serve syndication feeds simply by having
+// the codecs installed.
+//
+// Maps are also understood.  They are read using the Field* keys (e.g. "title",
+// "id", "link") so objects implementing the codecs.Facade interface will still
+// produce sensible feeds.
+package feed
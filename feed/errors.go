@@ -0,0 +1,26 @@
+package feed
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorNotAFeed is returned when an object cannot be converted into a Feed.
+var ErrorNotAFeed = errors.New("codecs: feed: Object cannot be converted into a feed.  Implement FeedProvider or EntryProvider, or use the Feed and Entry types.")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil *Feed or *interface{}.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: feed: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: feed: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: feed: Unmarshal(unsupported " + e.Type.String() + ")"
+}
@@ -0,0 +1,349 @@
+package feed
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// OptionKeyFeedID is the option key for the ID of the generated feed.
+	OptionKeyFeedID string = "feed.id"
+
+	// OptionKeyFeedTitle is the option key for the title of the generated feed.
+	OptionKeyFeedTitle string = "feed.title"
+
+	// OptionKeyFeedSubtitle is the option key for the subtitle (or description) of
+	// the generated feed.
+	OptionKeyFeedSubtitle string = "feed.subtitle"
+
+	// OptionKeyFeedLink is the option key for the link of the generated feed.
+	OptionKeyFeedLink string = "feed.link"
+)
+
+// The keys used when reading entries (and feeds) from maps.
+const (
+	FieldID        string = "id"
+	FieldTitle     string = "title"
+	FieldSubtitle  string = "subtitle"
+	FieldLink      string = "link"
+	FieldSummary   string = "summary"
+	FieldContent   string = "content"
+	FieldAuthor    string = "author"
+	FieldPublished string = "published"
+	FieldUpdated   string = "updated"
+	FieldEntries   string = "entries"
+)
+
+// Feed is a format neutral representation of a syndication feed.
+type Feed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Link     string
+	Author   string
+	Updated  time.Time
+	Entries  []*Entry
+}
+
+// Entry is a format neutral representation of a single item in a feed.
+type Entry struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Author    string
+	Published time.Time
+	Updated   time.Time
+}
+
+// FeedProvider is the interface objects should implement if they want to
+// be marshalled as a whole feed.
+type FeedProvider interface {
+	Feed(options map[string]interface{}) (*Feed, error)
+}
+
+// EntryProvider is the interface objects should implement if they want to
+// be marshalled as an entry in a feed.
+type EntryProvider interface {
+	FeedEntry(options map[string]interface{}) (*Entry, error)
+}
+
+// FeedFrom converts the object into a Feed.
+//
+// Feed values, FeedProvider objects and maps containing an "entries" key are
+// treated as a whole feed; arrays and slices are treated as a list of
+// entries; anything else is treated as a feed with a single entry.
+//
+// Any feed level details (ID, title etc.) that are missing get taken from
+// the options (see the OptionKeyFeed* constants).
+func FeedFrom(object interface{}, options map[string]interface{}) (*Feed, error) {
+
+	f, err := feedFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// fill in the blanks from the options
+	if len(f.ID) == 0 {
+		f.ID = stringOption(options, OptionKeyFeedID)
+	}
+	if len(f.Title) == 0 {
+		f.Title = stringOption(options, OptionKeyFeedTitle)
+	}
+	if len(f.Subtitle) == 0 {
+		f.Subtitle = stringOption(options, OptionKeyFeedSubtitle)
+	}
+	if len(f.Link) == 0 {
+		f.Link = stringOption(options, OptionKeyFeedLink)
+	}
+	if len(f.ID) == 0 {
+		f.ID = f.Link
+	}
+
+	// the feed was updated when its latest entry was
+	if f.Updated.IsZero() {
+		for _, entry := range f.Entries {
+			if entry.updated().After(f.Updated) {
+				f.Updated = entry.updated()
+			}
+		}
+	}
+
+	return f, nil
+}
+
+func feedFrom(object interface{}, options map[string]interface{}) (*Feed, error) {
+
+	switch o := object.(type) {
+	case *Feed:
+		if o == nil {
+			return &Feed{}, nil
+		}
+		copied := *o
+		return &copied, nil
+	case Feed:
+		return &o, nil
+	case FeedProvider:
+		f, err := o.Feed(options)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			return &Feed{}, nil
+		}
+		copied := *f
+		return &copied, nil
+	}
+
+	if m, ok := toMap(object); ok {
+		if _, hasEntries := m[FieldEntries]; hasEntries {
+			entries, err := entriesFrom(m[FieldEntries], options)
+			if err != nil {
+				return nil, err
+			}
+			return &Feed{
+				ID:       stringValue(m[FieldID]),
+				Title:    stringValue(m[FieldTitle]),
+				Subtitle: stringValue(m[FieldSubtitle]),
+				Link:     stringValue(m[FieldLink]),
+				Author:   stringValue(m[FieldAuthor]),
+				Updated:  timeValue(m[FieldUpdated]),
+				Entries:  entries,
+			}, nil
+		}
+	}
+
+	entries, err := entriesFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Feed{Entries: entries}, nil
+
+}
+
+// entriesFrom gets the entries represented by the object, which may be a
+// single entry or an array or slice of them.
+func entriesFrom(object interface{}, options map[string]interface{}) ([]*Entry, error) {
+
+	if object == nil {
+		return nil, nil
+	}
+
+	switch o := object.(type) {
+	case []*Entry:
+		return o, nil
+	case []Entry:
+		entries := make([]*Entry, len(o))
+		for i := range o {
+			entries[i] = &o[i]
+		}
+		return entries, nil
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Array || value.Kind() == reflect.Slice {
+
+		entries := make([]*Entry, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+
+			entry, err := entryFrom(value.Index(i).Interface(), options)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if entry != nil {
+				entries = append(entries, entry)
+			}
+
+		}
+
+		return entries, nil
+	}
+
+	entry, err := entryFrom(object, options)
+
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	return []*Entry{entry}, nil
+
+}
+
+// entryFrom converts a single object into an Entry.
+func entryFrom(object interface{}, options map[string]interface{}) (*Entry, error) {
+
+	switch o := object.(type) {
+	case nil:
+		return nil, nil
+	case *Entry:
+		return o, nil
+	case Entry:
+		return &o, nil
+	case EntryProvider:
+		return o.FeedEntry(options)
+	}
+
+	if m, ok := toMap(object); ok {
+		return &Entry{
+			ID:        stringValue(m[FieldID]),
+			Title:     stringValue(m[FieldTitle]),
+			Link:      stringValue(m[FieldLink]),
+			Summary:   stringValue(m[FieldSummary]),
+			Content:   stringValue(m[FieldContent]),
+			Author:    stringValue(m[FieldAuthor]),
+			Published: timeValue(m[FieldPublished]),
+			Updated:   timeValue(m[FieldUpdated]),
+		}, nil
+	}
+
+	return nil, ErrorNotAFeed
+}
+
+// updated gets the time the entry was last updated, falling back on when it
+// was published.
+func (e *Entry) updated() time.Time {
+	if e.Updated.IsZero() {
+		return e.Published
+	}
+	return e.Updated
+}
+
+// toMap gets the object as a map[string]interface{} if it is any kind of map
+// with string keys (such as objects.Map).
+func toMap(object interface{}) (map[string]interface{}, bool) {
+
+	if m, ok := object.(map[string]interface{}); ok {
+		return m, true
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+
+	m := make(map[string]interface{}, value.Len())
+	for _, key := range value.MapKeys() {
+		m[key.String()] = value.MapIndex(key).Interface()
+	}
+
+	return m, true
+}
+
+func stringOption(options map[string]interface{}, key string) string {
+	if options == nil {
+		return ""
+	}
+	return stringValue(options[key])
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// timeValue gets a time.Time from the value, which may be a time.Time or a
+// string in RFC3339 format.
+func timeValue(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case *time.Time:
+		if v != nil {
+			return *v
+		}
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseTime parses the value with the first layout that works.
+func parseTime(value string, layouts ...string) time.Time {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// setFeed sets the unmarshalled feed into obj, which must be a *Feed or a
+// *interface{}.
+func setFeed(obj interface{}, f *Feed) error {
+
+	switch target := obj.(type) {
+	case *Feed:
+		if target != nil {
+			*target = *f
+			return nil
+		}
+	case **Feed:
+		if target != nil {
+			*target = f
+			return nil
+		}
+	case *interface{}:
+		if target != nil {
+			*target = f
+			return nil
+		}
+	}
+
+	return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+}
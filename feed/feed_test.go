@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testPost struct {
+	title string
+	url   string
+	when  time.Time
+}
+
+func (p *testPost) FeedEntry(options map[string]interface{}) (*Entry, error) {
+	return &Entry{ID: p.url, Link: p.url, Title: p.title, Updated: p.when}, nil
+}
+
+func TestFeedFrom_Slice(t *testing.T) {
+
+	earlier := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2013, 2, 1, 0, 0, 0, 0, time.UTC)
+	posts := []interface{}{&testPost{"One", "http://x/1", earlier}, &testPost{"Two", "http://x/2", later}}
+
+	f, err := FeedFrom(posts, map[string]interface{}{OptionKeyFeedTitle: "Posts", OptionKeyFeedLink: "http://x/"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Posts", f.Title)
+		assert.Equal(t, "http://x/", f.ID, "ID should default to the link")
+		assert.Equal(t, later, f.Updated, "Updated should be the latest entry")
+		if assert.Equal(t, 2, len(f.Entries)) {
+			assert.Equal(t, "One", f.Entries[0].Title)
+			assert.Equal(t, "Two", f.Entries[1].Title)
+		}
+	}
+
+}
+
+func TestFeedFrom_Maps(t *testing.T) {
+
+	data := map[string]interface{}{
+		FieldTitle: "News",
+		FieldEntries: []interface{}{
+			map[string]interface{}{FieldTitle: "Hello", FieldUpdated: "2013-01-02T03:04:05Z"},
+		},
+	}
+
+	f, err := FeedFrom(data, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "News", f.Title)
+		if assert.Equal(t, 1, len(f.Entries)) {
+			assert.Equal(t, "Hello", f.Entries[0].Title)
+			assert.Equal(t, time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC), f.Entries[0].Updated)
+		}
+	}
+
+}
+
+func TestFeedFrom_Unsupported(t *testing.T) {
+
+	_, err := FeedFrom([]int{1, 2}, nil)
+	assert.Equal(t, ErrorNotAFeed, err)
+
+}
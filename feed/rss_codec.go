@@ -0,0 +1,149 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"time"
+)
+
+// rssVersion is the version of RSS produced by the RssCodec.
+const rssVersion string = "2.0"
+
+// RssCodec converts objects to and from RSS 2.0 feeds.
+type RssCodec struct{}
+
+func init() {
+	codecs.Register(new(RssCodec))
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title,omitempty"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Author      string   `xml:"author,omitempty"`
+	GUID        *rssGUID `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr,omitempty"`
+	Value       string `xml:",chardata"`
+}
+
+// Marshal converts an object to an RSS feed.
+func (c *RssCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	f, err := FeedFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc := rssDocument{
+		Version: rssVersion,
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Subtitle,
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, entry := range f.Entries {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Summary,
+			Author:      entry.Author,
+		}
+		if len(item.Description) == 0 {
+			item.Description = entry.Content
+		}
+		if len(entry.ID) > 0 {
+			item.GUID = &rssGUID{Value: entry.ID}
+			if entry.ID != entry.Link {
+				item.GUID.IsPermaLink = "false"
+			}
+		}
+		if published := entry.Published; !published.IsZero() {
+			item.PubDate = published.Format(time.RFC1123Z)
+		} else if !entry.Updated.IsZero() {
+			item.PubDate = entry.Updated.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	bytes, err := xml.Marshal(doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), bytes...), nil
+}
+
+// Unmarshal converts an RSS feed into a *Feed.  The obj must be a *Feed,
+// **Feed or *interface{}.
+func (c *RssCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	var doc rssDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	f := &Feed{
+		Title:    doc.Channel.Title,
+		Subtitle: doc.Channel.Description,
+		Link:     doc.Channel.Link,
+		ID:       doc.Channel.Link,
+		Updated:  parseTime(doc.Channel.LastBuildDate, time.RFC1123Z, time.RFC1123),
+	}
+
+	for _, item := range doc.Channel.Items {
+		entry := &Entry{
+			Title:     item.Title,
+			Link:      item.Link,
+			Summary:   item.Description,
+			Author:    item.Author,
+			Published: parseTime(item.PubDate, time.RFC1123Z, time.RFC1123),
+		}
+		if item.GUID != nil {
+			entry.ID = item.GUID.Value
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+
+	return setFeed(obj, f)
+}
+
+// ContentType returns the content type for this codec.
+func (c *RssCodec) ContentType() string {
+	return constants.ContentTypeRSS
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *RssCodec) FileExtension() string {
+	return constants.FileExtensionRSS
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *RssCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var rssCodec RssCodec
+
+func TestRssInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(RssCodec), "RssCodec")
+}
+
+func TestRssContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeRSS, rssCodec.ContentType())
+}
+
+func TestRssFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionRSS, rssCodec.FileExtension())
+}
+
+func TestRssCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, rssCodec.CanMarshalWithCallback())
+}
+
+func TestRssMarshalAndUnmarshal(t *testing.T) {
+
+	published := time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC)
+	posts := []interface{}{&testPost{"One", "http://x/1", published}}
+
+	bytes, err := rssCodec.Marshal(posts, map[string]interface{}{OptionKeyFeedTitle: "Posts", OptionKeyFeedLink: "http://x/"})
+
+	if assert.NoError(t, err) {
+
+		out := string(bytes)
+		assert.Contains(t, out, `<rss version="2.0"><channel><title>Posts</title><link>http://x/</link>`)
+		assert.Contains(t, out, `<guid>http://x/1</guid>`)
+		assert.Contains(t, out, `<pubDate>Wed, 02 Jan 2013 03:04:05 +0000</pubDate>`)
+
+		var obj interface{}
+		if assert.NoError(t, rssCodec.Unmarshal(bytes, &obj)) {
+			f := obj.(*Feed)
+			assert.Equal(t, "Posts", f.Title)
+			if assert.Equal(t, 1, len(f.Entries)) {
+				assert.Equal(t, "One", f.Entries[0].Title)
+				assert.Equal(t, "http://x/1", f.Entries[0].ID)
+				assert.True(t, published.Equal(f.Entries[0].Published))
+			}
+		}
+
+	}
+
+}
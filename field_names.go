@@ -0,0 +1,53 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// FieldNames maps struct types to the names their fields are given in public
+// data, by Go field name, so that types that can't be tagged (such as
+// generated or vendored types) are named as an API requires.  Give it with the
+// constants.OptionKeyFieldNames option.
+type FieldNames map[reflect.Type]map[string]string
+
+// Rename names the fields of structs of the object's type (or the type it
+// points to) in public data, by Go field name, adding to any names already
+// given.  Fields renamed "" are left out.
+func (n FieldNames) Rename(object interface{}, names map[string]string) {
+
+	if object == nil {
+		panic("codecs: FieldNames.Rename object is nil.")
+	}
+
+	objectType := reflect.TypeOf(object)
+	for objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	renamed := make(map[string]string, len(n[objectType])+len(names))
+	for field, name := range n[objectType] {
+		renamed[field] = name
+	}
+	for field, name := range names {
+		renamed[field] = name
+	}
+
+	n[objectType] = renamed
+}
+
+// Copy copies the field names, so that changes to the copy aren't shared.
+func (n FieldNames) Copy() FieldNames {
+	copied := make(FieldNames, len(n))
+	for objectType, names := range n {
+		copied[objectType] = names
+	}
+	return copied
+}
+
+// fieldNamesFor gets the names given by the constants.OptionKeyFieldNames
+// option for the fields of the struct type, or nil if there are none.
+func fieldNamesFor(structType reflect.Type, options map[string]interface{}) map[string]string {
+	names, _ := options[constants.OptionKeyFieldNames].(FieldNames)
+	return names[structType]
+}
@@ -0,0 +1,35 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// generated stands for a type that can't be tagged.
+type generated struct {
+	UserID   int
+	UserName string
+	Internal string
+}
+
+func TestPublicData_FieldNames(t *testing.T) {
+
+	names := FieldNames{}
+	names.Rename(&generated{}, map[string]string{"UserID": "id"})
+	names.Rename(generated{}, map[string]string{"UserName": "name", "Internal": ""})
+
+	assert.Equal(t, map[string]string{"UserID": "id", "UserName": "name", "Internal": ""}, names[reflect.TypeOf(generated{})])
+
+	public, err := PublicData([]generated{{UserID: 1, UserName: "Mat", Internal: "x"}}, map[string]interface{}{constants.OptionKeyFieldNames: names})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": 1, "name": "Mat"}}, public)
+	}
+
+	// copies don't share changes
+	copied := names.Copy()
+	copied.Rename(generated{}, map[string]string{"UserID": "user_id"})
+	assert.Equal(t, "id", names[reflect.TypeOf(generated{})]["UserID"])
+
+}
@@ -0,0 +1,17 @@
+// A codec for rendering data as HTML pages using html/template.
+//
+// Templates are registered by name or by the type of the object they render:
+//
+//	html.RegisterTemplate("people", peopleTemplate)
+//	html.RegisterTypeTemplate(Person{}, personTemplate)
+//
+// Marshal uses the template named by the OptionKeyTemplate option if there is
+// one, otherwise the template registered for the object's type, and executes
+// it with the object as its data.  Slices without a template of their own are
+// rendered by executing their element type's template for each element in
+// turn.  Objects without a template are rendered by DefaultTemplate, which
+// shows maps as definition lists, slices as ordered lists and anything else
+// as text, so browsers hitting API endpoints get a readable page.
+//
+// HTML cannot be unmarshalled.
+package html
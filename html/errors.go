@@ -0,0 +1,13 @@
+package html
+
+import (
+	"errors"
+)
+
+// ErrorUnmarshalNotSupported is returned when Unmarshal is called, as HTML
+// cannot be unmarshalled.
+var ErrorUnmarshalNotSupported = errors.New("codecs: html: Unmarshalling HTML is not supported")
+
+// ErrorTemplateNotFound is returned when the template named by the
+// OptionKeyTemplate option has not been registered.
+var ErrorTemplateNotFound = errors.New("codecs: html: Template is not registered")
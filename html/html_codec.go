@@ -0,0 +1,115 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// OptionKeyTemplate is the option key for the name of the registered template
+// to render with.
+const OptionKeyTemplate = "html.template"
+
+// OptionKeyTitle is the option key for the page title used by
+// DefaultTemplate.
+const OptionKeyTitle = "html.title"
+
+// DefaultTitle is the page title used by DefaultTemplate unless
+// OptionKeyTitle says otherwise.
+const DefaultTitle = "Data"
+
+// HtmlCodec renders objects as HTML pages.
+type HtmlCodec struct{}
+
+func init() {
+	codecs.Register(new(HtmlCodec))
+}
+
+// Marshal renders the object through its template.
+func (c *HtmlCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var buffer bytes.Buffer
+
+	if name, ok := options[OptionKeyTemplate].(string); ok {
+
+		t, found := namedTemplate(name)
+		if !found {
+			return nil, ErrorTemplateNotFound
+		}
+
+		if err := t.Execute(&buffer, object); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+
+	if t, eachElement, found := typeTemplate(object); found {
+
+		if !eachElement {
+			if err := t.Execute(&buffer, object); err != nil {
+				return nil, err
+			}
+			return buffer.Bytes(), nil
+		}
+
+		v := reflect.Indirect(reflect.ValueOf(object))
+		for i := 0; i < v.Len(); i++ {
+			if err := t.Execute(&buffer, v.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return buffer.Bytes(), nil
+	}
+
+	// the default template works on generic values
+	jsonData, err := json.Marshal(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, err
+	}
+
+	title, ok := options[OptionKeyTitle].(string)
+	if !ok {
+		title = DefaultTitle
+	}
+
+	lang, _ := options[constants.OptionKeyClientLanguage].(string)
+
+	if err := DefaultTemplate.Execute(&buffer, defaultPage{Title: title, Lang: lang, Data: data}); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal is not supported for HTML. Returns an error.
+func (c *HtmlCodec) Unmarshal(data []byte, obj interface{}) error {
+	return ErrorUnmarshalNotSupported
+}
+
+// ContentType returns the content type for this codec.
+func (c *HtmlCodec) ContentType() string {
+	return constants.ContentTypeHTML
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *HtmlCodec) FileExtension() string {
+	return constants.FileExtensionHTML
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *HtmlCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// CanUnmarshal returns whether this codec is capable of unmarshalling.
+func (c *HtmlCodec) CanUnmarshal() bool {
+	return false
+}
@@ -0,0 +1,108 @@
+package html
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+var htmlCodec HtmlCodec
+
+type person struct {
+	Name string
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(HtmlCodec), "HtmlCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeHTML, htmlCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionHTML, htmlCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, htmlCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal_NamedTemplate(t *testing.T) {
+
+	RegisterTemplate("greeting", template.Must(template.New("greeting").Parse(`<p>Hello {{.name}}</p>`)))
+	defer RegisterTemplate("greeting", nil)
+
+	bytes, err := htmlCodec.Marshal(map[string]interface{}{"name": "<Mat>"}, map[string]interface{}{OptionKeyTemplate: "greeting"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<p>Hello &lt;Mat&gt;</p>", string(bytes))
+	}
+
+	_, err = htmlCodec.Marshal(nil, map[string]interface{}{OptionKeyTemplate: "missing"})
+	assert.Equal(t, ErrorTemplateNotFound, err)
+
+}
+
+func TestMarshal_TypeTemplate(t *testing.T) {
+
+	RegisterTypeTemplate(&person{}, template.Must(template.New("person").Parse(`<b>{{.Name}}</b>`)))
+	defer RegisterTypeTemplate(person{}, nil)
+
+	bytes, err := htmlCodec.Marshal(&person{"Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<b>Mat</b>", string(bytes))
+	}
+
+	bytes, err = htmlCodec.Marshal([]*person{{"Mat"}, {"Tyler"}}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<b>Mat</b><b>Tyler</b>", string(bytes))
+	}
+
+}
+
+func TestMarshal_DefaultTemplate(t *testing.T) {
+
+	object := map[string]interface{}{
+		"name":  "<script>",
+		"tags":  []string{"a", "b"},
+		"owner": nil,
+	}
+
+	bytes, err := htmlCodec.Marshal(object, map[string]interface{}{OptionKeyTitle: "Things"})
+
+	if assert.NoError(t, err) {
+		page := string(bytes)
+		assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+		assert.Contains(t, page, "<title>Things</title>")
+		assert.Contains(t, page, "<dt>name</dt>\n<dd>&lt;script&gt;</dd>")
+		assert.Contains(t, page, "<dt>owner</dt>\n<dd><em>null</em></dd>")
+		assert.Contains(t, page, "<ol>\n<li>a</li>\n<li>b</li>\n</ol>")
+		assert.True(t, strings.Index(page, "<dt>name</dt>") < strings.Index(page, "<dt>owner</dt>"))
+	}
+
+	bytes, err = htmlCodec.Marshal(person{"Mat"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), "<title>"+DefaultTitle+"</title>")
+		assert.Contains(t, string(bytes), "<dt>Name</dt>\n<dd>Mat</dd>")
+		assert.Contains(t, string(bytes), "<html>")
+	}
+
+	bytes, err = htmlCodec.Marshal(person{"Mat"}, map[string]interface{}{constants.OptionKeyClientLanguage: "en-GB"})
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<html lang="en-GB">`)
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorUnmarshalNotSupported, htmlCodec.Unmarshal([]byte("<p></p>"), &obj))
+
+}
@@ -0,0 +1,136 @@
+package html
+
+import (
+	"html/template"
+	"reflect"
+	"sync"
+)
+
+var (
+	templatesLock  sync.RWMutex
+	namedTemplates = map[string]*template.Template{}
+	typeTemplates  = map[reflect.Type]*template.Template{}
+)
+
+// DefaultTemplate renders objects that have no registered template.  It is
+// executed with a value whose Title is the page title, whose Lang is the
+// client's language (from the constants.OptionKeyClientLanguage option, if
+// given) and whose Data is the object converted to generic values (as by
+// encoding/json).
+var DefaultTemplate = template.Must(template.New("default").Funcs(template.FuncMap{"kind": kind}).Parse(`<!DOCTYPE html>
+<html{{with .Lang}} lang="{{.}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{template "value" .Data}}
+</body>
+</html>
+{{define "value"}}{{if eq (kind .) "map"}}<dl>
+{{range $key, $value := .}}<dt>{{$key}}</dt>
+<dd>{{template "value" $value}}</dd>
+{{end}}</dl>{{else if eq (kind .) "slice"}}<ol>
+{{range .}}<li>{{template "value" .}}</li>
+{{end}}</ol>{{else if eq (kind .) "nil"}}<em>null</em>{{else}}{{.}}{{end}}{{end}}
+`))
+
+// defaultPage is the data DefaultTemplate is executed with.
+type defaultPage struct {
+	Title string
+	Lang  string
+	Data  interface{}
+}
+
+// RegisterTemplate registers the template under the name, for selection by
+// the OptionKeyTemplate option.  Registering a nil template removes the name.
+func RegisterTemplate(name string, t *template.Template) {
+
+	templatesLock.Lock()
+	defer templatesLock.Unlock()
+
+	if t == nil {
+		delete(namedTemplates, name)
+		return
+	}
+	namedTemplates[name] = t
+}
+
+// RegisterTypeTemplate registers the template for rendering objects of the
+// same type as the given object (pointers are followed).  Registering a nil
+// template removes the type.
+func RegisterTypeTemplate(object interface{}, t *template.Template) {
+
+	templatesLock.Lock()
+	defer templatesLock.Unlock()
+
+	typ := reflect.TypeOf(object)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if t == nil {
+		delete(typeTemplates, typ)
+		return
+	}
+	typeTemplates[typ] = t
+}
+
+// namedTemplate gets the template registered under the name.
+func namedTemplate(name string) (*template.Template, bool) {
+
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+
+	t, ok := namedTemplates[name]
+	return t, ok
+}
+
+// typeTemplate gets the template registered for the object's type.  If there
+// isn't one and the object is a slice or array, the template registered for
+// its element type is returned, and eachElement is true.
+func typeTemplate(object interface{}) (t *template.Template, eachElement bool, found bool) {
+
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+
+	typ := reflect.TypeOf(object)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == nil {
+		return nil, false, false
+	}
+
+	if t, ok := typeTemplates[typ]; ok {
+		return t, false, true
+	}
+
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		elem := typ.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if t, ok := typeTemplates[elem]; ok {
+			return t, true, true
+		}
+	}
+
+	return nil, false, false
+}
+
+// kind gets the kind of generic value for the default template; "map",
+// "slice", "nil" or "value".
+func kind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "slice"
+	case nil:
+		return "nil"
+	}
+	return "value"
+}
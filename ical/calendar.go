@@ -0,0 +1,221 @@
+package ical
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// OptionKeyMethod is the option key for the iTIP METHOD of the calendar,
+	// e.g. "REQUEST" when sending invites.
+	OptionKeyMethod string = "ical.method"
+
+	// OptionKeyProductID is the option key for the PRODID of the calendar.
+	OptionKeyProductID string = "ical.prodid"
+)
+
+// DefaultProductID is the PRODID used when none is provided.
+var DefaultProductID string = "-//stretchr//codecs//EN"
+
+// The keys used when reading events from maps.
+const (
+	FieldUID         string = "uid"
+	FieldSummary     string = "summary"
+	FieldDescription string = "description"
+	FieldLocation    string = "location"
+	FieldURL         string = "url"
+	FieldStatus      string = "status"
+	FieldOrganizer   string = "organizer"
+	FieldAttendees   string = "attendees"
+	FieldStart       string = "start"
+	FieldEnd         string = "end"
+	FieldAllDay      string = "all_day"
+)
+
+// Calendar is a VCALENDAR containing events.
+type Calendar struct {
+	ProductID string
+	Method    string
+	Events    []*Event
+}
+
+// Event is a VEVENT.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+
+	// Organizer and Attendees are calendar user addresses, usually email
+	// addresses.  A "mailto:" prefix is added when marshalling if there is
+	// no scheme.
+	Organizer string
+	Attendees []string
+
+	Start time.Time
+	End   time.Time
+
+	// AllDay indicates that Start and End are dates rather than times.
+	AllDay bool
+
+	// Stamp is the DTSTAMP of the event.  The current time is used when
+	// marshalling if it's zero.
+	Stamp time.Time
+}
+
+// EventProvider is the interface objects should implement if they want to
+// be marshalled as calendar events.
+type EventProvider interface {
+	CalendarEvent(options map[string]interface{}) (*Event, error)
+}
+
+// CalendarFrom converts the object into a Calendar.  Calendar values are used
+// as they are; events, EventProvider objects and maps (or arrays and slices of
+// them) become the events of a new calendar.
+func CalendarFrom(object interface{}, options map[string]interface{}) (*Calendar, error) {
+
+	var cal *Calendar
+
+	switch o := object.(type) {
+	case *Calendar:
+		copied := *o
+		cal = &copied
+	case Calendar:
+		cal = &o
+	default:
+		events, err := eventsFrom(object, options)
+		if err != nil {
+			return nil, err
+		}
+		cal = &Calendar{Events: events}
+	}
+
+	if len(cal.ProductID) == 0 {
+		cal.ProductID = stringOption(options, OptionKeyProductID)
+	}
+	if len(cal.ProductID) == 0 {
+		cal.ProductID = DefaultProductID
+	}
+	if len(cal.Method) == 0 {
+		cal.Method = stringOption(options, OptionKeyMethod)
+	}
+
+	return cal, nil
+}
+
+func eventsFrom(object interface{}, options map[string]interface{}) ([]*Event, error) {
+
+	if object == nil {
+		return nil, nil
+	}
+
+	if events, ok := object.([]*Event); ok {
+		return events, nil
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Array || value.Kind() == reflect.Slice {
+
+		events := make([]*Event, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+
+			event, err := eventFrom(value.Index(i).Interface(), options)
+
+			if err != nil {
+				return nil, err
+			}
+
+			events = append(events, event)
+		}
+
+		return events, nil
+	}
+
+	event, err := eventFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Event{event}, nil
+}
+
+func eventFrom(object interface{}, options map[string]interface{}) (*Event, error) {
+
+	switch o := object.(type) {
+	case *Event:
+		return o, nil
+	case Event:
+		return &o, nil
+	case EventProvider:
+		return o.CalendarEvent(options)
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, ErrorNotAnEvent
+	}
+
+	m := make(map[string]interface{}, value.Len())
+	for _, key := range value.MapKeys() {
+		m[key.String()] = value.MapIndex(key).Interface()
+	}
+
+	event := &Event{
+		UID:         stringValue(m[FieldUID]),
+		Summary:     stringValue(m[FieldSummary]),
+		Description: stringValue(m[FieldDescription]),
+		Location:    stringValue(m[FieldLocation]),
+		URL:         stringValue(m[FieldURL]),
+		Status:      stringValue(m[FieldStatus]),
+		Organizer:   stringValue(m[FieldOrganizer]),
+		Start:       timeValue(m[FieldStart]),
+		End:         timeValue(m[FieldEnd]),
+	}
+	event.AllDay, _ = m[FieldAllDay].(bool)
+
+	switch attendees := m[FieldAttendees].(type) {
+	case []string:
+		event.Attendees = attendees
+	case []interface{}:
+		for _, attendee := range attendees {
+			event.Attendees = append(event.Attendees, stringValue(attendee))
+		}
+	}
+
+	return event, nil
+}
+
+func stringOption(options map[string]interface{}, key string) string {
+	if options == nil {
+		return ""
+	}
+	return stringValue(options[key])
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// timeValue gets a time.Time from the value, which may be a time.Time or a
+// string in RFC3339 format.
+func timeValue(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,11 @@
+// A codec for handling iCalendar (text/calendar) encoding and decoding.
+//
+// The codec marshals Calendar and Event values (or objects implementing the
+// EventProvider interface, or slices of them) into a VCALENDAR containing
+// VEVENT components, and parses incoming calendars (such as meeting invites)
+// back into a *Calendar.
+//
+// Maps are also understood, using the Field* keys (e.g. "uid", "summary",
+// "start") so objects implementing the codecs.Facade interface can still be
+// served as calendars.
+package ical
@@ -0,0 +1,30 @@
+package ical
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorNotAnEvent is returned when an object cannot be converted into an Event.
+var ErrorNotAnEvent = errors.New("codecs: ical: Object cannot be converted into a calendar event.  Implement EventProvider, or use the Calendar and Event types.")
+
+// ErrorNotACalendar is returned when Unmarshal is given data that doesn't contain
+// a VCALENDAR.
+var ErrorNotACalendar = errors.New("codecs: ical: Data does not contain a VCALENDAR")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil *Calendar or *interface{}.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: ical: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: ical: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: ical: Unmarshal(unsupported " + e.Type.String() + ")"
+}
@@ -0,0 +1,274 @@
+package ical
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/internal/contentline"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// dateTimeFormat is the layout of UTC DATE-TIME values.
+	dateTimeFormat string = "20060102T150405Z"
+
+	// localDateTimeFormat is the layout of floating (or TZID qualified) DATE-TIME values.
+	localDateTimeFormat string = "20060102T150405"
+
+	// dateFormat is the layout of DATE values.
+	dateFormat string = "20060102"
+)
+
+// ICalCodec converts objects to and from iCalendar data.
+type ICalCodec struct{}
+
+func init() {
+	codecs.Register(new(ICalCodec))
+}
+
+// Marshal converts an object to a VCALENDAR.
+func (c *ICalCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	cal, err := CalendarFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var w contentline.Writer
+	w.Write("BEGIN", nil, "VCALENDAR")
+	w.Write("VERSION", nil, "2.0")
+	w.Write("PRODID", nil, contentline.EscapeText(cal.ProductID))
+	if len(cal.Method) > 0 {
+		w.Write("METHOD", nil, strings.ToUpper(cal.Method))
+	}
+
+	now := time.Now()
+	for _, event := range cal.Events {
+
+		w.Write("BEGIN", nil, "VEVENT")
+		w.Write("UID", nil, contentline.EscapeText(event.UID))
+
+		stamp := event.Stamp
+		if stamp.IsZero() {
+			stamp = now
+		}
+		w.Write("DTSTAMP", nil, stamp.UTC().Format(dateTimeFormat))
+
+		writeTime(&w, "DTSTART", event.Start, event.AllDay)
+		writeTime(&w, "DTEND", event.End, event.AllDay)
+		writeText(&w, "SUMMARY", event.Summary)
+		writeText(&w, "DESCRIPTION", event.Description)
+		writeText(&w, "LOCATION", event.Location)
+		if len(event.URL) > 0 {
+			w.Write("URL", nil, event.URL)
+		}
+		if len(event.Status) > 0 {
+			w.Write("STATUS", nil, strings.ToUpper(event.Status))
+		}
+		if len(event.Organizer) > 0 {
+			w.Write("ORGANIZER", nil, calendarAddress(event.Organizer))
+		}
+		for _, attendee := range event.Attendees {
+			w.Write("ATTENDEE", nil, calendarAddress(attendee))
+		}
+
+		w.Write("END", nil, "VEVENT")
+	}
+
+	w.Write("END", nil, "VCALENDAR")
+
+	return w.Bytes(), nil
+}
+
+// Unmarshal converts iCalendar data into a *Calendar.  The obj must be a
+// *Calendar, **Calendar or *interface{}.
+//
+// Only VEVENT components are read; any other components (such as
+// VTIMEZONE or VALARM) are skipped.
+func (c *ICalCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	lines, err := contentline.Parse(data)
+
+	if err != nil {
+		return err
+	}
+
+	var cal *Calendar
+	var event *Event
+	var components []string
+
+	for _, line := range lines {
+
+		switch line.Name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(line.Value))
+			switch components[len(components)-1] {
+			case "VCALENDAR":
+				if cal == nil {
+					cal = new(Calendar)
+				}
+			case "VEVENT":
+				event = new(Event)
+			}
+			continue
+		case "END":
+			if len(components) > 0 {
+				if components[len(components)-1] == "VEVENT" && event != nil && cal != nil {
+					cal.Events = append(cal.Events, event)
+					event = nil
+				}
+				components = components[:len(components)-1]
+			}
+			continue
+		}
+
+		if len(components) == 0 {
+			continue
+		}
+
+		switch components[len(components)-1] {
+		case "VCALENDAR":
+			switch line.Name {
+			case "PRODID":
+				cal.ProductID = contentline.UnescapeText(line.Value)
+			case "METHOD":
+				cal.Method = line.Value
+			}
+		case "VEVENT":
+			readEventProperty(event, line)
+		}
+
+	}
+
+	if cal == nil {
+		return ErrorNotACalendar
+	}
+
+	switch target := obj.(type) {
+	case *Calendar:
+		if target != nil {
+			*target = *cal
+			return nil
+		}
+	case **Calendar:
+		if target != nil {
+			*target = cal
+			return nil
+		}
+	case *interface{}:
+		if target != nil {
+			*target = cal
+			return nil
+		}
+	}
+
+	return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+}
+
+// ContentType returns the content type for this codec.
+func (c *ICalCodec) ContentType() string {
+	return constants.ContentTypeICal
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ICalCodec) FileExtension() string {
+	return constants.FileExtensionICal
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ICalCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func readEventProperty(event *Event, line contentline.Line) {
+
+	switch line.Name {
+	case "UID":
+		event.UID = contentline.UnescapeText(line.Value)
+	case "SUMMARY":
+		event.Summary = contentline.UnescapeText(line.Value)
+	case "DESCRIPTION":
+		event.Description = contentline.UnescapeText(line.Value)
+	case "LOCATION":
+		event.Location = contentline.UnescapeText(line.Value)
+	case "URL":
+		event.URL = line.Value
+	case "STATUS":
+		event.Status = line.Value
+	case "ORGANIZER":
+		event.Organizer = trimMailto(line.Value)
+	case "ATTENDEE":
+		event.Attendees = append(event.Attendees, trimMailto(line.Value))
+	case "DTSTAMP":
+		event.Stamp, _ = parseTime(line)
+	case "DTSTART":
+		event.Start, event.AllDay = parseTime(line)
+	case "DTEND":
+		event.End, _ = parseTime(line)
+	}
+
+}
+
+// parseTime parses a DATE or DATE-TIME property, taking the TZID parameter
+// into account.  The bool is true if the value is a DATE.
+func parseTime(line contentline.Line) (time.Time, bool) {
+
+	value := line.Value
+
+	if strings.ToUpper(line.Param("VALUE")) == "DATE" || len(value) == len(dateFormat) {
+		t, _ := time.Parse(dateFormat, value)
+		return t, true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, _ := time.Parse(dateTimeFormat, value)
+		return t, false
+	}
+
+	location := time.Local
+	if tzid := line.Param("TZID"); len(tzid) > 0 {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+
+	t, _ := time.ParseInLocation(localDateTimeFormat, value, location)
+	return t, false
+}
+
+func writeTime(w *contentline.Writer, name string, t time.Time, allDay bool) {
+
+	if t.IsZero() {
+		return
+	}
+
+	if allDay {
+		w.Write(name, map[string]string{"VALUE": "DATE"}, t.Format(dateFormat))
+		return
+	}
+
+	w.Write(name, nil, t.UTC().Format(dateTimeFormat))
+}
+
+func writeText(w *contentline.Writer, name, value string) {
+	if len(value) > 0 {
+		w.Write(name, nil, contentline.EscapeText(value))
+	}
+}
+
+// calendarAddress adds the mailto: scheme to addresses without one.
+func calendarAddress(address string) string {
+	if strings.Contains(address, ":") {
+		return address
+	}
+	return "mailto:" + address
+}
+
+func trimMailto(address string) string {
+	if strings.HasPrefix(strings.ToLower(address), "mailto:") {
+		return address[len("mailto:"):]
+	}
+	return address
+}
@@ -0,0 +1,146 @@
+package ical
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var icalCodec ICalCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(ICalCodec), "ICalCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeICal, icalCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionICal, icalCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, icalCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	event := &Event{
+		UID:       "123@example.com",
+		Summary:   "Planning, part 2",
+		Start:     time.Date(2013, 3, 4, 15, 0, 0, 0, time.UTC),
+		End:       time.Date(2013, 3, 4, 16, 0, 0, 0, time.UTC),
+		Stamp:     time.Date(2013, 3, 1, 0, 0, 0, 0, time.UTC),
+		Organizer: "mat@example.com",
+	}
+
+	bytes, err := icalCodec.Marshal(event, map[string]interface{}{OptionKeyMethod: "request"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+			"VERSION:2.0\r\n"+
+			"PRODID:-//stretchr//codecs//EN\r\n"+
+			"METHOD:REQUEST\r\n"+
+			"BEGIN:VEVENT\r\n"+
+			"UID:123@example.com\r\n"+
+			"DTSTAMP:20130301T000000Z\r\n"+
+			"DTSTART:20130304T150000Z\r\n"+
+			"DTEND:20130304T160000Z\r\n"+
+			"SUMMARY:Planning\\, part 2\r\n"+
+			"ORGANIZER:mailto:mat@example.com\r\n"+
+			"END:VEVENT\r\n"+
+			"END:VCALENDAR\r\n", string(bytes))
+	}
+
+}
+
+func TestMarshal_Maps(t *testing.T) {
+
+	events := []interface{}{
+		map[string]interface{}{FieldUID: "1", FieldSummary: "Holiday", FieldStart: "2013-12-25T00:00:00Z", FieldAllDay: true},
+	}
+
+	bytes, err := icalCodec.Marshal(events, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), "DTSTART;VALUE=DATE:20131225\r\n")
+	}
+
+}
+
+func TestMarshal_NotAnEvent(t *testing.T) {
+
+	_, err := icalCodec.Marshal(42, nil)
+	assert.Equal(t, ErrorNotAnEvent, err)
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	invite := "BEGIN:VCALENDAR\r\n" +
+		"PRODID:-//Example//Invites//EN\r\n" +
+		"METHOD:REQUEST\r\n" +
+		"BEGIN:VTIMEZONE\r\n" +
+		"TZID:America/Denver\r\n" +
+		"END:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc\r\n" +
+		"DTSTART;TZID=America/Denver:20130304T090000\r\n" +
+		"DTEND:20130304T170000Z\r\n" +
+		"SUMMARY:Long\r\n" +
+		"  summary\r\n" +
+		"ATTENDEE;CN=Tyler:mailto:tyler@example.com\r\n" +
+		"ATTENDEE:mailto:ryan@example.com\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"DESCRIPTION:Ignored\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	var cal Calendar
+	if assert.NoError(t, icalCodec.Unmarshal([]byte(invite), &cal)) {
+
+		assert.Equal(t, "REQUEST", cal.Method)
+		if assert.Equal(t, 1, len(cal.Events)) {
+			event := cal.Events[0]
+			assert.Equal(t, "abc", event.UID)
+			assert.Equal(t, "Long summary", event.Summary)
+			assert.Equal(t, "", event.Description, "VALARM properties should be skipped")
+			assert.Equal(t, []string{"tyler@example.com", "ryan@example.com"}, event.Attendees)
+			assert.Equal(t, time.Date(2013, 3, 4, 17, 0, 0, 0, time.UTC), event.End)
+			if denver, err := time.LoadLocation("America/Denver"); err == nil {
+				assert.True(t, time.Date(2013, 3, 4, 9, 0, 0, 0, denver).Equal(event.Start))
+			}
+		}
+
+	}
+
+}
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+
+	event := Event{UID: "1", Summary: "Lunch; with\nnotes", Start: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC), AllDay: true}
+
+	bytes, _ := icalCodec.Marshal([]Event{event}, nil)
+
+	var obj interface{}
+	if assert.NoError(t, icalCodec.Unmarshal(bytes, &obj)) {
+		cal := obj.(*Calendar)
+		if assert.Equal(t, 1, len(cal.Events)) {
+			assert.Equal(t, event.Summary, cal.Events[0].Summary)
+			assert.Equal(t, event.Start, cal.Events[0].Start)
+			assert.True(t, cal.Events[0].AllDay)
+		}
+	}
+
+}
+
+func TestUnmarshal_NotACalendar(t *testing.T) {
+
+	var cal Calendar
+	assert.Equal(t, ErrorNotACalendar, icalCodec.Unmarshal([]byte("BEGIN:VCARD\r\nEND:VCARD\r\n"), &cal))
+
+}
@@ -0,0 +1,231 @@
+// Package contentline reads and writes the "content lines" shared by the
+// iCalendar (RFC 5545) and vCard (RFC 6350) formats:
+//
+//	NAME;PARAM=value;OTHER="quoted, value":the value
+//
+// Long lines are folded at 75 octets and lines end with CRLF.
+package contentline
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// maxLineLength is the number of octets after which lines are folded.
+const maxLineLength int = 75
+
+// ErrorMalformedLine is returned when a content line has no value.
+var ErrorMalformedLine = errors.New("codecs: content line is missing a ':' separated value")
+
+// Line is a single (unfolded) content line.
+type Line struct {
+
+	// Name is the upper case property name, e.g. "DTSTART".
+	Name string
+
+	// Params holds the property parameters keyed by upper case name.
+	Params map[string]string
+
+	// Value is the raw (still escaped) value.
+	Value string
+}
+
+// Param gets the named parameter, or an empty string.
+func (l Line) Param(name string) string {
+	return l.Params[strings.ToUpper(name)]
+}
+
+// Parse unfolds and splits the data into content lines.  Blank lines are
+// ignored.
+func Parse(data []byte) ([]Line, error) {
+
+	// unfold the lines - any line starting with whitespace continues the
+	// previous one
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	var unfolded []string
+	for _, raw := range strings.Split(text, "\n") {
+		if len(raw) > 0 && (raw[0] == ' ' || raw[0] == '\t') && len(unfolded) > 0 {
+			unfolded[len(unfolded)-1] += raw[1:]
+			continue
+		}
+		unfolded = append(unfolded, raw)
+	}
+
+	var lines []Line
+	for _, raw := range unfolded {
+
+		if len(strings.TrimSpace(raw)) == 0 {
+			continue
+		}
+
+		line, err := parseLine(raw)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// parseLine parses a single unfolded line, taking care not to split on
+// separators inside quoted parameter values.
+func parseLine(raw string) (Line, error) {
+
+	line := Line{Params: map[string]string{}}
+
+	var segments []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				segments = append(segments, raw[start:i])
+				start = i + 1
+			}
+		case ':':
+			if !inQuotes {
+				segments = append(segments, raw[start:i])
+				line.Value = raw[i+1:]
+				line.Name = strings.ToUpper(strings.TrimSpace(segments[0]))
+				for _, param := range segments[1:] {
+					kv := strings.SplitN(param, "=", 2)
+					if len(kv) == 2 {
+						line.Params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+					}
+				}
+				return line, nil
+			}
+		}
+	}
+
+	return line, ErrorMalformedLine
+}
+
+// Writer builds up folded content lines.
+type Writer struct {
+	buffer bytes.Buffer
+}
+
+// Write adds a content line.  Parameters are written in name order, and
+// are quoted when they contain separators.  The value is written as-is, so
+// use EscapeText for text values.
+func (w *Writer) Write(name string, params map[string]string, value string) {
+
+	var line bytes.Buffer
+	line.WriteString(name)
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		line.WriteString(";")
+		line.WriteString(key)
+		line.WriteString("=")
+		if strings.ContainsAny(params[key], ";:,") {
+			line.WriteString(`"` + params[key] + `"`)
+		} else {
+			line.WriteString(params[key])
+		}
+	}
+
+	line.WriteString(":")
+	line.WriteString(value)
+
+	w.buffer.WriteString(fold(line.String()))
+	w.buffer.WriteString("\r\n")
+}
+
+// Bytes gets the content lines written so far.
+func (w *Writer) Bytes() []byte {
+	return w.buffer.Bytes()
+}
+
+// fold splits the line into 75 octet chunks, without breaking up UTF-8
+// sequences.
+func fold(line string) string {
+
+	if len(line) <= maxLineLength {
+		return line
+	}
+
+	var folded bytes.Buffer
+	limit := maxLineLength
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > limit {
+			folded.WriteString("\r\n ")
+			length = 0
+			// the leading space counts towards the line length
+			limit = maxLineLength - 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+
+	return folded.String()
+}
+
+// textEscaper escapes TEXT values.
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// EscapeText escapes backslashes, semicolons, commas and new lines.
+func EscapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(value string) string {
+
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var out bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				out.WriteByte('\n')
+			default:
+				out.WriteByte(value[i])
+			}
+			continue
+		}
+		out.WriteByte(value[i])
+	}
+
+	return out.String()
+}
+
+// SplitValues splits a multi-valued property on unescaped commas (or
+// another separator), leaving the parts escaped.
+func SplitValues(value string, separator byte) []string {
+
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == separator {
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
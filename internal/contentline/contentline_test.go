@@ -0,0 +1,59 @@
+package contentline
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	lines, err := Parse([]byte("BEGIN:VEVENT\r\nATTENDEE;CN=\"Ryer, Mat\";ROLE=CHAIR:mailto:mat@\r\n example.com\r\n\r\nEND:VEVENT\r\n"))
+
+	if assert.NoError(t, err) && assert.Equal(t, 3, len(lines)) {
+		assert.Equal(t, "BEGIN", lines[0].Name)
+		assert.Equal(t, "VEVENT", lines[0].Value)
+		assert.Equal(t, "ATTENDEE", lines[1].Name)
+		assert.Equal(t, "Ryer, Mat", lines[1].Param("cn"))
+		assert.Equal(t, "CHAIR", lines[1].Param("ROLE"))
+		assert.Equal(t, "mailto:mat@example.com", lines[1].Value)
+	}
+
+}
+
+func TestParse_Malformed(t *testing.T) {
+
+	_, err := Parse([]byte("BEGIN\r\n"))
+	assert.Equal(t, ErrorMalformedLine, err)
+
+}
+
+func TestWriter(t *testing.T) {
+
+	var w Writer
+	w.Write("ATTENDEE", map[string]string{"ROLE": "CHAIR", "CN": "Ryer, Mat"}, "mailto:mat@example.com")
+	w.Write("DESCRIPTION", nil, strings.Repeat("x", 100))
+
+	out := string(w.Bytes())
+	assert.Equal(t, "ATTENDEE;CN=\"Ryer, Mat\";ROLE=CHAIR:mailto:mat@example.com\r\nDESCRIPTION:"+strings.Repeat("x", 63)+"\r\n "+strings.Repeat("x", 37)+"\r\n", out)
+
+	lines, err := Parse(w.Bytes())
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(lines)) {
+		assert.Equal(t, strings.Repeat("x", 100), lines[1].Value)
+	}
+
+}
+
+func TestEscapeText(t *testing.T) {
+
+	escaped := EscapeText("a,b;c\\d\ne")
+	assert.Equal(t, `a\,b\;c\\d\ne`, escaped)
+	assert.Equal(t, "a,b;c\\d\ne", UnescapeText(escaped))
+
+}
+
+func TestSplitValues(t *testing.T) {
+
+	assert.Equal(t, []string{`a\,b`, "c", ""}, SplitValues(`a\,b,c,`, ','))
+
+}
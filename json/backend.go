@@ -0,0 +1,61 @@
+package json
+
+import (
+	jsonEncoding "encoding/json"
+	"io"
+)
+
+// Backend is the JSON implementation a JsonCodec marshals and unmarshals with,
+// so that faster implementations (such as jsoniter, go-json or sonic) can be
+// used in place of encoding/json.  Their encoders and decoders are usually
+// concrete types, which a Backend wraps to return as Encoders and Decoders.
+type Backend interface {
+	Marshal(object interface{}) ([]byte, error)
+	MarshalIndent(object interface{}, prefix, indent string) ([]byte, error)
+	Unmarshal(data []byte, obj interface{}) error
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder writes JSON values to a writer, as json.Encoder does.
+type Encoder interface {
+	Encode(object interface{}) error
+	SetIndent(prefix, indent string)
+	SetEscapeHTML(on bool)
+}
+
+// Decoder reads JSON values from a reader, as json.Decoder does.
+type Decoder interface {
+	Decode(obj interface{}) error
+	UseNumber()
+	DisallowUnknownFields()
+	More() bool
+	Buffered() io.Reader
+}
+
+// StandardBackend is the Backend of JsonCodecs without one, which uses
+// encoding/json.
+var StandardBackend Backend = standardBackend{}
+
+// standardBackend is the Backend using encoding/json.
+type standardBackend struct{}
+
+func (standardBackend) Marshal(object interface{}) ([]byte, error) {
+	return jsonEncoding.Marshal(object)
+}
+
+func (standardBackend) MarshalIndent(object interface{}, prefix, indent string) ([]byte, error) {
+	return jsonEncoding.MarshalIndent(object, prefix, indent)
+}
+
+func (standardBackend) Unmarshal(data []byte, obj interface{}) error {
+	return jsonEncoding.Unmarshal(data, obj)
+}
+
+func (standardBackend) NewEncoder(w io.Writer) Encoder {
+	return jsonEncoding.NewEncoder(w)
+}
+
+func (standardBackend) NewDecoder(r io.Reader) Decoder {
+	return jsonEncoding.NewDecoder(r)
+}
@@ -0,0 +1,68 @@
+package json
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+// countingBackend counts the calls made to the standard backend through it.
+type countingBackend struct {
+	calls map[string]int
+}
+
+func (b *countingBackend) Marshal(object interface{}) ([]byte, error) {
+	b.calls["Marshal"]++
+	return StandardBackend.Marshal(object)
+}
+
+func (b *countingBackend) MarshalIndent(object interface{}, prefix, indent string) ([]byte, error) {
+	b.calls["MarshalIndent"]++
+	return StandardBackend.MarshalIndent(object, prefix, indent)
+}
+
+func (b *countingBackend) Unmarshal(data []byte, obj interface{}) error {
+	b.calls["Unmarshal"]++
+	return StandardBackend.Unmarshal(data, obj)
+}
+
+func (b *countingBackend) NewEncoder(w io.Writer) Encoder {
+	b.calls["NewEncoder"]++
+	return StandardBackend.NewEncoder(w)
+}
+
+func (b *countingBackend) NewDecoder(r io.Reader) Decoder {
+	b.calls["NewDecoder"]++
+	return StandardBackend.NewDecoder(r)
+}
+
+func TestJsonCodec_Backend(t *testing.T) {
+
+	backend := &countingBackend{calls: map[string]int{}}
+	codec := &JsonCodec{Backend: backend}
+	object := map[string]interface{}{"name": "Mat"}
+
+	data, err := codec.Marshal(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+	}
+
+	_, err = codec.Marshal(object, map[string]interface{}{constants.OptionKeyIndent: "  "})
+	assert.NoError(t, err)
+
+	_, err = codec.Marshal(object, map[string]interface{}{constants.OptionKeyNoHTMLEscape: true})
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, codec.Unmarshal(data, &decoded))
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &decoded, map[string]interface{}{constants.OptionKeyStrict: true}))
+	assert.NoError(t, codec.Encode(new(bytes.Buffer), object, nil))
+	assert.NoError(t, codec.Decode(strings.NewReader(`{"name":"Mat"}`), &decoded))
+	assert.Equal(t, "Mat", decoded["name"])
+
+	assert.Equal(t, map[string]int{"Marshal": 1, "MarshalIndent": 1, "Unmarshal": 1, "NewEncoder": 2, "NewDecoder": 2}, backend.calls)
+
+}
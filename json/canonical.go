@@ -0,0 +1,202 @@
+package json
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrorNotCanonical is returned when marshalling numbers canonical JSON can't
+// hold, such as NaN and infinities.
+var ErrorNotCanonical = errors.New("codecs: json: Value cannot be represented as canonical JSON")
+
+// canonicalize rewrites the JSON as canonical JSON (see RFC 8785), with the
+// keys of objects sorted, no insignificant whitespace, minimal escaping of
+// strings and numbers written as ECMAScript writes them.
+func canonicalize(data []byte) ([]byte, error) {
+
+	decoder := jsonEncoding.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := writeCanonical(&buffer, value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeCanonical writes the decoded JSON value to the buffer as canonical JSON.
+func writeCanonical(buffer *bytes.Buffer, value interface{}) error {
+
+	switch value := value.(type) {
+	case nil:
+		buffer.WriteString("null")
+
+	case bool:
+		buffer.WriteString(strconv.FormatBool(value))
+
+	case jsonEncoding.Number:
+		f, err := value.Float64()
+		if err != nil && !math.IsInf(f, 0) {
+			return err
+		}
+		number, err := canonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		buffer.WriteString(number)
+
+	case string:
+		writeCanonicalString(buffer, value)
+
+	case []interface{}:
+		buffer.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeCanonical(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		// keys are sorted by their UTF-16 code units
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+
+		buffer.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			writeCanonicalString(buffer, key)
+			buffer.WriteByte(':')
+			if err := writeCanonical(buffer, value[key]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+
+	default:
+		return fmt.Errorf("codecs: json: Unexpected %T in decoded JSON", value)
+	}
+
+	return nil
+}
+
+// lessUTF16 gets whether a sorts before b when compared by their UTF-16 code
+// units.
+func lessUTF16(a, b string) bool {
+
+	unitsA, unitsB := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+
+	for i := 0; i < len(unitsA) && i < len(unitsB); i++ {
+		if unitsA[i] != unitsB[i] {
+			return unitsA[i] < unitsB[i]
+		}
+	}
+
+	return len(unitsA) < len(unitsB)
+}
+
+// writeCanonicalString writes the string to the buffer quoted, escaping only
+// quotes, backslashes and control characters.
+func writeCanonicalString(buffer *bytes.Buffer, s string) {
+
+	buffer.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buffer, `\u%04x`, r)
+			} else {
+				buffer.WriteRune(r)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+}
+
+// canonicalNumber gets the number as ECMAScript's Number.prototype.toString
+// writes it: in the fewest digits that read back as the same float64, without
+// an exponent unless it is smaller than 1e-6 or at least 1e21.
+func canonicalNumber(f float64) (string, error) {
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrorNotCanonical
+	}
+
+	if f == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+
+	// get the shortest digits and the exponent, as in 1.2345e+06
+	formatted := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent := formatted, 0
+	if index := strings.IndexByte(formatted, 'e'); index >= 0 {
+		mantissa = formatted[:index]
+		exponent, _ = strconv.Atoi(formatted[index+1:])
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+
+	// the value is 0.digits times 10 to the power of n
+	k, n := len(digits), exponent+1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+
+	exponentSign := "+"
+	if n-1 < 0 {
+		exponentSign = "-"
+	}
+	exponentDigits := strconv.Itoa(int(math.Abs(float64(n - 1))))
+
+	if k == 1 {
+		return sign + digits + "e" + exponentSign + exponentDigits, nil
+	}
+
+	return sign + digits[:1] + "." + digits[1:] + "e" + exponentSign + exponentDigits, nil
+}
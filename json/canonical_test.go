@@ -0,0 +1,73 @@
+package json
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestCanonicalNumber(t *testing.T) {
+
+	for number, expected := range map[float64]string{
+		0:                      "0",
+		math.Copysign(0, -1):   "0",
+		4.50:                   "4.5",
+		-2e-3:                  "-0.002",
+		0.000001:               "0.000001",
+		1e-7:                   "1e-7",
+		1e-27:                  "1e-27",
+		333333333.33333329:     "333333333.3333333",
+		9007199254740992:       "9007199254740992",
+		295147905179352830000:  "295147905179352830000",
+		1e21:                   "1e+21",
+		1e30:                   "1e+30",
+		-1.5e300:               "-1.5e+300",
+		5e-324:                 "5e-324",
+		1.7976931348623157e308: "1.7976931348623157e+308",
+	} {
+		actual, err := canonicalNumber(number)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, actual, "%v", number)
+		}
+	}
+
+	_, err := canonicalNumber(math.NaN())
+	assert.Equal(t, ErrorNotCanonical, err)
+
+}
+
+func TestMarshal_Canonical(t *testing.T) {
+
+	options := map[string]interface{}{constants.OptionKeyCanonical: true, constants.OptionKeyIndent: "  "}
+
+	// keys are sorted by their UTF-16 code units, as in RFC 8785
+	object := map[string]interface{}{
+		"\u20ac":     "Euro Sign",
+		"\r":         "Carriage Return",
+		"\ufb33":     "Hebrew Letter Dalet With Dagesh",
+		"1":          "One",
+		"\U0001f600": "Emoji: Grinning Face",
+		"\u0080":     "Control",
+		"\u00f6":     "Latin Small Letter O With Diaeresis",
+		"string":     "\u20ac$\u000f\nA'B\"\\<&>",
+		"numbers":    []interface{}{333333333.33333329, 1e30, 4.50, 2e-3, 1e-27},
+		"literals":   []interface{}{nil, true, false},
+	}
+
+	data, err := codec.Marshal(object, options)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"literals\":[null,true,false],\"numbers\":[333333333.3333333,1e+30,4.5,0.002,1e-27],\"string\":\"\u20ac$\\u000f\\nA'B\\\"\\\\<&>\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(data))
+	}
+
+	// structs are canonical too
+	var buffer bytes.Buffer
+	if assert.NoError(t, codec.Encode(&buffer, struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}{"Mat", 30}, options)) {
+		assert.Equal(t, "{\"age\":30,\"name\":\"Mat\"}\n", buffer.String())
+	}
+
+}
@@ -0,0 +1,81 @@
+package json
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+)
+
+// keyFrame is an object or array being scanned by checkDuplicateKeys.
+type keyFrame struct {
+	// keys are the keys of the object so far, or nil for an array.
+	keys map[string]bool
+
+	// expectingKey is whether the next token of the object is a key.
+	expectingKey bool
+}
+
+// checkDuplicateKeys checks that no object in the JSON has the same key more
+// than once, returning a *DuplicateKeyError for the first that does.  Malformed
+// JSON is left for Unmarshal to report.
+func checkDuplicateKeys(data []byte) error {
+
+	decoder := jsonEncoding.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var stack []*keyFrame
+
+	// valueEnded notes that a value of the innermost object has been read,
+	// so that a key comes next
+	valueEnded := func() {
+		if len(stack) > 0 && stack[len(stack)-1].keys != nil {
+			stack[len(stack)-1].expectingKey = true
+		}
+	}
+
+	for {
+
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+
+		if len(stack) > 0 && stack[len(stack)-1].expectingKey {
+			if key, ok := token.(string); ok {
+				frame := stack[len(stack)-1]
+				if frame.keys[key] {
+					return &DuplicateKeyError{Key: key, Offset: keyStart(data, offset)}
+				}
+				frame.keys[key] = true
+				frame.expectingKey = false
+				continue
+			}
+		}
+
+		switch token {
+		case jsonEncoding.Delim('{'):
+			stack = append(stack, &keyFrame{keys: map[string]bool{}, expectingKey: true})
+		case jsonEncoding.Delim('['):
+			stack = append(stack, &keyFrame{})
+		case jsonEncoding.Delim('}'), jsonEncoding.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueEnded()
+		default:
+			valueEnded()
+		}
+	}
+}
+
+// keyStart gets the offset of the key read from the offset, skipping the
+// separator and whitespace before it.
+func keyStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ',', ' ', '\t', '\n', '\r':
+			offset++
+			continue
+		}
+		break
+	}
+	return offset
+}
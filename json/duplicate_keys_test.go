@@ -0,0 +1,37 @@
+package json
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnmarshalWithOptions_NoDuplicateKeys(t *testing.T) {
+
+	options := map[string]interface{}{constants.OptionKeyNoDuplicateKeys: true}
+	var object map[string]interface{}
+
+	// the last value wins by default
+	assert.NoError(t, codec.UnmarshalWithOptions([]byte(`{"role":"user","role":"admin"}`), &object, nil))
+	assert.Equal(t, "admin", object["role"])
+
+	err := codec.UnmarshalWithOptions([]byte(`{"role":"user", "role":"admin"}`), &object, options)
+	if assert.Error(t, err) {
+		assert.Equal(t, &DuplicateKeyError{Key: "role", Offset: 16}, err)
+		assert.Equal(t, `codecs: json: Duplicate key "role" at offset 16`, err.Error())
+	}
+
+	// keys of nested objects and objects in arrays are checked too
+	err = codec.UnmarshalWithOptions([]byte(`{"users":[{"id":1},{"id":2,"tags":["id"],"id":3}]}`), &object, options)
+	assert.Equal(t, &DuplicateKeyError{Key: "id", Offset: 41}, err)
+
+	// keys are only duplicates within the same object, and values aren't
+	// keys
+	data := []byte(`{"id":"id","user":{"id":1,"name":{"id":"x"}},"list":[{"id":1},{"id":2}],"name":"id"}`)
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &object, options))
+	assert.Equal(t, "id", object["id"])
+
+	// malformed JSON is reported by decoding
+	assert.Error(t, codec.UnmarshalWithOptions([]byte(`{"id":`), &object, options))
+
+}
@@ -0,0 +1,74 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrorNotArray is returned by DecodeSequence when the JSON isn't an array.
+var ErrorNotArray = errors.New("codecs: json: Data is not an array")
+
+// ErrorElementDecoded is returned when decoding an element of a sequence (see
+// DecodeSequence) more than once.
+var ErrorElementDecoded = errors.New("codecs: json: Element has already been decoded")
+
+// UnknownFieldError is the error UnmarshalWithOptions returns when the
+// constants.OptionKeyStrict option is true and the JSON has a field the object
+// doesn't have.
+type UnknownFieldError struct {
+	// Field is the name of the field, as it is in the JSON.
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("codecs: json: Unknown field \"%s\"", e.Field)
+}
+
+// StatusCode gets 400 Bad Request, the status to respond to requests with
+// unknown fields with.
+func (e *UnknownFieldError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// DuplicateKeyError is the error UnmarshalWithOptions returns when the
+// constants.OptionKeyNoDuplicateKeys option is true and an object in the JSON
+// has the same key more than once.
+type DuplicateKeyError struct {
+	// Key is the key, and Offset the offset in bytes of its second
+	// occurrence in the JSON.
+	Key    string
+	Offset int64
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("codecs: json: Duplicate key \"%s\" at offset %d", e.Key, e.Offset)
+}
+
+// StatusCode gets 400 Bad Request, the status to respond to requests with
+// duplicate keys with.
+func (e *DuplicateKeyError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// unknownFieldPrefix starts the errors encoding/json returns for unknown
+// fields, which are followed by the quoted field name.
+const unknownFieldPrefix = "json: unknown field "
+
+// unknownFieldError gets an *UnknownFieldError in place of the error
+// encoding/json returns for unknown fields, or the error as it is.
+func unknownFieldError(err error) error {
+
+	if err == nil || !strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		return err
+	}
+
+	field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+	if unquoteErr != nil {
+		return err
+	}
+
+	return &UnknownFieldError{Field: field}
+}
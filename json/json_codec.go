@@ -1,21 +1,255 @@
 package json
 
 import (
+	"bytes"
 	jsonEncoding "encoding/json"
+	"errors"
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
+	"io"
+	"reflect"
 )
 
 // JsonCodec converts objects to and from JSON.
-type JsonCodec struct{}
+type JsonCodec struct {
 
-// Converts an object to JSON.
+	// Backend is the JSON implementation to use, or nil for
+	// StandardBackend.  Canonical output and CheckLimits always use
+	// encoding/json.
+	Backend Backend
+}
+
+func init() {
+	codecs.Register(new(JsonCodec))
+}
+
+// PrettyIndent is the indentation of JSON marshalled with the
+// constants.OptionKeyPretty option but without the constants.OptionKeyIndent
+// option.
+var PrettyIndent = "  "
+
+// Converts an object to JSON, indented by the constants.OptionKeyIndent
+// option if it is given (see indentation), or as canonical JSON (see RFC 8785)
+// if the constants.OptionKeyCanonical option is true.  <, > and & are escaped
+// unless the constants.OptionKeyNoHTMLEscape option is true.
 func (c *JsonCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
-	return jsonEncoding.Marshal(object)
+	if canonical(options) {
+		data, err := jsonEncoding.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		return canonicalize(data)
+	}
+	return c.marshal(object, "", indentation(options), options)
+}
+
+// backend gets the codec's Backend, or StandardBackend if it has none.
+func (c *JsonCodec) backend() Backend {
+	if c.Backend == nil {
+		return StandardBackend
+	}
+	return c.Backend
+}
+
+// marshal converts an object to JSON as json.MarshalIndent does, or
+// json.Marshal does without an indent, leaving <, > and & as they are if the
+// constants.OptionKeyNoHTMLEscape option is true.
+func (c *JsonCodec) marshal(object interface{}, prefix, indent string, options map[string]interface{}) ([]byte, error) {
+
+	backend := c.backend()
+
+	if escapeHTML(options) {
+		if len(indent) > 0 {
+			return backend.MarshalIndent(object, prefix, indent)
+		}
+		return backend.Marshal(object)
+	}
+
+	var buffer bytes.Buffer
+	encoder := backend.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, indent)
+
+	if err := encoder.Encode(object); err != nil {
+		return nil, err
+	}
+
+	// unlike json.Marshal, the encoder ends the value with a newline
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// escapeHTML gets whether <, > and & are escaped, as they are unless the
+// constants.OptionKeyNoHTMLEscape option is true.
+func escapeHTML(options map[string]interface{}) bool {
+	noHTMLEscape, _ := options[constants.OptionKeyNoHTMLEscape].(bool)
+	return !noHTMLEscape
+}
+
+// canonical gets whether the constants.OptionKeyCanonical option is true.
+func canonical(options map[string]interface{}) bool {
+	canonical, _ := options[constants.OptionKeyCanonical].(bool)
+	return canonical
+}
+
+// indentation gets the indentation given by the constants.OptionKeyIndent
+// option, or PrettyIndent if only the constants.OptionKeyPretty option is true,
+// or empty for compact (or canonical) output.
+func indentation(options map[string]interface{}) string {
+
+	if canonical(options) {
+		return ""
+	}
+
+	if indent, ok := options[constants.OptionKeyIndent].(string); ok && len(indent) > 0 {
+		return indent
+	}
+
+	if pretty, _ := options[constants.OptionKeyPretty].(bool); pretty {
+		return PrettyIndent
+	}
+
+	return ""
 }
 
 // Unmarshal converts JSON into an object.
 func (c *JsonCodec) Unmarshal(data []byte, obj interface{}) error {
-	return jsonEncoding.Unmarshal(data, obj)
+	return c.backend().Unmarshal(data, obj)
+}
+
+// UnmarshalWithOptions converts JSON into an object, refusing fields the
+// object doesn't have with an *UnknownFieldError if the
+// constants.OptionKeyStrict option is true, and objects with the same key more
+// than once with a *DuplicateKeyError if the
+// constants.OptionKeyNoDuplicateKeys option is true.  Numbers are decoded into
+// interface{} values as the constants.OptionKeyNumbers option's
+// codecs.NumberMode says.
+func (c *JsonCodec) UnmarshalWithOptions(data []byte, obj interface{}, options map[string]interface{}) error {
+
+	if noDuplicateKeys, _ := options[constants.OptionKeyNoDuplicateKeys].(bool); noDuplicateKeys {
+		if err := checkDuplicateKeys(data); err != nil {
+			return err
+		}
+	}
+
+	strict, _ := options[constants.OptionKeyStrict].(bool)
+	numbers, _ := options[constants.OptionKeyNumbers].(codecs.NumberMode)
+
+	if !strict && numbers == codecs.NumbersAsFloat {
+		return c.Unmarshal(data, obj)
+	}
+
+	reader := bytes.NewReader(data)
+	decoder := c.backend().NewDecoder(reader)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if numbers != codecs.NumbersAsFloat {
+		decoder.UseNumber()
+	}
+
+	if err := decoder.Decode(obj); err != nil {
+		return unknownFieldError(err)
+	}
+
+	// like Unmarshal, refuse anything but whitespace after the value, which
+	// includes a stray } or ] that More doesn't see
+	if _, err := jsonEncoding.NewDecoder(io.MultiReader(decoder.Buffered(), reader)).Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+
+	if numbers == codecs.NumbersAsInt64 {
+		intNumbers(reflect.ValueOf(obj))
+	}
+
+	return nil
+}
+
+// Encode writes an object to w as JSON, followed by a newline, and indented
+// as Marshal indents it.  Slices are written element by element, as
+// EncodeSequence writes them.
+func (c *JsonCodec) Encode(w io.Writer, object interface{}, options map[string]interface{}) error {
+	if canonical(options) {
+		data, err := c.Marshal(object, options)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	if sequence, ok := sliceSequence(object); ok {
+		return c.EncodeSequence(w, sequence, options)
+	}
+	encoder := c.backend().NewEncoder(w)
+	encoder.SetEscapeHTML(escapeHTML(options))
+	if indent := indentation(options); len(indent) > 0 {
+		encoder.SetIndent("", indent)
+	}
+	return encoder.Encode(object)
+}
+
+// EncodeSequence writes the elements of the sequence to w as a JSON array as
+// they come, followed by a newline, and indented as Marshal indents it.  Writers
+// with a Flush method (such as http.ResponseWriters and bufio.Writers) are
+// flushed after the first element and then every FlushEvery elements, so that
+// clients get the array as it is written.
+func (c *JsonCodec) EncodeSequence(w io.Writer, sequence codecs.Sequence, options map[string]interface{}) error {
+
+	indent := indentation(options)
+
+	separator, open, close := ",", "[", "]\n"
+	if len(indent) > 0 {
+		separator, open, close = ",\n"+indent, "[\n"+indent, "\n]\n"
+	}
+
+	written := 0
+	err := sequence.Each(func(element interface{}) error {
+
+		var data []byte
+		var err error
+		if len(indent) > 0 {
+			data, err = c.marshal(element, indent, indent, options)
+		} else {
+			data, err = c.Marshal(element, options)
+		}
+		if err != nil {
+			return err
+		}
+
+		prefix := separator
+		if written == 0 {
+			prefix = open
+		}
+		written++
+
+		if _, err := io.WriteString(w, prefix); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+
+		if written == 1 || (FlushEvery > 0 && written%FlushEvery == 0) {
+			return flush(w)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if written == 0 {
+		_, err = io.WriteString(w, "[]\n")
+		return err
+	}
+
+	_, err = io.WriteString(w, close)
+	return err
+}
+
+// Decode reads JSON from r into an object.
+func (c *JsonCodec) Decode(r io.Reader, obj interface{}) error {
+	return c.backend().NewDecoder(r).Decode(obj)
 }
 
 // ContentType returns the content type for this codec.
@@ -28,7 +262,50 @@ func (c *JsonCodec) FileExtension() string {
 	return constants.FileExtensionJSON
 }
 
+// Suffixes returns the structured syntax suffixes this codec can handle.
+func (c *JsonCodec) Suffixes() []string {
+	return []string{"json"}
+}
+
 // CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
 func (c *JsonCodec) CanMarshalWithCallback() bool {
 	return false
 }
+
+// CheckLimits checks that the JSON doesn't exceed the limits, scanning it
+// without decoding it into an object.
+func (c *JsonCodec) CheckLimits(data []byte, limits codecs.DecodeLimits) error {
+
+	decoder := jsonEncoding.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	checker := codecs.LimitChecker{Limits: limits}
+
+	for {
+
+		token, err := decoder.Token()
+
+		// malformed data is left for Unmarshal to report
+		if err != nil {
+			return nil
+		}
+
+		switch token := token.(type) {
+		case jsonEncoding.Delim:
+			if token == '}' || token == ']' {
+				checker.Leave()
+				continue
+			}
+			err = checker.Enter()
+		case string:
+			err = checker.String(len(token))
+		}
+
+		if err == nil {
+			err = checker.Element()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
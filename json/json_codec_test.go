@@ -1,6 +1,8 @@
 package json
 
 import (
+	"bytes"
+	jsonEncoding "encoding/json"
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +17,13 @@ func TestInterface(t *testing.T) {
 
 }
 
+func TestSuffixes(t *testing.T) {
+
+	assert.Implements(t, (*codecs.SuffixCodec)(nil), new(JsonCodec), "JsonCodec")
+	assert.Equal(t, []string{"json"}, codec.Suffixes())
+
+}
+
 func TestMarshal(t *testing.T) {
 
 	obj := make(map[string]string)
@@ -30,6 +39,60 @@ func TestMarshal(t *testing.T) {
 
 }
 
+func TestMarshal_Indent(t *testing.T) {
+
+	bytes, err := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyIndent: "  "})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"name\": \"Mat\"\n}", string(bytes))
+	}
+
+}
+
+func TestMarshal_Pretty(t *testing.T) {
+
+	bytes, err := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyPretty: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"name\": \"Mat\"\n}", string(bytes))
+	}
+
+	// an indent given wins
+	bytes, err = codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyPretty: true, constants.OptionKeyIndent: "\t"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n\t\"name\": \"Mat\"\n}", string(bytes))
+	}
+
+}
+
+func TestMarshal_NoHTMLEscape(t *testing.T) {
+
+	object := map[string]string{"link": "<a href=\"/?a=1&b=2\">"}
+
+	data, err := codec.Marshal(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"link":"\u003ca href=\"/?a=1\u0026b=2\"\u003e"}`, string(data))
+	}
+
+	options := map[string]interface{}{constants.OptionKeyNoHTMLEscape: true}
+
+	data, err = codec.Marshal(object, options)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"link":"<a href=\"/?a=1&b=2\">"}`, string(data))
+	}
+
+	options[constants.OptionKeyIndent] = "  "
+	data, err = codec.Marshal(object, options)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"link\": \"<a href=\\\"/?a=1&b=2\\\">\"\n}", string(data))
+	}
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, codec.Encode(&buffer, object, map[string]interface{}{constants.OptionKeyNoHTMLEscape: true})) {
+		assert.Equal(t, `{"link":"<a href=\"/?a=1&b=2\">"}`+"\n", buffer.String())
+	}
+
+}
+
 func TestUnmarshal(t *testing.T) {
 
 	jsonString := `{"name":"Mat"}`
@@ -45,6 +108,111 @@ func TestUnmarshal(t *testing.T) {
 
 }
 
+func TestUnmarshalWithOptions_Strict(t *testing.T) {
+
+	var object struct {
+		Name string `json:"name"`
+	}
+
+	assert.NoError(t, codec.UnmarshalWithOptions([]byte(`{"name":"Mat","age":30}`), &object, nil))
+	assert.Equal(t, "Mat", object.Name)
+
+	err := codec.UnmarshalWithOptions([]byte(`{"name":"Mat","age":30}`), &object, map[string]interface{}{constants.OptionKeyStrict: true})
+	if assert.Error(t, err) {
+		assert.Equal(t, &UnknownFieldError{Field: "age"}, err)
+		assert.Equal(t, `codecs: json: Unknown field "age"`, err.Error())
+	}
+	assert.Error(t, codec.UnmarshalWithOptions([]byte(`{"name":"Mat"} {}`), &object, map[string]interface{}{constants.OptionKeyStrict: true}))
+	assert.Error(t, codec.UnmarshalWithOptions([]byte(`{"name":"Mat"}}`), &object, map[string]interface{}{constants.OptionKeyStrict: true}))
+	assert.Error(t, codec.UnmarshalWithOptions([]byte(`{"name":"Mat"} ]`), &object, map[string]interface{}{constants.OptionKeyStrict: true}))
+	assert.NoError(t, codec.UnmarshalWithOptions([]byte(`{"name":"Tyler"}`+"\n"), &object, map[string]interface{}{constants.OptionKeyStrict: true}))
+	assert.Equal(t, "Tyler", object.Name)
+
+}
+
+func TestUnmarshalWithOptions_Numbers(t *testing.T) {
+
+	data := []byte(`{"id":9007199254740993,"price":10.25,"huge":18446744073709551616,"items":[{"count":3}]}`)
+
+	var object map[string]interface{}
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &object, nil))
+	assert.Equal(t, float64(9007199254740992), object["id"])
+
+	object = nil
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &object, map[string]interface{}{constants.OptionKeyNumbers: codecs.NumbersAsJSONNumber}))
+	assert.Equal(t, jsonEncoding.Number("9007199254740993"), object["id"])
+	assert.Equal(t, jsonEncoding.Number("10.25"), object["price"])
+
+	object = nil
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &object, map[string]interface{}{constants.OptionKeyNumbers: codecs.NumbersAsInt64}))
+	assert.Equal(t, map[string]interface{}{
+		"id":    int64(9007199254740993),
+		"price": 10.25,
+		"huge":  jsonEncoding.Number("18446744073709551616"),
+		"items": []interface{}{map[string]interface{}{"count": int64(3)}},
+	}, object)
+
+	// numbers in interface{} fields of structs are decoded too
+	var order struct {
+		ID    interface{} `json:"id"`
+		Price float64     `json:"price"`
+	}
+	assert.NoError(t, codec.UnmarshalWithOptions(data, &order, map[string]interface{}{constants.OptionKeyNumbers: codecs.NumbersAsInt64}))
+	assert.Equal(t, int64(9007199254740993), order.ID)
+	assert.Equal(t, 10.25, order.Price)
+
+}
+
+func TestEncodeAndDecode(t *testing.T) {
+
+	assert.Implements(t, (*codecs.StreamingCodec)(nil), new(JsonCodec), "JsonCodec")
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, codec.Encode(&buffer, map[string]string{"name": "Mat"}, nil)) {
+		assert.Equal(t, "{\"name\":\"Mat\"}\n", buffer.String())
+	}
+
+	var object map[string]interface{}
+	if assert.NoError(t, codec.Decode(&buffer, &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+}
+
+// sequenceOf makes a codecs.Sequence of the elements.
+func sequenceOf(elements ...interface{}) codecs.Sequence {
+	return codecs.SequenceFunc(func(fn func(element interface{}) error) error {
+		for _, element := range elements {
+			if err := fn(element); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestEncodeSequence(t *testing.T) {
+
+	assert.Implements(t, (*codecs.SequenceEncoder)(nil), new(JsonCodec), "JsonCodec")
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, codec.EncodeSequence(&buffer, sequenceOf(map[string]string{"name": "Mat"}, 2), nil)) {
+		assert.Equal(t, "[{\"name\":\"Mat\"},2]\n", buffer.String())
+	}
+
+	buffer.Reset()
+	if assert.NoError(t, codec.EncodeSequence(&buffer, sequenceOf(map[string]string{"name": "Mat"}, 2), map[string]interface{}{constants.OptionKeyIndent: "  "})) {
+		indented, _ := codec.Marshal([]interface{}{map[string]string{"name": "Mat"}, 2}, map[string]interface{}{constants.OptionKeyIndent: "  "})
+		assert.Equal(t, string(indented)+"\n", buffer.String())
+	}
+
+	buffer.Reset()
+	if assert.NoError(t, codec.EncodeSequence(&buffer, sequenceOf(), nil)) {
+		assert.Equal(t, "[]\n", buffer.String())
+	}
+
+}
+
 func TestResponseContentType(t *testing.T) {
 
 	assert.Equal(t, codec.ContentType(), constants.ContentTypeJSON)
@@ -62,3 +230,17 @@ func TestCanMarshalWithCallback(t *testing.T) {
 	assert.False(t, codec.CanMarshalWithCallback())
 
 }
+
+func TestCheckLimits(t *testing.T) {
+
+	data := []byte(`{"name":"Mat","tags":[["a"],["b"]]}`)
+
+	assert.NoError(t, codec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 3, MaxElements: 9, MaxStringLength: 4}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 2}, codec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 2}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitElements, Max: 8}, codec.CheckLimits(data, codecs.DecodeLimits{MaxElements: 8}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitStringLength, Max: 3}, codec.CheckLimits(data, codecs.DecodeLimits{MaxStringLength: 3}))
+
+	// malformed data is left for Unmarshal
+	assert.NoError(t, codec.CheckLimits([]byte(`{"name":`), codecs.DecodeLimits{MaxDepth: 1}))
+
+}
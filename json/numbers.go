@@ -0,0 +1,77 @@
+package json
+
+import (
+	jsonEncoding "encoding/json"
+	"reflect"
+	"strings"
+)
+
+// intNumbers replaces the json.Numbers held by interface{} values within the
+// value with int64s where they are whole numbers that fit, and float64s
+// otherwise.  Whole numbers too large for int64s are left as json.Numbers so
+// that they aren't corrupted.
+func intNumbers(value reflect.Value) {
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			intNumbers(value.Elem())
+		}
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return
+		}
+		if number, ok := value.Elem().Interface().(jsonEncoding.Number); ok {
+			if value.CanSet() {
+				value.Set(reflect.ValueOf(numberValue(number)))
+			}
+			return
+		}
+		intNumbers(value.Elem())
+
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			item := value.MapIndex(key)
+			if item.Kind() == reflect.Interface && !item.IsNil() {
+				if number, ok := item.Elem().Interface().(jsonEncoding.Number); ok {
+					value.SetMapIndex(key, reflect.ValueOf(numberValue(number)))
+					continue
+				}
+			}
+			intNumbers(item)
+		}
+
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			intNumbers(value.Index(i))
+		}
+
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Field(i); field.CanSet() {
+				intNumbers(field)
+			}
+		}
+	}
+}
+
+// numberValue gets the number as an int64 if it is a whole number that fits,
+// or otherwise as a float64, leaving whole numbers too large for an int64 as
+// they are.
+func numberValue(number jsonEncoding.Number) interface{} {
+
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+
+	if !strings.ContainsAny(number.String(), ".eE") {
+		return number
+	}
+
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+
+	return number
+}
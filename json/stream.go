@@ -0,0 +1,102 @@
+package json
+
+import (
+	"encoding"
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// FlushEvery is the number of elements EncodeSequence writes between flushes
+// of writers with a Flush method, or 0 to flush only after the first element.
+var FlushEvery = 100
+
+// marshalerTypes are the interfaces of values that marshal themselves, which
+// slices aren't written element by element if they implement.
+var marshalerTypes = []reflect.Type{
+	reflect.TypeOf((*jsonEncoding.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+}
+
+// sliceSequence gets the elements of the object as a sequence if it is a
+// slice (other than nil slices, []byte and slices that marshal themselves)
+// that can be written element by element.
+func sliceSequence(object interface{}) (codecs.Sequence, bool) {
+
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Slice || value.IsNil() || value.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	for _, marshalerType := range marshalerTypes {
+		if value.Type().Implements(marshalerType) {
+			return nil, false
+		}
+	}
+
+	return codecs.SequenceFunc(func(fn func(element interface{}) error) error {
+		for i := 0; i < value.Len(); i++ {
+			if err := fn(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), true
+}
+
+// flush flushes the writer if it has a Flush method.
+func flush(w io.Writer) error {
+	switch flusher := w.(type) {
+	case interface{ Flush() error }:
+		return flusher.Flush()
+	case http.Flusher:
+		flusher.Flush()
+	}
+	return nil
+}
+
+// DecodeSequence reads a JSON array from r, calling fn for each of its
+// elements as they are read (see codecs.SequenceDecoder), so that large arrays
+// needn't be held in memory.  Elements are decoded with encoding/json.
+func (c *JsonCodec) DecodeSequence(r io.Reader, fn func(decode func(obj interface{}) error) error) error {
+
+	decoder := jsonEncoding.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != jsonEncoding.Delim('[') {
+		return ErrorNotArray
+	}
+
+	for decoder.More() {
+
+		decoded := false
+		decode := func(obj interface{}) error {
+			if decoded {
+				return ErrorElementDecoded
+			}
+			decoded = true
+			return decoder.Decode(obj)
+		}
+
+		if err := fn(decode); err != nil {
+			return err
+		}
+
+		// skip elements fn didn't decode
+		if !decoded {
+			var skipped jsonEncoding.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+		}
+	}
+
+	// read the closing bracket
+	_, err = decoder.Token()
+	return err
+}
@@ -0,0 +1,113 @@
+package json
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+)
+
+// flushingBuffer records how much had been written each time it is flushed.
+type flushingBuffer struct {
+	bytes.Buffer
+	flushed []int
+}
+
+func (b *flushingBuffer) Flush() {
+	b.flushed = append(b.flushed, b.Len())
+}
+
+func TestEncode_Slices(t *testing.T) {
+
+	for _, object := range []interface{}{
+		[]interface{}{map[string]string{"name": "Mat"}, 2, "<&>"},
+		[]string{},
+		[]int(nil),
+		[]byte("raw"),
+		net.IPv4(10, 0, 0, 1),
+	} {
+		for _, options := range []map[string]interface{}{nil, {constants.OptionKeyIndent: "  "}} {
+
+			var expected bytes.Buffer
+			encoder := jsonEncoding.NewEncoder(&expected)
+			if indent, ok := options[constants.OptionKeyIndent].(string); ok {
+				encoder.SetIndent("", indent)
+			}
+			encoder.Encode(object)
+
+			var buffer bytes.Buffer
+			if assert.NoError(t, codec.Encode(&buffer, object, options)) {
+				assert.Equal(t, expected.String(), buffer.String())
+			}
+		}
+	}
+
+}
+
+func TestEncodeSequence_Flush(t *testing.T) {
+
+	defer func(flushEvery int) { FlushEvery = flushEvery }(FlushEvery)
+	FlushEvery = 2
+
+	buffer := new(flushingBuffer)
+	if assert.NoError(t, codec.Encode(buffer, []int{1, 2, 3, 4, 5}, nil)) {
+		assert.Equal(t, "[1,2,3,4,5]\n", buffer.String())
+		assert.Equal(t, []int{2, 4, 8}, buffer.flushed)
+	}
+
+}
+
+func TestDecodeSequence(t *testing.T) {
+
+	assert.Implements(t, (*codecs.SequenceDecoder)(nil), new(JsonCodec), "JsonCodec")
+
+	type person struct {
+		Name string `json:"name"`
+	}
+
+	var people []person
+	err := codec.DecodeSequence(strings.NewReader(` [{"name":"Mat"}, {"name":"Tyler"}, {"skip":[1,2]}, {"name":"Ryan"}]`), func(decode func(obj interface{}) error) error {
+		if len(people) == 2 {
+			people = append(people, person{Name: "skipped"})
+			return nil
+		}
+		var p person
+		if err := decode(&p); err != nil {
+			return err
+		}
+		assert.Equal(t, ErrorElementDecoded, decode(&p))
+		people = append(people, p)
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []person{{"Mat"}, {"Tyler"}, {"skipped"}, {"Ryan"}}, people)
+	}
+
+	// errors from fn stop the decoding
+	stop := errors.New("stop")
+	calls := 0
+	err = codec.DecodeSequence(strings.NewReader(`[1,2,3]`), func(decode func(obj interface{}) error) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+
+	err = codec.DecodeSequence(strings.NewReader(`{"name":"Mat"}`), func(decode func(obj interface{}) error) error {
+		return nil
+	})
+	assert.Equal(t, ErrorNotArray, err)
+
+	err = codec.DecodeSequence(strings.NewReader(`[1,2`), func(decode func(obj interface{}) error) error {
+		var number int
+		return decode(&number)
+	})
+	assert.Error(t, err)
+
+}
@@ -0,0 +1,14 @@
+// A codec for leniently decoding JSON5 (and HJSON style) input.
+//
+// Unmarshal accepts the things people tend to write in hand edited files:
+//
+//	// line, /* block */ and # comments
+//	trailing commas in objects and arrays
+//	unquoted object keys
+//	'single quoted' strings and escaped line breaks within strings
+//	hexadecimal numbers, leading + signs and leading or trailing decimal points
+//
+// The input is rewritten as strict JSON and then decoded by encoding/json, so
+// the usual encoding/json rules apply to the target object.  Marshal always
+// writes strict JSON, which is also valid JSON5.
+package json5
@@ -0,0 +1,46 @@
+package json5
+
+import (
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+)
+
+// Json5Codec leniently reads JSON5 and writes strict JSON.
+type Json5Codec struct{}
+
+func init() {
+	codecs.Register(new(Json5Codec))
+}
+
+// Marshal converts an object to (strict) JSON.
+func (c *Json5Codec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return jsonEncoding.Marshal(object)
+}
+
+// Unmarshal converts JSON5 into an object.
+func (c *Json5Codec) Unmarshal(data []byte, obj interface{}) error {
+
+	strict, err := normalize(data)
+
+	if err != nil {
+		return err
+	}
+
+	return jsonEncoding.Unmarshal(strict, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *Json5Codec) ContentType() string {
+	return constants.ContentTypeJSON5
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *Json5Codec) FileExtension() string {
+	return constants.FileExtensionJSON5
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *Json5Codec) CanMarshalWithCallback() bool {
+	return false
+}
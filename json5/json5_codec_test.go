@@ -0,0 +1,100 @@
+package json5
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var json5Codec Json5Codec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(Json5Codec), "Json5Codec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeJSON5, json5Codec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionJSON5, json5Codec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, json5Codec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	bytes, err := json5Codec.Marshal(map[string]interface{}{"name": "Mat", "tags": []string{"a"}}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat","tags":["a"]}`, string(bytes))
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	data := `
+	// the service configuration
+	{
+		name: 'codecs', /* unquoted key, single quotes */
+		$port: 0x1F90,
+		ratio: .5,
+		offset: +10,
+		scale: 2.,
+		"quoted": "it's \"fine\"",
+		multi: 'one \
+two',
+		# an HJSON style comment
+		list: [1, 2, 3,],
+		nested: {enabled: true, nothing: null,},
+	}
+	`
+
+	var obj map[string]interface{}
+	if assert.NoError(t, json5Codec.Unmarshal([]byte(data), &obj)) {
+		assert.Equal(t, map[string]interface{}{
+			"name":   "codecs",
+			"$port":  float64(8080),
+			"ratio":  0.5,
+			"offset": float64(10),
+			"scale":  float64(2),
+			"quoted": `it's "fine"`,
+			"multi":  "one two",
+			"list":   []interface{}{float64(1), float64(2), float64(3)},
+			"nested": map[string]interface{}{"enabled": true, "nothing": nil},
+		}, obj)
+	}
+
+}
+
+func TestUnmarshal_StrictJSON(t *testing.T) {
+
+	var obj struct {
+		Name  string   `json:"name"`
+		Path  string   `json:"path"`
+		Items []string `json:"items"`
+	}
+
+	if assert.NoError(t, json5Codec.Unmarshal([]byte(`{"name":"Mat","path":"a\/b é 😀","items":["x"]}`), &obj)) {
+		assert.Equal(t, "Mat", obj.Name)
+		assert.Equal(t, "a/b é 😀", obj.Path)
+		assert.Equal(t, []string{"x"}, obj.Items)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var obj interface{}
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: Infinity}`), &obj))
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: NaN}`), &obj))
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: 'open}`), &obj))
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: 1 /* open}`), &obj))
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: bare}`), &obj))
+	assert.Error(t, json5Codec.Unmarshal([]byte(`{a: 1,, }`), &obj))
+
+}
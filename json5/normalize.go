@@ -0,0 +1,270 @@
+package json5
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizer rewrites JSON5 input as strict JSON.
+type normalizer struct {
+	data   []byte
+	pos    int
+	output bytes.Buffer
+}
+
+// normalize rewrites the JSON5 data as strict JSON.
+func normalize(data []byte) ([]byte, error) {
+
+	n := &normalizer{data: data}
+
+	for n.pos < len(n.data) {
+
+		c := n.data[n.pos]
+		switch {
+		case c == '/' || c == '#':
+			if err := n.comment(); err != nil {
+				return nil, err
+			}
+		case c == '"' || c == '\'':
+			if err := n.str(); err != nil {
+				return nil, err
+			}
+		case c == ',':
+			n.pos++
+			// trailing commas are dropped
+			if next := n.peek(); next != '}' && next != ']' {
+				n.output.WriteByte(',')
+			}
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			if err := n.number(); err != nil {
+				return nil, err
+			}
+		case c == '_' || c == '$' || c == '\\' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)):
+			if err := n.identifier(); err != nil {
+				return nil, err
+			}
+		default:
+			n.output.WriteByte(c)
+			n.pos++
+		}
+
+	}
+
+	return n.output.Bytes(), nil
+}
+
+// peek gets the next significant byte without consuming anything, or 0 at
+// the end of the data.
+func (n *normalizer) peek() byte {
+
+	for i := n.pos; i < len(n.data); {
+		switch c := n.data[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || (c == '/' && i+1 < len(n.data) && n.data[i+1] == '/'):
+			for i < len(n.data) && n.data[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(n.data) && n.data[i+1] == '*':
+			end := bytes.Index(n.data[i+2:], []byte("*/"))
+			if end < 0 {
+				return 0
+			}
+			i += end + 4
+		default:
+			return c
+		}
+	}
+
+	return 0
+}
+
+func (n *normalizer) comment() error {
+
+	switch {
+	case n.data[n.pos] == '#' || bytes.HasPrefix(n.data[n.pos:], []byte("//")):
+		for n.pos < len(n.data) && n.data[n.pos] != '\n' {
+			n.pos++
+		}
+	case bytes.HasPrefix(n.data[n.pos:], []byte("/*")):
+		end := bytes.Index(n.data[n.pos+2:], []byte("*/"))
+		if end < 0 {
+			return fmt.Errorf("codecs: json5: Unterminated comment at offset %d", n.pos)
+		}
+		n.pos += end + 4
+	default:
+		return fmt.Errorf("codecs: json5: Unexpected '/' at offset %d", n.pos)
+	}
+
+	// keep tokens either side of the comment apart
+	n.output.WriteByte(' ')
+
+	return nil
+}
+
+func (n *normalizer) str() error {
+
+	start := n.pos
+	quote := n.data[n.pos]
+	n.pos++
+
+	var s strings.Builder
+	for n.pos < len(n.data) {
+
+		c := n.data[n.pos]
+		switch {
+		case c == quote:
+			n.pos++
+			return n.writeString(s.String())
+		case c == '\n':
+			return fmt.Errorf("codecs: json5: Unescaped line break in string at offset %d", n.pos)
+		case c == '\\':
+			if n.pos+1 >= len(n.data) {
+				return fmt.Errorf("codecs: json5: Unterminated string at offset %d", start)
+			}
+			n.pos += 2
+			switch escaped := n.data[n.pos-1]; escaped {
+			case 'b':
+				s.WriteByte('\b')
+			case 'f':
+				s.WriteByte('\f')
+			case 'n':
+				s.WriteByte('\n')
+			case 'r':
+				s.WriteByte('\r')
+			case 't':
+				s.WriteByte('\t')
+			case 'v':
+				s.WriteByte('\v')
+			case '0':
+				s.WriteByte(0)
+			case '\n':
+				// escaped line breaks continue the string
+			case '\r':
+				if n.pos < len(n.data) && n.data[n.pos] == '\n' {
+					n.pos++
+				}
+			case 'x', 'u':
+				size := 2
+				if escaped == 'u' {
+					size = 4
+				}
+				if n.pos+size > len(n.data) {
+					return fmt.Errorf("codecs: json5: Invalid escape at offset %d", n.pos-2)
+				}
+				r, err := strconv.ParseUint(string(n.data[n.pos:n.pos+size]), 16, 32)
+				if err != nil {
+					return fmt.Errorf("codecs: json5: Invalid escape at offset %d", n.pos-2)
+				}
+				n.pos += size
+				// surrogate pairs
+				if r >= 0xD800 && r < 0xDC00 && bytes.HasPrefix(n.data[n.pos:], []byte(`\u`)) && n.pos+6 <= len(n.data) {
+					if low, err := strconv.ParseUint(string(n.data[n.pos+2:n.pos+6]), 16, 32); err == nil && low >= 0xDC00 && low < 0xE000 {
+						r = (r-0xD800)<<10 + (low - 0xDC00) + 0x10000
+						n.pos += 6
+					}
+				}
+				s.WriteRune(rune(r))
+			default:
+				s.WriteByte(escaped)
+			}
+		default:
+			s.WriteByte(c)
+			n.pos++
+		}
+	}
+
+	return fmt.Errorf("codecs: json5: Unterminated string at offset %d", start)
+}
+
+func (n *normalizer) writeString(s string) error {
+
+	quoted, err := jsonEncoding.Marshal(s)
+
+	if err != nil {
+		return err
+	}
+
+	n.output.Write(quoted)
+
+	return nil
+}
+
+func (n *normalizer) number() error {
+
+	start := n.pos
+	for n.pos < len(n.data) && (isWordByte(n.data[n.pos]) || strings.IndexByte(".+-", n.data[n.pos]) >= 0) {
+		n.pos++
+	}
+	token := string(n.data[start:n.pos])
+
+	sign := ""
+	raw := token
+	if strings.HasPrefix(raw, "-") {
+		sign = "-"
+		raw = raw[1:]
+	} else if strings.HasPrefix(raw, "+") {
+		raw = raw[1:]
+	}
+
+	switch {
+	case raw == "Infinity" || raw == "NaN":
+		return fmt.Errorf("codecs: json5: %s cannot be represented in JSON", token)
+	case strings.HasPrefix(raw, "0x") || strings.HasPrefix(raw, "0X"):
+		i, err := strconv.ParseUint(raw[2:], 16, 64)
+		if err != nil {
+			return fmt.Errorf("codecs: json5: Invalid number %q at offset %d", token, start)
+		}
+		n.output.WriteString(sign + strconv.FormatUint(i, 10))
+		return nil
+	}
+
+	if strings.HasPrefix(raw, ".") {
+		raw = "0" + raw
+	}
+	if point := strings.Index(raw, "."); point >= 0 && (point == len(raw)-1 || raw[point+1] < '0' || raw[point+1] > '9') {
+		raw = raw[:point+1] + "0" + raw[point+1:]
+	}
+
+	if _, err := strconv.ParseFloat(raw, 64); err != nil {
+		return fmt.Errorf("codecs: json5: Invalid number %q at offset %d", token, start)
+	}
+
+	n.output.WriteString(sign + raw)
+
+	return nil
+}
+
+func (n *normalizer) identifier() error {
+
+	start := n.pos
+	for n.pos < len(n.data) && isWordByte(n.data[n.pos]) {
+		n.pos++
+	}
+	word := string(n.data[start:n.pos])
+
+	if n.peek() == ':' {
+		return n.writeString(word)
+	}
+
+	switch word {
+	case "true", "false", "null":
+		n.output.WriteString(word)
+	case "Infinity", "NaN":
+		return fmt.Errorf("codecs: json5: %s cannot be represented in JSON", word)
+	default:
+		return fmt.Errorf("codecs: json5: Unexpected %q at offset %d", word, start)
+	}
+
+	return nil
+}
+
+// isWordByte gets whether the byte can be part of an identifier or number.
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= utf8.RuneSelf || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,238 @@
+package codecs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonFields gets the exported fields of the struct type as encoding/json
+// sees them, in their declared order: the fields of embedded structs without
+// json tags are promoted in their place, and of fields with the same name, the
+// shallowest is kept (or, at the same depth, the only one with a json tag
+// name), while the others are left out.
+func jsonFields(t reflect.Type) []fieldInfo {
+
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var candidates []jsonCandidate
+	visited := map[reflect.Type]int{}
+	current := []embedded{{t: t}}
+
+	for depth := 0; len(current) > 0; depth++ {
+
+		var next []embedded
+		for _, e := range current {
+
+			// embedded structs seen nearer the top are already promoted
+			if visitedDepth, ok := visited[e.t]; ok && visitedDepth < depth {
+				continue
+			}
+			visited[e.t] = depth
+
+			for i := 0; i < e.t.NumField(); i++ {
+
+				field := e.t.Field(i)
+				field.Index = append(append([]int(nil), e.index...), i)
+
+				// fields tagged "-" are kept, without names, for codec
+				// tags to name
+				tag := field.Tag.Get("json")
+				if comma := strings.Index(tag, ","); comma >= 0 {
+					tag = tag[:comma]
+				}
+
+				if field.Anonymous && len(tag) == 0 {
+					fieldType := field.Type
+					if fieldType.Kind() == reflect.Ptr {
+						fieldType = fieldType.Elem()
+					}
+					if fieldType.Kind() == reflect.Struct {
+						next = append(next, embedded{t: fieldType, index: field.Index})
+						continue
+					}
+				}
+				if len(field.PkgPath) > 0 {
+					continue
+				}
+
+				info := fieldInfo{StructField: field, name: publicFieldName(field)}
+				info.codecName, info.omitEmpty = codecFieldName(field)
+				info.jsonOmitEmpty, info.jsonString = jsonOptions(field)
+				candidates = append(candidates, jsonCandidate{fieldInfo: info, depth: depth, tagged: len(info.name) > 0 && len(tag) > 0})
+			}
+		}
+		current = next
+	}
+
+	// keep the dominant field of each name
+	var fields []fieldInfo
+	var names []string
+	byName := map[string][]jsonCandidate{}
+	for _, c := range candidates {
+		if len(c.name) == 0 {
+			fields = append(fields, c.fieldInfo)
+			continue
+		}
+		if _, ok := byName[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+
+	for _, name := range names {
+		if field, ok := dominantField(byName[name]); ok {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i].Index, fields[j].Index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return fields
+}
+
+// jsonCandidate is a field that may be one of a struct's fields as
+// encoding/json sees them, found at the depth of embedding.
+type jsonCandidate struct {
+	fieldInfo
+	depth  int
+	tagged bool
+}
+
+// dominantField gets the field of the candidates with the same name that
+// encoding/json keeps, or false if it leaves them all out.
+func dominantField(candidates []jsonCandidate) (fieldInfo, bool) {
+
+	shallowest := candidates[0].depth
+	for _, c := range candidates {
+		if c.depth < shallowest {
+			shallowest = c.depth
+		}
+	}
+
+	var found, tagged []jsonCandidate
+	for _, c := range candidates {
+		if c.depth == shallowest {
+			found = append(found, c)
+			if c.tagged {
+				tagged = append(tagged, c)
+			}
+		}
+	}
+
+	switch {
+	case len(found) == 1:
+		return found[0].fieldInfo, true
+	case len(tagged) == 1:
+		return tagged[0].fieldInfo, true
+	}
+	return fieldInfo{}, false
+}
+
+// jsonOptions gets whether the json tag of the field has the omitempty and
+// string options.  The string option only applies to fields of strings,
+// numbers and bools (or pointers to them).
+func jsonOptions(field reflect.StructField) (omitEmpty, asString bool) {
+
+	tag := field.Tag.Get("json")
+	comma := strings.Index(tag, ",")
+	if comma < 0 {
+		return false, false
+	}
+
+	for _, option := range strings.Split(tag[comma+1:], ",") {
+		switch option {
+		case "omitempty":
+			omitEmpty = true
+		case "string":
+			asString = true
+		}
+	}
+
+	if asString {
+		fieldType := field.Type
+		if fieldType.Name() == "" && fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch fieldType.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+		default:
+			asString = false
+		}
+	}
+
+	return omitEmpty, asString
+}
+
+// fieldValue gets the value of the field in the struct, or false if an
+// embedded struct pointer on the way to it is nil.
+func (f *fieldInfo) fieldValue(structValue reflect.Value) (reflect.Value, bool) {
+
+	value := structValue
+	for i, index := range f.Index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(index)
+	}
+
+	return value, true
+}
+
+// jsonValue gets the value of the field in the struct as encoding/json writes
+// it, as a string for fields tagged with the string option, or false if
+// encoding/json leaves it out, as it does empty fields tagged omitempty.
+func (f *fieldInfo) jsonValue(structValue reflect.Value) (reflect.Value, bool) {
+
+	value, ok := f.fieldValue(structValue)
+	if !ok || (f.jsonOmitEmpty && isEmptyJSON(value)) {
+		return reflect.Value{}, false
+	}
+
+	if f.jsonString && !(value.Kind() == reflect.Ptr && value.IsNil()) {
+		data, err := json.Marshal(value.Interface())
+		if err == nil {
+			return reflect.ValueOf(string(data)), true
+		}
+	}
+
+	return value, true
+}
+
+// isEmptyJSON gets whether the value is empty as encoding/json's omitempty
+// option sees it.
+func isEmptyJSON(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
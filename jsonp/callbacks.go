@@ -0,0 +1,91 @@
+package jsonp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	callbacksLock sync.RWMutex
+	callbacks     = map[string]bool{}
+)
+
+// InvalidCallbackError is the error Marshal returns for callbacks it won't
+// call, since they aren't JavaScript identifiers (such as "jQuery123" or
+// "app.handle") or aren't registered (see RegisterCallback).
+type InvalidCallbackError struct {
+	// Callback is the callback as it was given.
+	Callback string
+}
+
+func (e *InvalidCallbackError) Error() string {
+	return fmt.Sprintf("codecs: jsonp: Invalid callback %q", e.Callback)
+}
+
+// StatusCode gets 400 Bad Request, the status to respond to requests for
+// invalid callbacks with.
+func (e *InvalidCallbackError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// RegisterCallback allows the callback.  Once any callbacks are registered,
+// Marshal calls only those.
+func RegisterCallback(name string) {
+
+	callbacksLock.Lock()
+	defer callbacksLock.Unlock()
+
+	callbacks[name] = true
+}
+
+// UnregisterCallback removes the callback registered with RegisterCallback.
+func UnregisterCallback(name string) {
+
+	callbacksLock.Lock()
+	defer callbacksLock.Unlock()
+
+	delete(callbacks, name)
+}
+
+// checkCallback gets an *InvalidCallbackError if the callback isn't one
+// Marshal will call.
+func checkCallback(name string) error {
+
+	if !isIdentifierPath(name) {
+		return &InvalidCallbackError{Callback: name}
+	}
+
+	callbacksLock.RLock()
+	defer callbacksLock.RUnlock()
+
+	if len(callbacks) > 0 && !callbacks[name] {
+		return &InvalidCallbackError{Callback: name}
+	}
+
+	return nil
+}
+
+// isIdentifierPath gets whether the name is JavaScript identifiers (of
+// letters, digits, '_' and '$', not starting with a digit) separated by dots.
+func isIdentifierPath(name string) bool {
+
+	for _, identifier := range strings.Split(name, ".") {
+
+		if identifier == "" {
+			return false
+		}
+
+		for i, r := range identifier {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == '$':
+			case r >= '0' && r <= '9' && i > 0:
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
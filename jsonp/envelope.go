@@ -0,0 +1,44 @@
+package jsonp
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"net/http"
+)
+
+// envelope wraps objects with the status and headers of the response, since
+// JSONP is loaded by script tags, which can't see them.
+type envelope struct {
+	Status  int         `json:"status"`
+	Headers interface{} `json:"headers"`
+	Data    interface{} `json:"data"`
+}
+
+// enveloped gets whether the object is to be wrapped in an envelope.
+func (c *JsonPCodec) enveloped(options map[string]interface{}) bool {
+	if enveloped, ok := options[constants.OptionKeyEnvelope].(bool); ok {
+		return enveloped
+	}
+	return c.Envelope
+}
+
+// newEnvelope wraps the object with the status and headers given by the
+// options, which are 200 OK and none unless given.
+func newEnvelope(object interface{}, options map[string]interface{}) *envelope {
+
+	status, ok := options[constants.OptionKeyStatus].(int)
+	if !ok {
+		status = http.StatusOK
+	}
+
+	headers := options[constants.OptionKeyHeaders]
+	switch value := headers.(type) {
+	case nil:
+		headers = map[string]string{}
+	case http.Header:
+		if value == nil {
+			headers = map[string]string{}
+		}
+	}
+
+	return &envelope{Status: status, Headers: headers, Data: object}
+}
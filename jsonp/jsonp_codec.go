@@ -1,10 +1,12 @@
 package jsonp
 
 import (
+	"bytes"
 	jsonEncoding "encoding/json"
 	"errors"
+	"fmt"
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
-	stewstrings "github.com/stretchr/stew/strings"
 )
 
 // ErrorMissingCallback is the error for when a callback option is expected but missing.
@@ -14,13 +16,52 @@ var ErrorMissingCallback = errors.New("A callback is required for JSONP")
 var ErrorUnmarshalNotSupported = errors.New("Unmarshalling an object is not supported for JSONP")
 
 // JsonPCodec converts objects to JSONP.
-type JsonPCodec struct{}
+type JsonPCodec struct {
+
+	// CallbackOption is the key of the option giving the callback, or
+	// constants.OptionKeyClientCallback when empty.
+	CallbackOption string
+
+	// DefaultCallback is the callback called when the options don't give
+	// one.  When empty, Marshal returns ErrorMissingCallback instead.
+	DefaultCallback string
+
+	// ApplicationJavaScript serves JSONP as application/javascript rather
+	// than text/javascript, which it still answers to (as an alias).
+	ApplicationJavaScript bool
+
+	// Envelope wraps objects as {"status": 404, "headers": {...}, "data":
+	// ...} so that clients can see the status and headers of responses, which
+	// are given by the constants.OptionKeyStatus and
+	// constants.OptionKeyHeaders options (an http.Header or a
+	// map[string]string), since JSONP must be served as 200 OK.  The
+	// constants.OptionKeyEnvelope option overrides it.
+	Envelope bool
+}
+
+func init() {
+	codecs.Register(new(JsonPCodec))
+}
 
-// Marshal converts an object to JSONP.
+// Marshal converts an object to JSONP, such as /**/callback({"name":"Mat"});,
+// returning an *InvalidCallbackError for callbacks that aren't safe to call.
 func (c *JsonPCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 
-	if len(options) == 0 {
-		return nil, ErrorMissingCallback
+	// #codec-context-options
+	// the assumption is options[0] is the callback parameter,
+	// and options[1] is the client-context (NB: not *Context) string.
+
+	callbackFunctionName, err := c.callback(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCallback(callbackFunctionName); err != nil {
+		return nil, err
+	}
+
+	if c.enveloped(options) {
+		object = newEnvelope(object, options)
 	}
 
 	json, err := jsonEncoding.Marshal(object)
@@ -29,28 +70,67 @@ func (c *JsonPCodec) Marshal(object interface{}, options map[string]interface{})
 		return nil, err
 	}
 
-	// #codec-context-options
-	// the assumption is options[0] is the callback parameter,
-	// and options[1] is the client-context (NB: not *Context) string.
+	var clientContext []byte
+	if clientContextString, hasClientContext := options[constants.OptionKeyClientContext].(string); hasClientContext {
+		if clientContext, err = jsonEncoding.Marshal(clientContextString); err != nil {
+			return nil, err
+		}
+	}
+
+	return frame(callbackFunctionName, json, clientContext), nil
+}
+
+// callback gets the callback given by the options, or the default callback,
+// or an *InvalidCallbackError if the option isn't a string.
+func (c *JsonPCodec) callback(options map[string]interface{}) (string, error) {
 
-	var callbackFunctionName string
-	var callbackString string
-	var clientContextString string
-	var ok bool
+	key := c.CallbackOption
+	if key == "" {
+		key = constants.OptionKeyClientCallback
+	}
+
+	callback, ok := options[key]
+	if !ok {
+		if c.DefaultCallback == "" {
+			return "", ErrorMissingCallback
+		}
+		return c.DefaultCallback, nil
+	}
 
-	if callbackFunctionName, ok = options[constants.OptionKeyClientCallback].(string); !ok {
-		panic("stretchrcom/codecs: JSONP requires the options to contain the constants.OptionKeyClientCallback value.")
+	name, ok := callback.(string)
+	if !ok {
+		return "", &InvalidCallbackError{Callback: fmt.Sprint(callback)}
 	}
 
-	clientContextString, hasClientContext := options[constants.OptionKeyClientContext].(string)
+	return name, nil
+}
 
-	if !hasClientContext {
-		callbackString = stewstrings.MergeStrings(callbackFunctionName, "(", string(json), ");")
-	} else {
-		callbackString = stewstrings.MergeStrings(options[constants.OptionKeyClientCallback].(string), "(", string(json), `,"`, clientContextString, `"`, ");")
+// frame gets the JSONP calling the callback with the JSON (and the client
+// context, if there is one), framed as OWASP recommends: the empty comment
+// keeps the response from starting with bytes chosen by the client (as
+// Rosetta Flash attacks need), and the line separators, which end JavaScript
+// strings in older browsers, are escaped.
+func frame(callback string, json, clientContext []byte) []byte {
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString("/**/")
+	buffer.WriteString(callback)
+	buffer.WriteByte('(')
+	buffer.Write(escapeLineSeparators(json))
+	if clientContext != nil {
+		buffer.WriteByte(',')
+		buffer.Write(escapeLineSeparators(clientContext))
 	}
+	buffer.WriteString(");")
 
-	return []byte(callbackString), nil
+	return buffer.Bytes()
+}
+
+// escapeLineSeparators escapes U+2028 and U+2029 in the JSON.
+func escapeLineSeparators(json []byte) []byte {
+	json = bytes.Replace(json, []byte("\u2028"), []byte(`\u2028`), -1)
+	return bytes.Replace(json, []byte("\u2029"), []byte(`\u2029`), -1)
 }
 
 // Unmarshal is not supported for JSONP. Returns an error.
@@ -60,9 +140,20 @@ func (c *JsonPCodec) Unmarshal(data []byte, obj interface{}) error {
 
 // ContentType returns the content type for this codec.
 func (c *JsonPCodec) ContentType() string {
+	if c.ApplicationJavaScript {
+		return constants.ContentTypeJSONPAlias
+	}
 	return constants.ContentTypeJSONP
 }
 
+// Aliases returns the other content types this codec handles.
+func (c *JsonPCodec) Aliases() []string {
+	if c.ApplicationJavaScript {
+		return []string{constants.ContentTypeJSONP}
+	}
+	return []string{constants.ContentTypeJSONPAlias}
+}
+
 // FileExtension returns the file extension for this codec.
 func (c *JsonPCodec) FileExtension() string {
 	return constants.FileExtensionJSONP
@@ -72,3 +163,8 @@ func (c *JsonPCodec) FileExtension() string {
 func (c *JsonPCodec) CanMarshalWithCallback() bool {
 	return true
 }
+
+// CanUnmarshal returns whether this codec is capable of unmarshalling.
+func (c *JsonPCodec) CanUnmarshal() bool {
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
 )
 
@@ -47,7 +48,7 @@ func TestMarshal(t *testing.T) {
 		t.Errorf("Shouldn't return error: %s", jsonPError)
 	}
 
-	assert.Equal(t, string(jsonPString), `candyCorn({"name":"Mat"});`)
+	assert.Equal(t, string(jsonPString), `/**/candyCorn({"name":"Mat"});`)
 
 }
 
@@ -64,7 +65,20 @@ func TestMarshal_WithContext(t *testing.T) {
 		t.Errorf("Shouldn't return error: %s", jsonPError)
 	}
 
-	assert.Equal(t, string(jsonPString), `candyCorn({"name":"Mat"},"halloween");`)
+	assert.Equal(t, string(jsonPString), `/**/candyCorn({"name":"Mat"},"halloween");`)
+
+}
+
+func TestMarshal_Escaping(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	obj := map[string]interface{}{"text": "one\u2028two\u2029three"}
+
+	jsonP, err := codec.Marshal(obj, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyClientContext: "\");alert(1);//\u2028"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn({"text":"one\u2028two\u2029three"},"\");alert(1);//\u2028");`, string(jsonP))
+	}
 
 }
 
@@ -80,6 +94,67 @@ func TestMarshal_WithoutCallback(t *testing.T) {
 	assert.Equal(t, jsonPError, ErrorMissingCallback)
 }
 
+func TestMarshal_Configured(t *testing.T) {
+
+	codec := &JsonPCodec{CallbackOption: "jsonp", DefaultCallback: "handle"}
+
+	jsonP, err := codec.Marshal(1, map[string]interface{}{"jsonp": "candyCorn", constants.OptionKeyClientCallback: "ignored"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn(1);`, string(jsonP))
+	}
+
+	jsonP, err = codec.Marshal(1, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/handle(1);`, string(jsonP))
+	}
+
+	_, err = new(JsonPCodec).Marshal(1, map[string]interface{}{"not-relevant": true})
+	assert.Equal(t, ErrorMissingCallback, err)
+
+}
+
+func TestContentType_ApplicationJavaScript(t *testing.T) {
+
+	codec := new(JsonPCodec)
+	assert.Equal(t, []string{constants.ContentTypeJSONPAlias}, codec.Aliases())
+
+	codec.ApplicationJavaScript = true
+	assert.Equal(t, constants.ContentTypeJSONPAlias, codec.ContentType())
+	assert.Equal(t, []string{constants.ContentTypeJSONP}, codec.Aliases())
+
+}
+
+func TestMarshal_Envelope(t *testing.T) {
+
+	codec := &JsonPCodec{Envelope: true}
+
+	jsonP, err := codec.Marshal(map[string]string{"error": "Not found"}, map[string]interface{}{
+		constants.OptionKeyClientCallback: "candyCorn",
+		constants.OptionKeyStatus:         404,
+		constants.OptionKeyHeaders:        http.Header{"Retry-After": {"120"}},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn({"status":404,"headers":{"Retry-After":["120"]},"data":{"error":"Not found"}});`, string(jsonP))
+	}
+
+	jsonP, err = codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn({"status":200,"headers":{},"data":1});`, string(jsonP))
+	}
+
+	// the option overrides the codec
+	jsonP, err = codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyEnvelope: false})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn(1);`, string(jsonP))
+	}
+
+	jsonP, err = new(JsonPCodec).Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyEnvelope: true, constants.OptionKeyHeaders: map[string]string{"ETag": `"1"`}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `/**/candyCorn({"status":200,"headers":{"ETag":"\"1\""},"data":1});`, string(jsonP))
+	}
+
+}
+
 func TestUnmarshal(t *testing.T) {
 
 	codec := new(JsonPCodec)
@@ -91,3 +166,43 @@ func TestUnmarshal(t *testing.T) {
 
 	assert.Equal(t, jsonPError, ErrorUnmarshalNotSupported)
 }
+
+func TestMarshal_InvalidCallback(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	for _, callback := range []string{"", "alert(1);foo", "1abc", "app..handle", "a b", "<script>"} {
+		_, err := codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: callback})
+		if assert.IsType(t, &InvalidCallbackError{}, err, callback) {
+			assert.Equal(t, callback, err.(*InvalidCallbackError).Callback)
+			assert.Equal(t, 400, err.(*InvalidCallbackError).StatusCode())
+		}
+	}
+
+	for _, callback := range []string{"candyCorn", "jQuery_123", "$", "app.handlers.done"} {
+		_, err := codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: callback})
+		assert.NoError(t, err, callback)
+	}
+
+	// callbacks that aren't strings are refused rather than panicking
+	_, err := codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: 123})
+	if assert.IsType(t, &InvalidCallbackError{}, err) {
+		assert.Equal(t, "123", err.(*InvalidCallbackError).Callback)
+	}
+
+}
+
+func TestMarshal_RegisteredCallbacks(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	RegisterCallback("candyCorn")
+	defer UnregisterCallback("candyCorn")
+
+	_, err := codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn"})
+	assert.NoError(t, err)
+
+	_, err = codec.Marshal(1, map[string]interface{}{constants.OptionKeyClientCallback: "gummyBear"})
+	assert.IsType(t, &InvalidCallbackError{}, err)
+
+}
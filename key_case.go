@@ -0,0 +1,143 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// KeyCase is a style of map keys, which public data can be given in so that
+// Go styled field names can serve clients expecting another style.  Give it
+// with the constants.OptionKeyKeyCase option.
+type KeyCase int
+
+const (
+	// KeyCaseAsIs leaves keys as they are.  It is the default.
+	KeyCaseAsIs KeyCase = iota
+
+	// CamelCase keys look like userName.
+	CamelCase
+
+	// SnakeCase keys look like user_name.
+	SnakeCase
+
+	// KebabCase keys look like user-name.
+	KebabCase
+
+	// PascalCase keys look like UserName, as exported Go fields do.
+	PascalCase
+)
+
+// keyCase gets the key case given by the constants.OptionKeyKeyCase option.
+func keyCase(options map[string]interface{}) KeyCase {
+	keyCase, _ := options[constants.OptionKeyKeyCase].(KeyCase)
+	return keyCase
+}
+
+// Convert converts the key into the case.  Words in the key are told apart by
+// changes of case (keeping acronyms such as ID together) and by underscores,
+// hyphens and spaces.
+func (c KeyCase) Convert(key string) string {
+
+	if c == KeyCaseAsIs {
+		return key
+	}
+
+	words := keyWords(key)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if c == PascalCase || (c == CamelCase && i > 0) {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+
+	switch c {
+	case SnakeCase:
+		return strings.Join(words, "_")
+	case KebabCase:
+		return strings.Join(words, "-")
+	}
+
+	return strings.Join(words, "")
+}
+
+// keyWords splits the key into its words.
+func keyWords(key string) []string {
+
+	var words []string
+	var word []rune
+	runes := []rune(key)
+
+	for i, r := range runes {
+
+		if r == '_' || r == '-' || r == ' ' {
+			if len(word) > 0 {
+				words, word = append(words, string(word)), nil
+			}
+			continue
+		}
+
+		// a new word starts at an upper case letter following a lower case
+		// letter or digit, or at the last letter of an acronym followed by a
+		// lower case letter (as the S of HTTPServer)
+		if unicode.IsUpper(r) && len(word) > 0 {
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words, word = append(words, string(word)), nil
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return words
+}
+
+// stringMapType is the type of map[string]interface{}.
+var stringMapType = reflect.TypeOf(map[string]interface{}(nil))
+
+// ConvertKeys copies the data (as unmarshalled into an interface{}) with the
+// string keys of its maps, including those nested in maps and slices,
+// converted into the case.
+func ConvertKeys(data interface{}, keyCase KeyCase) interface{} {
+
+	switch data := data.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			converted[keyCase.Convert(key)] = ConvertKeys(value, keyCase)
+		}
+		return converted
+	case map[interface{}]interface{}:
+		converted := make(map[interface{}]interface{}, len(data))
+		for key, value := range data {
+			if keyString, ok := key.(string); ok {
+				key = keyCase.Convert(keyString)
+			}
+			converted[key] = ConvertKeys(value, keyCase)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(data))
+		for i, item := range data {
+			converted[i] = ConvertKeys(item, keyCase)
+		}
+		return converted
+	}
+
+	// other map types, such as objects.Map
+	if value := reflect.ValueOf(data); value.IsValid() && value.Type().ConvertibleTo(stringMapType) {
+		return ConvertKeys(value.Convert(stringMapType).Interface(), keyCase)
+	}
+
+	return data
+}
@@ -0,0 +1,57 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/stew/objects"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKeyCase_Convert(t *testing.T) {
+
+	for key, expected := range map[string][4]string{
+		"UserName":   {"userName", "user_name", "user-name", "UserName"},
+		"user_name":  {"userName", "user_name", "user-name", "UserName"},
+		"user-name":  {"userName", "user_name", "user-name", "UserName"},
+		"UserID":     {"userId", "user_id", "user-id", "UserId"},
+		"HTTPServer": {"httpServer", "http_server", "http-server", "HttpServer"},
+		"address2":   {"address2", "address2", "address2", "Address2"},
+	} {
+		assert.Equal(t, expected[0], CamelCase.Convert(key), key)
+		assert.Equal(t, expected[1], SnakeCase.Convert(key), key)
+		assert.Equal(t, expected[2], KebabCase.Convert(key), key)
+		assert.Equal(t, expected[3], PascalCase.Convert(key), key)
+		assert.Equal(t, key, KeyCaseAsIs.Convert(key), key)
+	}
+
+}
+
+// customer has Go styled field names.
+type customer struct {
+	FirstName string
+	HomeURL   string
+	Orders    []map[string]interface{}
+}
+
+func TestPublicData_KeyCase(t *testing.T) {
+
+	object := &customer{FirstName: "Mat", HomeURL: "http://example.com", Orders: []map[string]interface{}{{"OrderID": 1}}}
+
+	public, err := PublicData(object, Options{KeyCase: SnakeCase}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"first_name": "Mat",
+			"home_url":   "http://example.com",
+			"orders":     []interface{}{map[string]interface{}{"order_id": 1}},
+		}, public)
+	}
+
+	public, err = PublicData(objects.Map{"FirstName": "Mat"}, map[string]interface{}{constants.OptionKeyKeyCase: CamelCase})
+	if assert.NoError(t, err) {
+		assert.Equal(t, objects.Map{"firstName": "Mat"}, public)
+	}
+
+	assert.Equal(t, map[string]interface{}{"FirstName": "Mat", "Orders": []interface{}{map[string]interface{}{"OrderId": 1}}},
+		ConvertKeys(objects.Map{"first_name": "Mat", "orders": []interface{}{map[string]interface{}{"order_id": 1}}}, PascalCase))
+
+}
@@ -0,0 +1,126 @@
+package markdown
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// writeCodeBlock writes the object as a fenced code block; strings as they
+// are, and anything else as indented JSON.
+func writeCodeBlock(w io.Writer, object interface{}) error {
+
+	var info, content string
+	if v := indirect(reflect.ValueOf(object)); v.IsValid() && v.Kind() == reflect.String {
+		content = v.String()
+	} else {
+		b, err := json.MarshalIndent(object, "", "  ")
+		if err != nil {
+			return err
+		}
+		info, content = "json", string(b)
+	}
+
+	// the fence has to be longer than any run of backticks in the content
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", 3)
+	if longest >= 3 {
+		fence = strings.Repeat("`", longest+1)
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	_, err := io.WriteString(w, fence+info+"\n"+content+fence+"\n")
+
+	return err
+}
+
+// openingFence gets the fence and info string if the line opens a code
+// block.
+func openingFence(line string) (string, string, bool) {
+
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return "", "", false
+	}
+
+	marker := trimmed[0]
+	if marker != '`' && marker != '~' {
+		return "", "", false
+	}
+
+	n := 0
+	for n < len(trimmed) && trimmed[n] == marker {
+		n++
+	}
+
+	info := strings.TrimSpace(trimmed[n:])
+	if n < 3 || (marker == '`' && strings.Contains(info, "`")) {
+		return "", "", false
+	}
+
+	// only the first word of the info string is the language
+	if fields := strings.Fields(info); len(fields) > 0 {
+		info = fields[0]
+	}
+
+	return trimmed[:n], info, true
+}
+
+// readCodeBlock reads the contents of the code block starting at the
+// opening fence, decoding it if it is marked as json.
+func readCodeBlock(lines []string, fence, info string) (interface{}, error) {
+
+	var content []string
+	for _, line := range lines[1:] {
+		if closing := strings.TrimSpace(line); strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+			break
+		}
+		content = append(content, line)
+	}
+
+	text := strings.Join(content, "\n")
+
+	if info != "json" {
+		return text, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// unmarshal reads the first table or code block in the Markdown.
+func unmarshal(data string) (interface{}, error) {
+
+	lines := strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n")
+
+	for i, line := range lines {
+
+		if fence, info, ok := openingFence(line); ok {
+			return readCodeBlock(lines[i:], fence, info)
+		}
+
+		if strings.Contains(line, "|") && i+1 < len(lines) && isDelimiterRow(lines[i+1], len(splitRow(line))) {
+			return readTable(lines[i:]), nil
+		}
+	}
+
+	return nil, ErrorNoContent
+}
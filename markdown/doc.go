@@ -0,0 +1,30 @@
+// A codec for rendering data as GitHub flavored Markdown.
+//
+// Marshal renders slices (or arrays) of structs or maps as a table, with a
+// row for each element:
+//
+//	| id | name  | score |
+//	|---:|-------|------:|
+//	|  1 | Mat   |   9.5 |
+//	|  2 | Tyler |       |
+//
+// Struct columns are named by the `markdown` struct tag, falling back on the
+// `json` tag and then the field name, and are written in field order.  Maps
+// (such as facade public data) use their sorted keys as columns.  Columns
+// holding only numbers are right aligned, nil values are left empty, and
+// values that aren't strings, numbers or bools are written as JSON.
+//
+// Anything else is written as a fenced code block; strings as they are and
+// other values as indented JSON:
+//
+//	```json
+//	{
+//	  "name": "Mat"
+//	}
+//	```
+//
+// Unmarshal reads tables into a []map[string]interface{}, decoding cells that
+// hold JSON (such as numbers and bools) and leaving the rest as strings, and
+// reads code blocks into their contents (decoded if the block is marked as
+// json).  Targets other than an interface{} use encoding/json's rules.
+package markdown
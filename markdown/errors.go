@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorNoContent is returned when unmarshalling Markdown that contains
+// neither a table nor a code block.
+var ErrorNoContent = errors.New("codecs: markdown: Data contains no table or code block")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: markdown: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: markdown: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: markdown: Unmarshal(nil " + e.Type.String() + ")"
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// MarkdownCodec renders objects as Markdown tables and code blocks, and reads
+// them back.
+type MarkdownCodec struct{}
+
+func init() {
+	codecs.Register(new(MarkdownCodec))
+}
+
+// Marshal converts an object to Markdown; a table for slices of structs or
+// maps, and a fenced code block for anything else.
+func (c *MarkdownCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var buffer bytes.Buffer
+
+	if columns, rows, ok := tabulate(object); ok {
+		if err := writeTable(&buffer, columns, rows); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+
+	if err := writeCodeBlock(&buffer, object); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal converts the first table or code block in the Markdown into an
+// object.
+func (c *MarkdownCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	value, err := unmarshal(string(data))
+
+	if err != nil {
+		return err
+	}
+
+	// generic values can be set directly
+	switch target := obj.(type) {
+	case *interface{}:
+		*target = value
+		return nil
+	case *[]map[string]interface{}:
+		if rows, ok := value.([]map[string]interface{}); ok {
+			*target = rows
+			return nil
+		}
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *MarkdownCodec) ContentType() string {
+	return constants.ContentTypeMarkdown
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *MarkdownCodec) FileExtension() string {
+	return constants.FileExtensionMarkdown
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *MarkdownCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,170 @@
+package markdown
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var markdownCodec MarkdownCodec
+
+type result struct {
+	ID     int      `markdown:"id"`
+	Name   string   `json:"name"`
+	Score  *float64 `markdown:"score"`
+	Tags   []string `markdown:"tags"`
+	Secret string   `markdown:"-"`
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(MarkdownCodec), "MarkdownCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeMarkdown, markdownCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionMarkdown, markdownCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, markdownCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal_Structs(t *testing.T) {
+
+	score := 9.5
+	results := []*result{
+		{ID: 1, Name: "Mat", Score: &score, Tags: []string{"a", "b"}, Secret: "shh"},
+		{ID: 20, Name: "Tyler | Bunnell", Tags: nil},
+	}
+
+	bytes, err := markdownCodec.Marshal(results, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, ""+
+			"|  id | name             | score | tags      |\n"+
+			"|----:|------------------|------:|-----------|\n"+
+			"|   1 | Mat              |   9.5 | [\"a\",\"b\"] |\n"+
+			"|  20 | Tyler \\| Bunnell |       |           |\n", string(bytes))
+	}
+
+}
+
+func TestMarshal_Maps(t *testing.T) {
+
+	rows := []interface{}{
+		map[string]interface{}{"name": "Mat", "note": "line one\nline two"},
+		map[string]interface{}{"name": "Tyler", "age": 30},
+	}
+
+	bytes, err := markdownCodec.Marshal(rows, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, ""+
+			"| age | name  | note                 |\n"+
+			"|----:|-------|----------------------|\n"+
+			"|     | Mat   | line one<br>line two |\n"+
+			"|  30 | Tyler |                      |\n", string(bytes))
+	}
+
+}
+
+func TestMarshal_CodeBlocks(t *testing.T) {
+
+	bytes, err := markdownCodec.Marshal("hello", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "```\nhello\n```\n", string(bytes))
+	}
+
+	bytes, err = markdownCodec.Marshal("use ```go fences", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "````\nuse ```go fences\n````\n", string(bytes))
+	}
+
+	bytes, err = markdownCodec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "```json\n{\n  \"name\": \"Mat\"\n}\n```\n", string(bytes))
+	}
+
+	bytes, err = markdownCodec.Marshal(42, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "```json\n42\n```\n", string(bytes))
+	}
+
+}
+
+func TestRoundTrip_Table(t *testing.T) {
+
+	score := 9.5
+	results := []result{
+		{ID: 1, Name: "Mat", Score: &score, Tags: []string{"a"}},
+		{ID: 2, Name: "Tyler | Bunnell", Tags: []string{}},
+	}
+
+	bytes, err := markdownCodec.Marshal(results, nil)
+
+	if assert.NoError(t, err) {
+
+		var back []result
+		if assert.NoError(t, markdownCodec.Unmarshal(bytes, &back)) {
+			assert.Equal(t, results, back)
+		}
+
+		var generic interface{}
+		if assert.NoError(t, markdownCodec.Unmarshal(bytes, &generic)) {
+			rows := generic.([]map[string]interface{})
+			assert.Equal(t, 2, len(rows))
+			assert.Equal(t, float64(1), rows[0]["id"])
+			assert.Equal(t, "Tyler | Bunnell", rows[1]["name"])
+			assert.Nil(t, rows[1]["score"])
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Table(t *testing.T) {
+
+	data := "Some results:\n\n" +
+		"name | done\n" +
+		":--- | :---:\n" +
+		"Write code | true\n" +
+		"Test it\n" +
+		"\n" +
+		"Not part of the table | false\n"
+
+	var rows []map[string]interface{}
+	if assert.NoError(t, markdownCodec.Unmarshal([]byte(data), &rows)) {
+		assert.Equal(t, []map[string]interface{}{
+			{"name": "Write code", "done": true},
+		}, rows)
+	}
+
+}
+
+func TestUnmarshal_CodeBlocks(t *testing.T) {
+
+	var s string
+	if assert.NoError(t, markdownCodec.Unmarshal([]byte("Output:\n\n~~~ text\nline one\nline two\n~~~\n"), &s)) {
+		assert.Equal(t, "line one\nline two", s)
+	}
+
+	var m map[string]interface{}
+	if assert.NoError(t, markdownCodec.Unmarshal([]byte("```json\n{\"name\": \"Mat\"}\n```\n"), &m)) {
+		assert.Equal(t, "Mat", m["name"])
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorNoContent, markdownCodec.Unmarshal([]byte("# Just a heading\n"), &obj))
+
+	_, isInvalid := markdownCodec.Unmarshal(nil, obj).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
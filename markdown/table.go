@@ -0,0 +1,373 @@
+package markdown
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// indirect follows pointers and interfaces, returning an invalid value for
+// nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// fieldName gets the column name for the struct field, or "-" if it should
+// be skipped.
+func fieldName(sf reflect.StructField) string {
+
+	tag := sf.Tag.Get("markdown")
+	if len(tag) == 0 {
+		tag = sf.Tag.Get("json")
+	}
+
+	if name := strings.Split(tag, ",")[0]; len(name) > 0 {
+		return name
+	}
+
+	return sf.Name
+}
+
+// tabulate gets the columns and cell values of the object if it is a slice
+// (or array) of structs or maps with string keys.  Cells are invalid values
+// when they are nil.
+func tabulate(object interface{}) ([]string, [][]reflect.Value, bool) {
+
+	v := indirect(reflect.ValueOf(object))
+
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil, nil, false
+	}
+
+	elementType := v.Type().Elem()
+	for elementType.Kind() == reflect.Ptr {
+		elementType = elementType.Elem()
+	}
+
+	var rows []reflect.Value
+	for i := 0; i < v.Len(); i++ {
+		row := indirect(v.Index(i))
+		if !row.IsValid() {
+			return nil, nil, false
+		}
+		rows = append(rows, row)
+	}
+
+	// interface{} elements get their type from the rows
+	if elementType.Kind() == reflect.Interface && len(rows) > 0 {
+		elementType = rows[0].Type()
+	}
+
+	switch elementType.Kind() {
+	case reflect.Struct:
+		for _, row := range rows {
+			if row.Type() != elementType {
+				return nil, nil, false
+			}
+		}
+		return structTable(elementType, rows)
+	case reflect.Map:
+		if len(rows) == 0 {
+			// without any rows there are no columns to write
+			return nil, nil, false
+		}
+		for _, row := range rows {
+			if row.Kind() != reflect.Map || row.Type().Key().Kind() != reflect.String {
+				return nil, nil, false
+			}
+		}
+		return mapTable(rows)
+	}
+
+	return nil, nil, false
+}
+
+func structTable(t reflect.Type, rows []reflect.Value) ([]string, [][]reflect.Value, bool) {
+
+	var columns []string
+	var indexes []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name := fieldName(sf); len(sf.PkgPath) == 0 && name != "-" {
+			columns = append(columns, name)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, nil, false
+	}
+
+	cells := make([][]reflect.Value, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]reflect.Value, len(indexes))
+		for c, index := range indexes {
+			cells[r][c] = indirect(row.Field(index))
+		}
+	}
+
+	return columns, cells, true
+}
+
+func mapTable(rows []reflect.Value) ([]string, [][]reflect.Value, bool) {
+
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for _, key := range row.MapKeys() {
+			if name := key.String(); !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, nil, false
+	}
+
+	sort.Strings(columns)
+
+	cells := make([][]reflect.Value, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]reflect.Value, len(columns))
+		for c, name := range columns {
+			key := reflect.ValueOf(name).Convert(row.Type().Key())
+			cells[r][c] = indirect(row.MapIndex(key))
+		}
+	}
+
+	return columns, cells, true
+}
+
+// isNumber gets whether the value is an int, uint or float.
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatCell gets the text of a table cell.
+func formatCell(v reflect.Value) (string, error) {
+
+	if !v.IsValid() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()) {
+		return "", nil
+	}
+
+	var text string
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := marshaler.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	} else if v.Kind() == reflect.String {
+		text = v.String()
+	} else if v.Kind() == reflect.Bool || isNumber(v) {
+		text = fmt.Sprint(v.Interface())
+	} else {
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	}
+
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "|", `\|`, -1)
+	text = strings.Replace(text, "\n", "<br>", -1)
+
+	return text, nil
+}
+
+// writeTable writes the cells as a table, with the columns padded to line
+// up.
+func writeTable(w io.Writer, columns []string, rows [][]reflect.Value) error {
+
+	widths := make([]int, len(columns))
+	numeric := make([]bool, len(columns))
+	header := make([]string, len(columns))
+	for c, name := range columns {
+		header[c], _ = formatCell(reflect.ValueOf(name))
+		widths[c] = utf8.RuneCountInString(header[c])
+		if widths[c] < 3 {
+			widths[c] = 3
+		}
+	}
+
+	text := make([][]string, len(rows))
+	for r, row := range rows {
+		text[r] = make([]string, len(columns))
+		for c, cell := range row {
+
+			s, err := formatCell(cell)
+			if err != nil {
+				return err
+			}
+			text[r][c] = s
+
+			if n := utf8.RuneCountInString(s); n > widths[c] {
+				widths[c] = n
+			}
+		}
+	}
+
+	for c := range columns {
+		for r := range rows {
+			if cell := rows[r][c]; cell.IsValid() {
+				if numeric[c] = isNumber(cell); !numeric[c] {
+					break
+				}
+			}
+		}
+	}
+
+	// pad fills the cell out to the width of its column
+	pad := func(s string, c int) string {
+		padding := strings.Repeat(" ", widths[c]-utf8.RuneCountInString(s))
+		if numeric[c] {
+			return padding + s
+		}
+		return s + padding
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for c, s := range cells {
+			padded[c] = pad(s, c)
+		}
+		return "| " + strings.Join(padded, " | ") + " |\n"
+	}
+
+	delimiters := make([]string, len(columns))
+	for c := range columns {
+		if numeric[c] {
+			delimiters[c] = strings.Repeat("-", widths[c]+1) + ":"
+		} else {
+			delimiters[c] = strings.Repeat("-", widths[c]+2)
+		}
+	}
+
+	output := line(header) + "|" + strings.Join(delimiters, "|") + "|\n"
+	for _, cells := range text {
+		output += line(cells)
+	}
+
+	_, err := io.WriteString(w, output)
+
+	return err
+}
+
+// splitRow gets the cells in a table row.
+func splitRow(line string) []string {
+
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell []byte
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell = append(cell, '|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, string(cell))
+			cell = cell[:0]
+		default:
+			cell = append(cell, line[i])
+		}
+	}
+	cells = append(cells, string(cell))
+
+	for i, c := range cells {
+		c = strings.Replace(strings.TrimSpace(c), "<br>", "\n", -1)
+		cells[i] = strings.Replace(c, "<br/>", "\n", -1)
+	}
+
+	return cells
+}
+
+// isDelimiterRow gets whether the line is a table's delimiter row with the
+// number of columns.
+func isDelimiterRow(line string, columns int) bool {
+
+	if !strings.Contains(line, "-") {
+		return false
+	}
+
+	cells := splitRow(line)
+	if len(cells) != columns {
+		return false
+	}
+
+	for _, cell := range cells {
+		cell = strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if len(cell) == 0 || strings.Trim(cell, "-") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cellValue gets the value of the cell text; nil if it is empty, the value
+// if it is JSON, or else the text.
+func cellValue(text string) interface{} {
+
+	if len(text) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text
+	}
+
+	return value
+}
+
+// readTable reads the rows of the table starting at the header line.
+func readTable(lines []string) []map[string]interface{} {
+
+	columns := splitRow(lines[0])
+	rows := []map[string]interface{}{}
+
+	for _, line := range lines[2:] {
+
+		if len(strings.TrimSpace(line)) == 0 || !strings.Contains(line, "|") {
+			break
+		}
+
+		cells := splitRow(line)
+		row := make(map[string]interface{}, len(columns))
+		for c, name := range columns {
+			if c < len(cells) {
+				row[name] = cellValue(cells[c])
+			} else {
+				row[name] = nil
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
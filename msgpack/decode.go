@@ -0,0 +1,427 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ErrorUnexpectedEnd is returned when Msgpack ends in the middle of a value.
+var ErrorUnexpectedEnd = errors.New("codecs: msgpack: Unexpected end of data")
+
+// decoder reads Msgpack into generic values, for codecs whose choices
+// msgpack.Unmarshal can't read (see usesDecoder).
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// usesDecoder gets whether the codec, with the choices the options make, reads
+// Msgpack with the decoder rather than msgpack.Unmarshal, which predates
+// binary data, timestamps and other extensions, and can't read structs
+// written as arrays.  That is decided by the codec's configuration, not the
+// data, so that the same data is always read the same way.
+func usesDecoder(codec *MsgpackCodec, options map[string]interface{}) bool {
+	return newEncoder(codec, options).writesStructs() || hasExtensions()
+}
+
+// unmarshalExtended decodes the Msgpack into the object, which must be a
+// pointer.
+func unmarshalExtended(data []byte, obj interface{}) error {
+
+	target := reflect.ValueOf(obj)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("codecs: msgpack: Cannot unmarshal into %T", obj)
+	}
+
+	d := &decoder{data: data}
+	value, err := d.value()
+	if err != nil {
+		return err
+	}
+
+	return assign(target.Elem(), value)
+}
+
+// read consumes the next n bytes.
+func (d *decoder) read(n int) ([]byte, error) {
+
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+// uint reads an unsigned big endian integer of n bytes.
+func (d *decoder) uint(n int) (uint64, error) {
+
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// length reads a length of n bytes.
+func (d *decoder) length(n int) (int, error) {
+	length, err := d.uint(n)
+	return int(length), err
+}
+
+// value reads the next value.  Integers are read as int64 (or uint64 if they
+// don't fit), maps as map[string]interface{} (or map[interface{}]interface{}
+// if they have keys other than strings), arrays as []interface{} and binary
+// data as []byte.
+func (d *decoder) value() (interface{}, error) {
+
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format := b[0]; {
+	case format <= 0x7f:
+		return int64(format), nil
+	case format <= 0x8f:
+		return d.mapOf(int(format & 0x0f))
+	case format <= 0x9f:
+		return d.arrayOf(int(format & 0x0f))
+	case format <= 0xbf:
+		return d.str(int(format & 0x1f))
+	case format >= 0xe0:
+		return int64(int8(format)), nil
+	}
+
+	var length int
+
+	switch format := b[0]; format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6: // bin 8, 16 and 32
+		if length, err = d.length(1 << (format - 0xc4)); err != nil {
+			return nil, err
+		}
+		data, err := d.read(length)
+		return append([]byte(nil), data...), err
+	case 0xc7, 0xc8, 0xc9: // ext 8, 16 and 32
+		if length, err = d.length(1 << (format - 0xc7)); err != nil {
+			return nil, err
+		}
+		return d.extension(length)
+	case 0xca:
+		bits, err := d.uint(4)
+		return math.Float32frombits(uint32(bits)), err
+	case 0xcb:
+		bits, err := d.uint(8)
+		return math.Float64frombits(bits), err
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8, 16, 32 and 64
+		n, err := d.uint(1 << (format - 0xcc))
+		if n > math.MaxInt64 {
+			return n, err
+		}
+		return int64(n), err
+	case 0xd0:
+		n, err := d.uint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := d.uint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := d.uint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := d.uint(8)
+		return int64(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1, 2, 4, 8 and 16
+		return d.extension(1 << (format - 0xd4))
+	case 0xd9, 0xda, 0xdb: // str 8, 16 and 32
+		if length, err = d.length(1 << (format - 0xd9)); err != nil {
+			return nil, err
+		}
+		return d.str(length)
+	case 0xdc, 0xdd: // array 16 and 32
+		if length, err = d.length(2 << (format - 0xdc)); err != nil {
+			return nil, err
+		}
+		return d.arrayOf(length)
+	case 0xde, 0xdf: // map 16 and 32
+		if length, err = d.length(2 << (format - 0xde)); err != nil {
+			return nil, err
+		}
+		return d.mapOf(length)
+	}
+
+	return nil, fmt.Errorf("codecs: msgpack: Invalid format 0x%x at offset %d", b[0], d.pos-1)
+}
+
+// str reads a string of the length.
+func (d *decoder) str(length int) (interface{}, error) {
+	b, err := d.read(length)
+	return string(b), err
+}
+
+// arrayOf reads an array of the length.
+func (d *decoder) arrayOf(length int) (interface{}, error) {
+
+	if length > len(d.data)-d.pos {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	array := make([]interface{}, length)
+	for i := range array {
+		item, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		array[i] = item
+	}
+
+	return array, nil
+}
+
+// mapOf reads a map of the length.
+func (d *decoder) mapOf(length int) (interface{}, error) {
+
+	if length > len(d.data)-d.pos {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	keys := make([]interface{}, length)
+	values := make([]interface{}, length)
+	stringKeys := true
+
+	for i := 0; i < length; i++ {
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		if keys[i] = key; key == nil || !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("codecs: msgpack: Invalid map key %v at offset %d", key, d.pos)
+		}
+		if _, ok := key.(string); !ok {
+			stringKeys = false
+		}
+		if values[i], err = d.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	if stringKeys {
+		m := make(map[string]interface{}, length)
+		for i, key := range keys {
+			m[key.(string)] = values[i]
+		}
+		return m, nil
+	}
+
+	m := make(map[interface{}]interface{}, length)
+	for i, key := range keys {
+		m[key] = values[i]
+	}
+	return m, nil
+}
+
+// extension reads the type code and data of an extension of the length,
+// decoding it with the decoder registered for its type.
+func (d *decoder) extension(length int) (interface{}, error) {
+
+	code, err := d.uint(1)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := d.read(length)
+	if err != nil {
+		return nil, err
+	}
+
+	if extension := extensionCoded(int8(code)); extension != nil {
+		return extension.decode(data)
+	}
+
+	if int8(code) == timestampCode {
+		return decodeTimestamp(data)
+	}
+
+	return Extension{Type: int8(code), Data: append([]byte(nil), data...)}, nil
+}
+
+// assign stores the generic value in the target, converting it as necessary.
+func assign(target reflect.Value, value interface{}) error {
+
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(target.Type()) {
+		target.Set(v)
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return assign(target.Elem(), value)
+	}
+
+	if isNumber(v.Kind()) && isNumber(target.Kind()) {
+		target.Set(v.Convert(target.Type()))
+		return nil
+	}
+
+	switch value := value.(type) {
+	case string:
+		switch {
+		case target.Kind() == reflect.String:
+			target.SetString(value)
+			return nil
+		case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+			target.SetBytes([]byte(value))
+			return nil
+		}
+	case []byte:
+		if target.Kind() == reflect.String {
+			target.SetString(string(value))
+			return nil
+		}
+	case []interface{}:
+		switch target.Kind() {
+		case reflect.Slice:
+			slice := reflect.MakeSlice(target.Type(), len(value), len(value))
+			for i, item := range value {
+				if err := assign(slice.Index(i), item); err != nil {
+					return err
+				}
+			}
+			target.Set(slice)
+			return nil
+		case reflect.Array:
+			for i := 0; i < target.Len() && i < len(value); i++ {
+				if err := assign(target.Index(i), value[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		case reflect.Struct:
+			return assignStructArray(target, value)
+		}
+	case map[string]interface{}:
+		switch target.Kind() {
+		case reflect.Map:
+			return assignMap(target, v)
+		case reflect.Struct:
+			return assignStruct(target, value)
+		}
+	case map[interface{}]interface{}:
+		if target.Kind() == reflect.Map {
+			return assignMap(target, v)
+		}
+	}
+
+	return fmt.Errorf("codecs: msgpack: Cannot unmarshal %T into %s", value, target.Type())
+}
+
+// assignMap stores the entries of the generic map in the target map.
+func assignMap(target, m reflect.Value) error {
+
+	result := reflect.MakeMapWithSize(target.Type(), m.Len())
+
+	for _, key := range m.MapKeys() {
+
+		k := reflect.New(target.Type().Key()).Elem()
+		if err := assign(k, key.Interface()); err != nil {
+			return err
+		}
+
+		element := reflect.New(target.Type().Elem()).Elem()
+		if err := assign(element, m.MapIndex(key).Interface()); err != nil {
+			return err
+		}
+
+		result.SetMapIndex(k, element)
+	}
+
+	target.Set(result)
+	return nil
+}
+
+// assignStruct stores the entries of the generic map in the exported fields of
+// the target struct with their keys as names (or the names their msgpack tags
+// give them), matched without regard to case if no field has the name
+// exactly.
+func assignStruct(target reflect.Value, m map[string]interface{}) error {
+
+	fields := structFields(target.Type())
+
+	for key, item := range m {
+
+		index := -1
+		for _, field := range fields {
+			if field.name == key {
+				index = field.index
+				break
+			}
+			if index < 0 && strings.EqualFold(field.name, key) {
+				index = field.index
+			}
+		}
+
+		if index < 0 {
+			continue
+		}
+
+		if err := assign(target.Field(index), item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assignStructArray stores the items of the generic array in the exported
+// fields of the target struct (other than those tagged `msgpack:"-"`) in their
+// declared order, as structs are written as arrays.
+func assignStructArray(target reflect.Value, array []interface{}) error {
+
+	for _, field := range structFields(target.Type()) {
+
+		if len(array) == 0 {
+			break
+		}
+
+		if err := assign(target.Field(field.index), array[0]); err != nil {
+			return err
+		}
+		array = array[1:]
+	}
+
+	return nil
+}
+
+// isNumber gets whether the kind is a number's.
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
@@ -0,0 +1,279 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/ugorji/go-msgpack"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// rawMessageType is the type of codecs.RawMessage.
+var rawMessageType = reflect.TypeOf(codecs.RawMessage{})
+
+// encoder writes values as Msgpack, writing maps and arrays itself so that the
+// entries of maps with string keys can be sorted, RawMessages written as they
+// are and values of registered extension types written as extensions.
+type encoder struct {
+	codec    *MsgpackCodec
+	sortKeys bool
+
+	// bin, timestamps and structArrays are the encoding choices (see
+	// MsgpackCodec), which make the encoder write all structs itself.
+	bin, timestamps, structArrays bool
+}
+
+// newEncoder makes an encoder for the codec, with the choices the options
+// make.
+func newEncoder(codec *MsgpackCodec, options map[string]interface{}) *encoder {
+	e := &encoder{codec: codec, bin: codec.BinBytes, timestamps: codec.Timestamps, structArrays: codec.StructsAsArrays}
+	e.sortKeys, _ = options[constants.OptionKeySortKeys].(bool)
+	choose(&e.bin, options[constants.OptionKeyBinBytes])
+	choose(&e.timestamps, options[constants.OptionKeyTimestamps])
+	choose(&e.structArrays, options[constants.OptionKeyStructsAsArrays])
+	return e
+}
+
+// choose sets the choice to the option, if it is a bool.
+func choose(choice *bool, option interface{}) {
+	if chosen, ok := option.(bool); ok {
+		*choice = chosen
+	}
+}
+
+// needed gets whether the encoder is needed to write the value, rather than
+// msgpack.Marshal.
+func (e *encoder) needed(value reflect.Value) bool {
+	return e.sortKeys || e.writesStructs() || needsEncoder(value)
+}
+
+// writesStructs gets whether the encoder writes all structs itself.
+func (e *encoder) writesStructs() bool {
+	return e.bin || e.timestamps || e.structArrays
+}
+
+// marshal writes the value to the buffer as Msgpack.  Other values (including
+// structs without RawMessages or extensions, whose fields keep their declared
+// order, unless the encoder writes all structs) are left to msgpack.Marshal.
+func (e *encoder) marshal(buffer *bytes.Buffer, value reflect.Value) error {
+
+	if value.IsValid() && value.CanInterface() {
+		if extension := extensionFor(value.Type()); extension != nil {
+			data, err := extension.encode(value.Interface())
+			if err != nil {
+				return err
+			}
+			writeExtension(buffer, extension.code, data)
+			return nil
+		}
+		if e.timestamps && value.Type() == timeType {
+			writeExtension(buffer, timestampCode, encodeTimestamp(value.Interface().(time.Time)))
+			return nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			return e.marshal(buffer, value.Elem())
+		}
+
+	case reflect.Map:
+		if value.IsNil() {
+			break
+		}
+
+		keys := value.MapKeys()
+		if e.sortKeys && value.Type().Key().Kind() == reflect.String {
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		}
+
+		writeHeader(buffer, 0x80, 0xde, 0xdf, len(keys))
+		for _, key := range keys {
+			if err := e.marshal(buffer, key); err != nil {
+				return err
+			}
+			if err := e.marshal(buffer, value.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Array, reflect.Slice:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			break
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if e.bin {
+				writeBin(buffer, value)
+				return nil
+			}
+			break
+		}
+
+		writeHeader(buffer, 0x90, 0xdc, 0xdd, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			if err := e.marshal(buffer, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		if value.Type() == rawMessageType {
+			return e.marshalRaw(buffer, value.Interface().(codecs.RawMessage))
+		}
+		// times are left to msgpack.Marshal unless written as timestamps
+		if (e.writesStructs() && value.Type() != timeType) || needsEncoder(value) {
+			return e.marshalStruct(buffer, value)
+		}
+	}
+
+	var object interface{}
+	if value.IsValid() && value.CanInterface() {
+		object = value.Interface()
+	}
+
+	data, err := msgpack.Marshal(object)
+	if err != nil {
+		return err
+	}
+	buffer.Write(data)
+
+	return nil
+}
+
+// marshalRaw writes the message as it is if it is Msgpack, or else decoded
+// with the codec registered for its content type.
+func (e *encoder) marshalRaw(buffer *bytes.Buffer, message codecs.RawMessage) error {
+
+	if message.EncodedFor(e.codec) {
+		buffer.Write(message.Data())
+		return nil
+	}
+
+	var decoded interface{}
+	if err := message.Decode(&decoded); err != nil {
+		return err
+	}
+
+	return e.marshal(buffer, reflect.ValueOf(decoded))
+}
+
+// marshalStruct writes the exported fields of the struct as a map keyed by
+// their names (or the names their msgpack tags give them, leaving out empty
+// fields tagged omitempty), as msgpack.Marshal does, or as an array in their
+// declared order if the encoder writes structs as arrays.
+func (e *encoder) marshalStruct(buffer *bytes.Buffer, value reflect.Value) error {
+
+	fields := structFields(value.Type())
+
+	if e.structArrays {
+		writeHeader(buffer, 0x90, 0xdc, 0xdd, len(fields))
+	} else {
+		written := fields[:0:0]
+		for _, field := range fields {
+			if !field.omitEmpty || !isEmpty(value.Field(field.index)) {
+				written = append(written, field)
+			}
+		}
+		fields = written
+		writeHeader(buffer, 0x80, 0xde, 0xdf, len(fields))
+	}
+
+	for _, field := range fields {
+		if !e.structArrays {
+			if err := e.marshal(buffer, reflect.ValueOf(field.name)); err != nil {
+				return err
+			}
+		}
+		if err := e.marshal(buffer, value.Field(field.index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// needsEncoder gets whether the value is or holds a codecs.RawMessage or a
+// value of a registered extension type, which msgpack.Marshal can't write.
+func needsEncoder(value reflect.Value) bool {
+
+	if !value.IsValid() {
+		return false
+	}
+
+	if value.Type() == rawMessageType || extensionFor(value.Type()) != nil {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return !value.IsNil() && needsEncoder(value.Elem())
+
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			if needsEncoder(value.MapIndex(key)) {
+				return true
+			}
+		}
+
+	case reflect.Array, reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		for i := 0; i < value.Len(); i++ {
+			if needsEncoder(value.Index(i)) {
+				return true
+			}
+		}
+
+	case reflect.Struct:
+		for _, field := range structFields(value.Type()) {
+			if needsEncoder(value.Field(field.index)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// writeBin writes the bytes of the []byte or byte array with the bin family.
+func writeBin(buffer *bytes.Buffer, value reflect.Value) {
+
+	data := make([]byte, value.Len())
+	reflect.Copy(reflect.ValueOf(data), value)
+
+	switch length := len(data); {
+	case length <= 0xff:
+		buffer.WriteByte(0xc4)
+		buffer.WriteByte(byte(length))
+	case length <= 0xffff:
+		buffer.WriteByte(0xc5)
+		binary.Write(buffer, binary.BigEndian, uint16(length))
+	default:
+		buffer.WriteByte(0xc6)
+		binary.Write(buffer, binary.BigEndian, uint32(length))
+	}
+
+	buffer.Write(data)
+}
+
+// writeHeader writes the header of a map or array of the length, using the
+// fix, 16 bit or 32 bit form it fits in.
+func writeHeader(buffer *bytes.Buffer, fix, header16, header32 byte, length int) {
+	switch {
+	case length < 16:
+		buffer.WriteByte(fix | byte(length))
+	case length <= 0xffff:
+		buffer.WriteByte(header16)
+		binary.Write(buffer, binary.BigEndian, uint16(length))
+	default:
+		buffer.WriteByte(header32)
+		binary.Write(buffer, binary.BigEndian, uint32(length))
+	}
+}
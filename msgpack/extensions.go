@@ -0,0 +1,133 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ExtensionEncoder encodes a value as the data of a Msgpack extension.
+type ExtensionEncoder func(value interface{}) ([]byte, error)
+
+// ExtensionDecoder decodes the data of a Msgpack extension into a value.
+type ExtensionDecoder func(data []byte) (interface{}, error)
+
+// Extension is an extension value of a type without a registered decoder, as
+// unmarshalled into an interface{}.
+type Extension struct {
+	// Type is the extension's type code, and Data its encoded data.
+	Type int8
+	Data []byte
+}
+
+// extension is a registered extension type.
+type extension struct {
+	code   int8
+	encode ExtensionEncoder
+	decode ExtensionDecoder
+}
+
+var (
+	extensionsLock sync.RWMutex
+	extensionTypes = map[reflect.Type]*extension{}
+	extensionCodes = map[int8]reflect.Type{}
+)
+
+// RegisterExtension registers the Msgpack extension type with the code (from
+// 0 to 127; the negative codes are reserved for types the Msgpack
+// specification defines) for values of the object's type (or the type it
+// points to).  Marshal writes them as the extension, with the data encode
+// gets, and Unmarshal reads the extension with decode.  Registering nil
+// functions removes the extension type.  RegisterExtension panics if the code
+// is negative.
+func RegisterExtension(code int8, object interface{}, encode ExtensionEncoder, decode ExtensionDecoder) {
+
+	if code < 0 {
+		panic(fmt.Sprintf("codecs: msgpack: RegisterExtension code %d is reserved.", code))
+	}
+
+	if object == nil {
+		panic("codecs: msgpack: RegisterExtension object is nil.")
+	}
+
+	objectType := reflect.TypeOf(object)
+	for objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	extensionsLock.Lock()
+	defer extensionsLock.Unlock()
+
+	if registered, ok := extensionTypes[objectType]; ok {
+		delete(extensionCodes, registered.code)
+		delete(extensionTypes, objectType)
+	}
+	if registered, ok := extensionCodes[code]; ok {
+		delete(extensionTypes, registered)
+		delete(extensionCodes, code)
+	}
+
+	if encode == nil && decode == nil {
+		return
+	}
+
+	if encode == nil || decode == nil {
+		panic("codecs: msgpack: RegisterExtension needs both an encoder and a decoder.")
+	}
+
+	extensionTypes[objectType] = &extension{code: code, encode: encode, decode: decode}
+	extensionCodes[code] = objectType
+}
+
+// extensionFor gets the extension type registered for the type, or nil.
+func extensionFor(valueType reflect.Type) *extension {
+	extensionsLock.RLock()
+	defer extensionsLock.RUnlock()
+	return extensionTypes[valueType]
+}
+
+// hasExtensions gets whether any extension types are registered.
+func hasExtensions() bool {
+	extensionsLock.RLock()
+	defer extensionsLock.RUnlock()
+	return len(extensionTypes) > 0
+}
+
+// extensionCoded gets the extension type registered with the code, or nil.
+func extensionCoded(code int8) *extension {
+	extensionsLock.RLock()
+	defer extensionsLock.RUnlock()
+	return extensionTypes[extensionCodes[code]]
+}
+
+// writeExtension writes the extension value with the code and data, using the
+// fixext form if the data fits one exactly.
+func writeExtension(buffer *bytes.Buffer, code int8, data []byte) {
+
+	switch length := len(data); {
+	case length == 1:
+		buffer.WriteByte(0xd4)
+	case length == 2:
+		buffer.WriteByte(0xd5)
+	case length == 4:
+		buffer.WriteByte(0xd6)
+	case length == 8:
+		buffer.WriteByte(0xd7)
+	case length == 16:
+		buffer.WriteByte(0xd8)
+	case length <= 0xff:
+		buffer.WriteByte(0xc7)
+		buffer.WriteByte(byte(length))
+	case length <= 0xffff:
+		buffer.WriteByte(0xc8)
+		binary.Write(buffer, binary.BigEndian, uint16(length))
+	default:
+		buffer.WriteByte(0xc9)
+		binary.Write(buffer, binary.BigEndian, uint32(length))
+	}
+
+	buffer.WriteByte(byte(code))
+	buffer.Write(data)
+}
@@ -0,0 +1,77 @@
+package msgpack
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// decimal is encoded as extension type 1, holding its digits.
+type decimal string
+
+func registerDecimal() {
+	RegisterExtension(1, decimal(""), func(value interface{}) ([]byte, error) {
+		return []byte(value.(decimal)), nil
+	}, func(data []byte) (interface{}, error) {
+		return decimal(data), nil
+	})
+}
+
+func TestRegisterExtension(t *testing.T) {
+
+	registerDecimal()
+	defer RegisterExtension(1, decimal(""), nil, nil)
+
+	codec := new(MsgpackCodec)
+
+	packed, err := codec.Marshal(decimal("1.5"), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0xc7, 0x03, 0x01, '1', '.', '5'}, packed)
+	}
+
+	packed, err = codec.Marshal([]interface{}{decimal("12.5"), decimal("0.1")}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x92, 0xd6, 0x01, '1', '2', '.', '5', 0xc7, 0x03, 0x01, '0', '.', '1'}, packed)
+	}
+
+	// {"price": 12.5, "count": 2}
+	data := []byte{0x82, 0xa5, 'p', 'r', 'i', 'c', 'e', 0xd6, 0x01, '1', '2', '.', '5', 0xa5, 'c', 'o', 'u', 'n', 't', 0x02}
+
+	var generic interface{}
+	if assert.NoError(t, codec.Unmarshal(data, &generic)) {
+		assert.Equal(t, map[string]interface{}{"price": decimal("12.5"), "count": int64(2)}, generic)
+	}
+
+	var item struct {
+		Price *decimal
+		Count int
+	}
+	if assert.NoError(t, codec.Unmarshal(data, &item)) {
+		if assert.NotNil(t, item.Price) {
+			assert.Equal(t, decimal("12.5"), *item.Price)
+		}
+		assert.Equal(t, 2, item.Count)
+	}
+
+	// negative codes are the specification's, such as -1 for timestamps
+	assert.Panics(t, func() {
+		RegisterExtension(-1, decimal(""), nil, nil)
+	})
+
+}
+
+func TestUnmarshal_UnregisteredExtension(t *testing.T) {
+
+	// extensions are read once any extension type is registered
+	registerDecimal()
+	defer RegisterExtension(1, decimal(""), nil, nil)
+
+	codec := new(MsgpackCodec)
+
+	var generic []interface{}
+	if assert.NoError(t, codec.Unmarshal([]byte{0x92, 0xd4, 0x05, 0xff, 0xc0}, &generic)) {
+		assert.Equal(t, []interface{}{Extension{Type: 5, Data: []byte{0xff}}, nil}, generic)
+	}
+
+	assert.Equal(t, ErrorUnexpectedEnd, codec.Unmarshal([]byte{0x92, 0xd6, 0x05, 0xff}, &generic))
+
+}
@@ -0,0 +1,70 @@
+package msgpack
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structField is an exported field of a struct as Msgpack sees it, with the
+// name and options from its msgpack tag (as in `msgpack:"name,omitempty"`), as
+// msgpack.Marshal reads them.
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// structFields gets the exported fields of the struct type in their declared
+// order, leaving out those tagged `msgpack:"-"`.
+func structFields(structType reflect.Type) []structField {
+
+	var fields []structField
+
+	for i := 0; i < structType.NumField(); i++ {
+
+		field := structType.Field(i)
+		if len(field.PkgPath) != 0 {
+			continue
+		}
+
+		tag := field.Tag.Get("msgpack")
+		if tag == "-" {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		f := structField{index: i, name: options[0]}
+		if len(f.name) == 0 {
+			f.name = field.Name
+		}
+		for _, option := range options[1:] {
+			if option == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// isEmpty gets whether the value is empty, and so is left out of the map a
+// struct is written as if its field is tagged omitempty.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return value.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/codecs"
+)
+
+// CheckLimits checks that the Msgpack doesn't exceed the limits, scanning it
+// without decoding it into an object.  Maps and arrays count towards the depth
+// limit, and strings and binary data towards the string length limit.  Since
+// the scan isn't recursive, deeply nested data can't exhaust the stack.
+func (c *MsgpackCodec) CheckLimits(data []byte, limits codecs.DecodeLimits) error {
+
+	checker := codecs.LimitChecker{Limits: limits}
+
+	// remaining holds the number of values left to read in each map or array
+	// being read
+	var remaining []int64
+
+	for offset := 0; offset < len(data); {
+
+		if err := checker.Element(); err != nil {
+			return err
+		}
+
+		skip, length, count, ok := header(data[offset:])
+
+		// malformed data is left for Unmarshal to report
+		if !ok {
+			return nil
+		}
+
+		if count >= 0 {
+			if err := checker.Enter(); err != nil {
+				return err
+			}
+			if count > 0 {
+				remaining = append(remaining, count)
+				offset += skip
+				continue
+			}
+			checker.Leave()
+		}
+
+		offset += skip
+
+		if length >= 0 {
+			if err := checker.String(int(length)); err != nil {
+				return err
+			}
+			if length > int64(len(data)-offset) {
+				return nil
+			}
+			offset += int(length)
+		}
+
+		// finish the maps and arrays this value completes
+		for len(remaining) > 0 {
+			remaining[len(remaining)-1]--
+			if remaining[len(remaining)-1] > 0 {
+				break
+			}
+			remaining = remaining[:len(remaining)-1]
+			checker.Leave()
+		}
+
+		// only the first value is unmarshalled
+		if len(remaining) == 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// header reads the header of the Msgpack value at the start of the data,
+// getting the number of bytes to skip to the next value (not counting the
+// length of any string or binary data), the length of the string or binary
+// data (or -1), and the number of values in the map or array (or -1).  ok is
+// false if the data is truncated or malformed.
+func header(data []byte) (skip int, length, count int64, ok bool) {
+
+	if len(data) == 0 {
+		return 0, -1, -1, false
+	}
+
+	format := data[0]
+
+	// the size of the header following the format byte
+	size := func(n int) (int64, bool) {
+		if len(data) < 1+n {
+			return 0, false
+		}
+		switch n {
+		case 1:
+			return int64(data[1]), true
+		case 2:
+			return int64(binary.BigEndian.Uint16(data[1:])), true
+		}
+		return int64(binary.BigEndian.Uint32(data[1:])), true
+	}
+
+	switch {
+	case format <= 0x7f, format >= 0xe0, format == 0xc0, format == 0xc2, format == 0xc3:
+		return 1, -1, -1, true
+	case format <= 0x8f:
+		return 1, -1, int64(format&0x0f) * 2, true
+	case format <= 0x9f:
+		return 1, -1, int64(format & 0x0f), true
+	case format <= 0xbf:
+		return 1, int64(format & 0x1f), -1, true
+	}
+
+	switch format {
+	case 0xc4, 0xd9: // bin 8, str 8
+		length, ok = size(1)
+		return 2, length, -1, ok
+	case 0xc5, 0xda: // bin 16, str 16
+		length, ok = size(2)
+		return 3, length, -1, ok
+	case 0xc6, 0xdb: // bin 32, str 32
+		length, ok = size(4)
+		return 5, length, -1, ok
+	case 0xc7: // ext 8
+		length, ok = size(1)
+		return 3 + int(length), -1, -1, ok
+	case 0xc8: // ext 16
+		length, ok = size(2)
+		return 4 + int(length), -1, -1, ok
+	case 0xc9: // ext 32
+		length, ok = size(4)
+		return 6 + int(length), -1, -1, ok
+	case 0xcc, 0xd0: // uint 8, int 8
+		return 2, -1, -1, true
+	case 0xcd, 0xd1: // uint 16, int 16
+		return 3, -1, -1, true
+	case 0xca, 0xce, 0xd2: // float 32, uint 32, int 32
+		return 5, -1, -1, true
+	case 0xcb, 0xcf, 0xd3: // float 64, uint 64, int 64
+		return 9, -1, -1, true
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1, 2, 4, 8 and 16
+		return 2 + 1<<(format-0xd4), -1, -1, true
+	case 0xdc: // array 16
+		count, ok = size(2)
+		return 3, -1, count, ok
+	case 0xdd: // array 32
+		count, ok = size(4)
+		return 5, -1, count, ok
+	case 0xde: // map 16
+		count, ok = size(2)
+		return 3, -1, count * 2, ok
+	case 0xdf: // map 32
+		count, ok = size(4)
+		return 5, -1, count * 2, ok
+	}
+
+	return 0, -1, -1, false
+}
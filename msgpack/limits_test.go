@@ -0,0 +1,34 @@
+package msgpack
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	// {"name": "Mat", "tags": [[1], [0xcd, 0x01, 0x00]]}
+	data := []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'M', 'a', 't', 0xa4, 't', 'a', 'g', 's', 0x92, 0x91, 0x01, 0x91, 0xcd, 0x01, 0x00}
+
+	assert.NoError(t, codec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 3, MaxElements: 9, MaxStringLength: 4}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 2}, codec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 2}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitElements, Max: 8}, codec.CheckLimits(data, codecs.DecodeLimits{MaxElements: 8}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitStringLength, Max: 3}, codec.CheckLimits(data, codecs.DecodeLimits{MaxStringLength: 3}))
+
+	// deep nesting is refused without recursing
+	nested := make([]byte, 100000)
+	for i := range nested {
+		nested[i] = 0x91
+	}
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 64}, codec.CheckLimits(nested, codecs.DecodeLimits{MaxDepth: 64}))
+
+	// a string longer than the data is refused before it is read
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitStringLength, Max: 16}, codec.CheckLimits([]byte{0xdb, 0xff, 0xff, 0xff, 0xff}, codecs.DecodeLimits{MaxStringLength: 16}))
+
+	// malformed data is left for Unmarshal
+	assert.NoError(t, codec.CheckLimits([]byte{0x92, 0xc1}, codecs.DecodeLimits{MaxDepth: 1}))
+
+}
@@ -1,20 +1,68 @@
 package msgpack
 
 import (
+	"bytes"
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/ugorji/go-msgpack"
+	"reflect"
 )
 
-// MsgpackCodec converts objects to and from Msgpack.
-type MsgpackCodec struct{}
+// MsgpackCodec converts objects to and from Msgpack.  Its fields make the
+// encoding choices other Msgpack implementations may insist on, which the
+// options with the constants.OptionKeyBinBytes, constants.OptionKeyTimestamps
+// and constants.OptionKeyStructsAsArrays keys override.
+type MsgpackCodec struct {
 
-// Converts an object to Msgpack.
+	// BinBytes writes []byte with the bin family, rather than as strings.
+	BinBytes bool
+
+	// Timestamps writes time.Time as the timestamp extension.
+	Timestamps bool
+
+	// StructsAsArrays writes structs as arrays of their exported fields in
+	// their declared order, rather than as maps keyed by their names, and
+	// reads them back into structs the same way.
+	StructsAsArrays bool
+}
+
+func init() {
+	codecs.Register(new(MsgpackCodec))
+}
+
+// Converts an object to Msgpack, with the keys of maps in sorted order if the
+// constants.OptionKeySortKeys option is true.  codecs.RawMessages holding
+// Msgpack are written as they are, and values of registered extension types
+// as extensions (see RegisterExtension).
 func (c *MsgpackCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	if value, encoder := reflect.ValueOf(object), newEncoder(c, options); encoder.needed(value) {
+		var buffer bytes.Buffer
+		if err := encoder.marshal(&buffer, value); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
 	return msgpack.Marshal(object)
 }
 
-// Unmarshal converts Msgpack into an object.
+// Unmarshal converts Msgpack into an object.  A codec making any of the
+// encoding choices (or any codec, once extension types are registered) reads
+// binary data, and extensions, which are decoded by the decoders registered
+// for their types (timestamps into time.Time), or else unmarshalled as
+// Extensions.
 func (c *MsgpackCodec) Unmarshal(data []byte, obj interface{}) error {
+	return c.UnmarshalWithOptions(data, obj, nil)
+}
+
+// UnmarshalWithOptions converts Msgpack into an object as Unmarshal does, with
+// the options with the constants.OptionKeyBinBytes,
+// constants.OptionKeyTimestamps and constants.OptionKeyStructsAsArrays keys
+// overriding the codec's choices, so that data marshalled with them is read
+// back the same way.
+func (c *MsgpackCodec) UnmarshalWithOptions(data []byte, obj interface{}, options map[string]interface{}) error {
+	if usesDecoder(c, options) {
+		return unmarshalExtended(data, obj)
+	}
 	return msgpack.Unmarshal(data, obj, nil)
 }
 
@@ -28,7 +76,22 @@ func (c *MsgpackCodec) FileExtension() string {
 	return constants.FileExtensionMsgpack
 }
 
+// Aliases returns the other content types this codec handles.
+func (c *MsgpackCodec) Aliases() []string {
+	return []string{constants.ContentTypeMsgpackAlias}
+}
+
 // CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
 func (c *MsgpackCodec) CanMarshalWithCallback() bool {
 	return false
 }
+
+// Binary returns whether this codec's representation is binary.
+func (c *MsgpackCodec) Binary() bool {
+	return true
+}
+
+// BatchMode returns how this codec marshals several objects into one body.
+func (c *MsgpackCodec) BatchMode() codecs.BatchMode {
+	return codecs.BatchArray
+}
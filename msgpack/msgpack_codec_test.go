@@ -5,11 +5,13 @@ import (
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestInterface(t *testing.T) {
 
 	assert.Implements(t, (*codecs.Codec)(nil), new(MsgpackCodec), "MsgpackCodec")
+	assert.Implements(t, (*codecs.UnmarshalOptionsCodec)(nil), new(MsgpackCodec), "MsgpackCodec")
 
 }
 
@@ -32,6 +34,153 @@ func TestMarshal(t *testing.T) {
 
 }
 
+func TestMarshal_SortKeys(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	obj := map[string]interface{}{"b": "y", "a": []interface{}{map[string]string{"d": "w", "c": "v"}}}
+	options := map[string]interface{}{constants.OptionKeySortKeys: true}
+
+	expectedResult := []byte{0x82, 0xa1, 'a', 0x91, 0x82, 0xa1, 'c', 0xa1, 'v', 0xa1, 'd', 0xa1, 'w', 0xa1, 'b', 0xa1, 'y'}
+
+	for i := 0; i < 10; i++ {
+		packed, err := codec.Marshal(obj, options)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expectedResult, packed)
+		}
+	}
+
+}
+
+func TestMarshal_RawMessage(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	// {"name":"Mat"} already encoded
+	raw := codecs.NewRawMessage(constants.ContentTypeMsgpack, []byte{0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'M', 'a', 't'})
+
+	packed, err := codec.Marshal([]interface{}{raw}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, append([]byte{0x91}, raw.Data()...), packed)
+	}
+
+	var object struct {
+		Person codecs.RawMessage
+	}
+	object.Person = raw
+
+	packed, err = codec.Marshal(&object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, append([]byte{0x81, 0xa6, 'P', 'e', 'r', 's', 'o', 'n'}, raw.Data()...), packed)
+	}
+
+}
+
+func TestMarshal_EncodingChoices(t *testing.T) {
+
+	codec := &MsgpackCodec{BinBytes: true, Timestamps: true, StructsAsArrays: true}
+
+	type record struct {
+		Data []byte
+		At   time.Time
+	}
+
+	object := record{Data: []byte{1, 2}, At: time.Unix(1, 0).UTC()}
+	expectedResult := []byte{0x92, 0xc4, 0x02, 0x01, 0x02, 0xd6, 0xff, 0x00, 0x00, 0x00, 0x01}
+
+	packed, err := codec.Marshal(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedResult, packed)
+	}
+
+	var decoded record
+	if assert.NoError(t, codec.Unmarshal(packed, &decoded)) {
+		assert.Equal(t, object, decoded)
+	}
+
+	// the options override the codec
+	packed, err = new(MsgpackCodec).Marshal([]byte{1}, map[string]interface{}{constants.OptionKeyBinBytes: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0xc4, 0x01, 0x01}, packed)
+	}
+
+	// and read back what they wrote
+	options := map[string]interface{}{constants.OptionKeyBinBytes: true, constants.OptionKeyTimestamps: true, constants.OptionKeyStructsAsArrays: true}
+	packed, err = new(MsgpackCodec).Marshal(object, options)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedResult, packed)
+
+		var decoded record
+		if assert.NoError(t, new(MsgpackCodec).UnmarshalWithOptions(packed, &decoded, options)) {
+			assert.Equal(t, object, decoded)
+		}
+	}
+
+}
+
+func TestMarshal_StructTags(t *testing.T) {
+
+	type tagged struct {
+		Name   string `msgpack:"name"`
+		Secret string `msgpack:"-"`
+		Note   string `msgpack:",omitempty"`
+		Data   []byte `msgpack:"data"`
+	}
+
+	object := tagged{Name: "Mat", Secret: "shh", Data: []byte{1}}
+	expected := tagged{Name: "Mat", Data: []byte{1}}
+
+	codec := &MsgpackCodec{BinBytes: true}
+	packed, err := codec.Marshal(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa3, 'M', 'a', 't', 0xa4, 'd', 'a', 't', 'a', 0xc4, 0x01, 0x01}, packed)
+
+		var decoded tagged
+		if assert.NoError(t, codec.Unmarshal(packed, &decoded)) {
+			assert.Equal(t, expected, decoded)
+		}
+	}
+
+	// omitempty doesn't apply to structs written as arrays
+	codec = &MsgpackCodec{BinBytes: true, StructsAsArrays: true}
+	packed, err = codec.Marshal(object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x93, 0xa3, 'M', 'a', 't', 0xa0, 0xc4, 0x01, 0x01}, packed)
+
+		var decoded tagged
+		if assert.NoError(t, codec.Unmarshal(packed, &decoded)) {
+			assert.Equal(t, expected, decoded)
+		}
+	}
+
+}
+
+func TestMarshal_Timestamps(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+	options := map[string]interface{}{constants.OptionKeyTimestamps: true}
+
+	for _, moment := range []time.Time{time.Unix(1, 0), time.Unix(1, 5), time.Unix(-1, 0), time.Unix(1<<35, 999999999)} {
+
+		packed, err := codec.Marshal(moment, options)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		var decoded time.Time
+		if assert.NoError(t, codec.UnmarshalWithOptions(packed, &decoded, options)) {
+			assert.True(t, moment.Equal(decoded), "%s != %s", moment, decoded)
+		}
+	}
+
+	packed, _ := codec.Marshal(time.Unix(1, 5), options)
+	assert.Equal(t, []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x01}, packed)
+
+	packed, _ = codec.Marshal(time.Unix(-1, 0), options)
+	assert.Equal(t, []byte{0xc7, 0x0c, 0xff, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, packed)
+
+}
+
 func TestUnmarshal(t *testing.T) {
 
 	codec := new(MsgpackCodec)
@@ -55,6 +204,13 @@ func TestResponseContentType(t *testing.T) {
 
 }
 
+func TestAliases(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+	assert.Equal(t, []string{constants.ContentTypeMsgpackAlias}, codec.Aliases())
+
+}
+
 func TestFileExtension(t *testing.T) {
 
 	codec := new(MsgpackCodec)
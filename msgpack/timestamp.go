@@ -0,0 +1,62 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"time"
+)
+
+// timestampCode is the type code of the timestamp extension the Msgpack
+// specification defines.
+const timestampCode = -1
+
+// ErrorInvalidTimestamp is returned when unmarshalling a timestamp extension
+// whose data isn't 4, 8 or 12 bytes long.
+var ErrorInvalidTimestamp = errors.New("codecs: msgpack: Invalid timestamp")
+
+// timeType is the type of time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeTimestamp gets the data of the timestamp extension for the time, in
+// the smallest of the 32, 64 and 96 bit forms it fits.
+func encodeTimestamp(t time.Time) []byte {
+
+	seconds, nanoseconds := t.Unix(), uint32(t.Nanosecond())
+
+	if seconds >= 0 && seconds < 1<<34 {
+
+		if nanoseconds == 0 && seconds <= math.MaxUint32 {
+			data := make([]byte, 4)
+			binary.BigEndian.PutUint32(data, uint32(seconds))
+			return data
+		}
+
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(nanoseconds)<<34|uint64(seconds))
+		return data
+	}
+
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data, nanoseconds)
+	binary.BigEndian.PutUint64(data[4:], uint64(seconds))
+	return data
+}
+
+// decodeTimestamp gets the time, in UTC, from the data of the timestamp
+// extension.
+func decodeTimestamp(data []byte) (interface{}, error) {
+
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		value := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(value&(1<<34-1)), int64(value>>34)).UTC(), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))).UTC(), nil
+	}
+
+	return nil, ErrorInvalidTimestamp
+}
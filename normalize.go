@@ -0,0 +1,270 @@
+package codecs
+
+import (
+	"context"
+	"encoding"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"time"
+)
+
+// normalization is how public data is rewritten after it is resolved, as
+// given by the options.
+type normalization struct {
+	empty    EmptyPolicy
+	keyCase  KeyCase
+	text     bool
+	time     timeFormat
+	ints     bool
+	adapters map[reflect.Type]Adapter
+}
+
+// normalizationFor gets the normalization given by the options, and the
+// registered adapters.
+func normalizationFor(options map[string]interface{}) normalization {
+	text, _ := options[constants.OptionKeyTextMarshalers].(bool)
+	ints, _ := options[constants.OptionKeyIntsAsStrings].(bool)
+	return normalization{empty: emptyPolicy(options), keyCase: keyCase(options), text: text, time: timeFormatFor(options), ints: ints, adapters: registeredAdapters()}
+}
+
+// isZero gets whether the normalization changes nothing.
+func (n normalization) isZero() bool {
+	return n.empty == EmptyAsNull && n.keyCase == KeyCaseAsIs && !n.text && n.time == (timeFormat{}) && !n.ints && len(n.adapters) == 0
+}
+
+// adaptsOnly gets whether the normalization only applies the registered
+// adapters, leaving values that don't hold adapted ones as they are.
+func (n normalization) adaptsOnly() bool {
+	return len(n.adapters) > 0 && n.empty == EmptyAsNull && n.keyCase == KeyCaseAsIs && !n.text && n.time == (timeFormat{}) && !n.ints
+}
+
+// holdsAdapted gets whether the value is, or holds, a value of a type with a
+// registered adapter.
+func (n normalization) holdsAdapted(value reflect.Value, level int) bool {
+
+	// let apply report too much recursion
+	if level > facadeMaxRecursionLevel {
+		return true
+	}
+
+	if !value.IsValid() || isNil(value) {
+		return false
+	}
+
+	if _, ok := n.adapters[value.Type()]; ok {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return n.holdsAdapted(value.Elem(), level+1)
+
+	case reflect.Array, reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		for i := 0; i < value.Len(); i++ {
+			if n.holdsAdapted(value.Index(i), level+1) {
+				return true
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			if n.holdsAdapted(value.MapIndex(key), level+1) {
+				return true
+			}
+		}
+
+	case reflect.Struct:
+		for _, field := range typeInfoFor(value.Type()).fields {
+			if len(field.name) == 0 {
+				continue
+			}
+			if fieldValue, ok := field.fieldValue(value); ok && n.holdsAdapted(fieldValue, level+1) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// resolvePublicData gets the public data of the object, applying the
+// registered adapters, and the empty policy, key case, time format, large
+// integers as strings and marshalling of text and binary marshalers given by
+// the options.
+func resolvePublicData(ctx context.Context, object interface{}, options map[string]interface{}) (interface{}, error) {
+
+	data, err := publicData(ctx, object, 0, options)
+	if err != nil {
+		return nil, err
+	}
+
+	n := normalizationFor(options)
+	if n.isZero() {
+		return data, nil
+	}
+
+	return n.apply(reflectValueOf(data), 0)
+}
+
+// apply copies the value with the normalization applied, converting structs
+// with exported fields into map[string]interface{}s (as PublicData does) so
+// that their fields are covered too.
+func (n normalization) apply(value reflect.Value, level int) (interface{}, error) {
+
+	// make sure we don't end up with too much recursion
+	if level > facadeMaxRecursionLevel {
+		return nil, PublicDataTooMuchRecursion
+	}
+
+	if n.time != (timeFormat{}) && typeOf(value) == timeType {
+		return n.time.format(value.Interface().(time.Time)), nil
+	}
+
+	if adapter, ok := n.adapters[typeOf(value)]; ok && !isNil(value) {
+		return adapter(value.Interface())
+	}
+
+	// leave values without adapted ones to the codecs, which have their own
+	// struct tags
+	if n.adaptsOnly() && value.IsValid() && !isNil(value) && !n.holdsAdapted(value, level) {
+		return value.Interface(), nil
+	}
+
+	if n.text && value.IsValid() && !isNil(value) {
+		if marshalled, ok, err := marshalText(value); ok {
+			return marshalled, err
+		}
+	}
+
+	if n.ints {
+		if integer, ok := safeInteger(value); ok {
+			return integer, nil
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return n.apply(value.Elem(), level+1)
+
+	case reflect.Array, reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			// leave bytes to the codecs
+			return value.Interface(), nil
+		}
+		if value.Kind() == reflect.Slice && value.IsNil() && n.empty != EmptyAsCollection {
+			return value.Interface(), nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			item, err := n.apply(value.Index(i), level+1)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+
+	case reflect.Map:
+		if value.IsNil() && n.empty != EmptyAsCollection {
+			return value.Interface(), nil
+		}
+		mapType := value.Type()
+		if mapType.Elem() != interfaceType {
+			mapType = reflect.MapOf(mapType.Key(), interfaceType)
+		}
+		copied := reflect.MakeMapWithSize(mapType, value.Len())
+		for _, key := range value.MapKeys() {
+			item := value.MapIndex(key)
+			if n.empty == EmptyOmit && isEmpty(item) {
+				continue
+			}
+			public, err := n.apply(item, level+1)
+			if err != nil {
+				return nil, err
+			}
+			if key.Kind() == reflect.String {
+				key = reflect.ValueOf(n.keyCase.Convert(key.String())).Convert(key.Type())
+			}
+			copied.SetMapIndex(key, reflectValueOf(public))
+		}
+		return copied.Interface(), nil
+
+	case reflect.Struct:
+		info := typeInfoFor(value.Type())
+		if len(info.fields) == 0 {
+			// such as time.Time, which codecs know how to marshal
+			return value.Interface(), nil
+		}
+		fields := make(map[string]interface{}, len(info.fields))
+		for _, field := range info.fields {
+			if len(field.name) == 0 {
+				continue
+			}
+			fieldValue, ok := field.jsonValue(value)
+			if !ok || (n.empty == EmptyOmit && isEmpty(fieldValue)) {
+				continue
+			}
+			public, err := n.apply(fieldValue, level+1)
+			if err != nil {
+				return nil, err
+			}
+			fields[n.keyCase.Convert(field.name)] = public
+		}
+		return fields, nil
+	}
+
+	return value.Interface(), nil
+}
+
+// textMarshalerType and binaryMarshalerType are the types of the
+// encoding.TextMarshaler and encoding.BinaryMarshaler interfaces.
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+// marshalText marshals the value as a string if it (or a pointer to it) is an
+// encoding.TextMarshaler, or else as []byte if it is an
+// encoding.BinaryMarshaler, getting whether it is either.
+func marshalText(value reflect.Value) (interface{}, bool, error) {
+
+	info := typeInfoFor(value.Type())
+	if !info.textMarshaler && !info.binaryMarshaler {
+		return nil, false, nil
+	}
+
+	// use a pointer to (a copy of) the value for pointer receivers
+	marshaler := func(marshalerType reflect.Type) interface{} {
+		if value.Type().Implements(marshalerType) {
+			return value.Interface()
+		}
+		copied := reflect.New(value.Type())
+		copied.Elem().Set(value)
+		return copied.Interface()
+	}
+
+	if info.textMarshaler {
+		text, err := marshaler(textMarshalerType).(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+
+	data, err := marshaler(binaryMarshalerType).(encoding.BinaryMarshaler).MarshalBinary()
+	return data, true, err
+}
+
+// typeOf gets the type of the value, or nil if it is invalid.
+func typeOf(value reflect.Value) reflect.Type {
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Type()
+}
@@ -0,0 +1,80 @@
+package codecs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+// uuid marshals itself as text.
+type uuid [2]byte
+
+func (u uuid) MarshalText() ([]byte, error) {
+	return []byte{'0' + u[0], '-', '0' + u[1]}, nil
+}
+
+// decimal marshals itself as text with a pointer receiver.
+type decimal struct {
+	units, cents int
+}
+
+func (d *decimal) MarshalText() ([]byte, error) {
+	if d.cents > 99 {
+		return nil, errors.New("too many cents")
+	}
+	return []byte(fmt.Sprintf("%d.%02d", d.units, d.cents)), nil
+}
+
+// blob only marshals itself as binary.
+type blob struct {
+	data string
+}
+
+func (b blob) MarshalBinary() ([]byte, error) {
+	return []byte(b.data), nil
+}
+
+// invoice holds values that marshal themselves.
+type invoice struct {
+	ID      uuid      `json:"id"`
+	Total   decimal   `json:"total"`
+	Server  net.IP    `json:"server"`
+	Created time.Time `json:"created"`
+	Extra   blob      `json:"extra"`
+}
+
+func TestPublicData_TextMarshalers(t *testing.T) {
+
+	created := time.Date(2014, 1, 2, 3, 4, 5, 0, time.UTC)
+	object := &invoice{ID: uuid{1, 2}, Total: decimal{3, 45}, Server: net.IPv4(10, 0, 0, 1), Created: created, Extra: blob{"raw"}}
+
+	// values are left as they are by default
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(object))
+	}
+
+	public, err = PublicData(object, Options{TextMarshalers: true}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"id":      "1-2",
+			"total":   "3.45",
+			"server":  "10.0.0.1",
+			"created": "2014-01-02T03:04:05Z",
+			"extra":   []byte("raw"),
+		}, public)
+	}
+
+	public, err = PublicData(map[string]interface{}{"ids": []uuid{{1, 1}}}, map[string]interface{}{constants.OptionKeyTextMarshalers: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"ids": []interface{}{"1-1"}}, public)
+	}
+
+	_, err = PublicData(&invoice{Total: decimal{1, 100}}, Options{TextMarshalers: true}.Map())
+	assert.EqualError(t, err, "too many cents")
+
+}
@@ -0,0 +1,48 @@
+package codecs
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// NumberMode says what codecs that can (such as JSON) decode numbers into when
+// unmarshalling into interface{} values, whose numbers are otherwise float64s
+// that can't hold every int64.  Give it with the constants.OptionKeyNumbers
+// option.
+type NumberMode int
+
+const (
+	// NumbersAsFloat decodes numbers into float64s.  It is the default.
+	NumbersAsFloat NumberMode = iota
+
+	// NumbersAsJSONNumber decodes numbers into json.Numbers, which keep
+	// their text.
+	NumbersAsJSONNumber
+
+	// NumbersAsInt64 decodes whole numbers into int64s where they fit, and
+	// others into float64s.
+	NumbersAsInt64
+)
+
+// maxSafeInteger is the largest integer JavaScript numbers hold exactly.
+const maxSafeInteger = 1<<53 - 1
+
+// safeInteger gets the integer as a string if it is too large for JavaScript
+// numbers to hold exactly, getting whether it is an integer.
+func safeInteger(value reflect.Value) (interface{}, bool) {
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := value.Int(); i > maxSafeInteger || i < -maxSafeInteger {
+			return strconv.FormatInt(i, 10), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := value.Uint(); u > maxSafeInteger {
+			return strconv.FormatUint(u, 10), true
+		}
+	default:
+		return nil, false
+	}
+
+	return value.Interface(), true
+}
@@ -0,0 +1,42 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// ledger holds integers too large for JavaScript numbers.
+type ledger struct {
+	ID      int64   `json:"id"`
+	Balance uint64  `json:"balance"`
+	Count   int     `json:"count"`
+	Debts   []int64 `json:"debts"`
+}
+
+func TestPublicData_IntsAsStrings(t *testing.T) {
+
+	object := &ledger{ID: 9007199254740993, Balance: 18446744073709551615, Count: 3, Debts: []int64{-9007199254740993, 12}}
+
+	// integers are left as they are by default
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(object))
+	}
+
+	public, err = PublicData(object, Options{IntsAsStrings: true}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"id":      "9007199254740993",
+			"balance": "18446744073709551615",
+			"count":   3,
+			"debts":   []interface{}{"-9007199254740993", int64(12)},
+		}, public)
+	}
+
+	public, err = PublicData(int64(9007199254740991), map[string]interface{}{constants.OptionKeyIntsAsStrings: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(9007199254740991), public)
+	}
+
+}
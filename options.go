@@ -0,0 +1,215 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+)
+
+// Options holds the common marshalling and unmarshalling options as typed fields, so that they
+// can't be misspelled.  Use Map to get the options map codecs take.
+type Options struct {
+
+	// Indent is the indentation for codecs that can indent their output (such
+	// as JSON), or empty for compact output.
+	Indent string
+
+	// Pretty makes codecs that can indent their output do so with their own
+	// indentation, if Indent isn't given.
+	Pretty bool
+
+	// Canonical makes codecs that can (such as JSON) marshal canonically,
+	// so that payloads that are signed or hashed always marshal to the same
+	// bytes.  Canonical output is never indented.
+	Canonical bool
+
+	// NoHTMLEscape makes codecs that escape <, > and & for embedding in
+	// HTML (such as JSON) leave them as they are, so that URLs and markup
+	// are marshalled verbatim.
+	NoHTMLEscape bool
+
+	// Callback is the name of the callback function for codecs that can
+	// marshal with a callback (such as JSONP).
+	Callback string
+
+	// Context is the client context passed to the callback.
+	Context string
+
+	// Fields are the only fields of maps (including maps in slices) to
+	// marshal, or empty for all fields.  Fields of nested maps are given by
+	// paths such as "author.name".
+	Fields []string
+
+	// Charset is the charset to transcode textual output into, or empty for
+	// UTF-8.
+	Charset string
+
+	// Language is the client's language tag, for codecs that localize their
+	// output.
+	Language string
+
+	// Strict makes codecs that can (such as JSON) refuse data with fields the
+	// object being unmarshalled into doesn't have.
+	Strict bool
+
+	// NoDuplicateKeys makes codecs that can (such as JSON) refuse data with
+	// objects that have the same key more than once, which parsers could
+	// otherwise disagree about.
+	NoDuplicateKeys bool
+
+	// Version is the API version to marshal the public data of (see
+	// VersionedFacade), or empty for the default.
+	Version string
+
+	// StructTags builds the public data of structs with codec struct tags
+	// from their tags, such as `codec:"name,omitempty"` (see PublicData).
+	StructTags bool
+
+	// Empty says what to do with nil and empty values in public data (see
+	// EmptyPolicy).
+	Empty EmptyPolicy
+
+	// KeyCase is the case to convert the keys of public data into, and to
+	// convert keys from when unmarshalling (see KeyCase).
+	KeyCase KeyCase
+
+	// FacadeErrors says what to do when getting the public data of an
+	// element of an array or slice fails (see FacadeErrorPolicy).
+	FacadeErrors FacadeErrorPolicy
+
+	// ElementErrors collects the errors of the elements FacadeErrors skips
+	// or replaces, if it is set.
+	ElementErrors *ElementErrors
+
+	// TextMarshalers marshals values implementing encoding.TextMarshaler
+	// (such as UUIDs and decimals) as their text, and others implementing
+	// encoding.BinaryMarshaler as their bytes, in public data, so that every
+	// codec represents them as encoding/json does.
+	TextMarshalers bool
+
+	// TimeLayout is the layout (such as time.RFC3339) to format times in
+	// public data with, and TimeUTC converts them into UTC first, so that
+	// every codec gives times the same way.  TimeUnix gives them as numbers
+	// instead (see UnixTime).
+	TimeLayout string
+	TimeUTC    bool
+	TimeUnix   UnixTime
+
+	// Numbers is what codecs that can (such as JSON) decode numbers into
+	// when unmarshalling into interface{} values (see NumberMode).
+	Numbers NumberMode
+
+	// IntsAsStrings gives integers in public data too large for JavaScript
+	// numbers to hold exactly (such as int64 IDs) as strings.
+	IntsAsStrings bool
+
+	// SortKeys makes codecs that can (such as XML and Msgpack) marshal the
+	// keys of maps in sorted order, so that the same object always marshals
+	// to the same bytes.  JSON always sorts the keys of maps, and the fields
+	// of structs keep the order they are declared in.
+	SortKeys bool
+
+	// Roles are the roles of the client, deciding which struct fields appear
+	// in public data (see PublicData).
+	Roles []string
+
+	// Extra holds any other options, by key.
+	Extra map[string]interface{}
+}
+
+// Map gets the options as an options map, keyed by the constants.OptionKey
+// keys.  Options that aren't set are left out, and Extra options are copied
+// in as they are (without overriding the typed fields).
+func (o Options) Map() map[string]interface{} {
+
+	options := map[string]interface{}{}
+	for key, value := range o.Extra {
+		options[key] = value
+	}
+
+	set := func(key, value string) {
+		if len(value) > 0 {
+			options[key] = value
+		}
+	}
+
+	set(constants.OptionKeyIndent, o.Indent)
+	set(constants.OptionKeyClientCallback, o.Callback)
+	set(constants.OptionKeyClientContext, o.Context)
+	set(constants.OptionKeyCharset, o.Charset)
+	set(constants.OptionKeyClientLanguage, o.Language)
+	set(constants.OptionKeyVersion, o.Version)
+	set(constants.OptionKeyTimeLayout, o.TimeLayout)
+
+	if len(o.Fields) > 0 {
+		options[constants.OptionKeyFields] = o.Fields
+	}
+
+	if len(o.Roles) > 0 {
+		options[constants.OptionKeyRoles] = o.Roles
+	}
+
+	if o.NoDuplicateKeys {
+		options[constants.OptionKeyNoDuplicateKeys] = true
+	}
+
+	if o.Pretty {
+		options[constants.OptionKeyPretty] = true
+	}
+
+	if o.Canonical {
+		options[constants.OptionKeyCanonical] = true
+	}
+
+	if o.NoHTMLEscape {
+		options[constants.OptionKeyNoHTMLEscape] = true
+	}
+
+	if o.Strict {
+		options[constants.OptionKeyStrict] = true
+	}
+
+	if o.StructTags {
+		options[constants.OptionKeyStructTags] = true
+	}
+
+	if o.TextMarshalers {
+		options[constants.OptionKeyTextMarshalers] = true
+	}
+
+	if o.TimeUTC {
+		options[constants.OptionKeyTimeUTC] = true
+	}
+
+	if o.TimeUnix != UnixTimeNone {
+		options[constants.OptionKeyTimeUnix] = o.TimeUnix
+	}
+
+	if o.Numbers != NumbersAsFloat {
+		options[constants.OptionKeyNumbers] = o.Numbers
+	}
+
+	if o.IntsAsStrings {
+		options[constants.OptionKeyIntsAsStrings] = true
+	}
+
+	if o.SortKeys {
+		options[constants.OptionKeySortKeys] = true
+	}
+
+	if o.Empty != EmptyAsNull {
+		options[constants.OptionKeyEmpty] = o.Empty
+	}
+
+	if o.KeyCase != KeyCaseAsIs {
+		options[constants.OptionKeyKeyCase] = o.KeyCase
+	}
+
+	if o.FacadeErrors != FacadeErrorFail {
+		options[constants.OptionKeyFacadeErrors] = o.FacadeErrors
+	}
+
+	if o.ElementErrors != nil {
+		options[constants.OptionKeyElementErrors] = o.ElementErrors
+	}
+
+	return options
+}
@@ -0,0 +1,31 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOptions_Map(t *testing.T) {
+
+	options := Options{
+		Indent:   "  ",
+		Callback: "cb",
+		Fields:   []string{"name"},
+		Extra:    map[string]interface{}{"html.title": "People", constants.OptionKeyIndent: "\t"},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		constants.OptionKeyIndent:         "  ",
+		constants.OptionKeyClientCallback: "cb",
+		constants.OptionKeyFields:         []string{"name"},
+		"html.title":                      "People",
+	}, options.Map())
+
+	assert.Equal(t, map[string]interface{}{}, Options{}.Map())
+
+	assert.Equal(t, map[string]interface{}{constants.OptionKeyStrict: true}, Options{Strict: true}.Map())
+
+	assert.Equal(t, map[string]interface{}{constants.OptionKeySortKeys: true}, Options{SortKeys: true}.Map())
+
+}
@@ -0,0 +1,8 @@
+// Adapts OpenTelemetry tracing to codec services, so that negotiating,
+// marshalling and unmarshalling show up as spans in distributed traces:
+//
+//	service.SetTracer(otelcodecs.NewTracer(otel.GetTracerProvider()))
+//
+// Spans are started by the context aware methods of services.WebCodecService
+// (such as MarshalWithCodecContext), as children of the span in the context.
+package otelcodecs
@@ -0,0 +1,65 @@
+package otelcodecs
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/codecs/services"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer spans are started with.
+const InstrumentationName = "github.com/stretchr/codecs"
+
+// tracer is a services.Tracer starting OpenTelemetry spans.
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer makes a services.Tracer starting spans with the TracerProvider.
+func NewTracer(provider trace.TracerProvider) services.Tracer {
+	return &tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+// Start starts a span as a child of any span in the context.
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, services.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, &otelSpan{span}
+}
+
+// otelSpan is a services.Span wrapping an OpenTelemetry span.
+type otelSpan struct {
+	span trace.Span
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *otelSpan) SetAttribute(key string, value interface{}) {
+	s.span.SetAttributes(keyValue(key, value))
+}
+
+// RecordError records the error and marks the span as failed.
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End ends the span.
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// keyValue makes the attribute for the value.
+func keyValue(key string, value interface{}) attribute.KeyValue {
+	switch value := value.(type) {
+	case string:
+		return attribute.String(key, value)
+	case int64:
+		return attribute.Int64(key, value)
+	case int:
+		return attribute.Int(key, value)
+	case bool:
+		return attribute.Bool(key, value)
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
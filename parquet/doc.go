@@ -0,0 +1,34 @@
+// A codec for handling Apache Parquet encoding and decoding.
+//
+// Marshal writes a single row group Parquet file from a slice (or array) of
+// structs, with one column per exported field.  The schema comes from the
+// fields' types and their `parquet` struct tags:
+//
+//	type Export struct {
+//		ID      int64      `parquet:"id"`
+//		Name    string     `parquet:"name"`
+//		Deleted *time.Time `parquet:"deleted_at"` // pointers are optional columns
+//		Secret  string     `parquet:"-"`          // skipped
+//	}
+//
+// Types map to Parquet as follows:
+//
+//	bool                        BOOLEAN
+//	int8, int16, int32          INT32 (uint8 and uint16 too)
+//	int, int64, uint32          INT64
+//	uint, uint64                INT64 (UINT_64)
+//	float32, float64            FLOAT, DOUBLE
+//	string                      BYTE_ARRAY (UTF8)
+//	[]byte                      BYTE_ARRAY
+//	time.Time                   INT64 (TIMESTAMP_MICROS)
+//
+// Slices of maps (such as facade public data) are also supported, in which
+// case the columns are the sorted keys, they are all optional and their types
+// come from the values.  Int and float values in maps are written as INT64 and
+// DOUBLE.
+//
+// Values are PLAIN encoded and uncompressed.  Unmarshal reads flat files using
+// those encodings (such as the ones written by Marshal) into a slice of
+// structs, or a []map[string]interface{} when unmarshalling into an
+// interface{}.
+package parquet
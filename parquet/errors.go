@@ -0,0 +1,37 @@
+package parquet
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrorNotParquet is returned when unmarshalling data that isn't a Parquet
+	// file.
+	ErrorNotParquet = errors.New("codecs: parquet: Data is not a Parquet file")
+
+	// ErrorNotTabular is returned when marshalling something other than a
+	// slice of structs or maps.
+	ErrorNotTabular = errors.New("codecs: parquet: Only slices of structs or maps can be marshalled")
+
+	// ErrorUnexpectedEnd is returned when the data ends part way through a
+	// structure.
+	ErrorUnexpectedEnd = errors.New("codecs: parquet: Unexpected end of data")
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: parquet: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: parquet: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: parquet: Unmarshal(nil " + e.Type.String() + ")"
+}
@@ -0,0 +1,155 @@
+package parquet
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"time"
+)
+
+// ParquetCodec converts slices of structs to and from Parquet files.
+type ParquetCodec struct{}
+
+func init() {
+	codecs.Register(new(ParquetCodec))
+}
+
+// Marshal converts a slice of structs (or maps) to a Parquet file.
+func (c *ParquetCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return marshal(object)
+}
+
+// Unmarshal converts a Parquet file into a slice of structs or maps.
+func (c *ParquetCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	rows, err := unmarshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	switch target := obj.(type) {
+	case *interface{}:
+		*target = rows
+		return nil
+	case *[]map[string]interface{}:
+		*target = rows
+		return nil
+	}
+
+	target := rv.Elem()
+
+	switch target.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(target.Type(), len(rows), len(rows))
+		for i, row := range rows {
+			if err := assignRow(slice.Index(i), row); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+	case reflect.Struct:
+		if len(rows) > 0 {
+			return assignRow(target, rows[0])
+		}
+		return nil
+	}
+
+	return fmt.Errorf("codecs: parquet: Cannot unmarshal into %s", target.Type())
+}
+
+// assignRow sets the struct's fields from the row.
+func assignRow(target reflect.Value, row map[string]interface{}) error {
+
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf("codecs: parquet: Cannot unmarshal rows into %s", target.Type())
+	}
+
+	for _, f := range structFields(target.Type()) {
+		if value, ok := row[f.name]; ok && value != nil {
+			if err := assignValue(target.Field(f.index), value); err != nil {
+				return fmt.Errorf("codecs: parquet: Column %q: %s", f.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// assignValue sets the field to the column value.
+func assignValue(target reflect.Value, value interface{}) error {
+
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch value.(type) {
+	case time.Time:
+		if target.Type() == timeType {
+			target.Set(v)
+			return nil
+		}
+	case string, []byte:
+		if target.Kind() == reflect.String || target.Type() == bytesType {
+			target.Set(v.Convert(target.Type()))
+			return nil
+		}
+	case bool:
+		if target.Kind() == reflect.Bool {
+			target.SetBool(value.(bool))
+			return nil
+		}
+	default:
+		if isNumber(target.Kind()) {
+			target.Set(v.Convert(target.Type()))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot unmarshal %T into %s", value, target.Type())
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the content type for this codec.
+func (c *ParquetCodec) ContentType() string {
+	return constants.ContentTypeParquet
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ParquetCodec) FileExtension() string {
+	return constants.FileExtensionParquet
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ParquetCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// Binary returns whether this codec's representation is binary.
+func (c *ParquetCodec) Binary() bool {
+	return true
+}
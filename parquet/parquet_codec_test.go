@@ -0,0 +1,249 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var parquetCodec ParquetCodec
+
+type exportRow struct {
+	ID      int64      `parquet:"id"`
+	Name    string     `parquet:"name"`
+	Score   float64    `parquet:"score"`
+	Ratio   float32    `parquet:"ratio"`
+	Small   int8       `parquet:"small"`
+	Count   uint64     `parquet:"count"`
+	Active  bool       `parquet:"active"`
+	Data    []byte     `parquet:"data"`
+	Created time.Time  `parquet:"created"`
+	Deleted *time.Time `parquet:"deleted_at"`
+	Note    *string    `parquet:"note"`
+	Secret  string     `parquet:"-"`
+	Plain   int32
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(ParquetCodec), "ParquetCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeParquet, parquetCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionParquet, parquetCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, parquetCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal_FileLayout(t *testing.T) {
+
+	data, err := parquetCodec.Marshal([]exportRow{{ID: 1, Name: "Mat"}}, nil)
+
+	if assert.NoError(t, err) {
+		assert.True(t, bytes.HasPrefix(data, []byte("PAR1")))
+		assert.True(t, bytes.HasSuffix(data, []byte("PAR1")))
+
+		metadataLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		metadata, err := (&thriftDecoder{data: data[len(data)-8-metadataLength : len(data)-8]}).readStruct()
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(1), metadata[1])
+			assert.Equal(t, int64(1), metadata[3])
+			assert.Equal(t, createdBy, thriftString(metadata, 6))
+
+			// the root plus a leaf for each column
+			schema := metadata[2].([]interface{})
+			assert.Equal(t, 13, len(schema))
+			assert.Equal(t, int64(12), thriftInt(schema[0].(map[int16]interface{}), 5))
+
+			name := schema[2].(map[int16]interface{})
+			assert.Equal(t, "name", thriftString(name, 4))
+			assert.Equal(t, int64(typeByteArray), thriftInt(name, 1))
+			assert.Equal(t, int64(convertedUTF8), thriftInt(name, 6))
+			assert.Equal(t, int64(repetitionRequired), thriftInt(name, 3))
+
+			deleted := schema[10].(map[int16]interface{})
+			assert.Equal(t, "deleted_at", thriftString(deleted, 4))
+			assert.Equal(t, int64(convertedTimestampUs), thriftInt(deleted, 6))
+			assert.Equal(t, int64(repetitionOptional), thriftInt(deleted, 3))
+		}
+	}
+
+}
+
+func TestRoundTrip_Structs(t *testing.T) {
+
+	deleted := time.Date(2013, 5, 1, 9, 0, 0, 123000, time.UTC)
+	note := "hello"
+	rows := []exportRow{
+		{ID: 1, Name: "Mat", Score: 1.5, Ratio: 0.25, Small: -3, Count: 1 << 63, Active: true, Data: []byte{1, 2},
+			Created: time.Date(2013, 4, 1, 12, 0, 0, 0, time.UTC), Deleted: &deleted, Note: &note, Secret: "s", Plain: 7},
+		{ID: 2, Name: "Tyler", Created: time.Date(2013, 4, 2, 12, 0, 0, 0, time.UTC)},
+		{ID: 3, Name: "Ryan", Active: true, Note: &note},
+	}
+
+	data, err := parquetCodec.Marshal(rows, nil)
+
+	if assert.NoError(t, err) {
+
+		var back []exportRow
+		if assert.NoError(t, parquetCodec.Unmarshal(data, &back)) {
+			if assert.Equal(t, 3, len(back)) {
+				rows[0].Secret = ""
+				assert.Equal(t, rows[0].Created, back[0].Created)
+				assert.True(t, deleted.Equal(*back[0].Deleted))
+				back[0].Deleted, rows[0].Deleted = nil, nil
+				assert.Equal(t, rows[0], back[0])
+				assert.Nil(t, back[1].Deleted)
+				assert.Nil(t, back[1].Note)
+				assert.Equal(t, "hello", *back[2].Note)
+				assert.Equal(t, true, back[2].Active)
+				assert.Equal(t, "Tyler", back[1].Name)
+			}
+		}
+
+		var generic interface{}
+		if assert.NoError(t, parquetCodec.Unmarshal(data, &generic)) {
+			generics := generic.([]map[string]interface{})
+			assert.Equal(t, int64(2), generics[1]["id"])
+			assert.Equal(t, int32(-3), generics[0]["small"])
+			assert.Equal(t, uint64(1<<63), generics[0]["count"])
+			assert.Nil(t, generics[1]["note"])
+			assert.Equal(t, "hello", generics[2]["note"])
+		}
+	}
+
+}
+
+func TestRoundTrip_Maps(t *testing.T) {
+
+	rows := []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30, "score": 1.5},
+		map[string]interface{}{"name": "Tyler", "age": int64(12), "admin": true},
+	}
+
+	data, err := parquetCodec.Marshal(rows, nil)
+
+	if assert.NoError(t, err) {
+		var back []map[string]interface{}
+		if assert.NoError(t, parquetCodec.Unmarshal(data, &back)) {
+			assert.Equal(t, []map[string]interface{}{
+				{"admin": nil, "age": int64(30), "name": "Mat", "score": 1.5},
+				{"admin": true, "age": int64(12), "name": "Tyler", "score": nil},
+			}, back)
+		}
+	}
+
+}
+
+func TestMarshal_Empty(t *testing.T) {
+
+	data, err := parquetCodec.Marshal([]exportRow{}, nil)
+
+	if assert.NoError(t, err) {
+		var back []exportRow
+		if assert.NoError(t, parquetCodec.Unmarshal(data, &back)) {
+			assert.Equal(t, 0, len(back))
+		}
+	}
+
+}
+
+func TestMarshal_Errors(t *testing.T) {
+
+	_, err := parquetCodec.Marshal("nope", nil)
+	assert.Equal(t, ErrorNotTabular, err)
+
+	_, err = parquetCodec.Marshal([]interface{}{map[string]interface{}{"a": 1}, "nope"}, nil)
+	assert.Equal(t, ErrorNotTabular, err)
+
+	_, err = parquetCodec.Marshal([]map[string]interface{}{{"a": 1}, {"a": "one"}}, nil)
+	assert.Error(t, err)
+
+	_, err = parquetCodec.Marshal([]struct{ Tags []string }{{}}, nil)
+	assert.Error(t, err)
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var rows []exportRow
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal([]byte("not a parquet file"), &rows))
+
+	_, isInvalid := parquetCodec.Unmarshal(nil, rows).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
+
+// malformedFile writes a file with one required int64 column and one value,
+// but with the counts and sizes given instead of the real ones.
+func malformedFile(rows, groupRows, pageSize, pageValues int64) []byte {
+
+	var file bytes.Buffer
+	file.Write(magic)
+
+	var header bytes.Buffer
+	writeThrift(&header, new(thriftStructValue).
+		add(1, pageTypeData).
+		add(2, int32(8)).
+		add(3, pageSize).
+		add(5, new(thriftStructValue).
+			add(1, pageValues).
+			add(2, encodingPlain)))
+
+	offset := int64(file.Len())
+	file.Write(header.Bytes())
+	binary.Write(&file, binary.LittleEndian, int64(1))
+
+	chunk := new(thriftStructValue).
+		add(2, offset).
+		add(3, new(thriftStructValue).
+			add(1, typeInt64).
+			add(4, compressionUncompressed).
+			add(9, offset))
+
+	var metadata bytes.Buffer
+	writeThrift(&metadata, new(thriftStructValue).
+		add(1, int32(1)).
+		add(2, []*thriftStructValue{
+			new(thriftStructValue).add(4, "schema").add(5, int32(1)),
+			new(thriftStructValue).add(1, typeInt64).add(3, repetitionRequired).add(4, "id"),
+		}).
+		add(3, rows).
+		add(4, []*thriftStructValue{
+			new(thriftStructValue).add(1, []*thriftStructValue{chunk}).add(3, groupRows),
+		}))
+
+	file.Write(metadata.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(metadata.Len()))
+	file.Write(magic)
+
+	return file.Bytes()
+}
+
+func TestUnmarshal_MalformedCounts(t *testing.T) {
+
+	var rows []map[string]interface{}
+
+	if assert.NoError(t, parquetCodec.Unmarshal(malformedFile(1, 1, 8, 1), &rows)) {
+		assert.Equal(t, []map[string]interface{}{{"id": int64(1)}}, rows)
+	}
+
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(-1, 1, 8, 1), &rows))
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(1<<40, 1, 8, 1), &rows))
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(1, -1, 8, 1), &rows))
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(1, 1<<40, 8, 1), &rows))
+	assert.Equal(t, ErrorUnexpectedEnd, parquetCodec.Unmarshal(malformedFile(1, 1, -8, 1), &rows))
+	assert.Equal(t, ErrorUnexpectedEnd, parquetCodec.Unmarshal(malformedFile(1, 1, 1<<40, 1), &rows))
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(1, 1, 8, -1), &rows))
+	assert.Equal(t, ErrorNotParquet, parquetCodec.Unmarshal(malformedFile(1, 1, 8, 1<<40), &rows))
+
+}
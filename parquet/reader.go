@@ -0,0 +1,332 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// leaf describes a column of the file being read.
+type leaf struct {
+	name      string
+	physical  int32
+	converted int32
+	optional  bool
+}
+
+// unmarshal reads the rows of the Parquet file.
+func unmarshal(data []byte) ([]map[string]interface{}, error) {
+
+	if len(data) < 12 || !bytes.HasPrefix(data, magic) || !bytes.HasSuffix(data, magic) {
+		return nil, ErrorNotParquet
+	}
+
+	metadataLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metadataStart := len(data) - 8 - metadataLength
+
+	if metadataLength < 0 || metadataStart < len(magic) {
+		return nil, ErrorNotParquet
+	}
+
+	metadata, err := (&thriftDecoder{data: data[metadataStart : len(data)-8]}).readStruct()
+
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := readSchema(metadata)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowCount := thriftInt(metadata, 3)
+	if !fits(rowCount, data) {
+		return nil, ErrorNotParquet
+	}
+
+	rows := make([]map[string]interface{}, 0, rowCount)
+	rowGroups, _ := metadata[4].([]interface{})
+
+	for _, item := range rowGroups {
+
+		rowGroup, _ := item.(map[int16]interface{})
+		chunks, _ := rowGroup[1].([]interface{})
+
+		groupCount := thriftInt(rowGroup, 3)
+		if !fits(int64(len(rows))+groupCount, data) {
+			return nil, ErrorNotParquet
+		}
+		count := int(groupCount)
+
+		if len(chunks) != len(leaves) {
+			return nil, fmt.Errorf("codecs: parquet: Row group has %d columns, expected %d", len(chunks), len(leaves))
+		}
+
+		start := len(rows)
+		for i := 0; i < count; i++ {
+			rows = append(rows, make(map[string]interface{}, len(leaves)))
+		}
+
+		for i, item := range chunks {
+
+			chunk, _ := item.(map[int16]interface{})
+			values, err := readChunk(data, chunk, leaves[i], count)
+			if err != nil {
+				return nil, err
+			}
+
+			for j, value := range values {
+				rows[start+j][leaves[i].name] = value
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// fits gets whether the file can hold the number of values, none of which can
+// take less than a bit, so that counts read from malformed files are refused
+// before anything is allocated for them.
+func fits(count int64, data []byte) bool {
+	return count >= 0 && count <= 8*int64(len(data))
+}
+
+// readSchema gets the leaf columns from the file metadata.
+func readSchema(metadata map[int16]interface{}) ([]leaf, error) {
+
+	elements, _ := metadata[2].([]interface{})
+
+	if len(elements) == 0 {
+		return nil, ErrorNotParquet
+	}
+
+	var leaves []leaf
+	for _, item := range elements[1:] {
+
+		element, _ := item.(map[int16]interface{})
+		if thriftInt(element, 5) > 0 || thriftInt(element, 3) == int64(repetitionRepeated) {
+			return nil, fmt.Errorf("codecs: parquet: Nested and repeated columns are not supported")
+		}
+
+		converted := noConvertedType
+		if _, ok := element[6]; ok {
+			converted = int32(thriftInt(element, 6))
+		}
+
+		leaves = append(leaves, leaf{
+			name:      thriftString(element, 4),
+			physical:  int32(thriftInt(element, 1)),
+			converted: converted,
+			optional:  thriftInt(element, 3) == int64(repetitionOptional),
+		})
+	}
+
+	return leaves, nil
+}
+
+// readChunk reads the values of the column chunk.
+func readChunk(data []byte, chunk map[int16]interface{}, column leaf, count int) ([]interface{}, error) {
+
+	meta, _ := chunk[3].(map[int16]interface{})
+
+	if meta == nil {
+		return nil, fmt.Errorf("codecs: parquet: Column %q has no metadata", column.name)
+	}
+	if thriftInt(meta, 4) != int64(compressionUncompressed) {
+		return nil, fmt.Errorf("codecs: parquet: Column %q is compressed, which is not supported", column.name)
+	}
+	if _, ok := meta[11]; ok {
+		return nil, fmt.Errorf("codecs: parquet: Column %q is dictionary encoded, which is not supported", column.name)
+	}
+
+	pos := int(thriftInt(meta, 9))
+	values := make([]interface{}, 0, count)
+
+	for len(values) < count {
+
+		if pos < 0 || pos >= len(data) {
+			return nil, ErrorUnexpectedEnd
+		}
+
+		d := &thriftDecoder{data: data, pos: pos}
+		header, err := d.readStruct()
+		if err != nil {
+			return nil, err
+		}
+
+		size := thriftInt(header, 3)
+		if size < 0 || size > int64(len(data)-d.pos) {
+			return nil, ErrorUnexpectedEnd
+		}
+		page := data[d.pos : d.pos+int(size)]
+		pos = d.pos + int(size)
+
+		pageHeader, _ := header[5].(map[int16]interface{})
+		if thriftInt(header, 1) != int64(pageTypeData) || pageHeader == nil {
+			return nil, fmt.Errorf("codecs: parquet: Column %q uses unsupported page types", column.name)
+		}
+		if thriftInt(pageHeader, 2) != int64(encodingPlain) {
+			return nil, fmt.Errorf("codecs: parquet: Column %q is not PLAIN encoded, which is not supported", column.name)
+		}
+
+		pageCount := thriftInt(pageHeader, 1)
+		if !fits(pageCount, data) || pageCount > int64(count-len(values)) {
+			return nil, ErrorNotParquet
+		}
+
+		pageValues, err := readPage(page, column, int(pageCount))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, pageValues...)
+	}
+
+	return values, nil
+}
+
+// readPage reads the values of a data page.
+func readPage(page []byte, column leaf, count int) ([]interface{}, error) {
+
+	levels := make([]int, count)
+	for i := range levels {
+		levels[i] = 1
+	}
+
+	if column.optional {
+		if len(page) < 4 {
+			return nil, ErrorUnexpectedEnd
+		}
+		length := int(binary.LittleEndian.Uint32(page))
+		if 4+length > len(page) {
+			return nil, ErrorUnexpectedEnd
+		}
+		if err := decodeLevels(page[4:4+length], levels); err != nil {
+			return nil, err
+		}
+		page = page[4+length:]
+	}
+
+	values := make([]interface{}, count)
+	var pos, defined int
+
+	for i, level := range levels {
+
+		if level == 0 {
+			continue
+		}
+
+		var value interface{}
+		switch column.physical {
+		case typeBoolean:
+			if defined/8 >= len(page) {
+				return nil, ErrorUnexpectedEnd
+			}
+			value = page[defined/8]&(1<<uint(defined%8)) != 0
+		case typeInt32, typeFloat:
+			if pos+4 > len(page) {
+				return nil, ErrorUnexpectedEnd
+			}
+			bits := binary.LittleEndian.Uint32(page[pos:])
+			pos += 4
+			if column.physical == typeFloat {
+				value = math.Float32frombits(bits)
+			} else {
+				value = int32(bits)
+			}
+		case typeInt64, typeDouble:
+			if pos+8 > len(page) {
+				return nil, ErrorUnexpectedEnd
+			}
+			bits := binary.LittleEndian.Uint64(page[pos:])
+			pos += 8
+			if column.physical == typeDouble {
+				value = math.Float64frombits(bits)
+			} else {
+				value = int64Value(int64(bits), column.converted)
+			}
+		case typeByteArray:
+			if pos+4 > len(page) {
+				return nil, ErrorUnexpectedEnd
+			}
+			length := int(binary.LittleEndian.Uint32(page[pos:]))
+			pos += 4
+			if length < 0 || pos+length > len(page) {
+				return nil, ErrorUnexpectedEnd
+			}
+			b := make([]byte, length)
+			copy(b, page[pos:pos+length])
+			pos += length
+			if column.converted == convertedUTF8 {
+				value = string(b)
+			} else {
+				value = b
+			}
+		default:
+			return nil, fmt.Errorf("codecs: parquet: Column %q has an unsupported type", column.name)
+		}
+
+		values[i] = value
+		defined++
+	}
+
+	return values, nil
+}
+
+// int64Value gets the value of an INT64 column.
+func int64Value(i int64, converted int32) interface{} {
+	switch converted {
+	case convertedTimestampUs:
+		return time.Unix(0, i*int64(time.Microsecond)).UTC()
+	case convertedTimestampMs:
+		return time.Unix(0, i*int64(time.Millisecond)).UTC()
+	case convertedUint64:
+		return uint64(i)
+	}
+	return i
+}
+
+// decodeLevels reads the RLE/bit-packed hybrid encoded definition levels
+// (with a bit width of 1).
+func decodeLevels(data []byte, levels []int) error {
+
+	var pos, i int
+	for i < len(levels) {
+
+		header, size := binary.Uvarint(data[pos:])
+		if size <= 0 {
+			return ErrorUnexpectedEnd
+		}
+		pos += size
+
+		if header&1 == 0 {
+			// a run of one value
+			if pos >= len(data) {
+				return ErrorUnexpectedEnd
+			}
+			value := int(data[pos] & 1)
+			pos++
+			for n := header >> 1; n > 0 && i < len(levels); n-- {
+				levels[i] = value
+				i++
+			}
+			continue
+		}
+
+		// groups of eight bit-packed values
+		for n := header >> 1; n > 0; n-- {
+			if pos >= len(data) {
+				return ErrorUnexpectedEnd
+			}
+			for bit := uint(0); bit < 8 && i < len(levels); bit++ {
+				levels[i] = int(data[pos]>>bit) & 1
+				i++
+			}
+			pos++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package parquet
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Parquet physical types.
+const (
+	typeBoolean   int32 = 0
+	typeInt32     int32 = 1
+	typeInt64     int32 = 2
+	typeFloat     int32 = 4
+	typeDouble    int32 = 5
+	typeByteArray int32 = 6
+)
+
+// Parquet converted types.  noConvertedType means the column has none.
+const (
+	noConvertedType      int32 = -1
+	convertedUTF8        int32 = 0
+	convertedTimestampMs int32 = 9
+	convertedTimestampUs int32 = 10
+	convertedUint8       int32 = 11
+	convertedUint16      int32 = 12
+	convertedUint64      int32 = 14
+	convertedInt8        int32 = 15
+	convertedInt16       int32 = 16
+)
+
+// Parquet repetitions, encodings, compression codecs and page types.
+const (
+	repetitionRequired      int32 = 0
+	repetitionOptional      int32 = 1
+	repetitionRepeated      int32 = 2
+	encodingPlain           int32 = 0
+	encodingRLE             int32 = 3
+	compressionUncompressed int32 = 0
+	pageTypeData            int32 = 0
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// column describes a column of the file being written.
+type column struct {
+	name      string
+	physical  int32
+	converted int32
+	optional  bool
+
+	// value gets the column's value from the row, or nil if it is null.
+	value func(row reflect.Value) (interface{}, error)
+}
+
+// field describes a struct field.
+type field struct {
+	name  string
+	index int
+}
+
+// structFields gets the exported fields of the struct type, named by their
+// parquet tags.
+func structFields(t reflect.Type) []field {
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("parquet"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		fields = append(fields, field{name, i})
+	}
+
+	return fields
+}
+
+// physicalType gets the physical and converted types for values of the type.
+// Maps widen their values to INT64 and DOUBLE so mixed rows still agree.
+func physicalType(t reflect.Type, widen bool) (int32, int32, bool) {
+
+	if t == timeType {
+		return typeInt64, convertedTimestampUs, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return typeBoolean, noConvertedType, true
+	case reflect.Int8:
+		if !widen {
+			return typeInt32, convertedInt8, true
+		}
+	case reflect.Int16:
+		if !widen {
+			return typeInt32, convertedInt16, true
+		}
+	case reflect.Int32:
+		if !widen {
+			return typeInt32, noConvertedType, true
+		}
+	case reflect.Uint8:
+		if !widen {
+			return typeInt32, convertedUint8, true
+		}
+	case reflect.Uint16:
+		if !widen {
+			return typeInt32, convertedUint16, true
+		}
+	case reflect.Float32:
+		if !widen {
+			return typeFloat, noConvertedType, true
+		}
+		return typeDouble, noConvertedType, true
+	case reflect.Float64:
+		return typeDouble, noConvertedType, true
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return typeInt64, convertedUint64, true
+	case reflect.String:
+		return typeByteArray, convertedUTF8, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return typeByteArray, noConvertedType, true
+		}
+		return 0, 0, false
+	default:
+		if !isInt(t.Kind()) {
+			return 0, 0, false
+		}
+	}
+
+	// everything else (including widened small ints) is an INT64
+	return typeInt64, noConvertedType, true
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return true
+	}
+	return false
+}
+
+// normalize gets the value to write for the column's physical type.
+func normalize(v reflect.Value, physical int32) interface{} {
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).UnixNano() / int64(time.Microsecond)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if physical == typeInt32 {
+			return int32(v.Int())
+		}
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if physical == typeInt32 {
+			return int32(v.Uint())
+		}
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		if physical == typeFloat {
+			return float32(v.Float())
+		}
+		return v.Float()
+	case reflect.String:
+		return []byte(v.String())
+	}
+
+	return v.Bytes()
+}
+
+// structColumns gets the columns for rows of the struct type.
+func structColumns(t reflect.Type) ([]column, error) {
+
+	var columns []column
+	for _, f := range structFields(t) {
+
+		fieldType := t.Field(f.index).Type
+		optional := fieldType.Kind() == reflect.Ptr
+		if optional {
+			fieldType = fieldType.Elem()
+		}
+
+		physical, converted, ok := physicalType(fieldType, false)
+		if !ok {
+			return nil, fmt.Errorf("codecs: parquet: Cannot write field %s of type %s", t.Field(f.index).Name, t.Field(f.index).Type)
+		}
+
+		index := f.index
+		columns = append(columns, column{
+			name:      f.name,
+			physical:  physical,
+			converted: converted,
+			optional:  optional,
+			value: func(row reflect.Value) (interface{}, error) {
+				v := row.Field(index)
+				if v.Kind() == reflect.Ptr {
+					if v.IsNil() {
+						return nil, nil
+					}
+					v = v.Elem()
+				}
+				return normalize(v, physical), nil
+			},
+		})
+	}
+
+	return columns, nil
+}
+
+// mapColumns gets the (optional) columns for rows of maps, with the types
+// taken from the values.
+func mapColumns(rows []reflect.Value) ([]column, error) {
+
+	types := map[string]reflect.Type{}
+	var names []string
+	for _, row := range rows {
+		for _, key := range row.MapKeys() {
+
+			name := key.String()
+			if _, seen := types[name]; !seen {
+				names = append(names, name)
+				types[name] = nil
+			}
+
+			value := indirect(row.MapIndex(key))
+			if !value.IsValid() || types[name] != nil {
+				continue
+			}
+			types[name] = value.Type()
+		}
+	}
+
+	sort.Strings(names)
+
+	var columns []column
+	for _, name := range names {
+
+		physical, converted := typeByteArray, convertedUTF8
+		if t := types[name]; t != nil {
+			var ok bool
+			if physical, converted, ok = physicalType(t, true); !ok {
+				return nil, fmt.Errorf("codecs: parquet: Cannot write %q values of type %s", name, t)
+			}
+		}
+
+		key := reflect.ValueOf(name)
+		columnName := name
+		columnPhysical, columnConverted := physical, converted
+		columns = append(columns, column{
+			name:      name,
+			physical:  physical,
+			converted: converted,
+			optional:  true,
+			value: func(row reflect.Value) (interface{}, error) {
+				v := indirect(row.MapIndex(key.Convert(row.Type().Key())))
+				if !v.IsValid() {
+					return nil, nil
+				}
+				if physical, converted, _ := physicalType(v.Type(), true); physical != columnPhysical || converted != columnConverted {
+					return nil, fmt.Errorf("codecs: parquet: Column %q has mixed types", columnName)
+				}
+				return normalize(v, columnPhysical), nil
+			},
+		})
+	}
+
+	return columns, nil
+}
+
+// indirect follows pointers and interfaces, returning an invalid value for
+// nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,308 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// The Parquet metadata is written using the Thrift compact protocol.  Only
+// the parts of the protocol Parquet uses are implemented.
+
+// Thrift compact protocol types.
+const (
+	thriftTrue   byte = 1
+	thriftFalse  byte = 2
+	thriftByte   byte = 3
+	thriftI16    byte = 4
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftDouble byte = 7
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftSet    byte = 10
+	thriftMap    byte = 11
+	thriftStruct byte = 12
+)
+
+// thriftField is a field in a struct being written.  The value can be an
+// int32, int64, bool, string, []byte, *thriftStructValue, or a slice of
+// int32, string or *thriftStructValue.
+type thriftField struct {
+	id    int16
+	value interface{}
+}
+
+// thriftStructValue is a struct being written, with its fields in id order.
+// Nil fields are left out.
+type thriftStructValue struct {
+	fields []thriftField
+}
+
+// add adds a field to the struct.
+func (s *thriftStructValue) add(id int16, value interface{}) *thriftStructValue {
+	s.fields = append(s.fields, thriftField{id, value})
+	return s
+}
+
+// writeThrift writes the struct to the buffer.
+func writeThrift(buffer *bytes.Buffer, s *thriftStructValue) {
+
+	var last int16
+	for _, field := range s.fields {
+
+		if field.value == nil {
+			continue
+		}
+
+		fieldType := thriftType(field.value)
+		if b, ok := field.value.(bool); ok && !b {
+			fieldType = thriftFalse
+		}
+
+		if delta := field.id - last; delta > 0 && delta <= 15 {
+			buffer.WriteByte(byte(delta)<<4 | fieldType)
+		} else {
+			buffer.WriteByte(fieldType)
+			writeVarint(buffer, zigzag(int64(field.id)))
+		}
+		last = field.id
+
+		writeThriftValue(buffer, field.value)
+	}
+
+	buffer.WriteByte(0)
+
+}
+
+func thriftType(value interface{}) byte {
+	switch value.(type) {
+	case bool:
+		return thriftTrue
+	case int32:
+		return thriftI32
+	case int64:
+		return thriftI64
+	case string, []byte:
+		return thriftBinary
+	case *thriftStructValue:
+		return thriftStruct
+	case []int32, []string, []*thriftStructValue:
+		return thriftList
+	}
+	panic(fmt.Sprintf("codecs: parquet: Cannot write %T as thrift", value))
+}
+
+func writeThriftValue(buffer *bytes.Buffer, value interface{}) {
+
+	switch v := value.(type) {
+	case bool:
+		// the value is part of the field type
+	case int32:
+		writeVarint(buffer, zigzag(int64(v)))
+	case int64:
+		writeVarint(buffer, zigzag(v))
+	case string:
+		writeVarint(buffer, uint64(len(v)))
+		buffer.WriteString(v)
+	case []byte:
+		writeVarint(buffer, uint64(len(v)))
+		buffer.Write(v)
+	case *thriftStructValue:
+		writeThrift(buffer, v)
+	case []int32:
+		writeListHeader(buffer, len(v), thriftI32)
+		for _, item := range v {
+			writeThriftValue(buffer, item)
+		}
+	case []string:
+		writeListHeader(buffer, len(v), thriftBinary)
+		for _, item := range v {
+			writeThriftValue(buffer, item)
+		}
+	case []*thriftStructValue:
+		writeListHeader(buffer, len(v), thriftStruct)
+		for _, item := range v {
+			writeThriftValue(buffer, item)
+		}
+	}
+
+}
+
+func writeListHeader(buffer *bytes.Buffer, size int, elementType byte) {
+	if size < 15 {
+		buffer.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	buffer.WriteByte(0xF0 | elementType)
+	writeVarint(buffer, uint64(size))
+}
+
+func writeVarint(buffer *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buffer.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// thriftDecoder reads Thrift compact protocol structs as maps of field id to
+// value.  Integers are read as int64, binary as []byte, lists and sets as
+// []interface{} and structs as map[int16]interface{}.
+type thriftDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *thriftDecoder) readStruct() (map[int16]interface{}, error) {
+
+	fields := map[int16]interface{}{}
+	var last int16
+
+	for {
+
+		header, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+
+		fieldType := header & 0x0F
+		if delta := int16(header >> 4); delta != 0 {
+			last += delta
+		} else {
+			id, err := d.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			last = int16(unzigzag(id))
+		}
+
+		switch fieldType {
+		case thriftTrue:
+			fields[last] = true
+		case thriftFalse:
+			fields[last] = false
+		default:
+			value, err := d.readValue(fieldType)
+			if err != nil {
+				return nil, err
+			}
+			fields[last] = value
+		}
+	}
+
+}
+
+func (d *thriftDecoder) readValue(valueType byte) (interface{}, error) {
+
+	switch valueType {
+	case thriftTrue, thriftFalse:
+		// booleans in lists take a byte each
+		b, err := d.readByte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := d.readByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		v, err := d.readVarint()
+		return unzigzag(v), err
+	case thriftDouble:
+		if d.pos+8 > len(d.data) {
+			return nil, ErrorUnexpectedEnd
+		}
+		d.pos += 8
+		return binary.LittleEndian.Uint64(d.data[d.pos-8 : d.pos]), nil
+	case thriftBinary:
+		length, err := d.readVarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(d.data)-d.pos) < length {
+			return nil, ErrorUnexpectedEnd
+		}
+		d.pos += int(length)
+		return d.data[d.pos-int(length) : d.pos], nil
+	case thriftList, thriftSet:
+		header, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = d.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(len(d.data)-d.pos) {
+			return nil, ErrorUnexpectedEnd
+		}
+		items := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			item, err := d.readValue(header & 0x0F)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case thriftMap:
+		size, err := d.readVarint()
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		types, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := d.readValue(types >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := d.readValue(types & 0x0F); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return d.readStruct()
+	}
+
+	return nil, fmt.Errorf("codecs: parquet: Unknown thrift type %d", valueType)
+}
+
+func (d *thriftDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrorUnexpectedEnd
+	}
+	d.pos++
+	return d.data[d.pos-1], nil
+}
+
+func (d *thriftDecoder) readVarint() (uint64, error) {
+	v, size := binary.Uvarint(d.data[d.pos:])
+	if size <= 0 {
+		return 0, ErrorUnexpectedEnd
+	}
+	d.pos += size
+	return v, nil
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// thriftInt gets the integer field from the struct.
+func thriftInt(s map[int16]interface{}, id int16) int64 {
+	i, _ := s[id].(int64)
+	return i
+}
+
+// thriftString gets the binary field from the struct as a string.
+func thriftString(s map[int16]interface{}, id int16) string {
+	b, _ := s[id].([]byte)
+	return string(b)
+}
@@ -0,0 +1,245 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+)
+
+// magic starts and ends every Parquet file.
+var magic = []byte("PAR1")
+
+// createdBy is written into the file metadata.
+const createdBy = "github.com/stretchr/codecs"
+
+// marshal writes the rows of the object as a Parquet file.
+func marshal(object interface{}) ([]byte, error) {
+
+	rows, columns, err := tabulate(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file bytes.Buffer
+	file.Write(magic)
+
+	schema := []*thriftStructValue{
+		new(thriftStructValue).add(4, "schema").add(5, int32(len(columns))),
+	}
+	var chunks []*thriftStructValue
+	var totalSize int64
+
+	for _, c := range columns {
+
+		repetition := repetitionRequired
+		if c.optional {
+			repetition = repetitionOptional
+		}
+		element := new(thriftStructValue).add(1, c.physical).add(3, repetition).add(4, c.name)
+		if c.converted != noConvertedType {
+			element.add(6, c.converted)
+		}
+		schema = append(schema, element)
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		page, err := writePage(rows, c)
+		if err != nil {
+			return nil, err
+		}
+
+		var header bytes.Buffer
+		writeThrift(&header, new(thriftStructValue).
+			add(1, pageTypeData).
+			add(2, int32(len(page))).
+			add(3, int32(len(page))).
+			add(5, new(thriftStructValue).
+				add(1, int32(len(rows))).
+				add(2, encodingPlain).
+				add(3, encodingRLE).
+				add(4, encodingRLE)))
+
+		offset := int64(file.Len())
+		size := int64(header.Len() + len(page))
+		file.Write(header.Bytes())
+		file.Write(page)
+		totalSize += size
+
+		chunks = append(chunks, new(thriftStructValue).
+			add(2, offset).
+			add(3, new(thriftStructValue).
+				add(1, c.physical).
+				add(2, []int32{encodingPlain, encodingRLE}).
+				add(3, []string{c.name}).
+				add(4, compressionUncompressed).
+				add(5, int64(len(rows))).
+				add(6, size).
+				add(7, size).
+				add(9, offset)))
+	}
+
+	rowGroups := []*thriftStructValue{}
+	if len(rows) > 0 {
+		rowGroups = append(rowGroups, new(thriftStructValue).
+			add(1, chunks).
+			add(2, totalSize).
+			add(3, int64(len(rows))))
+	}
+
+	var metadata bytes.Buffer
+	writeThrift(&metadata, new(thriftStructValue).
+		add(1, int32(1)).
+		add(2, schema).
+		add(3, int64(len(rows))).
+		add(4, rowGroups).
+		add(6, createdBy))
+
+	file.Write(metadata.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(metadata.Len()))
+	file.Write(magic)
+
+	return file.Bytes(), nil
+}
+
+// tabulate gets the rows and columns of the object.
+func tabulate(object interface{}) ([]reflect.Value, []column, error) {
+
+	v := indirect(reflect.ValueOf(object))
+
+	if !v.IsValid() {
+		return nil, nil, ErrorNotTabular
+	}
+
+	var rows []reflect.Value
+	var elementType reflect.Type
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		rows = []reflect.Value{v}
+		elementType = v.Type()
+	case reflect.Slice, reflect.Array:
+		elementType = v.Type().Elem()
+		for elementType.Kind() == reflect.Ptr {
+			elementType = elementType.Elem()
+		}
+		for i := 0; i < v.Len(); i++ {
+			row := indirect(v.Index(i))
+			if !row.IsValid() {
+				return nil, nil, ErrorNotTabular
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, nil, ErrorNotTabular
+	}
+
+	// interface{} elements get their type from the rows
+	if elementType.Kind() == reflect.Interface && len(rows) > 0 {
+		elementType = rows[0].Type()
+	}
+
+	for _, row := range rows {
+		if row.Type() != elementType && (row.Kind() != reflect.Map || elementType.Kind() != reflect.Map) {
+			return nil, nil, ErrorNotTabular
+		}
+	}
+
+	switch elementType.Kind() {
+	case reflect.Struct:
+		columns, err := structColumns(elementType)
+		return rows, columns, err
+	case reflect.Map:
+		for _, row := range rows {
+			if row.Type().Key().Kind() != reflect.String {
+				return nil, nil, ErrorNotTabular
+			}
+		}
+		columns, err := mapColumns(rows)
+		return rows, columns, err
+	case reflect.Interface:
+		// an empty slice, so there's nothing to write
+		return rows, nil, nil
+	}
+
+	return nil, nil, ErrorNotTabular
+}
+
+// writePage writes the column's data page.
+func writePage(rows []reflect.Value, c column) ([]byte, error) {
+
+	var page bytes.Buffer
+	var values []interface{}
+	var levels []int
+
+	for _, row := range rows {
+		value, err := c.value(row)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			levels = append(levels, 0)
+			continue
+		}
+		levels = append(levels, 1)
+		values = append(values, value)
+	}
+
+	if c.optional {
+		encoded := encodeLevels(levels)
+		binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+		page.Write(encoded)
+	}
+
+	if c.physical == typeBoolean {
+		bits := make([]byte, (len(values)+7)/8)
+		for i, value := range values {
+			if value.(bool) {
+				bits[i/8] |= 1 << uint(i%8)
+			}
+		}
+		page.Write(bits)
+		return page.Bytes(), nil
+	}
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case int32:
+			binary.Write(&page, binary.LittleEndian, v)
+		case int64:
+			binary.Write(&page, binary.LittleEndian, v)
+		case float32:
+			binary.Write(&page, binary.LittleEndian, math.Float32bits(v))
+		case float64:
+			binary.Write(&page, binary.LittleEndian, math.Float64bits(v))
+		case []byte:
+			binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+			page.Write(v)
+		}
+	}
+
+	return page.Bytes(), nil
+}
+
+// encodeLevels writes the definition levels (which are 0 or 1) as runs of
+// the RLE/bit-packed hybrid encoding.
+func encodeLevels(levels []int) []byte {
+
+	var buffer bytes.Buffer
+	for start := 0; start < len(levels); {
+
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+
+		writeVarint(&buffer, uint64(end-start)<<1)
+		buffer.WriteByte(byte(levels[start]))
+		start = end
+	}
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,9 @@
+// Adapts Prometheus collectors to codec services, so that the formats clients
+// use can be graphed:
+//
+//	service.SetMetrics(promcodecs.NewMetrics(prometheus.DefaultRegisterer))
+//
+// Marshalling and unmarshalling are counted, and their durations and sizes
+// observed, labelled with the event (marshal or unmarshal) and the content type
+// of the codec.
+package promcodecs
@@ -0,0 +1,85 @@
+package promcodecs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/codecs/services"
+	"time"
+)
+
+// Namespace is the namespace of the metrics' names.
+const Namespace = "codecs"
+
+// Labels the metrics are labelled with.
+const (
+	LabelEvent       = "event"
+	LabelContentType = "content_type"
+	LabelOutcome     = "outcome"
+)
+
+// Outcomes a count is labelled with.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// metrics is a services.Metrics recording with Prometheus collectors.
+type metrics struct {
+	total    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// NewMetrics makes a services.Metrics with collectors registered with the
+// registerer, which are:
+//
+//	codecs_total: the number of marshals and unmarshals
+//	codecs_duration_seconds: how long they took
+//	codecs_size_bytes: the size of the data
+//
+// NewMetrics panics if the collectors can't be registered (as
+// prometheus.MustRegister does).
+func NewMetrics(registerer prometheus.Registerer) services.Metrics {
+
+	m := &metrics{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "total",
+			Help:      "The number of marshals and unmarshals.",
+		}, []string{LabelEvent, LabelContentType, LabelOutcome}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "duration_seconds",
+			Help:      "How long marshals and unmarshals took.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{LabelEvent, LabelContentType}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "size_bytes",
+			Help:      "The size of the data marshalled and unmarshalled.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{LabelEvent, LabelContentType}),
+	}
+
+	registerer.MustRegister(m.total, m.duration, m.size)
+
+	return m
+}
+
+// Count counts one marshal or unmarshal.
+func (m *metrics) Count(event, contentType string, failed bool) {
+	outcome := OutcomeSuccess
+	if failed {
+		outcome = OutcomeError
+	}
+	m.total.WithLabelValues(event, contentType, outcome).Inc()
+}
+
+// ObserveDuration observes how long a marshal or unmarshal took.
+func (m *metrics) ObserveDuration(event, contentType string, duration time.Duration) {
+	m.duration.WithLabelValues(event, contentType).Observe(duration.Seconds())
+}
+
+// ObserveSize observes the size of the data marshalled or unmarshalled.
+func (m *metrics) ObserveSize(event, contentType string, size int64) {
+	m.size.WithLabelValues(event, contentType).Observe(float64(size))
+}
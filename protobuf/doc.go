@@ -0,0 +1,16 @@
+// A codec for the protobuf text format, for debugging endpoints where people
+// rather than programs read the messages.
+//
+// Messages (anything implementing proto.Message) are marshalled and
+// unmarshalled directly.  Public data and other generic values don't say which
+// message they are, so the message type an endpoint expects is given by its
+// full name, which is looked up in protoregistry.GlobalTypes (where generated
+// message packages register themselves, and which the binary format shares):
+//
+//	codec := &protobuf.ProtoTextCodec{MessageType: "example.Person"}
+//
+// or by the constants.OptionKeyMessageType option.  Generic values are
+// converted into the message using protojson's rules before being marshalled,
+// and unmarshalling into an interface{} gives a new message of the type.
+// google.protobuf.Any fields are expanded using the same registry.
+package protobuf
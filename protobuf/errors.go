@@ -0,0 +1,33 @@
+package protobuf
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrorUnknownMessageType is returned when the selected message type has
+	// not been registered in protoregistry.GlobalTypes.
+	ErrorUnknownMessageType = errors.New("codecs: protobuf: Message type is not registered")
+
+	// ErrorNoMessageType is returned when a generic value is marshalled or
+	// unmarshalled without a message type.
+	ErrorNoMessageType = errors.New("codecs: protobuf: No message type is selected")
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: protobuf: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: protobuf: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: protobuf: Unmarshal(nil " + e.Type.String() + ")"
+}
@@ -0,0 +1,162 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"reflect"
+)
+
+// PrettyIndent is the indentation used for the constants.OptionKeyPretty
+// option but without the constants.OptionKeyIndent option.
+const PrettyIndent = "  "
+
+// ProtoTextCodec converts protobuf messages to and from the protobuf text
+// format.
+type ProtoTextCodec struct {
+
+	// MessageType is the full name (such as "example.Person") of the message
+	// type this codec expects for generic values.
+	MessageType string
+}
+
+func init() {
+	codecs.Register(new(ProtoTextCodec))
+}
+
+// Marshal converts a message to the text format, on several lines indented by
+// the constants.OptionKeyIndent option (or PrettyIndent for the
+// constants.OptionKeyPretty option).  Other values are converted to the
+// selected message type first.
+func (c *ProtoTextCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	message, ok := object.(proto.Message)
+	if !ok {
+
+		var err error
+		if message, err = c.newMessage(options); err != nil {
+			return nil, err
+		}
+
+		jsonData, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := (protojson.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}).Unmarshal(jsonData, message); err != nil {
+			return nil, err
+		}
+	}
+
+	marshalOptions := prototext.MarshalOptions{Resolver: protoregistry.GlobalTypes}
+	if indent := indentation(options); len(indent) > 0 {
+		marshalOptions.Multiline = true
+		marshalOptions.Indent = indent
+	}
+
+	return marshalOptions.Marshal(message)
+}
+
+// indentation gets the indentation given by the constants.OptionKeyIndent
+// option, or PrettyIndent if only the constants.OptionKeyPretty option is true,
+// or empty for output on one line.
+func indentation(options map[string]interface{}) string {
+
+	if indent, ok := options[constants.OptionKeyIndent].(string); ok && len(indent) > 0 {
+		return indent
+	}
+
+	if pretty, _ := options[constants.OptionKeyPretty].(bool); pretty {
+		return PrettyIndent
+	}
+
+	return ""
+}
+
+// Unmarshal converts the text format into an object.  An interface{} is given
+// a new message of the codec's MessageType.
+func (c *ProtoTextCodec) Unmarshal(data []byte, obj interface{}) error {
+	return c.UnmarshalWithOptions(data, obj, nil)
+}
+
+// UnmarshalWithOptions converts the text format into an object as Unmarshal
+// does, with the constants.OptionKeyMessageType option overriding the codec's
+// MessageType.  Objects other than messages and interface{}s get
+// encoding/json's treatment of the message's protojson.
+func (c *ProtoTextCodec) UnmarshalWithOptions(data []byte, obj interface{}, options map[string]interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	unmarshalOptions := prototext.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}
+
+	if message, ok := obj.(proto.Message); ok {
+		return unmarshalOptions.Unmarshal(data, message)
+	}
+
+	message, err := c.newMessage(options)
+	if err != nil {
+		return err
+	}
+
+	if err := unmarshalOptions.Unmarshal(data, message); err != nil {
+		return err
+	}
+
+	// generic values can be set directly
+	if target, ok := obj.(*interface{}); ok {
+		*target = message
+		return nil
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := protojson.MarshalOptions{Resolver: protoregistry.GlobalTypes}.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, obj)
+}
+
+// newMessage makes a new message of the type given by the
+// constants.OptionKeyMessageType option (or the codec's MessageType).
+func (c *ProtoTextCodec) newMessage(options map[string]interface{}) (proto.Message, error) {
+
+	name, ok := options[constants.OptionKeyMessageType].(string)
+	if !ok {
+		name = c.MessageType
+	}
+
+	if len(name) == 0 {
+		return nil, ErrorNoMessageType
+	}
+
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, ErrorUnknownMessageType
+	}
+
+	return messageType.New().Interface(), nil
+}
+
+// ContentType returns the content type for this codec.
+func (c *ProtoTextCodec) ContentType() string {
+	return constants.ContentTypeTextPB
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ProtoTextCodec) FileExtension() string {
+	return constants.FileExtensionTextPB
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ProtoTextCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,103 @@
+package protobuf
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"strings"
+	"testing"
+)
+
+var protoTextCodec ProtoTextCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(ProtoTextCodec), "ProtoTextCodec")
+	assert.Implements(t, (*codecs.UnmarshalOptionsCodec)(nil), new(ProtoTextCodec), "ProtoTextCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeTextPB, protoTextCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionTextPB, protoTextCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, protoTextCodec.CanMarshalWithCallback())
+}
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+
+	bytes, err := protoTextCodec.Marshal(wrapperspb.String("Mat"), nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `"Mat"`)
+
+		out := new(wrapperspb.StringValue)
+		if assert.NoError(t, protoTextCodec.Unmarshal(bytes, out)) {
+			assert.True(t, proto.Equal(wrapperspb.String("Mat"), out))
+		}
+	}
+
+	bytes, err = protoTextCodec.Marshal(wrapperspb.String("Mat"), map[string]interface{}{constants.OptionKeyPretty: true})
+
+	if assert.NoError(t, err) {
+		assert.True(t, strings.HasSuffix(string(bytes), "\n"))
+	}
+
+}
+
+func TestMarshalAndUnmarshal_MessageType(t *testing.T) {
+
+	codec := &ProtoTextCodec{MessageType: "google.protobuf.StringValue"}
+
+	// generic values are converted into the message
+	bytes, err := codec.Marshal("Tyler", nil)
+
+	if assert.NoError(t, err) {
+
+		var obj interface{}
+		if assert.NoError(t, codec.Unmarshal(bytes, &obj)) {
+			assert.True(t, proto.Equal(wrapperspb.String("Tyler"), obj.(proto.Message)))
+		}
+
+		var name string
+		if assert.NoError(t, codec.Unmarshal(bytes, &name)) {
+			assert.Equal(t, "Tyler", name)
+		}
+	}
+
+	// the option overrides the codec's message type
+	bytes, err = protoTextCodec.Marshal(int64(42), map[string]interface{}{constants.OptionKeyMessageType: "google.protobuf.Int64Value"})
+
+	if assert.NoError(t, err) {
+		var obj interface{}
+		if assert.NoError(t, protoTextCodec.UnmarshalWithOptions(bytes, &obj, map[string]interface{}{constants.OptionKeyMessageType: "google.protobuf.Int64Value"})) {
+			assert.True(t, proto.Equal(wrapperspb.Int64(42), obj.(proto.Message)))
+		}
+	}
+
+}
+
+func TestErrors(t *testing.T) {
+
+	var obj interface{}
+
+	_, err := protoTextCodec.Marshal("Mat", nil)
+	assert.Equal(t, ErrorNoMessageType, err)
+	assert.Equal(t, ErrorNoMessageType, protoTextCodec.Unmarshal([]byte(`value: "Mat"`), &obj))
+
+	codec := &ProtoTextCodec{MessageType: "example.Missing"}
+	_, err = codec.Marshal("Mat", nil)
+	assert.Equal(t, ErrorUnknownMessageType, err)
+	assert.Equal(t, ErrorUnknownMessageType, codec.Unmarshal([]byte(`value: "Mat"`), &obj))
+
+	assert.Error(t, protoTextCodec.Unmarshal([]byte(`value: `), new(wrapperspb.StringValue)))
+
+	_, isInvalid := protoTextCodec.Unmarshal([]byte(`value: "Mat"`), obj).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
@@ -0,0 +1,120 @@
+package codecs
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/codecs/constants"
+	"mime"
+	"strings"
+)
+
+// ErrorNoCodecForRawMessage is returned when decoding a RawMessage in a content
+// type no registered codec handles.
+var ErrorNoCodecForRawMessage = errors.New("codecs: No codec is registered for the content type of the RawMessage")
+
+// RawMessage is data already encoded in a content type, such as JSON stored in
+// a database.  Codecs for its content type that recognise RawMessages (such as
+// JSON and Msgpack) write it as it is, without decoding and encoding it again;
+// others get it decoded with the codec registered for its content type.
+//
+// Unmarshalling JSON into a RawMessage keeps the JSON as it is, so that
+// parsing it can be put off (see Decode).
+type RawMessage struct {
+	contentType string
+	data        []byte
+}
+
+// NewRawMessage makes a RawMessage of the data, encoded in the content type.
+func NewRawMessage(contentType string, data []byte) RawMessage {
+	return RawMessage{contentType: contentType, data: data}
+}
+
+// ContentType gets the content type the message is encoded in.
+func (m RawMessage) ContentType() string {
+	return m.contentType
+}
+
+// Data gets the encoded message.
+func (m RawMessage) Data() []byte {
+	return m.data
+}
+
+// EncodedFor gets whether the message is encoded in one of the codec's content
+// types, so that the codec can write it as it is.
+func (m RawMessage) EncodedFor(codec Codec) bool {
+	mediaType, _, err := mime.ParseMediaType(m.contentType)
+	return err == nil && codecHandles(codec, mediaType)
+}
+
+// Decode decodes the message into obj with the codec registered for its
+// content type.
+func (m RawMessage) Decode(obj interface{}) error {
+
+	mediaType, _, err := mime.ParseMediaType(m.contentType)
+	if err != nil {
+		return err
+	}
+
+	for _, codec := range Registered() {
+		if codecHandles(codec, mediaType) {
+			return codec.Unmarshal(m.data, obj)
+		}
+	}
+
+	return ErrorNoCodecForRawMessage
+}
+
+// MarshalJSON gets the message as it is if it is JSON, or else decoded and
+// encoded as JSON.  An empty message is null.
+func (m RawMessage) MarshalJSON() ([]byte, error) {
+
+	if len(m.data) == 0 {
+		return []byte("null"), nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(m.contentType)
+	if err == nil && (strings.EqualFold(mediaType, constants.ContentTypeJSON) || strings.HasSuffix(strings.ToLower(mediaType), "+json")) {
+		return m.data, nil
+	}
+
+	var value interface{}
+	if err := m.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// UnmarshalJSON keeps a copy of the JSON as the message.
+func (m *RawMessage) UnmarshalJSON(data []byte) error {
+	m.contentType = constants.ContentTypeJSON
+	m.data = append([]byte(nil), data...)
+	return nil
+}
+
+// codecHandles gets whether the codec handles the media type, as its content
+// type, one of its aliases or one of its structured syntax suffixes.
+func codecHandles(codec Codec, mediaType string) bool {
+
+	if strings.EqualFold(codec.ContentType(), mediaType) {
+		return true
+	}
+
+	if aliasCodec, ok := codec.(AliasCodec); ok {
+		for _, alias := range aliasCodec.Aliases() {
+			if strings.EqualFold(alias, mediaType) {
+				return true
+			}
+		}
+	}
+
+	if suffixCodec, ok := codec.(SuffixCodec); ok {
+		for _, suffix := range suffixCodec.Suffixes() {
+			if strings.HasSuffix(strings.ToLower(mediaType), "+"+strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package codecs
+
+import (
+	"encoding/json"
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+// document holds a message stored already encoded.
+type document struct {
+	ID   int        `json:"id"`
+	Body RawMessage `json:"body"`
+}
+
+func TestRawMessage_JSON(t *testing.T) {
+
+	object := &document{ID: 1, Body: NewRawMessage("application/json", []byte(`{"title":"Hello","tags":["a"]}`))}
+
+	data, err := json.Marshal(object)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"id":1,"body":{"title":"Hello","tags":["a"]}}`, string(data))
+	}
+
+	// raw messages are left as they are in public data
+	public, err := PublicData(object, Options{KeyCase: PascalCase}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, object.Body, public.(map[string]interface{})["Body"])
+	}
+
+	// parsing unmarshalled raw messages is put off
+	var decoded document
+	if assert.NoError(t, json.Unmarshal([]byte(`{"id":2,"body":{"title":"Bye"}}`), &decoded)) {
+		assert.Equal(t, "application/json", decoded.Body.ContentType())
+		assert.Equal(t, `{"title":"Bye"}`, string(decoded.Body.Data()))
+	}
+
+	empty, err := json.Marshal(RawMessage{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "null", string(empty))
+	}
+
+}
+
+func TestRawMessage_Decode(t *testing.T) {
+
+	codec := new(test.TestCodec)
+	codec.On("ContentType").Return("application/x-raw-test")
+	codec.On("Unmarshal", []byte("title=Hello"), mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(1).(*interface{}) = map[string]interface{}{"title": "Hello"}
+	}).Return(nil)
+	Register(codec)
+
+	message := NewRawMessage("application/x-raw-test; charset=utf-8", []byte("title=Hello"))
+	assert.True(t, message.EncodedFor(codec))
+
+	// messages in other content types are decoded and encoded again
+	data, err := json.Marshal(message)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"title":"Hello"}`, string(data))
+	}
+
+	_, err = json.Marshal(NewRawMessage("application/x-unknown", []byte("?")))
+	assert.Error(t, err)
+
+	var value interface{}
+	assert.Equal(t, ErrorNoCodecForRawMessage, NewRawMessage("application/x-unknown", []byte("?")).Decode(&value))
+
+}
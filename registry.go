@@ -0,0 +1,45 @@
+package codecs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	registered     []Codec
+	registeredLock sync.RWMutex
+)
+
+// Register makes the codec available to services made from the registry (see
+// services.NewWebCodecServiceFromRegistry).  Codec packages register their
+// codecs when they are initialised, so importing a package is enough to enable
+// its codecs.  Register panics if the codec is nil, or if a codec is already
+// registered for its content type.
+func Register(codec Codec) {
+
+	if codec == nil {
+		panic("codecs: Register codec is nil.")
+	}
+
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+
+	for _, existing := range registered {
+		if strings.EqualFold(existing.ContentType(), codec.ContentType()) {
+			panic(fmt.Sprintf("codecs: Register called twice for content type \"%s\".", codec.ContentType()))
+		}
+	}
+
+	registered = append(registered, codec)
+}
+
+// Registered gets the registered codecs, in the order they were registered
+// in.
+func Registered() []Codec {
+
+	registeredLock.RLock()
+	defer registeredLock.RUnlock()
+
+	return append([]Codec(nil), registered...)
+}
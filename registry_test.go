@@ -0,0 +1,30 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+
+	codec := new(test.TestCodec)
+	codec.On("ContentType").Return("application/x-registry-test")
+
+	before := len(Registered())
+	Register(codec)
+
+	registered := Registered()
+	if assert.Equal(t, before+1, len(registered)) {
+		assert.Equal(t, codec, registered[len(registered)-1])
+	}
+
+	assert.Panics(t, func() {
+		Register(codec)
+	}, "registering a content type twice")
+
+	assert.Panics(t, func() {
+		Register(nil)
+	}, "registering nil")
+
+}
@@ -0,0 +1,161 @@
+package codecs
+
+import (
+	"context"
+	"reflect"
+)
+
+// Sequence is a series of elements that can be gone through once, such as the
+// public data of the rows of a database cursor, so that they needn't all be
+// held in memory.
+type Sequence interface {
+
+	// Each calls fn with each element in turn, stopping at (and returning)
+	// the first error.
+	Each(fn func(element interface{}) error) error
+}
+
+// SequenceFunc is a function used as a Sequence.
+type SequenceFunc func(fn func(element interface{}) error) error
+
+// Each calls the function.
+func (f SequenceFunc) Each(fn func(element interface{}) error) error {
+	return f(fn)
+}
+
+// IsSequence gets whether the object is a channel (which is received from
+// until it is closed) or an iterator function of the form
+// func(yield func(T) bool) (such as an iter.Seq), whose public data is got
+// element by element.
+func IsSequence(object interface{}) bool {
+	return isSequence(reflect.ValueOf(object))
+}
+
+// isSequence gets whether the value is a channel or an iterator function.
+func isSequence(value reflect.Value) bool {
+
+	switch value.Kind() {
+	case reflect.Chan:
+		return value.Type().ChanDir()&reflect.RecvDir != 0
+	case reflect.Func:
+		t := value.Type()
+		if t.NumIn() != 1 || t.NumOut() != 0 || t.In(0).Kind() != reflect.Func {
+			return false
+		}
+		yield := t.In(0)
+		return yield.NumIn() == 1 && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+	}
+
+	return false
+}
+
+// PublicDataSequence gets a Sequence of the public data of the elements of the
+// channel or iterator function (see IsSequence), or false if the object isn't
+// one.  The public data of each element is got as it comes, as PublicData gets
+// that of the elements of a slice (including the FacadeErrorPolicy, empty
+// policy and key case given by the options).  The context stops the sequence
+// (returning its error) while it waits on a channel.
+func PublicDataSequence(ctx context.Context, object interface{}, options map[string]interface{}) (Sequence, bool) {
+
+	value := reflect.ValueOf(object)
+	if !isSequence(value) {
+		return nil, false
+	}
+
+	return &publicSequence{
+		ctx:           ctx,
+		source:        value,
+		options:       options,
+		normalization: normalizationFor(options),
+	}, true
+}
+
+// publicSequence is the Sequence got by PublicDataSequence.
+type publicSequence struct {
+	ctx           context.Context
+	source        reflect.Value
+	options       map[string]interface{}
+	normalization normalization
+	level         int
+}
+
+// Each calls fn with the public data of each element in turn.
+func (s *publicSequence) Each(fn func(element interface{}) error) error {
+
+	index := 0
+	each := func(element interface{}) error {
+
+		public, err := publicData(s.ctx, element, s.level+1, s.options)
+		if err != nil {
+			var keep bool
+			if public, keep, err = handleElementError(index, err, s.options); err != nil || !keep {
+				index++
+				return err
+			}
+		}
+		index++
+
+		if !s.normalization.isZero() {
+			if public, err = s.normalization.apply(reflectValueOf(public), s.level+1); err != nil {
+				return err
+			}
+		}
+
+		return fn(public)
+	}
+
+	if s.source.Kind() == reflect.Chan {
+		return s.receive(each)
+	}
+
+	var err error
+	yield := reflect.MakeFunc(s.source.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		err = each(args[0].Interface())
+		return []reflect.Value{reflect.ValueOf(err == nil)}
+	})
+	s.source.Call([]reflect.Value{yield})
+
+	return err
+}
+
+// receive calls each with the elements received from the channel until it is
+// closed or the context is done.
+func (s *publicSequence) receive(each func(element interface{}) error) error {
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: s.source},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.ctx.Done())},
+	}
+
+	for {
+		chosen, element, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return s.ctx.Err()
+		}
+		if !ok {
+			return nil
+		}
+		if err := each(element.Interface()); err != nil {
+			return err
+		}
+	}
+}
+
+// sequenceSlice gets the public data of the elements of the sequence as a
+// slice, for codecs that can't write them as they come.
+func sequenceSlice(ctx context.Context, value reflect.Value, level int, options map[string]interface{}) ([]interface{}, error) {
+
+	sequence := &publicSequence{ctx: ctx, source: value, options: options, level: level}
+
+	elements := []interface{}{}
+	err := sequence.Each(func(element interface{}) error {
+		elements = append(elements, element)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return elements, nil
+}
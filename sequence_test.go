@@ -0,0 +1,86 @@
+package codecs
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// authors is an iterator function over authors.
+func authors(names ...string) func(yield func(*author) bool) {
+	return func(yield func(*author) bool) {
+		for _, name := range names {
+			if !yield(&author{Name: name}) {
+				return
+			}
+		}
+	}
+}
+
+func TestIsSequence(t *testing.T) {
+
+	assert.True(t, IsSequence(make(chan int)))
+	assert.True(t, IsSequence((<-chan int)(nil)))
+	assert.True(t, IsSequence(authors()))
+	assert.True(t, IsSequence(func(yield func(interface{}) bool) {}))
+
+	assert.False(t, IsSequence(make(chan<- int)))
+	assert.False(t, IsSequence(func() {}))
+	assert.False(t, IsSequence(func(yield func(int)) {}))
+	assert.False(t, IsSequence([]int{1}))
+	assert.False(t, IsSequence(nil))
+
+}
+
+func TestPublicData_Sequences(t *testing.T) {
+
+	items := make(chan *author, 2)
+	items <- &author{Name: "Mat"}
+	items <- &author{Name: "Tyler"}
+	close(items)
+
+	public, err := PublicData(items, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Mat"}, map[string]interface{}{"name": "Tyler"}}, public)
+	}
+
+	public, err = PublicData(authors("Mat"), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Mat"}}, public)
+	}
+
+	public, err = PublicData(authors(), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{}, public)
+	}
+
+}
+
+func TestPublicDataSequence(t *testing.T) {
+
+	_, ok := PublicDataSequence(context.Background(), []int{1}, nil)
+	assert.False(t, ok)
+
+	sequence, ok := PublicDataSequence(context.Background(), authors("Mat", "Tyler", "Ed"), Options{KeyCase: PascalCase}.Map())
+	if assert.True(t, ok) {
+
+		var got []interface{}
+		err := sequence.Each(func(element interface{}) error {
+			got = append(got, element)
+			if len(got) == 2 {
+				return assert.AnError
+			}
+			return nil
+		})
+
+		assert.Equal(t, assert.AnError, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"Name": "Mat"}, map[string]interface{}{"Name": "Tyler"}}, got)
+	}
+
+	// waiting on a channel stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sequence, _ = PublicDataSequence(ctx, make(chan *author), nil)
+	assert.Equal(t, context.Canceled, sequence.Each(func(element interface{}) error { return nil }))
+
+}
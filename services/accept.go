@@ -0,0 +1,419 @@
+package services
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is a media range from an Accept header, as described by RFC 7231
+// section 5.3.2.
+type MediaRange struct {
+
+	// Type and Subtype are the lower case parts of the media range, either of
+	// which may be "*".
+	Type    string
+	Subtype string
+
+	// Params holds the media type parameters (those before the q parameter),
+	// keyed by their lower case names.
+	Params map[string]string
+
+	// Quality is the q value, between 0 and 1.
+	Quality float64
+}
+
+// String gets the media range without its parameters.
+func (m MediaRange) String() string {
+	return m.Type + "/" + m.Subtype
+}
+
+// specificity ranks the media range so that more specific ranges come first
+// among ranges of equal quality.
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	case len(m.Params) > 0:
+		return 3
+	}
+	return 2
+}
+
+// AcceptDiagnostic describes a malformed media range ParseAccept skipped, for
+// logging clients that send bad Accept headers.
+type AcceptDiagnostic struct {
+
+	// Range is the malformed media range, as it was given.
+	Range string
+
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+// String gets the diagnostic as text.
+func (d AcceptDiagnostic) String() string {
+	return fmt.Sprintf("%q: %s", d.Range, d.Reason)
+}
+
+// ParseAccept parses the Accept header into its media ranges, ordered by
+// quality and then specificity (keeping the header's order for ties).  Media
+// ranges that are malformed or have an invalid q value are skipped.
+func ParseAccept(accept string) []MediaRange {
+	ranges, _ := ParseAcceptWithDiagnostics(accept)
+	return ranges
+}
+
+// ParseAcceptWithDiagnostics parses the Accept header as ParseAccept does,
+// also describing the malformed media ranges it skipped.  Empty elements and
+// parameters (as in "text/html;,") are ignored rather than reported.
+func ParseAcceptWithDiagnostics(accept string) ([]MediaRange, []AcceptDiagnostic) {
+
+	var ranges []MediaRange
+	var diagnostics []AcceptDiagnostic
+	for _, element := range splitQuoted(accept, ',') {
+
+		if len(strings.TrimSpace(element)) == 0 {
+			continue
+		}
+
+		if mediaRange, reason := parseMediaRange(element); len(reason) == 0 {
+			ranges = append(ranges, mediaRange)
+		} else {
+			diagnostics = append(diagnostics, AcceptDiagnostic{Range: strings.TrimSpace(element), Reason: reason})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].Quality != ranges[j].Quality {
+			return ranges[i].Quality > ranges[j].Quality
+		}
+		return ranges[i].specificity() > ranges[j].specificity()
+	})
+
+	return ranges, diagnostics
+}
+
+// parseMediaRange parses a single media range, with its parameters, getting
+// the reason it is malformed if it is.
+func parseMediaRange(element string) (MediaRange, string) {
+
+	parts := splitQuoted(element, ';')
+
+	mediaType := strings.ToLower(strings.TrimSpace(parts[0]))
+	slash := strings.Index(mediaType, "/")
+	if slash <= 0 || slash == len(mediaType)-1 || !isToken(mediaType[:slash]) || !isToken(mediaType[slash+1:]) {
+		return MediaRange{}, "the media type isn't of the form type/subtype"
+	}
+
+	mediaRange := MediaRange{
+		Type:    mediaType[:slash],
+		Subtype: mediaType[slash+1:],
+		Params:  map[string]string{},
+		Quality: 1,
+	}
+
+	if mediaRange.Type == "*" && mediaRange.Subtype != "*" {
+		return MediaRange{}, "a wildcard type has a subtype other than *"
+	}
+
+	for _, param := range parts[1:] {
+
+		if len(strings.TrimSpace(param)) == 0 {
+			continue
+		}
+
+		equals := strings.Index(param, "=")
+		if equals < 0 {
+			return MediaRange{}, fmt.Sprintf("the parameter %q has no value", strings.TrimSpace(param))
+		}
+
+		name := strings.ToLower(strings.TrimSpace(param[:equals]))
+		value := strings.TrimSpace(param[equals+1:])
+		if !isToken(name) {
+			return MediaRange{}, fmt.Sprintf("the parameter name %q isn't a token", name)
+		}
+
+		if name == "q" {
+			quality, ok := parseQuality(value)
+			if !ok {
+				return MediaRange{}, fmt.Sprintf("the q value %q isn't between 0 and 1 with at most three decimal places", value)
+			}
+			mediaRange.Quality = quality
+
+			// anything after q is an accept-ext
+			break
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			unquoted, ok := unquote(value)
+			if !ok {
+				return MediaRange{}, fmt.Sprintf("the quoted value of the parameter %q is unterminated", name)
+			}
+			value = unquoted
+		} else if !isToken(value) {
+			return MediaRange{}, fmt.Sprintf("the value of the parameter %q isn't a token", name)
+		}
+
+		mediaRange.Params[name] = value
+	}
+
+	return mediaRange, ""
+}
+
+// parseQuality parses a q value, which has at most three decimal places and
+// is between 0 and 1.
+func parseQuality(value string) (float64, bool) {
+
+	if len(value) == 0 || len(value) > 5 || (value[0] != '0' && value[0] != '1') {
+		return 0, false
+	}
+
+	if len(value) > 1 && value[1] != '.' {
+		return 0, false
+	}
+
+	for i := 2; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' || (value[0] == '1' && value[i] != '0') {
+			return 0, false
+		}
+	}
+
+	quality, err := strconv.ParseFloat(value, 64)
+
+	return quality, err == nil
+}
+
+// splitQuoted splits the string on the separator, ignoring separators within
+// quoted strings.
+func splitQuoted(s string, separator byte) []string {
+
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == separator:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// unquote gets the value of a quoted string.
+func unquote(s string) (string, bool) {
+
+	if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+		return "", false
+	}
+
+	var value []byte
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' {
+			i++
+			if i == len(s)-1 {
+				return "", false
+			}
+		}
+		value = append(value, s[i])
+	}
+
+	return string(value), true
+}
+
+// isToken gets whether the string is an RFC 7230 token.
+func isToken(s string) bool {
+
+	if len(s) == 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches gets whether the media range includes the type and subtype.
+func (m MediaRange) matches(mediaType, subtype string) bool {
+	return m.Type == "*" || (m.Type == mediaType && (m.Subtype == "*" || m.Subtype == subtype))
+}
+
+// suffix gets the structured syntax suffix of the media range's subtype (such
+// as "json" for application/vnd.example+json), or "" if it has none.
+func (m MediaRange) suffix() string {
+	if plus := strings.LastIndex(m.Subtype, "+"); plus >= 0 {
+		return m.Subtype[plus+1:]
+	}
+	return ""
+}
+
+// satisfies gets whether the media range's parameters are acceptable to a
+// codec understanding the parameters; required parameters must be given, and
+// values must be among the supported ones.  Parameters the codec doesn't
+// declare are ignored.
+func (m MediaRange) satisfies(parameters []codecs.Parameter) bool {
+
+	for _, parameter := range parameters {
+
+		value, given := m.Params[strings.ToLower(parameter.Name)]
+		if !given {
+			if parameter.Required {
+				return false
+			}
+			continue
+		}
+
+		if len(parameter.Values) == 0 {
+			continue
+		}
+
+		supported := false
+		for _, v := range parameter.Values {
+			if v == value {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolveParameters gets the parameters asked for by the params of the
+// matched media range (nil if no range was matched).  For codecs declaring
+// their parameters these are the declared parameters, taking their defaults
+// if they weren't given, and otherwise they are all of the params.
+func resolveParameters(params map[string]string, codec codecs.Codec) map[string]string {
+
+	resolved := map[string]string{}
+
+	parameterCodec, ok := codec.(codecs.ParameterCodec)
+	if !ok {
+		for name, value := range params {
+			resolved[name] = value
+		}
+		return resolved
+	}
+
+	for _, parameter := range parameterCodec.Parameters() {
+		name := strings.ToLower(parameter.Name)
+		if value, given := params[name]; given {
+			resolved[name] = value
+		} else if len(parameter.Default) > 0 {
+			resolved[name] = parameter.Default
+		}
+	}
+
+	return resolved
+}
+
+// acceptance gets the quality the ranges give the codec, taken from the most
+// specific range that matches its content type or one of its aliases (or,
+// failing that, one of its suffixes) and has parameters the codec can
+// satisfy, along with that range's position in the ranges (or -1 if none
+// match) and whether it matched the content type exactly.
+func acceptance(ranges []MediaRange, codec codecs.Codec) (float64, int, bool) {
+
+	contentTypes := codecContentTypes(codec)
+
+	var suffixes []string
+	if suffixCodec, ok := codec.(codecs.SuffixCodec); ok {
+		suffixes = suffixCodec.Suffixes()
+	}
+
+	var parameters []codecs.Parameter
+	if parameterCodec, ok := codec.(codecs.ParameterCodec); ok {
+		parameters = parameterCodec.Parameters()
+	}
+
+	best, exact := -1, false
+	for i, mediaRange := range ranges {
+
+		matched, exactly := false, false
+		for _, contentType := range contentTypes {
+			mediaType, subtype := parseMediaType(contentType)
+			if mediaRange.matches(mediaType, subtype) {
+				matched = true
+				exactly = exactly || (mediaRange.Type == mediaType && mediaRange.Subtype == subtype)
+			}
+		}
+		if !matched && len(mediaRange.suffix()) > 0 {
+			for _, suffix := range suffixes {
+				if strings.ToLower(suffix) == mediaRange.suffix() {
+					matched = true
+				}
+			}
+		}
+
+		// among equally specific ranges, a quality of 0 wins
+		if matched && mediaRange.satisfies(parameters) && (best < 0 || mediaRange.specificity() > ranges[best].specificity() ||
+			(mediaRange.specificity() == ranges[best].specificity() && mediaRange.Quality == 0)) {
+			best, exact = i, exactly
+		}
+	}
+
+	if best < 0 {
+		return 0, -1, false
+	}
+
+	return ranges[best].Quality, best, exact
+}
+
+// codecContentTypes gets the content type of the codec followed by its
+// aliases, if it has any.
+func codecContentTypes(codec codecs.Codec) []string {
+
+	contentTypes := []string{codec.ContentType()}
+	if aliasCodec, ok := codec.(codecs.AliasCodec); ok {
+		contentTypes = append(contentTypes, aliasCodec.Aliases()...)
+	}
+
+	return contentTypes
+}
+
+// isJSONP gets whether the codec is for JSONP, as either text/javascript or
+// application/javascript.
+func isJSONP(codec codecs.Codec) bool {
+	for _, contentType := range codecContentTypes(codec) {
+		if mediaType, subtype := parseMediaType(contentType); mediaType+"/"+subtype == constants.ContentTypeJSONP {
+			return true
+		}
+	}
+	return false
+}
+
+// forbids gets whether the ranges give the codec a quality of 0, refusing it.
+func forbids(ranges []MediaRange, codec codecs.Codec) bool {
+	quality, index, _ := acceptance(ranges, codec)
+	return index >= 0 && quality == 0
+}
+
+// parseMediaType gets the lower case type and subtype of a content type,
+// ignoring any parameters.
+func parseMediaType(contentType string) (string, string) {
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if slash := strings.Index(mediaType, "/"); slash >= 0 {
+		return mediaType[:slash], mediaType[slash+1:]
+	}
+
+	return mediaType, ""
+}
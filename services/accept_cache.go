@@ -0,0 +1,117 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultAcceptCacheSize is the number of negotiations a WebCodecService made
+// by NewWebCodecService remembers.
+const DefaultAcceptCacheSize = 256
+
+// acceptCache is a concurrency safe, least recently used cache of the results
+// of negotiating codecs for responding, keyed by the accept string, extension
+// and whether there is a callback.
+type acceptCache struct {
+	lock    sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// acceptCacheEntry is a remembered negotiation.
+type acceptCacheEntry struct {
+	key         string
+	negotiation *Negotiation
+	err         error
+}
+
+// newAcceptCache makes a cache remembering at most size negotiations.
+func newAcceptCache(size int) *acceptCache {
+	return &acceptCache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// acceptCacheKey gets the key for the negotiation.
+func acceptCacheKey(accept, extension string, hasCallback bool) string {
+	if hasCallback {
+		return "1" + extension + "\x00" + accept
+	}
+	return "0" + extension + "\x00" + accept
+}
+
+// get gets the negotiation remembered for the key.  The negotiation is copied,
+// so callers may change it.
+func (c *acceptCache) get(key string) (*Negotiation, error, bool) {
+
+	if c == nil {
+		return nil, nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	c.order.MoveToFront(element)
+
+	entry := element.Value.(*acceptCacheEntry)
+	return entry.negotiation.copy(), entry.err, true
+}
+
+// put remembers the negotiation for the key, forgetting the least recently
+// used one if the cache is full.
+func (c *acceptCache) put(key string, negotiation *Negotiation, err error) {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&acceptCacheEntry{key, negotiation.copy(), err})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*acceptCacheEntry).key)
+	}
+}
+
+// clear forgets every negotiation, for when the service changes.
+func (c *acceptCache) clear() {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// copyParams copies the media type parameters.
+func copyParams(params map[string]string) map[string]string {
+
+	if params == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(params))
+	for name, value := range params {
+		copied[name] = value
+	}
+	return copied
+}
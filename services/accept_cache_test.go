@@ -0,0 +1,72 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAcceptCache(t *testing.T) {
+
+	cache := newAcceptCache(2)
+	jsonCodec := new(json.JsonCodec)
+
+	cache.put("a", &Negotiation{Codec: jsonCodec, Params: map[string]string{"version": "1"}}, nil)
+	cache.put("b", &Negotiation{Codec: jsonCodec}, nil)
+
+	negotiation, _, ok := cache.get("a")
+	if assert.True(t, ok) {
+		assert.Equal(t, jsonCodec, negotiation.Codec)
+		assert.Equal(t, map[string]string{"version": "1"}, negotiation.Params)
+
+		// changing the negotiation doesn't change the cache
+		negotiation.Params["version"] = "2"
+		negotiation, _, _ = cache.get("a")
+		assert.Equal(t, "1", negotiation.Params["version"])
+	}
+
+	// b is the least recently used
+	cache.put("c", &Negotiation{Codec: jsonCodec}, nil)
+
+	_, _, ok = cache.get("b")
+	assert.False(t, ok)
+	_, _, ok = cache.get("a")
+	assert.True(t, ok)
+
+	cache.clear()
+	_, _, ok = cache.get("a")
+	assert.False(t, ok)
+
+}
+
+func TestGetCodecForResponding_Cache(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec)}}
+	service.SetAcceptCacheSize(10)
+
+	codec, _ := service.GetCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	assert.Equal(t, 1, service.cache.order.Len())
+
+	// adding codecs forgets what was negotiated
+	service.AddCodec(new(xml.SimpleXmlCodec))
+
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeXML, "", true)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	assert.Equal(t, 2, service.cache.order.Len())
+
+	service.SetStrict(true)
+	assert.Equal(t, 0, service.cache.order.Len())
+
+	service.SetAcceptCacheSize(0)
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	assert.Nil(t, service.cache)
+
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAccept(t *testing.T) {
+
+	ranges := ParseAccept(` text/*;q=0.3, text/html ;q=0.7, text/html;level=1,
+		text/html;level=2;q=0.4, */*;q=0.5 , application/json;q=0.700`)
+
+	var order []string
+	for _, r := range ranges {
+		order = append(order, r.String())
+	}
+
+	assert.Equal(t, []string{"text/html", "text/html", "application/json", "*/*", "text/html", "text/*"}, order)
+	assert.Equal(t, map[string]string{"level": "1"}, ranges[0].Params)
+	assert.Equal(t, 1.0, ranges[0].Quality)
+	assert.Equal(t, 0.7, ranges[2].Quality)
+	assert.Equal(t, map[string]string{"level": "2"}, ranges[4].Params)
+
+}
+
+func TestParseAccept_Params(t *testing.T) {
+
+	ranges := ParseAccept(`Application/JSON; Profile="a;b,c"; q=1; ext=ignored`)
+
+	if assert.Equal(t, 1, len(ranges)) {
+		assert.Equal(t, "application", ranges[0].Type)
+		assert.Equal(t, "json", ranges[0].Subtype)
+		assert.Equal(t, map[string]string{"profile": "a;b,c"}, ranges[0].Params)
+	}
+
+}
+
+func TestParseAccept_Invalid(t *testing.T) {
+
+	for _, accept := range []string{
+		"json",
+		"application/",
+		"*/json",
+		"application/json;q=1.5",
+		"application/json;q=1.001",
+		"application/json;q=0.0001",
+		"application/json;q=.5",
+		"application/json;q",
+		`application/json;profile="unterminated`,
+	} {
+		assert.Equal(t, 0, len(ParseAccept(accept)), accept)
+	}
+
+	assert.Equal(t, 2, len(ParseAccept("text/xml,,bad,application/json")))
+
+}
+
+func TestParseAcceptWithDiagnostics(t *testing.T) {
+
+	ranges, diagnostics := ParseAcceptWithDiagnostics(" text/html;; level=1 ; , bad, application/json;q=, */json, text/plain;q=0.5")
+
+	if assert.Equal(t, 2, len(ranges)) {
+		assert.Equal(t, "text/html", ranges[0].String())
+		assert.Equal(t, map[string]string{"level": "1"}, ranges[0].Params)
+		assert.Equal(t, "text/plain", ranges[1].String())
+	}
+
+	if assert.Equal(t, 3, len(diagnostics)) {
+		assert.Equal(t, "bad", diagnostics[0].Range)
+		assert.Equal(t, "the media type isn't of the form type/subtype", diagnostics[0].Reason)
+		assert.Equal(t, "application/json;q=", diagnostics[1].Range)
+		assert.Equal(t, "*/json", diagnostics[2].Range)
+	}
+
+	// nothing panics, however bad the header
+	for _, accept := range []string{";", ";;q=", "/", "*/", `"`, `a/b;c="\`, "a/b;=1", "a/b;q=1;q", ",,,", "\x00/\xff"} {
+		assert.NotPanics(t, func() { ParseAcceptWithDiagnostics(accept) }, accept)
+	}
+
+}
+
+func TestGetCodecForResponding_Quality(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, _ := service.GetCodecForResponding("text/xml;q=0.5, application/json;q=0.9", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("application/json;q=0, text/csv;q=0.1", "", false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	// whole media types are matched, not parts of them
+	codec, _ = service.GetCodecForResponding("application/jsonp, text/xml;q=0.1", "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("application/json;q=0", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+}
+
+func TestGetCodecForResponding_Exclusions(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodecForResponding("text/xml;q=0, */*", "", false)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	// the fallback codec is skipped if it is forbidden
+	codec, err = service.GetCodecForResponding("application/json;q=0", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSONP, codec.ContentType())
+	}
+
+	codec, err = service.GetCodecForResponding("text/javascript;q=0", "", true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	// a refusal beats an equally specific acceptance
+	codec, _ = service.GetCodecForResponding("text/xml, text/xml;q=0, text/csv;q=0.1", "", false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	_, err = service.GetCodecForResponding("*/*;q=0", "", false)
+	assert.IsType(t, &NotAcceptableError{}, err)
+
+}
+
+func TestGetCodecForResponding_TieBreaking(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	// the order of the accept string makes no difference
+	for _, accept := range []string{"text/xml, application/json", "application/json, text/xml"} {
+		codec, _ := service.GetCodecForResponding(accept, "", false)
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType(), accept)
+	}
+
+	// more specific ranges beat wildcards
+	for _, accept := range []string{"text/*, text/csv", "text/csv, text/*", "*/*, text/csv"} {
+		codec, _ := service.GetCodecForResponding(accept, "", false)
+		assert.Equal(t, constants.ContentTypeCSV, codec.ContentType(), accept)
+	}
+
+	// exact matches beat suffixes
+	codec, _ := service.GetCodecForResponding("application/vnd.myco.order+xml, text/csv", "", false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+}
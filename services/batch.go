@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/codecs"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// BatchItemError is the error marshalling one of the objects of a batch.
+type BatchItemError struct {
+
+	// Index is the index of the object in the batch.
+	Index int
+
+	// Err is the error marshalling it.
+	Err error
+}
+
+// BatchError is the error MarshalBatchWithCodec returns when some of the
+// objects couldn't be marshalled.  The objects that could are marshalled as
+// usual.
+type BatchError struct {
+
+	// Errors are the errors for the objects that couldn't be marshalled, in
+	// the order of the objects.
+	Errors []BatchItemError
+}
+
+func (e *BatchError) Error() string {
+
+	messages := make([]string, len(e.Errors))
+	for i, itemError := range e.Errors {
+		messages[i] = fmt.Sprintf("%d: %s", itemError.Index, itemError.Err)
+	}
+
+	return "Batch items could not be marshalled: " + strings.Join(messages, "; ")
+}
+
+// batchMode gets how the codec marshals batches.
+func batchMode(codec codecs.Codec) codecs.BatchMode {
+
+	if batchCodec, ok := codec.(codecs.BatchCodec); ok {
+		return batchCodec.BatchMode()
+	}
+
+	if codecs.CapabilitiesOf(codec).Binary {
+		return codecs.BatchMultipart
+	}
+
+	return codecs.BatchArray
+}
+
+// MarshalBatchWithCodec marshals the objects with the codec and options into
+// one body, in the codec's codecs.BatchMode: as an array, as documents
+// separated by the codec's DocumentSeparator, or as a multipart/mixed body with
+// a part (with a Content-ID of the object's index) for each object.  The
+// content type of the body is returned along with it.  Objects that can't be
+// marshalled are left out, and a *BatchError saying why is returned with the
+// body of the rest.
+func (s *WebCodecService) MarshalBatchWithCodec(codec codecs.Codec, objects []interface{}, options map[string]interface{}) ([]byte, string, error) {
+
+	var batchErr *BatchError
+	var marshalled [][]byte
+	var indexes []int
+
+	for index, object := range objects {
+
+		data, err := s.MarshalWithCodec(codec, object, options)
+
+		if err != nil {
+			if batchErr == nil {
+				batchErr = new(BatchError)
+			}
+			batchErr.Errors = append(batchErr.Errors, BatchItemError{index, err})
+			continue
+		}
+
+		marshalled = append(marshalled, data)
+		indexes = append(indexes, index)
+	}
+
+	var data []byte
+	contentType := codec.ContentType()
+
+	switch batchMode(codec) {
+	case codecs.BatchDocuments:
+
+		var separator []byte
+		if documentCodec, ok := codec.(codecs.DocumentCodec); ok {
+			separator = documentCodec.DocumentSeparator()
+		}
+		data = bytes.Join(marshalled, separator)
+
+	case codecs.BatchMultipart:
+
+		var buffer bytes.Buffer
+		writer := multipart.NewWriter(&buffer)
+
+		for i, item := range marshalled {
+
+			part, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type": {codec.ContentType()},
+				"Content-Id":   {strconv.Itoa(indexes[i])},
+			})
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			if _, err := part.Write(item); err != nil {
+				return nil, "", err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+
+		data = buffer.Bytes()
+		contentType = "multipart/mixed; boundary=" + writer.Boundary()
+
+	default:
+
+		marshallable := make([]interface{}, len(indexes))
+		for i, index := range indexes {
+			marshallable[i] = objects[index]
+		}
+
+		var err error
+		if data, err = s.MarshalWithCodec(codec, marshallable, options); err != nil {
+			return nil, "", err
+		}
+
+	}
+
+	if batchErr != nil {
+		return data, contentType, batchErr
+	}
+
+	return data, contentType, nil
+}
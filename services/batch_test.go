@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"testing"
+)
+
+func TestMarshalBatchWithCodec_Array(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	data, contentType, err := service.MarshalBatchWithCodec(new(json.JsonCodec), []interface{}{
+		map[string]interface{}{"name": "Mat"},
+		map[string]interface{}{"name": func() {}},
+		map[string]interface{}{"name": "Tyler"},
+	}, nil)
+
+	assert.Equal(t, `[{"name":"Mat"},{"name":"Tyler"}]`, string(data))
+	assert.Equal(t, "application/json", contentType)
+
+	if assert.IsType(t, &BatchError{}, err) {
+		batchErr := err.(*BatchError)
+		if assert.Equal(t, 1, len(batchErr.Errors)) {
+			assert.Equal(t, 1, batchErr.Errors[0].Index)
+			assert.Error(t, batchErr.Errors[0].Err)
+		}
+	}
+
+	_, _, err = service.MarshalBatchWithCodec(new(json.JsonCodec), []interface{}{map[string]interface{}{"name": "Mat"}}, nil)
+	assert.NoError(t, err)
+
+}
+
+func TestMarshalBatchWithCodec_Documents(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	data, contentType, err := service.MarshalBatchWithCodec(new(ndjsonCodec), []interface{}{
+		map[string]interface{}{"name": "Mat"},
+		map[string]interface{}{"name": "Tyler"},
+	}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\"name\":\"Mat\"}\n{\"name\":\"Tyler\"}", string(data))
+		assert.Equal(t, "application/x-ndjson", contentType)
+	}
+
+}
+
+func TestMarshalBatchWithCodec_Multipart(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	data, contentType, err := service.MarshalBatchWithCodec(new(binaryCodec), []interface{}{
+		map[string]interface{}{"name": "Mat"},
+		map[string]interface{}{"name": func() {}},
+		map[string]interface{}{"name": "Tyler"},
+	}, nil)
+
+	assert.IsType(t, &BatchError{}, err)
+
+	mediaType, params, parseErr := mime.ParseMediaType(contentType)
+	if assert.NoError(t, parseErr) {
+		assert.Equal(t, "multipart/mixed", mediaType)
+
+		reader := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+		for _, expected := range []struct{ id, body string }{{"0", `{"name":"Mat"}`}, {"2", `{"name":"Tyler"}`}} {
+			part, err := reader.NextPart()
+			if assert.NoError(t, err) {
+				body, _ := ioutil.ReadAll(part)
+				assert.Equal(t, expected.id, part.Header.Get("Content-ID"))
+				assert.Equal(t, "application/x-buffered", part.Header.Get("Content-Type"))
+				assert.Equal(t, expected.body, string(body))
+			}
+		}
+	}
+
+}
+
+// ndjsonCodec marshals batches as newline delimited JSON.
+type ndjsonCodec struct {
+	bufferedCodec
+}
+
+func (c *ndjsonCodec) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (c *ndjsonCodec) BatchMode() codecs.BatchMode {
+	return codecs.BatchDocuments
+}
+
+func (c *ndjsonCodec) DocumentSeparator() []byte {
+	return []byte("\n")
+}
+
+// binaryCodec is a JSON codec claiming to be binary.
+type binaryCodec struct {
+	bufferedCodec
+}
+
+func (c *binaryCodec) Binary() bool {
+	return true
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"mime"
+	"strings"
+)
+
+// ErrorCharsetNotSupported is the error for when a charset is given that cannot
+// be transcoded.
+var ErrorCharsetNotSupported = errors.New("Charset is not supported.")
+
+// charsetUTF8 is the charset codecs work in.
+const charsetUTF8 = "utf-8"
+
+// lookupCharset gets the encoding for the charset, or nil for UTF-8.
+func lookupCharset(charset string) (encoding.Encoding, error) {
+
+	if len(charset) == 0 || strings.EqualFold(charset, charsetUTF8) || strings.EqualFold(charset, "utf8") {
+		return nil, nil
+	}
+
+	enc, err := ianaindex.MIME.Encoding(charset)
+	if err != nil || enc == nil {
+		return nil, ErrorCharsetNotSupported
+	}
+
+	if enc == unicode.UTF8 {
+		return nil, nil
+	}
+
+	return enc, nil
+}
+
+// DecodeCharset transcodes the data from the charset into UTF-8.  Byte order
+// marks are honored, and removed.
+func DecodeCharset(data []byte, charset string) ([]byte, error) {
+
+	enc, err := lookupCharset(charset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if enc == nil {
+		return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), nil
+	}
+
+	decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), data)
+
+	return decoded, err
+}
+
+// EncodeCharset transcodes the UTF-8 data into the charset, returning the
+// charset's preferred name.  Characters the charset cannot represent cause an
+// error.
+func EncodeCharset(data []byte, charset string) ([]byte, string, error) {
+
+	enc, err := lookupCharset(charset)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if enc == nil {
+		return data, charsetUTF8, nil
+	}
+
+	encoded, err := enc.NewEncoder().Bytes(data)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	name, err := ianaindex.MIME.Name(enc)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return encoded, name, nil
+}
+
+// contentTypeCharset gets the charset parameter of the content type.
+func contentTypeCharset(contentType string) string {
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		return params["charset"]
+	}
+
+	return ""
+}
+
+// isTextual gets whether the content type holds text, and so can be
+// transcoded.
+func isTextual(contentType string) bool {
+
+	mediaType, subtype := parseMediaType(contentType)
+	if mediaType == "text" {
+		return true
+	}
+
+	switch subtype {
+	case "json", "javascript", "xml", "xhtml+xml", "edn", "json5":
+		return true
+	}
+
+	return strings.HasSuffix(subtype, "+json") || strings.HasSuffix(subtype, "+xml")
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/csv"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDecodeCharset(t *testing.T) {
+
+	decoded, err := DecodeCharset([]byte("caf\xe9"), "ISO-8859-1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "café", string(decoded))
+	}
+
+	decoded, err = DecodeCharset([]byte("\xfe\xff\x00h\x00i"), "UTF-16")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hi", string(decoded))
+	}
+
+	decoded, err = DecodeCharset([]byte("\x93\xfa\x96\x7b"), "Shift_JIS")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "日本", string(decoded))
+	}
+
+	decoded, err = DecodeCharset([]byte("\xef\xbb\xbf{}"), "UTF-8")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{}", string(decoded))
+	}
+
+	_, err = DecodeCharset([]byte("x"), "klingon")
+	assert.Equal(t, ErrorCharsetNotSupported, err)
+
+}
+
+func TestEncodeCharset(t *testing.T) {
+
+	encoded, charset, err := EncodeCharset([]byte("café"), "latin1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "caf\xe9", string(encoded))
+		assert.Equal(t, "ISO-8859-1", charset)
+	}
+
+	encoded, charset, err = EncodeCharset([]byte("café"), "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "café", string(encoded))
+		assert.Equal(t, "utf-8", charset)
+	}
+
+	_, _, err = EncodeCharset([]byte("日本"), "ISO-8859-1")
+	assert.Error(t, err)
+
+}
+
+func TestMarshalWithCodecInCharset(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	data, charset, err := service.MarshalWithCodecInCharset(new(json.JsonCodec), map[string]interface{}{"name": "café"}, nil, "iso-8859-1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\"name\":\"caf\xe9\"}", string(data))
+		assert.Equal(t, "ISO-8859-1", charset)
+	}
+
+	data, charset, err = service.MarshalWithCodecInCharset(new(csv.CsvCodec), map[string]interface{}{"name": "café"}, nil, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "utf-8", charset)
+	}
+
+}
+
+func TestUnmarshalWithCodecAndContentType(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var object map[string]interface{}
+	err := service.UnmarshalWithCodecAndContentType(new(json.JsonCodec), []byte("{\"name\":\"caf\xe9\"}"), "application/json; charset=ISO-8859-1", &object)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "café", object["name"])
+	}
+
+	err = service.UnmarshalWithCodecAndContentType(new(json.JsonCodec), []byte("{}"), "application/json; charset=klingon", &object)
+	assert.Equal(t, ErrorCharsetNotSupported, err)
+
+}
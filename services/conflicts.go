@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"strings"
+)
+
+// ConflictPolicy says what AddCodec and TryAddCodec do when the codec being added has
+// the same content type (or alias) or file extension as an installed codec.
+type ConflictPolicy int
+
+const (
+	// ConflictError refuses the codec with a *CodecConflictError, which
+	// AddCodec, having no error to return, panics with.
+	ConflictError ConflictPolicy = iota
+
+	// ConflictReplace installs the codec in place of the codecs it conflicts
+	// with.
+	ConflictReplace
+
+	// ConflictShadow installs the codec after the codecs it conflicts with,
+	// which are used ahead of it.
+	ConflictShadow
+)
+
+// CodecConflictError is the error TryAddCodec returns when the codec being
+// added conflicts with an installed codec (see SetConflictPolicy).
+type CodecConflictError struct {
+	// Codec is the codec being added, and Installed the codec it conflicts
+	// with.
+	Codec, Installed codecs.Codec
+
+	// ContentType is the content type both codecs handle, or empty if they
+	// don't conflict by content type.
+	ContentType string
+
+	// FileExtension is the file extension both codecs have, or empty if
+	// they don't conflict by file extension.
+	FileExtension string
+}
+
+func (e *CodecConflictError) Error() string {
+	if len(e.ContentType) > 0 {
+		return fmt.Sprintf("A codec for content type \"%s\" is already installed.", e.ContentType)
+	}
+	return fmt.Sprintf("A codec for file extension \"%s\" is already installed.", e.FileExtension)
+}
+
+// SetConflictPolicy sets what AddCodec and TryAddCodec do when the codec being added has
+// the same content type (or alias) or file extension as an installed codec.  The
+// policy is ConflictError unless set.  Codecs whose media type parameters
+// (such as the version of codecs.VersionedCodec) tell them apart don't
+// conflict.
+func (s *WebCodecService) SetConflictPolicy(policy ConflictPolicy) {
+	s.conflictPolicy = policy
+}
+
+// conflict gets the first installed codec the codec conflicts with, if there
+// is one.
+func (s *WebCodecService) conflict(codec codecs.Codec) *CodecConflictError {
+	for _, installed := range s.codecs {
+		if conflict := conflictBetween(codec, installed); conflict != nil {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// conflictBetween gets the conflict between the codec and the installed codec,
+// if they conflict.
+func conflictBetween(codec, installed codecs.Codec) *CodecConflictError {
+
+	if distinguished(codec, installed) {
+		return nil
+	}
+
+	for _, contentType := range codecContentTypes(codec) {
+		mediaType, subtype := parseMediaType(contentType)
+		for _, installedContentType := range codecContentTypes(installed) {
+			if installedType, installedSubtype := parseMediaType(installedContentType); installedType == mediaType && installedSubtype == subtype {
+				return &CodecConflictError{Codec: codec, Installed: installed, ContentType: strings.ToLower(contentType)}
+			}
+		}
+	}
+
+	if extension := normalizeExtension(codec.FileExtension()); len(extension) > 0 && extension == normalizeExtension(installed.FileExtension()) {
+		return &CodecConflictError{Codec: codec, Installed: installed, FileExtension: extension}
+	}
+
+	return nil
+}
+
+// distinguished gets whether the codecs' media type parameters tell them
+// apart, as when both declare a parameter (such as version) with no supported
+// value in common.
+func distinguished(codec, other codecs.Codec) bool {
+
+	parameterCodec, ok := codec.(codecs.ParameterCodec)
+	otherParameterCodec, otherOk := other.(codecs.ParameterCodec)
+	if !ok || !otherOk {
+		return false
+	}
+
+	for _, parameter := range parameterCodec.Parameters() {
+		for _, otherParameter := range otherParameterCodec.Parameters() {
+			if strings.ToLower(parameter.Name) == strings.ToLower(otherParameter.Name) && len(parameter.Values) > 0 && len(otherParameter.Values) > 0 && !shareValue(parameter.Values, otherParameter.Values) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// shareValue gets whether the lists of values have a value in common.
+func shareValue(values, others []string) bool {
+	for _, value := range values {
+		for _, other := range others {
+			if value == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replaceConflicting installs the codec in place of the installed codecs it
+// conflicts with, at the position of the first of them.
+func (s *WebCodecService) replaceConflicting(codec codecs.Codec) {
+
+	installed := make([]codecs.Codec, 0, len(s.codecs))
+	replaced := false
+
+	for _, existing := range s.codecs {
+
+		if conflictBetween(codec, existing) != nil {
+			if !replaced {
+				installed = append(installed, codec)
+				replaced = true
+			}
+			if s.defaultCodec == existing {
+				s.defaultCodec = codec
+			}
+			continue
+		}
+
+		installed = append(installed, existing)
+	}
+
+	s.codecs = installed
+}
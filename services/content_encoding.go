@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// Content codings understood without registering them.
+const (
+	ContentEncodingGzip     = "gzip"
+	ContentEncodingDeflate  = "deflate"
+	ContentEncodingIdentity = "identity"
+)
+
+// ErrorContentEncodingNotSupported is the error for when a content coding is
+// given that has not been registered.
+var ErrorContentEncodingNotSupported = errors.New("Content encoding is not supported.")
+
+// ErrorContentEncodingNotAcceptable is the error for when the Accept-Encoding
+// header rules out every registered content coding, including identity.
+var ErrorContentEncodingNotAcceptable = errors.New("Content encoding is not acceptable.")
+
+// ContentEncoding compresses and decompresses data in a content coding.
+type ContentEncoding struct {
+
+	// NewWriter makes a writer compressing into w.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader makes a reader decompressing r.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	contentEncodings = map[string]ContentEncoding{
+		ContentEncodingGzip: {
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+			NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		},
+		ContentEncodingDeflate: {
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+			NewReader: zlib.NewReader,
+		},
+	}
+
+	// contentEncodingOrder holds the registered content codings in order of
+	// preference.
+	contentEncodingOrder = []string{ContentEncodingGzip, ContentEncodingDeflate}
+	contentEncodingsLock sync.RWMutex
+)
+
+// RegisterContentEncoding registers the content coding with the given name
+// (such as "br"), which is preferred after those already registered when the
+// Accept-Encoding header gives them the same quality.  Registering a nil
+// NewWriter removes the content coding.
+func RegisterContentEncoding(name string, coding ContentEncoding) {
+
+	contentEncodingsLock.Lock()
+	defer contentEncodingsLock.Unlock()
+
+	name = strings.ToLower(name)
+
+	for i, registered := range contentEncodingOrder {
+		if registered == name {
+			contentEncodingOrder = append(contentEncodingOrder[:i:i], contentEncodingOrder[i+1:]...)
+			break
+		}
+	}
+	delete(contentEncodings, name)
+
+	if coding.NewWriter == nil {
+		return
+	}
+	contentEncodings[name] = coding
+	contentEncodingOrder = append(contentEncodingOrder, name)
+}
+
+// lookupContentEncoding gets the content coding registered with the name.
+func lookupContentEncoding(name string) (ContentEncoding, bool) {
+
+	contentEncodingsLock.RLock()
+	defer contentEncodingsLock.RUnlock()
+
+	coding, ok := contentEncodings[strings.ToLower(name)]
+	return coding, ok
+}
+
+// NegotiateContentEncoding gets the content coding to respond in based on the
+// Accept-Encoding header, as described by RFC 7231 section 5.3.4.  The
+// registered content coding with the highest quality is used, with identity
+// (returned as "") used if the header is empty, gives identity a higher
+// quality, or rules out all of the registered content codings.  Malformed
+// codings in the header are skipped.
+func NegotiateContentEncoding(acceptEncoding string) (string, error) {
+
+	qualities := map[string]float64{}
+	for _, element := range splitQuoted(acceptEncoding, ',') {
+
+		parts := splitQuoted(element, ';')
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !isToken(name) {
+			continue
+		}
+
+		quality, ok := 1.0, true
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(strings.ToLower(param), "q=") {
+				quality, ok = parseQuality(strings.TrimSpace(param[2:]))
+			}
+		}
+
+		if ok {
+			qualities[name] = quality
+		}
+	}
+
+	// qualityOf gets the quality given to the content coding
+	qualityOf := func(name string, implicit float64) float64 {
+		if quality, ok := qualities[name]; ok {
+			return quality
+		}
+		if quality, ok := qualities["*"]; ok {
+			return quality
+		}
+		return implicit
+	}
+
+	if len(qualities) == 0 {
+		return "", nil
+	}
+
+	contentEncodingsLock.RLock()
+	defer contentEncodingsLock.RUnlock()
+
+	best, bestQuality := "", 0.0
+	for _, name := range contentEncodingOrder {
+		if quality := qualityOf(name, 0); quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+
+	// identity is acceptable unless it is ruled out, but is only preferred
+	// when the header gives it a higher quality
+	if best == "" || qualityOf(ContentEncodingIdentity, 0) > bestQuality {
+		if qualityOf(ContentEncodingIdentity, 1) == 0 {
+			return "", ErrorContentEncodingNotAcceptable
+		}
+		return "", nil
+	}
+
+	return best, nil
+}
+
+// Compress encodes the data in the content coding.  The identity coding (or
+// an empty one) leaves the data as it is.
+func Compress(data []byte, contentEncoding string) ([]byte, error) {
+
+	if len(contentEncoding) == 0 || strings.EqualFold(contentEncoding, ContentEncodingIdentity) {
+		return data, nil
+	}
+
+	coding, ok := lookupContentEncoding(contentEncoding)
+	if !ok {
+		return nil, ErrorContentEncodingNotSupported
+	}
+
+	var buffer bytes.Buffer
+	writer, err := coding.NewWriter(&buffer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Decompress decodes the data from the content coding.  The identity coding
+// (or an empty one) leaves the data as it is.
+func Decompress(data []byte, contentEncoding string) ([]byte, error) {
+
+	if len(contentEncoding) == 0 || strings.EqualFold(contentEncoding, ContentEncodingIdentity) {
+		return data, nil
+	}
+
+	reader, err := DecompressReader(bytes.NewReader(data), contentEncoding)
+
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// DecompressReader gets a reader decoding r from the content coding, so that
+// the decompressed data can be limited as it is read (see
+// WebCodecService.LimitReader).  The identity coding (or an empty one) reads
+// r as it is.
+func DecompressReader(r io.Reader, contentEncoding string) (io.ReadCloser, error) {
+
+	if len(contentEncoding) == 0 || strings.EqualFold(contentEncoding, ContentEncodingIdentity) {
+		return ioutil.NopCloser(r), nil
+	}
+
+	coding, ok := lookupContentEncoding(contentEncoding)
+	if !ok || coding.NewReader == nil {
+		return nil, ErrorContentEncodingNotSupported
+	}
+
+	return coding.NewReader(r)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNegotiateContentEncoding(t *testing.T) {
+
+	for acceptEncoding, expected := range map[string]string{
+		"":                          "",
+		"gzip, deflate":             "gzip",
+		"deflate;q=0.8, gzip;q=0.5": "deflate",
+		"GZIP;q=0.5":                "gzip",
+		"br":                        "",
+		"*":                         "gzip",
+		"*;q=0.5, gzip;q=0":         "deflate",
+		"gzip;q=0.5, identity":      "",
+		"gzip, identity;q=0.5":      "gzip",
+		"gzip;q=0, identity;q=0.1":  "",
+		"gzip;q=2, deflate;q=0.1":   "deflate",
+	} {
+		contentEncoding, err := NegotiateContentEncoding(acceptEncoding)
+		if assert.NoError(t, err, acceptEncoding) {
+			assert.Equal(t, expected, contentEncoding, acceptEncoding)
+		}
+	}
+
+	_, err := NegotiateContentEncoding("br, identity;q=0")
+	assert.Equal(t, ErrorContentEncodingNotAcceptable, err)
+
+	_, err = NegotiateContentEncoding("*;q=0")
+	assert.Equal(t, ErrorContentEncodingNotAcceptable, err)
+
+}
+
+func TestCompress(t *testing.T) {
+
+	for _, contentEncoding := range []string{ContentEncodingGzip, ContentEncodingDeflate, ContentEncodingIdentity, ""} {
+
+		compressed, err := Compress([]byte("hello hello hello"), contentEncoding)
+		if assert.NoError(t, err, contentEncoding) {
+
+			decompressed, err := Decompress(compressed, contentEncoding)
+			if assert.NoError(t, err, contentEncoding) {
+				assert.Equal(t, "hello hello hello", string(decompressed))
+			}
+		}
+	}
+
+	_, err := Compress([]byte("hello"), "br")
+	assert.Equal(t, ErrorContentEncodingNotSupported, err)
+
+	_, err = Decompress([]byte("hello"), "br")
+	assert.Equal(t, ErrorContentEncodingNotSupported, err)
+
+}
+
+func TestDecompressReader(t *testing.T) {
+
+	compressed, _ := Compress([]byte("hello hello hello"), ContentEncodingGzip)
+
+	reader, err := DecompressReader(bytes.NewReader(compressed), ContentEncodingGzip)
+	if assert.NoError(t, err) {
+
+		// the decompressed data can be limited as it is read
+		service := NewWebCodecService(WithMaxPayloadSize(5))
+		_, err := ioutil.ReadAll(service.LimitReader(reader, nil))
+		if assert.IsType(t, &PayloadTooLargeError{}, err) {
+			assert.Equal(t, int64(5), err.(*PayloadTooLargeError).Limit)
+		}
+	}
+
+	_, err = DecompressReader(bytes.NewReader(compressed), "br")
+	assert.Equal(t, ErrorContentEncodingNotSupported, err)
+
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestRegisterContentEncoding(t *testing.T) {
+
+	RegisterContentEncoding("plain", ContentEncoding{
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+	})
+	defer RegisterContentEncoding("plain", ContentEncoding{})
+
+	contentEncoding, _ := NegotiateContentEncoding("plain, gzip")
+	assert.Equal(t, "gzip", contentEncoding)
+
+	contentEncoding, _ = NegotiateContentEncoding("Plain, gzip;q=0.5")
+	assert.Equal(t, "plain", contentEncoding)
+
+	compressed, err := Compress([]byte("hello"), "plain")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hello", string(compressed))
+	}
+
+	_, err = Decompress(compressed, "plain")
+	assert.Equal(t, ErrorContentEncodingNotSupported, err)
+
+}
+
+func TestMarshalWithCodecAndContentEncoding(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	contentEncoding, err := service.GetContentEncodingForResponding("deflate, gzip;q=0.9")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ContentEncodingDeflate, contentEncoding)
+	}
+
+	data, err := service.MarshalWithCodecAndContentEncoding(new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil, contentEncoding)
+	if assert.NoError(t, err) {
+
+		decompressed, err := Decompress(data, contentEncoding)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `{"name":"Mat"}`, string(decompressed))
+		}
+	}
+
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs"
+	"time"
+)
+
+// GetCodecForRespondingContext gets the codec to use to respond as
+// GetCodecForResponding does, unless the context is already done, in which
+// case its error is returned.
+func (s *WebCodecService) GetCodecForRespondingContext(ctx context.Context, accept, extension string, hasCallback bool) (codecs.Codec, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, span := s.startSpan(ctx, SpanNegotiate, nil)
+	codec, err := s.GetCodecForResponding(accept, extension, hasCallback)
+
+	if span != nil {
+		span.SetAttribute(AttributeAccept, accept)
+		if codec != nil {
+			setCodecAttributes(span, codec)
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+
+	return codec, err
+}
+
+// MarshalWithCodecContext marshals the object as MarshalWithCodec does,
+// passing the context to codecs implementing codecs.ContextCodec and objects
+// implementing codecs.FacadeWithContext.  Other
+// codecs can't be interrupted, but the context's error is returned if it is
+// done before or while they marshal.
+func (s *WebCodecService) MarshalWithCodecContext(ctx context.Context, codec codecs.Codec, object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	started := time.Now()
+	ctx, span := s.startSpan(ctx, SpanMarshal, codec)
+	data, err := s.marshalWithCodecContext(ctx, codec, object, options)
+	s.recordCoding(LogEventMarshal, codec, started, int64(len(data)), err)
+	endSpan(span, int64(len(data)), err)
+
+	return data, err
+}
+
+// marshalWithCodecContext does the work of MarshalWithCodecContext.
+func (s *WebCodecService) marshalWithCodecContext(ctx context.Context, codec codecs.Codec, object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	// make sure we have at least one codec
+	s.assertCodecs()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// use the codec's default options
+	options = s.codecOptions(codec, options)
+
+	// run the hooks
+	object, err := s.beforeMarshal(codec, object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// get the public data
+	publicData, err := s.publicData(ctx, codec, object, options)
+
+	// if there was an error - return it
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if s.marshalsEmpty(publicData) {
+		data = []byte{}
+	} else if contextCodec, ok := codec.(codecs.ContextCodec); ok {
+		data, err = contextCodec.MarshalContext(ctx, publicData, options)
+	} else {
+		data, err = codec.Marshal(publicData, options)
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.afterMarshal(codec, object, options, data)
+}
+
+// UnmarshalWithCodecContext unmarshals the data into the object as
+// UnmarshalWithCodec does, passing the context to codecs implementing
+// codecs.ContextCodec.  Other codecs can't be interrupted, but the context's
+// error is returned if it is done before or while they unmarshal.
+func (s *WebCodecService) UnmarshalWithCodecContext(ctx context.Context, codec codecs.Codec, data []byte, object interface{}) error {
+
+	started := time.Now()
+	ctx, span := s.startSpan(ctx, SpanUnmarshal, codec)
+	err := s.unmarshalWithCodecContext(ctx, codec, data, object)
+	s.recordCoding(LogEventUnmarshal, codec, started, int64(len(data)), err)
+	endSpan(span, int64(len(data)), err)
+
+	return err
+}
+
+// unmarshalWithCodecContext does the work of UnmarshalWithCodecContext.
+func (s *WebCodecService) unmarshalWithCodecContext(ctx context.Context, codec codecs.Codec, data []byte, object interface{}) error {
+
+	// make sure we have at least one codec
+	s.assertCodecs()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// refuse data over the limit
+	if err := s.checkPayloadSize(codec, int64(len(data))); err != nil {
+		return err
+	}
+
+	// run the hooks
+	data, err := s.beforeUnmarshal(codec, data, object)
+
+	if err != nil {
+		return err
+	}
+
+	// refuse data exceeding the decode limits
+	if err := codecs.CheckLimits(codec, data, s.decodeLimits); err != nil {
+		return err
+	}
+
+	// deal with empty bodies as the policy says
+	if empty, err := s.emptyBody(data); empty {
+		return err
+	}
+
+	if contextCodec, ok := codec.(codecs.ContextCodec); ok {
+		return contextCodec.UnmarshalContext(ctx, data, object)
+	}
+
+	if err := codec.Unmarshal(data, object); err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// MarshalWithCodecInCharsetContext marshals the object as
+// MarshalWithCodecInCharset does, passing the context on as
+// MarshalWithCodecContext does.
+func (s *WebCodecService) MarshalWithCodecInCharsetContext(ctx context.Context, codec codecs.Codec, object interface{}, options map[string]interface{}, charset string) ([]byte, string, error) {
+
+	data, err := s.MarshalWithCodecContext(ctx, codec, object, options)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isTextual(codec.ContentType()) {
+		return data, "", nil
+	}
+
+	return EncodeCharset(data, charset)
+}
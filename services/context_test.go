@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type contextKey string
+
+// tracingCodec is a JSON codec that puts the trace ID from the context at the
+// start of its output.
+type tracingCodec struct {
+	json.JsonCodec
+}
+
+func (c *tracingCodec) MarshalContext(ctx context.Context, object interface{}, options map[string]interface{}) ([]byte, error) {
+	data, err := c.Marshal(object, options)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(ctx.Value(contextKey("trace")).(string)+" "), data...), nil
+}
+
+func (c *tracingCodec) UnmarshalContext(ctx context.Context, data []byte, obj interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Unmarshal(data, obj)
+}
+
+func TestMarshalWithCodecContext(t *testing.T) {
+
+	service := NewWebCodecService()
+	ctx := context.WithValue(context.Background(), contextKey("trace"), "abc")
+
+	data, err := service.MarshalWithCodecContext(ctx, new(tracingCodec), map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `abc {"name":"Mat"}`, string(data))
+	}
+
+	data, err = service.MarshalWithCodecContext(ctx, new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = service.MarshalWithCodecContext(cancelled, new(json.JsonCodec), nil, nil)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = service.GetCodecForRespondingContext(cancelled, "", "", false)
+	assert.Equal(t, context.Canceled, err)
+
+}
+
+func TestUnmarshalWithCodecContext(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var object map[string]interface{}
+	if assert.NoError(t, service.UnmarshalWithCodecContext(context.Background(), new(tracingCodec), []byte(`{"name":"Mat"}`), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, service.UnmarshalWithCodecContext(cancelled, new(json.JsonCodec), []byte(`{}`), &object))
+
+}
+
+// greeting is a FacadeWithContext whose public data is the trace ID from the
+// context.
+type greeting struct{}
+
+func (g *greeting) PublicData(options map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"trace": ""}, nil
+}
+
+func (g *greeting) PublicDataContext(ctx context.Context, options map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"trace": ctx.Value(contextKey("trace"))}, nil
+}
+
+func TestMarshalWithCodecContext_FacadeWithContext(t *testing.T) {
+
+	service := NewWebCodecService()
+	ctx := context.WithValue(context.Background(), contextKey("trace"), "abc")
+
+	data, err := service.MarshalWithCodecContext(ctx, new(json.JsonCodec), new(greeting), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"trace":"abc"}`, string(data))
+	}
+
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// ErrorEmptyBody is returned when unmarshalling an empty body with the
+// EmptyBodyError policy (see SetEmptyBodyPolicy).
+var ErrorEmptyBody = errors.New("Body is empty.")
+
+// EmptyBodyPolicy says what the service does when unmarshalling an empty body.
+type EmptyBodyPolicy int
+
+const (
+	// EmptyBodyAsCodec leaves empty bodies to the codec, so some codecs
+	// return an error and others leave the object as it is.
+	EmptyBodyAsCodec EmptyBodyPolicy = iota
+
+	// EmptyBodyError refuses empty bodies with ErrorEmptyBody.
+	EmptyBodyError
+
+	// EmptyBodyIgnore leaves the object as it is, without an error.
+	EmptyBodyIgnore
+)
+
+// NilObjectPolicy says what the service does when marshalling a nil object
+// (or an object whose public data is nil).
+type NilObjectPolicy int
+
+const (
+	// NilObjectAsCodec leaves nil objects to the codec, such as JSON
+	// marshalling them as null.
+	NilObjectAsCodec NilObjectPolicy = iota
+
+	// NilObjectEmpty marshals nil objects as an empty body, whatever the
+	// codec.
+	NilObjectEmpty
+)
+
+// SetEmptyBodyPolicy sets what the service does when unmarshalling an empty
+// body.  The policy is EmptyBodyAsCodec unless set.
+func (s *WebCodecService) SetEmptyBodyPolicy(policy EmptyBodyPolicy) {
+	s.emptyBodyPolicy = policy
+}
+
+// SetNilObjectPolicy sets what the service does when marshalling a nil
+// object.  The policy is NilObjectAsCodec unless set.
+func (s *WebCodecService) SetNilObjectPolicy(policy NilObjectPolicy) {
+	s.nilObjectPolicy = policy
+}
+
+// emptyBody gets whether the data is an empty body the service deals with
+// itself rather than leaving to the codec, and the error to return for it.
+func (s *WebCodecService) emptyBody(data []byte) (bool, error) {
+
+	if len(data) > 0 {
+		return false, nil
+	}
+
+	switch s.emptyBodyPolicy {
+	case EmptyBodyError:
+		return true, ErrorEmptyBody
+	case EmptyBodyIgnore:
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// emptyReader gets whether nothing can be read from r, if the service deals
+// with empty bodies itself, and a reader reading what r would have.
+func (s *WebCodecService) emptyReader(r io.Reader) (bool, io.Reader) {
+
+	if s.emptyBodyPolicy == EmptyBodyAsCodec {
+		return false, r
+	}
+
+	buffered := bufio.NewReader(r)
+	_, err := buffered.Peek(1)
+
+	return err == io.EOF, buffered
+}
+
+// marshalsEmpty gets whether the public data is marshalled as an empty body.
+func (s *WebCodecService) marshalsEmpty(publicData interface{}) bool {
+
+	if s.nilObjectPolicy != NilObjectEmpty {
+		return false
+	}
+
+	if publicData == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(publicData)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
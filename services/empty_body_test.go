@@ -0,0 +1,80 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSetEmptyBodyPolicy(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	codec := service.Codecs()[0]
+
+	// empty bodies are left to the codec by default
+	var object map[string]interface{}
+	err := service.UnmarshalWithCodec(codec, []byte{}, &object)
+	if assert.Error(t, err) {
+		assert.NotEqual(t, ErrorEmptyBody, err)
+	}
+
+	service.SetEmptyBodyPolicy(EmptyBodyError)
+	assert.Equal(t, ErrorEmptyBody, service.UnmarshalWithCodec(codec, []byte{}, &object))
+	assert.Equal(t, ErrorEmptyBody, service.UnmarshalWithCodecContext(context.Background(), codec, nil, &object))
+	assert.Equal(t, ErrorEmptyBody, service.UnmarshalWithCodecFrom(codec, strings.NewReader(""), &object))
+
+	// bodies that aren't empty are unmarshalled as usual
+	if assert.NoError(t, service.UnmarshalWithCodecFrom(codec, strings.NewReader(`{"name":"Mat"}`), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+	service = NewWebCodecServiceWith(new(json.JsonCodec))
+	WithEmptyBodyPolicy(EmptyBodyIgnore)(service)
+
+	object = map[string]interface{}{"name": "Mat"}
+	assert.NoError(t, service.UnmarshalWithCodec(codec, []byte{}, &object))
+	assert.NoError(t, service.UnmarshalWithCodecContext(context.Background(), codec, []byte{}, &object))
+	assert.NoError(t, service.UnmarshalWithCodecFrom(codec, strings.NewReader(""), &object))
+	assert.Equal(t, map[string]interface{}{"name": "Mat"}, object)
+
+}
+
+func TestSetNilObjectPolicy(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	codec := service.Codecs()[0]
+
+	// nil objects are left to the codec by default
+	data, err := service.MarshalWithCodec(codec, nil, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "null", string(data))
+	}
+
+	service = NewWebCodecService(WithoutDefaultCodecs(), WithCodecs(codec), WithNilObjectPolicy(NilObjectEmpty))
+
+	data, err = service.MarshalWithCodec(codec, nil, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{}, data)
+	}
+
+	var nilMap *map[string]interface{}
+	data, err = service.MarshalWithCodecContext(context.Background(), codec, nilMap, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{}, data)
+	}
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, service.MarshalWithCodecTo(codec, &buffer, nil, nil)) {
+		assert.Equal(t, 0, buffer.Len())
+	}
+
+	// objects that aren't nil are marshalled as usual
+	data, err = service.MarshalWithCodec(codec, map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+	}
+
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/codecs"
+)
+
+// Explanation describes how GetCodecForResponding negotiates, for debugging.
+type Explanation struct {
+
+	// Ranges are the media ranges parsed from the accept string, in the order
+	// of their priority.
+	Ranges []MediaRange
+
+	// Malformed describes the media ranges of the accept string that were
+	// skipped.
+	Malformed []AcceptDiagnostic
+
+	// Considered describes how each installed codec fared, in the order they
+	// were installed in.
+	Considered []Consideration
+
+	// Negotiation is the outcome, or nil if there was an error.
+	Negotiation *Negotiation
+
+	// Err is the error negotiating, if there was one.
+	Err error
+
+	// Reason says why the outcome was reached.
+	Reason string
+}
+
+// Consideration describes how a codec fared against the accept string.
+type Consideration struct {
+
+	// Codec is the codec considered.
+	Codec codecs.Codec
+
+	// MediaRange is the media range the codec matched, or nil if it matched
+	// none.
+	MediaRange *MediaRange
+
+	// Quality is the quality the matched media range gives the codec, and
+	// Weight the server's preference for it (see SetCodecWeight).
+	Quality float64
+	Weight  float64
+
+	// Exact is whether the codec matched the media range exactly, rather
+	// than by a wildcard or suffix.
+	Exact bool
+
+	// Forbidden is whether the accept string rules the codec out with a
+	// quality of 0.
+	Forbidden bool
+}
+
+// ExplainNegotiation negotiates as GetCodecForResponding does (without
+// caching), explaining the outcome.
+func (s *WebCodecService) ExplainNegotiation(accept, extension string, hasCallback bool) *Explanation {
+
+	explanation := new(Explanation)
+	explanation.Ranges, explanation.Malformed = ParseAcceptWithDiagnostics(accept)
+
+	for _, codec := range s.codecs {
+
+		quality, index, exact := acceptance(explanation.Ranges, codec)
+		consideration := Consideration{Codec: codec, Weight: s.weight(codec)}
+		if index >= 0 {
+			mediaRange := explanation.Ranges[index]
+			consideration.MediaRange = &mediaRange
+			consideration.Quality, consideration.Exact, consideration.Forbidden = quality, exact, quality == 0
+		}
+
+		explanation.Considered = append(explanation.Considered, consideration)
+	}
+
+	if s.negotiator != nil {
+		negotiation, err := s.negotiator.Negotiate(accept, extension, hasCallback, s.codecs)
+		explanation.Negotiation, explanation.Err = completeNegotiation(accept, negotiation, err, s.codecs)
+		explanation.Reason = "the codec was chosen by the service's Negotiator"
+		return explanation
+	}
+
+	explanation.Negotiation, explanation.Err = s.negotiate(accept, extension, hasCallback, s.codecs, &explanation.Reason)
+
+	return explanation
+}
+
+// String gets the explanation as text.
+func (e *Explanation) String() string {
+
+	var buffer bytes.Buffer
+
+	buffer.WriteString("media ranges:\n")
+	for _, mediaRange := range e.Ranges {
+		fmt.Fprintf(&buffer, "  %s %v q=%g\n", mediaRange, mediaRange.Params, mediaRange.Quality)
+	}
+
+	if len(e.Malformed) > 0 {
+		buffer.WriteString("malformed:\n")
+		for _, diagnostic := range e.Malformed {
+			fmt.Fprintf(&buffer, "  %s\n", diagnostic)
+		}
+	}
+
+	buffer.WriteString("codecs:\n")
+	for _, consideration := range e.Considered {
+		switch {
+		case consideration.MediaRange == nil:
+			fmt.Fprintf(&buffer, "  %s: no match\n", consideration.Codec.ContentType())
+		case consideration.Forbidden:
+			fmt.Fprintf(&buffer, "  %s: forbidden by %s\n", consideration.Codec.ContentType(), consideration.MediaRange)
+		default:
+			fmt.Fprintf(&buffer, "  %s: matched %s (exact: %t) q=%g weight=%g\n", consideration.Codec.ContentType(), consideration.MediaRange, consideration.Exact, consideration.Quality, consideration.Weight)
+		}
+	}
+
+	if e.Err != nil {
+		fmt.Fprintf(&buffer, "error: %s (%s)\n", e.Err, e.Reason)
+	} else {
+		fmt.Fprintf(&buffer, "chose %s because %s\n", e.Negotiation.ContentType, e.Reason)
+	}
+
+	return buffer.String()
+}
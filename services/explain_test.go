@@ -0,0 +1,46 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestExplainNegotiation(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), new(xml.SimpleXmlCodec)}}
+
+	explanation := service.ExplainNegotiation("application/json;q=0, */*;q=0.5", "", false)
+
+	if assert.NoError(t, explanation.Err) {
+		assert.Equal(t, constants.ContentTypeXML, explanation.Negotiation.Codec.ContentType())
+		assert.Contains(t, explanation.Reason, "highest weighed quality (0.5)")
+	}
+
+	assert.Equal(t, 2, len(explanation.Ranges))
+	if assert.Equal(t, 2, len(explanation.Considered)) {
+		assert.True(t, explanation.Considered[0].Forbidden)
+		assert.False(t, explanation.Considered[1].Forbidden)
+		assert.False(t, explanation.Considered[1].Exact)
+		assert.Equal(t, "*/*", explanation.Considered[1].MediaRange.String())
+		assert.Equal(t, 1.0, explanation.Considered[1].Weight)
+	}
+
+	text := explanation.String()
+	assert.Contains(t, text, "application/json: forbidden by application/json")
+	assert.Contains(t, text, "chose text/xml because")
+
+	explanation = service.ExplainNegotiation("image/png", "", false)
+	assert.Contains(t, explanation.Reason, "default codec")
+	assert.Nil(t, explanation.Considered[0].MediaRange)
+
+	service.SetStrict(true)
+	explanation = service.ExplainNegotiation("image/png", "", false)
+	assert.IsType(t, &NotAcceptableError{}, explanation.Err)
+	assert.True(t, strings.HasPrefix(explanation.String(), "media ranges:\n"))
+
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"strings"
+)
+
+// normalizeExtension gets the lower case extension with its leading dot.
+func normalizeExtension(extension string) string {
+	extension = strings.ToLower(extension)
+	if len(extension) > 0 && !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	return extension
+}
+
+// RegisterExtension maps the file extension (such as ".yml") to the content
+// type of the codec GetCodecForResponding should use for it, in addition to
+// the codecs' own FileExtension.  This allows codecs to be chosen by more than
+// one extension, and registered extensions take precedence over the codecs'
+// own.  Registering an empty content type removes the extension.
+func (s *WebCodecService) RegisterExtension(extension, contentType string) {
+
+	extension = normalizeExtension(extension)
+
+	if len(contentType) == 0 {
+		delete(s.extensions, extension)
+	} else {
+		if s.extensions == nil {
+			s.extensions = map[string]string{}
+		}
+		s.extensions[extension] = contentType
+	}
+
+	s.cache.clear()
+}
+
+// codecsForExtension gets the installed codecs for the extension, starting
+// with any handling the content type registered for it.
+func (s *WebCodecService) codecsForExtension(extension string, installed []codecs.Codec) []codecs.Codec {
+
+	extension = normalizeExtension(extension)
+
+	var matches []codecs.Codec
+	if contentType, ok := s.extensions[extension]; ok {
+		mediaType, subtype := parseMediaType(contentType)
+		for _, codec := range installed {
+			for _, codecContentType := range codecContentTypes(codec) {
+				if codecType, codecSubtype := parseMediaType(codecContentType); codecType == mediaType && codecSubtype == subtype {
+					matches = append(matches, codec)
+					break
+				}
+			}
+		}
+	}
+
+	for _, codec := range installed {
+		if normalizeExtension(codec.FileExtension()) == extension {
+			matches = append(matches, codec)
+		}
+	}
+
+	return matches
+}
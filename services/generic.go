@@ -0,0 +1,31 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+)
+
+// Unmarshal unmarshals the data with the codec into a new T, using the
+// service's UnmarshalWithCodec, so that callers get the type they want without
+// type asserting:
+//
+//	person, err := services.Unmarshal[Person](service, codec, data)
+//	fields, err := services.Unmarshal[map[string]interface{}](service, codec, data)
+//
+// The zero T is returned if there is an error.
+func Unmarshal[T any](s CodecService, codec codecs.Codec, data []byte) (T, error) {
+
+	var object T
+
+	if err := s.UnmarshalWithCodec(codec, data, &object); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return object, nil
+}
+
+// Marshal marshals the object with the codec and options using the service's
+// MarshalWithCodec, checking the type of the object at compile time.
+func Marshal[T any](s CodecService, codec codecs.Codec, object T, options map[string]interface{}) ([]byte, error) {
+	return s.MarshalWithCodec(codec, object, options)
+}
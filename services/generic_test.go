@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type genericPerson struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	person, err := Unmarshal[genericPerson](service, new(json.JsonCodec), []byte(`{"name":"Mat","age":30}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, genericPerson{Name: "Mat", Age: 30}, person)
+	}
+
+	fields, err := Unmarshal[map[string]interface{}](service, new(json.JsonCodec), []byte(`{"name":"Mat","age":30}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Mat", fields["name"])
+		assert.Equal(t, float64(30), fields["age"])
+	}
+
+	person, err = Unmarshal[genericPerson](service, new(json.JsonCodec), []byte(`{"name":"Mat","age":"thirty"}`))
+	assert.Error(t, err)
+	assert.Equal(t, genericPerson{}, person)
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	data, err := Marshal(service, new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+	}
+
+	data, err = Marshal(service, new(json.JsonCodec), genericPerson{Name: "Mat", Age: 30}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat","age":30}`, string(data))
+	}
+
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+)
+
+// BeforeMarshalHook is called with the object, codec and options before the
+// object is marshalled, and returns the object to marshal instead (such as the
+// object with personal data scrubbed, or wrapped in an envelope).  Returning
+// an error stops the marshalling with the error.
+type BeforeMarshalHook func(codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error)
+
+// AfterMarshalHook is called with the object, codec and options after the
+// object is marshalled, and returns the data to use instead of the marshalled
+// data.  Returning an error stops the marshalling with the error.
+type AfterMarshalHook func(codec codecs.Codec, object interface{}, options map[string]interface{}, data []byte) ([]byte, error)
+
+// BeforeUnmarshalHook is called with the data, codec and object before the
+// data is unmarshalled into the object, and returns the data to unmarshal
+// instead.  Returning an error stops the unmarshalling with the error.
+type BeforeUnmarshalHook func(codec codecs.Codec, data []byte, object interface{}) ([]byte, error)
+
+// OnBeforeMarshal adds a hook called before every object the service
+// marshals, after those already added.  The object a hook returns is the one
+// passed to the next hook.
+func (s *WebCodecService) OnBeforeMarshal(hook BeforeMarshalHook) {
+	s.beforeMarshalHooks = append(s.beforeMarshalHooks, hook)
+}
+
+// OnAfterMarshal adds a hook called after every object the service marshals,
+// after those already added.  The data a hook returns is the data passed to
+// the next hook.  Since the hooks need the whole of the data, MarshalWithCodecTo
+// doesn't stream while there are any.
+func (s *WebCodecService) OnAfterMarshal(hook AfterMarshalHook) {
+	s.afterMarshalHooks = append(s.afterMarshalHooks, hook)
+}
+
+// OnBeforeUnmarshal adds a hook called before the service unmarshals any data,
+// after those already added.  The data a hook returns is the data passed to
+// the next hook.  Since the hooks need the whole of the data,
+// UnmarshalWithCodecFrom doesn't stream while there are any.
+func (s *WebCodecService) OnBeforeUnmarshal(hook BeforeUnmarshalHook) {
+	s.beforeUnmarshalHooks = append(s.beforeUnmarshalHooks, hook)
+}
+
+// beforeMarshal runs the object through the before marshal hooks.
+func (s *WebCodecService) beforeMarshal(codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error) {
+
+	for _, hook := range s.beforeMarshalHooks {
+
+		var err error
+		object, err = hook(codec, object, options)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return object, nil
+}
+
+// afterMarshal runs the marshalled data through the after marshal hooks.
+func (s *WebCodecService) afterMarshal(codec codecs.Codec, object interface{}, options map[string]interface{}, data []byte) ([]byte, error) {
+
+	for _, hook := range s.afterMarshalHooks {
+
+		var err error
+		data, err = hook(codec, object, options, data)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// beforeUnmarshal runs the data through the before unmarshal hooks.
+func (s *WebCodecService) beforeUnmarshal(codec codecs.Codec, data []byte, object interface{}) ([]byte, error) {
+
+	for _, hook := range s.beforeUnmarshalHooks {
+
+		var err error
+		data, err = hook(codec, data, object)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestOnBeforeMarshal(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	// wrap everything in an envelope
+	service.OnBeforeMarshal(func(codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"data": object}, nil
+	})
+
+	// scrub passwords
+	service.OnBeforeMarshal(func(codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error) {
+		delete(object.(map[string]interface{})["data"].(map[string]interface{}), "password")
+		return object, nil
+	})
+
+	object := map[string]interface{}{"name": "Mat", "password": "secret"}
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"data":{"name":"Mat"}}`, string(data))
+	}
+
+	var buffer bytes.Buffer
+	object = map[string]interface{}{"name": "Mat", "password": "secret"}
+	if assert.NoError(t, service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, object, nil)) {
+		assert.Equal(t, "{\"data\":{\"name\":\"Mat\"}}\n", buffer.String())
+	}
+
+	hookErr := errors.New("refused")
+	service.OnBeforeMarshal(func(codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error) {
+		return nil, hookErr
+	})
+
+	_, err = service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	assert.Equal(t, hookErr, err)
+
+}
+
+func TestOnAfterMarshal(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var audited []string
+	service.OnAfterMarshal(func(codec codecs.Codec, object interface{}, options map[string]interface{}, data []byte) ([]byte, error) {
+		audited = append(audited, codec.ContentType()+" "+string(data))
+		return append(data, '!'), nil
+	})
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}!`, string(data))
+	}
+
+	// the data is buffered for the hooks rather than streamed
+	var buffer bytes.Buffer
+	if assert.NoError(t, service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, map[string]interface{}{"name": "Mat"}, nil)) {
+		assert.Equal(t, `{"name":"Mat"}!`, buffer.String())
+	}
+
+	assert.Equal(t, []string{`application/json {"name":"Mat"}`, `application/json {"name":"Mat"}`}, audited)
+
+}
+
+func TestOnBeforeUnmarshal(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	// unwrap the envelope
+	service.OnBeforeUnmarshal(func(codec codecs.Codec, data []byte, object interface{}) ([]byte, error) {
+		return bytes.TrimSuffix(bytes.TrimPrefix(data, []byte(`{"data":`)), []byte("}")), nil
+	})
+
+	var object map[string]interface{}
+	if assert.NoError(t, service.UnmarshalWithCodec(new(json.JsonCodec), []byte(`{"data":{"name":"Mat"}}`), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+	object = nil
+	if assert.NoError(t, service.UnmarshalWithCodecFrom(new(json.JsonCodec), strings.NewReader(`{"data":{"name":"Mat"}}`), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+}
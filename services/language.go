@@ -0,0 +1,142 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// LanguageRange is a language range from an Accept-Language header, as
+// described by RFC 7231 section 5.3.5.
+type LanguageRange struct {
+
+	// Tag is the lower case language tag (such as "en-gb"), or "*".
+	Tag string
+
+	// Quality is the q value, between 0 and 1.
+	Quality float64
+}
+
+// ParseAcceptLanguage parses the Accept-Language header into its language
+// ranges, ordered by quality (keeping the header's order for ties).  Language
+// ranges that are malformed or have an invalid q value are skipped.
+func ParseAcceptLanguage(acceptLanguage string) []LanguageRange {
+
+	var ranges []LanguageRange
+	for _, element := range strings.Split(acceptLanguage, ",") {
+
+		parts := strings.Split(element, ";")
+		tag := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !isLanguageRange(tag) {
+			continue
+		}
+
+		languageRange, ok := LanguageRange{Tag: tag, Quality: 1}, true
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(strings.ToLower(param), "q=") {
+				languageRange.Quality, ok = parseQuality(strings.TrimSpace(param[2:]))
+			}
+		}
+
+		if ok {
+			ranges = append(ranges, languageRange)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Quality > ranges[j].Quality
+	})
+
+	return ranges
+}
+
+// isLanguageRange gets whether the string is "*" or made of subtags of one to
+// eight letters and digits separated by hyphens.
+func isLanguageRange(s string) bool {
+
+	if s == "*" {
+		return true
+	}
+
+	for _, subtag := range strings.Split(s, "-") {
+		if len(subtag) == 0 || len(subtag) > 8 {
+			return false
+		}
+		for i := 0; i < len(subtag); i++ {
+			if c := subtag[i]; (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// NegotiateLanguage gets which of the available language tags to respond in
+// based on the Accept-Language header.  Each language range (in order of
+// quality) is looked up as described by RFC 4647 section 3.4, so "en-gb"
+// matches "en-GB", then "en", and "en" also matches "en-US".  Tags the header
+// gives a quality of 0 are never used.  The first available tag that isn't
+// ruled out is used if nothing matches, or "" if they all are.  If there are
+// no available tags, the header's preferred tag is used.
+func NegotiateLanguage(acceptLanguage string, available []string) string {
+
+	ranges := ParseAcceptLanguage(acceptLanguage)
+
+	// refused gets whether the tag is ruled out
+	refused := func(tag string) bool {
+		tag = strings.ToLower(tag)
+		for _, languageRange := range ranges {
+			if languageRange.Quality == 0 && (languageRange.Tag == tag || strings.HasPrefix(tag, languageRange.Tag+"-")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(available) == 0 {
+		for _, languageRange := range ranges {
+			if languageRange.Quality > 0 && languageRange.Tag != "*" {
+				return languageRange.Tag
+			}
+		}
+		return ""
+	}
+
+	for _, languageRange := range ranges {
+
+		if languageRange.Quality == 0 {
+			continue
+		}
+
+		// truncate the range until something matches
+		for tag := languageRange.Tag; len(tag) > 0; {
+
+			for _, language := range available {
+				if (tag == "*" || strings.ToLower(language) == tag) && !refused(language) {
+					return language
+				}
+			}
+
+			for _, language := range available {
+				if strings.HasPrefix(strings.ToLower(language), tag+"-") && !refused(language) {
+					return language
+				}
+			}
+
+			hyphen := strings.LastIndex(tag, "-")
+			if hyphen < 0 {
+				break
+			}
+			tag = tag[:hyphen]
+		}
+	}
+
+	for _, language := range available {
+		if !refused(language) {
+			return language
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+
+	ranges := ParseAcceptLanguage("da, en-GB;q=0.8, en;q=0.7, *;q=0.1, bad_tag, fr;q=2")
+
+	assert.Equal(t, []LanguageRange{{"da", 1}, {"en-gb", 0.8}, {"en", 0.7}, {"*", 0.1}}, ranges)
+
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+
+	available := []string{"en-US", "en-GB", "fr", "de-CH"}
+
+	for acceptLanguage, expected := range map[string]string{
+		"en-gb":                  "en-GB",
+		"en-AU, fr;q=0.5":        "en-US",
+		"fr-CA, en;q=0.5":        "fr",
+		"de":                     "de-CH",
+		"es, fr;q=0.1":           "fr",
+		"es":                     "en-US",
+		"*":                      "en-US",
+		"en, en-US;q=0":          "en-GB",
+		"":                       "en-US",
+		"en;q=0, fr;q=0, de;q=0": "",
+	} {
+		assert.Equal(t, expected, NegotiateLanguage(acceptLanguage, available), acceptLanguage)
+	}
+
+	assert.Equal(t, "en-gb", NegotiateLanguage("*, en-GB;q=0.5", nil))
+
+}
+
+func TestGetLanguageForResponding(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.SetLanguages("en", "fr")
+
+	assert.Equal(t, "fr", service.GetLanguageForResponding("fr-FR, en;q=0.8"))
+
+}
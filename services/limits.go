@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"io"
+	"strings"
+)
+
+// PayloadTooLargeError is the error for when data to unmarshal is larger than
+// the limit set with SetMaxPayloadSize or SetCodecMaxPayloadSize, for
+// responding with 413 Payload Too Large.
+type PayloadTooLargeError struct {
+	// ContentType is the content type of the codec the data was for.
+	ContentType string
+
+	// Limit is the most bytes the codec is allowed to unmarshal.
+	Limit int64
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("Payload for \"%s\" is larger than the limit of %d bytes.", e.ContentType, e.Limit)
+}
+
+// SetMaxPayloadSize sets the most bytes of data the service unmarshals, or
+// removes the limit if the size is 0.  Larger data is refused with a
+// PayloadTooLargeError, and readers given to UnmarshalWithCodecFrom aren't
+// read past the limit, so that a huge request can't exhaust memory.
+func (s *WebCodecService) SetMaxPayloadSize(size int64) {
+	s.maxPayloadSize = size
+}
+
+// SetCodecMaxPayloadSize sets the most bytes of data the service unmarshals
+// with the codec with the content type, in place of the limit set with
+// SetMaxPayloadSize.  A size of 0 removes the limit for the codec.
+func (s *WebCodecService) SetCodecMaxPayloadSize(contentType string, size int64) {
+	if s.codecMaxPayloadSizes == nil {
+		s.codecMaxPayloadSizes = map[string]int64{}
+	}
+	s.codecMaxPayloadSizes[strings.ToLower(contentType)] = size
+}
+
+// MaxPayloadSize gets the most bytes of data the service unmarshals with the
+// codec (or with any codec, for a nil codec), or 0 if there is no limit.
+func (s *WebCodecService) MaxPayloadSize(codec codecs.Codec) int64 {
+
+	if len(s.codecMaxPayloadSizes) == 0 || codec == nil {
+		return s.maxPayloadSize
+	}
+
+	if size, ok := s.codecMaxPayloadSizes[strings.ToLower(codec.ContentType())]; ok {
+		return size
+	}
+	return s.maxPayloadSize
+}
+
+// checkPayloadSize gets a PayloadTooLargeError if the size is over the limit
+// for the codec.
+func (s *WebCodecService) checkPayloadSize(codec codecs.Codec, size int64) error {
+	if limit := s.MaxPayloadSize(codec); limit > 0 && size > limit {
+		return &PayloadTooLargeError{ContentType: codec.ContentType(), Limit: limit}
+	}
+	return nil
+}
+
+// LimitReader gets a reader reading from r that fails with a
+// PayloadTooLargeError once more than the limit for the codec (or the limit
+// set with SetMaxPayloadSize, for a nil codec) has been read, or r itself if
+// there is no limit.
+func (s *WebCodecService) LimitReader(r io.Reader, codec codecs.Codec) io.Reader {
+
+	limit := s.MaxPayloadSize(codec)
+
+	if limit <= 0 {
+		return r
+	}
+
+	var contentType string
+	if codec != nil {
+		contentType = codec.ContentType()
+	}
+
+	return &limitedReader{Reader: r, remaining: limit, err: &PayloadTooLargeError{ContentType: contentType, Limit: limit}}
+}
+
+// limitedReader reads up to remaining bytes, failing with err if there are
+// more.
+type limitedReader struct {
+	io.Reader
+	remaining int64
+	err       *PayloadTooLargeError
+}
+
+func (r *limitedReader) Read(data []byte) (int, error) {
+
+	if r.remaining < 0 {
+		return 0, r.err
+	}
+
+	// read one byte past the limit, to find out if there are more
+	if int64(len(data)) > r.remaining+1 {
+		data = data[:r.remaining+1]
+	}
+
+	n, err := r.Reader.Read(data)
+	r.remaining -= int64(n)
+
+	if r.remaining < 0 {
+		return n + int(r.remaining), r.err
+	}
+
+	return n, err
+}
+
+// exceeded gets whether more than the limit has been read.
+func (r *limitedReader) exceeded() bool {
+	return r.remaining < 0
+}
+
+// limitedError gets the PayloadTooLargeError if the reader (from LimitReader)
+// read past its limit, since decoders don't always return the errors readers
+// give them, or otherwise err.
+func limitedError(r io.Reader, err error) error {
+	if limited, ok := r.(*limitedReader); ok && limited.exceeded() {
+		return limited.err
+	}
+	return err
+}
+
+// SetDecodeLimits sets the limits on the structure of data unmarshalled with
+// codecs implementing codecs.LimitedCodec (such as JSON, XML and Msgpack),
+// which refuse data exceeding them with a *codecs.DecodeLimitError before
+// decoding it.  Data for those codecs is read whole rather than streamed by
+// UnmarshalWithCodecFrom, so it is best limited by SetMaxPayloadSize too.
+func (s *WebCodecService) SetDecodeLimits(limits codecs.DecodeLimits) {
+	s.decodeLimits = limits
+}
+
+// limitsDecoding gets whether data for the codec is checked against the
+// decode limits.
+func (s *WebCodecService) limitsDecoding(codec codecs.Codec) bool {
+	_, ok := codec.(codecs.LimitedCodec)
+	return ok && s.decodeLimits != (codecs.DecodeLimits{})
+}
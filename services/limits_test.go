@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSetMaxPayloadSize(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	jsonCodec, xmlCodec := service.Codecs()[0], service.Codecs()[1]
+
+	assert.Equal(t, int64(0), service.MaxPayloadSize(jsonCodec))
+
+	service.SetMaxPayloadSize(14)
+	service.SetCodecMaxPayloadSize(constants.ContentTypeXML, 0)
+	assert.Equal(t, int64(14), service.MaxPayloadSize(jsonCodec))
+	assert.Equal(t, int64(0), service.MaxPayloadSize(xmlCodec))
+
+	var object map[string]interface{}
+	assert.NoError(t, service.UnmarshalWithCodec(jsonCodec, []byte(`{"name":"Mat"}`), &object))
+
+	err := service.UnmarshalWithCodec(jsonCodec, []byte(`{"name":"Mary"}`), &object)
+	assert.Equal(t, &PayloadTooLargeError{ContentType: constants.ContentTypeJSON, Limit: 14}, err)
+
+	err = service.UnmarshalWithCodecContext(context.Background(), jsonCodec, []byte(`{"name":"Mary"}`), &object)
+	assert.IsType(t, &PayloadTooLargeError{}, err)
+
+	// streaming
+	assert.NoError(t, service.UnmarshalWithCodecFrom(jsonCodec, strings.NewReader(`{"name":"Mat"}`), &object))
+	err = service.UnmarshalWithCodecFrom(jsonCodec, strings.NewReader(`{"name":"Mary"}`), &object)
+	assert.IsType(t, &PayloadTooLargeError{}, err)
+
+}
+
+func TestLimitReader(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	codec := service.Codecs()[0]
+
+	r := strings.NewReader("data")
+	assert.Equal(t, r, service.LimitReader(r, codec))
+
+	service.SetMaxPayloadSize(4)
+
+	data, err := ioutil.ReadAll(service.LimitReader(strings.NewReader("data"), codec))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	data, err = ioutil.ReadAll(service.LimitReader(strings.NewReader("datum"), codec))
+	assert.IsType(t, &PayloadTooLargeError{}, err)
+	assert.Equal(t, "datu", string(data))
+
+}
+
+func TestSetDecodeLimits(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	codec := service.Codecs()[0]
+	service.SetDecodeLimits(codecs.DecodeLimits{MaxDepth: 2})
+
+	var object interface{}
+	assert.NoError(t, service.UnmarshalWithCodec(codec, []byte(`[[1]]`), &object))
+
+	depthError := &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 2}
+	assert.Equal(t, depthError, service.UnmarshalWithCodec(codec, []byte(`[[[1]]]`), &object))
+	assert.Equal(t, depthError, service.UnmarshalWithCodecContext(context.Background(), codec, []byte(`[[[1]]]`), &object))
+	assert.Equal(t, depthError, service.UnmarshalWithCodecFrom(codec, strings.NewReader(`[[[1]]]`), &object))
+
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"io"
+)
+
+// Events logged by a WebCodecService's Logger.
+const (
+	LogEventNegotiation = "negotiation"
+	LogEventMarshal     = "marshal"
+	LogEventUnmarshal   = "unmarshal"
+)
+
+// Logger records what a WebCodecService does, for finding out why clients get
+// the formats they do.  Log is called with the event (one of the LogEvent
+// constants) and fields describing it:
+//
+//	negotiation: accept, extension, callback, content_type, codec, reason,
+//	             cached, malformed (the AcceptDiagnostics for media ranges
+//	             skipped) and error
+//	marshal, unmarshal: codec, size (in bytes) and error
+//
+// Fields that don't apply (such as error when there wasn't one) are left out.
+type Logger interface {
+	Log(event string, fields map[string]interface{})
+}
+
+// LoggerFunc is a function that is a Logger.
+type LoggerFunc func(event string, fields map[string]interface{})
+
+// Log calls the function.
+func (f LoggerFunc) Log(event string, fields map[string]interface{}) {
+	f(event, fields)
+}
+
+// SetLogger sets the Logger the service records negotiations, marshalling and
+// unmarshalling with, or stops logging if it is nil.
+func (s *WebCodecService) SetLogger(logger Logger) {
+	s.logger = logger
+}
+
+// logNegotiation logs the outcome of negotiating.
+func (s *WebCodecService) logNegotiation(accept, extension string, hasCallback bool, negotiation *Negotiation, err error, reason string, cached bool) {
+
+	if s.logger == nil {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"accept":    accept,
+		"extension": extension,
+		"callback":  hasCallback,
+		"cached":    cached,
+	}
+
+	if negotiation != nil {
+		fields["content_type"] = negotiation.ContentType
+		fields["codec"] = negotiation.Codec.ContentType()
+	}
+	if len(reason) > 0 {
+		fields["reason"] = reason
+	}
+	if _, diagnostics := ParseAcceptWithDiagnostics(accept); len(diagnostics) > 0 {
+		fields["malformed"] = diagnostics
+	}
+	if err != nil {
+		fields["error"] = err
+	}
+
+	s.logger.Log(LogEventNegotiation, fields)
+}
+
+// logCoding logs marshalling or unmarshalling size bytes with the codec.
+func (s *WebCodecService) logCoding(event string, codec codecs.Codec, size int64, err error) {
+
+	if s.logger == nil {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"codec": codec.ContentType(),
+		"size":  size,
+	}
+
+	if err != nil {
+		fields["error"] = err
+	}
+
+	s.logger.Log(event, fields)
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	io.Writer
+	count int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.Writer.Write(data)
+	w.count += int64(n)
+	return n, err
+}
+
+// Flush flushes the writer counted, if it has a Flush method, so that codecs
+// flushing as they write aren't held up by the count.
+func (w *countingWriter) Flush() error {
+	switch flusher := w.Writer.(type) {
+	case interface{ Flush() error }:
+		return flusher.Flush()
+	case interface{ Flush() }:
+		flusher.Flush()
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	count int64
+}
+
+func (r *countingReader) Read(data []byte) (int, error) {
+	n, err := r.Reader.Read(data)
+	r.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// logEntry is a logged event.
+type logEntry struct {
+	event  string
+	fields map[string]interface{}
+}
+
+func TestSetLogger(t *testing.T) {
+
+	var entries []logEntry
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetLogger(LoggerFunc(func(event string, fields map[string]interface{}) {
+		entries = append(entries, logEntry{event, fields})
+	}))
+
+	_, err := service.GetCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.NoError(t, err)
+	_, err = service.GetCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.NoError(t, err)
+
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, LogEventNegotiation, entries[0].event)
+		assert.Equal(t, constants.ContentTypeXML, entries[0].fields["codec"])
+		assert.Equal(t, constants.ContentTypeXML, entries[0].fields["accept"])
+		assert.Equal(t, false, entries[0].fields["cached"])
+		assert.NotEmpty(t, entries[0].fields["reason"])
+		assert.Equal(t, true, entries[1].fields["cached"])
+	}
+
+	entries = nil
+	service.MarshalWithCodec(new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+
+	var object map[string]interface{}
+	service.UnmarshalWithCodec(new(json.JsonCodec), []byte(`{"name":`), &object)
+
+	var buffer bytes.Buffer
+	service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, map[string]interface{}{"name": "Mat"}, nil)
+	service.UnmarshalWithCodecFrom(new(json.JsonCodec), strings.NewReader(`{"name":"Mat"}`), &object)
+
+	if assert.Equal(t, 4, len(entries)) {
+		assert.Equal(t, logEntry{LogEventMarshal, map[string]interface{}{"codec": constants.ContentTypeJSON, "size": int64(14)}}, entries[0])
+		assert.Equal(t, LogEventUnmarshal, entries[1].event)
+		assert.Equal(t, int64(8), entries[1].fields["size"])
+		assert.Error(t, entries[1].fields["error"].(error))
+		assert.Equal(t, logEntry{LogEventMarshal, map[string]interface{}{"codec": constants.ContentTypeJSON, "size": int64(15)}}, entries[2])
+		assert.Equal(t, logEntry{LogEventUnmarshal, map[string]interface{}{"codec": constants.ContentTypeJSON, "size": int64(14)}}, entries[3])
+	}
+
+}
+
+func TestSetLogger_MalformedAccept(t *testing.T) {
+
+	var entries []logEntry
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	service.SetLogger(LoggerFunc(func(event string, fields map[string]interface{}) {
+		entries = append(entries, logEntry{event, fields})
+	}))
+
+	service.GetCodecForResponding("application/json, bad", "", false)
+
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, []AcceptDiagnostic{{Range: "bad", Reason: "the media type isn't of the form type/subtype"}}, entries[0].fields["malformed"])
+	}
+
+}
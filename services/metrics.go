@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"time"
+)
+
+// Metrics measures the marshalling and unmarshalling a WebCodecService does,
+// for finding out which formats clients use.  Each method is called with the
+// event (LogEventMarshal or LogEventUnmarshal) and the content type of the
+// codec.  The promcodecs package adapts Prometheus collectors to it.
+type Metrics interface {
+
+	// Count counts one marshal or unmarshal, and whether it failed.
+	Count(event, contentType string, failed bool)
+
+	// ObserveDuration observes how long a marshal or unmarshal took.
+	ObserveDuration(event, contentType string, duration time.Duration)
+
+	// ObserveSize observes the size (in bytes) of the data marshalled or
+	// unmarshalled.
+	ObserveSize(event, contentType string, size int64)
+}
+
+// SetMetrics sets the Metrics the service measures marshalling and
+// unmarshalling with, or stops measuring if it is nil.
+func (s *WebCodecService) SetMetrics(metrics Metrics) {
+	s.metrics = metrics
+}
+
+// recordCoding logs and measures marshalling or unmarshalling size bytes with
+// the codec, having started at the time.
+func (s *WebCodecService) recordCoding(event string, codec codecs.Codec, started time.Time, size int64, err error) {
+
+	s.logCoding(event, codec, size, err)
+
+	if s.metrics == nil {
+		return
+	}
+
+	contentType := codec.ContentType()
+	s.metrics.Count(event, contentType, err != nil)
+	s.metrics.ObserveDuration(event, contentType, time.Since(started))
+	s.metrics.ObserveSize(event, contentType, size)
+}
+
+// recording gets whether marshalling and unmarshalling is logged or measured.
+func (s *WebCodecService) recording() bool {
+	return s.logger != nil || s.metrics != nil
+}
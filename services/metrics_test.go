@@ -0,0 +1,61 @@
+package services
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// measurement is a marshal or unmarshal measured by recordingMetrics.
+type measurement struct {
+	event, contentType string
+	failed             bool
+	size               int64
+	durations          int
+}
+
+// recordingMetrics is a Metrics remembering what it measures.
+type recordingMetrics struct {
+	measurements []measurement
+}
+
+func (m *recordingMetrics) Count(event, contentType string, failed bool) {
+	m.measurements = append(m.measurements, measurement{event: event, contentType: contentType, failed: failed})
+}
+
+func (m *recordingMetrics) ObserveDuration(event, contentType string, duration time.Duration) {
+	m.measurements[len(m.measurements)-1].durations++
+}
+
+func (m *recordingMetrics) ObserveSize(event, contentType string, size int64) {
+	m.measurements[len(m.measurements)-1].size = size
+}
+
+func TestSetMetrics(t *testing.T) {
+
+	metrics := new(recordingMetrics)
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+	service.SetMetrics(metrics)
+
+	service.MarshalWithCodec(new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+
+	var object map[string]interface{}
+	service.UnmarshalWithCodec(new(json.JsonCodec), []byte(`{"name":`), &object)
+
+	var buffer bytes.Buffer
+	service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, map[string]interface{}{"name": "Mat"}, nil)
+
+	assert.Equal(t, []measurement{
+		{LogEventMarshal, constants.ContentTypeJSON, false, 14, 1},
+		{LogEventUnmarshal, constants.ContentTypeJSON, true, 8, 1},
+		{LogEventMarshal, constants.ContentTypeJSON, false, 15, 1},
+	}, metrics.measurements)
+
+	service.SetMetrics(nil)
+	service.MarshalWithCodec(new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	assert.Equal(t, 3, len(metrics.measurements))
+
+}
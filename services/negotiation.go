@@ -0,0 +1,163 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"mime"
+	"strconv"
+)
+
+// Negotiator is the interface for choosing the codec to respond with, for
+// organisation specific rules.  Set one with WebCodecService.SetNegotiator;
+// WebCodecService is itself a Negotiator, which others can fall back to.
+type Negotiator interface {
+
+	// Negotiate chooses which of the installed codecs to respond with, given
+	// the accept string, the extension and whether there is a callback.  The
+	// ContentType and Params of the negotiation may be left empty to use
+	// those of the codec.
+	Negotiate(accept, extension string, hasCallback bool, installed []codecs.Codec) (*Negotiation, error)
+}
+
+// Negotiation is the outcome of negotiating how to respond.
+type Negotiation struct {
+
+	// Codec is the codec to respond with.
+	Codec codecs.Codec
+
+	// ContentType is the media type for the Content-Type header of the
+	// response, with its parameters.  It is the matched media range's type
+	// when that names one (such as an alias or vendor type), or else the
+	// codec's content type.
+	ContentType string
+
+	// Params are the media type parameters asked for, as resolved by
+	// GetCodecAndParametersForResponding.
+	Params map[string]string
+
+	// MediaRange is the media range from the accept string that was matched,
+	// or nil if none was.
+	MediaRange *MediaRange
+
+	// Version is the version parameter of the media type asked for, or
+	// defaulted to by the codec (see codecs.VersionedCodec), if there is one.
+	Version string
+}
+
+// newNegotiation makes the negotiation for responding with the codec, having
+// matched the range at the index (which is -1 if no range was matched).
+func newNegotiation(codec codecs.Codec, ranges []MediaRange, index int) *Negotiation {
+
+	negotiation := &Negotiation{Codec: codec, ContentType: codec.ContentType()}
+
+	var params map[string]string
+	if index >= 0 {
+		mediaRange := ranges[index]
+		negotiation.MediaRange = &mediaRange
+		params = mediaRange.Params
+
+		if mediaRange.Type != "*" && mediaRange.Subtype != "*" {
+			negotiation.ContentType = mediaRange.String()
+		}
+	}
+
+	negotiation.Params = resolveParameters(params, codec)
+	negotiation.Version = negotiation.Params[codecs.ParameterVersion]
+
+	if formatted := mime.FormatMediaType(negotiation.ContentType, negotiation.Params); len(formatted) > 0 {
+		negotiation.ContentType = formatted
+	}
+
+	return negotiation
+}
+
+// completeNegotiation fills in the content type and params of a negotiation
+// a Negotiator left empty, treating a missing codec as not acceptable.
+func completeNegotiation(accept string, negotiation *Negotiation, err error, installed []codecs.Codec) (*Negotiation, error) {
+
+	if err != nil {
+		return nil, err
+	}
+
+	if negotiation == nil || negotiation.Codec == nil {
+		return nil, &NotAcceptableError{Accept: accept, Supported: supportedContentTypes(installed)}
+	}
+
+	if len(negotiation.ContentType) == 0 {
+		negotiation.ContentType = negotiation.Codec.ContentType()
+	}
+
+	if negotiation.Params == nil {
+		negotiation.Params = resolveParameters(nil, negotiation.Codec)
+	}
+
+	if len(negotiation.Version) == 0 {
+		negotiation.Version = negotiation.Params[codecs.ParameterVersion]
+	}
+
+	return negotiation, nil
+}
+
+// copy copies the negotiation, so that changes to it aren't shared.
+func (n *Negotiation) copy() *Negotiation {
+
+	if n == nil {
+		return nil
+	}
+
+	copied := *n
+	copied.Params = copyParams(n.Params)
+	if n.MediaRange != nil {
+		mediaRange := *n.MediaRange
+		mediaRange.Params = copyParams(n.MediaRange.Params)
+		copied.MediaRange = &mediaRange
+	}
+
+	return &copied
+}
+
+// Options gets the options with the constants.OptionKeyVersion option set to
+// the negotiated version (unless it is empty or already set), so that objects
+// implementing codecs.VersionedFacade are marshalled in the version asked for,
+// and the constants.OptionKeyPretty option set if the media type asked for has
+// a true pretty parameter (see codecs.ParameterPretty).  The options given are
+// not changed.
+func (n *Negotiation) Options(options map[string]interface{}) map[string]interface{} {
+
+	extra := map[string]interface{}{}
+
+	if len(n.Version) > 0 {
+		extra[constants.OptionKeyVersion] = n.Version
+	}
+
+	if pretty, err := strconv.ParseBool(n.Params[codecs.ParameterPretty]); err == nil && pretty {
+		extra[constants.OptionKeyPretty] = true
+	}
+
+	for key := range extra {
+		if _, ok := options[key]; ok {
+			delete(extra, key)
+		}
+	}
+
+	if len(extra) == 0 {
+		return options
+	}
+
+	negotiated := make(map[string]interface{}, len(options)+len(extra))
+	for key, value := range options {
+		negotiated[key] = value
+	}
+	for key, value := range extra {
+		negotiated[key] = value
+	}
+
+	return negotiated
+}
+
+// MarshalWithNegotiation marshals the object with the negotiated codec (see
+// GetNegotiationForResponding), in the negotiated version (see
+// Negotiation.Options).
+func (s *WebCodecService) MarshalWithNegotiation(negotiation *Negotiation, object interface{}, options map[string]interface{}) ([]byte, error) {
+	return s.MarshalWithCodec(negotiation.Codec, object, negotiation.Options(options))
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetNegotiationForResponding(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	negotiation, err := service.GetNegotiationForResponding("application/xml;charset=utf-8, */*;q=0.1", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeXML, negotiation.Codec.ContentType())
+		assert.Equal(t, "application/xml; charset=utf-8", negotiation.ContentType)
+		assert.Equal(t, map[string]string{"charset": "utf-8"}, negotiation.Params)
+		if assert.NotNil(t, negotiation.MediaRange) {
+			assert.Equal(t, "application/xml", negotiation.MediaRange.String())
+		}
+	}
+
+	// wildcards respond with the codec's own content type
+	negotiation, err = service.GetNegotiationForResponding("text/*", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSONP, negotiation.ContentType)
+		assert.Equal(t, "text/*", negotiation.MediaRange.String())
+	}
+
+	negotiation, err = service.GetNegotiationForResponding("", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, negotiation.ContentType)
+		assert.Nil(t, negotiation.MediaRange)
+	}
+
+	_, err = service.GetNegotiationForResponding("*/*;q=0", "", false)
+	assert.IsType(t, &NotAcceptableError{}, err)
+
+}
+
+func TestGetNegotiationForResponding_VendorTypes(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), new(versionedCodec)}}
+
+	negotiation, err := service.GetNegotiationForResponding("application/vnd.myco.order+json;version=1;x=y", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "application/vnd.myco.order+json; version=1", negotiation.ContentType)
+	}
+
+	negotiation, err = service.GetNegotiationForResponding("application/vnd.myco.other+json", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, negotiation.Codec.ContentType())
+		assert.Equal(t, "application/vnd.myco.other+json", negotiation.ContentType)
+	}
+
+}
+
+// internalNegotiator gives internal clients msgpack, falling back to another
+// negotiator for everyone else.
+type internalNegotiator struct {
+	fallback Negotiator
+}
+
+func (n *internalNegotiator) Negotiate(accept, extension string, hasCallback bool, installed []codecs.Codec) (*Negotiation, error) {
+
+	if accept == "application/x-internal" {
+		for _, codec := range installed {
+			if codec.ContentType() == constants.ContentTypeMsgpack {
+				return &Negotiation{Codec: codec}, nil
+			}
+		}
+	}
+
+	if extension == ".none" {
+		return nil, nil
+	}
+
+	return n.fallback.Negotiate(accept, extension, hasCallback, installed)
+}
+
+func TestSetNegotiator(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.SetNegotiator(&internalNegotiator{service})
+
+	negotiation, err := service.GetNegotiationForResponding("application/x-internal", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeMsgpack, negotiation.Codec.ContentType())
+		assert.Equal(t, constants.ContentTypeMsgpack, negotiation.ContentType)
+		assert.Equal(t, map[string]string{}, negotiation.Params)
+	}
+
+	codec, err := service.GetCodecForResponding("text/csv", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+	}
+
+	_, err = service.GetCodecForResponding("", ".none", false)
+	assert.IsType(t, &NotAcceptableError{}, err)
+
+	service.SetNegotiator(nil)
+
+	codec, _ = service.GetCodecForResponding("application/x-internal", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+}
+
+// versionedPerson is shaped differently in each version of the API.
+type versionedPerson struct {
+	First, Last string
+}
+
+func (p *versionedPerson) PublicDataV(version string, options map[string]interface{}) (interface{}, error) {
+	if version == "1" {
+		return map[string]interface{}{"name": p.First + " " + p.Last}, nil
+	}
+	return map[string]interface{}{"first": p.First, "last": p.Last}, nil
+}
+
+func TestMarshalWithNegotiation(t *testing.T) {
+
+	v1 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api+json", Version: "1"}
+	v2 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api+json", Version: "2"}
+	service := NewWebCodecService(WithoutDefaultCodecs(), WithCodecs(v1, v2))
+	person := &versionedPerson{First: "Mat", Last: "Ryer"}
+
+	negotiation, err := service.GetNegotiationForResponding("application/vnd.api+json;version=1", "", false)
+	if assert.NoError(t, err) {
+		data, err := service.MarshalWithNegotiation(negotiation, person, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `{"name":"Mat Ryer"}`, string(data))
+		}
+	}
+
+	negotiation, err = service.GetNegotiationForResponding("application/vnd.api+json;version=2", "", false)
+	if assert.NoError(t, err) {
+		data, err := service.MarshalWithNegotiation(negotiation, person, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `{"first":"Mat","last":"Ryer"}`, string(data))
+		}
+
+		// a version given in the options wins
+		options := map[string]interface{}{constants.OptionKeyVersion: "1"}
+		assert.Equal(t, options, negotiation.Options(options))
+	}
+
+}
+
+func TestNegotiation_Options_Pretty(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec))
+
+	negotiation, err := service.GetNegotiationForResponding("application/json;pretty=true", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{constants.OptionKeyPretty: true}, negotiation.Options(nil))
+
+		data, err := service.MarshalWithNegotiation(negotiation, map[string]interface{}{"name": "Mat"}, nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "{\n  \"name\": \"Mat\"\n}", string(data))
+		}
+	}
+
+	negotiation, err = service.GetNegotiationForResponding("application/json;pretty=false", "", false)
+	if assert.NoError(t, err) {
+		assert.Nil(t, negotiation.Options(nil))
+	}
+
+}
+
+func TestGetNegotiationForResponding_Versions(t *testing.T) {
+
+	v1 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api+json", Version: "1"}
+	v2 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api+json", Version: "2"}
+	tree1 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api.v1+json", Version: "1"}
+	tree2 := &codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.api.v2+json", Version: "2"}
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), v1, v2, tree1, tree2}}
+
+	negotiation, err := service.GetNegotiationForResponding("application/vnd.api+json;version=2", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, v2, negotiation.Codec)
+		assert.Equal(t, "2", negotiation.Version)
+		assert.Equal(t, "application/vnd.api+json; version=2", negotiation.ContentType)
+	}
+
+	// the first installed version is used when no version is asked for
+	negotiation, err = service.GetNegotiationForResponding("application/vnd.api+json", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, v1, negotiation.Codec)
+		assert.Equal(t, "1", negotiation.Version)
+	}
+
+	negotiation, err = service.GetNegotiationForResponding("application/vnd.api.v2+json, application/vnd.api.v1+json;q=0.5", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tree2, negotiation.Codec)
+		assert.Equal(t, "2", negotiation.Version)
+	}
+
+	// unknown versions get the plain codec by suffix
+	negotiation, err = service.GetNegotiationForResponding("application/vnd.api+json;version=3", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, negotiation.Codec.ContentType())
+		assert.Equal(t, "3", negotiation.Version)
+	}
+
+	codec, err := service.GetCodec("application/vnd.api+json; version=2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, v2, codec)
+	}
+
+	codec, err = service.GetCodec("application/vnd.api.v1+json")
+	if assert.NoError(t, err) {
+		assert.Equal(t, tree1, codec)
+	}
+
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/stew/objects"
+	"strings"
+)
+
+// publicData gets the public data of the object (see codecs.PublicDataContext),
+// keeping only the fields given by the constants.OptionKeyFields option, if
+// there are any, and runs it through the transformers.
+func (s *WebCodecService) publicData(ctx context.Context, codec codecs.Codec, object interface{}, options map[string]interface{}) (interface{}, error) {
+
+	data, err := codecs.PublicDataContext(ctx, object, s.withFieldNames(options))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fields := optionFields(options); len(fields) > 0 {
+		data = filterFields(data, fields)
+	}
+
+	return s.transform(codec, data, options)
+}
+
+// optionFields gets the fields given by the constants.OptionKeyFields option,
+// which may be a []string or a comma separated string (as in a
+// "?fields=id,name" query).
+func optionFields(options map[string]interface{}) []string {
+
+	switch fields := options[constants.OptionKeyFields].(type) {
+	case []string:
+		return fields
+	case string:
+		var parsed []string
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); len(field) > 0 {
+				parsed = append(parsed, field)
+			}
+		}
+		return parsed
+	}
+
+	return nil
+}
+
+// fieldTree holds field paths such as "author.name" by their parts.  A field
+// mapping to nil is kept whole.
+type fieldTree map[string]fieldTree
+
+// newFieldTree makes a fieldTree from the field paths.
+func newFieldTree(fields []string) fieldTree {
+
+	tree := fieldTree{}
+	for _, field := range fields {
+
+		parts := strings.Split(field, ".")
+		node := tree
+		for i, part := range parts {
+
+			child, ok := node[part]
+			if ok && child == nil {
+				// the whole field is already kept
+				break
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if !ok {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+
+	return tree
+}
+
+// filterFields keeps only the fields of maps (including maps in slices),
+// following paths such as "author.name" into nested maps.
+func filterFields(data interface{}, fields []string) interface{} {
+	return newFieldTree(fields).filter(data)
+}
+
+// filter keeps only the fields in the tree.
+func (t fieldTree) filter(data interface{}) interface{} {
+
+	switch data := data.(type) {
+	case objects.Map:
+		return objects.Map(t.filter(map[string]interface{}(data)).(map[string]interface{}))
+	case map[string]interface{}:
+		filtered := map[string]interface{}{}
+		for field, subtree := range t {
+			if value, ok := data[field]; ok {
+				if subtree != nil {
+					value = subtree.filter(value)
+				}
+				filtered[field] = value
+			}
+		}
+		return filtered
+	case []interface{}:
+		filtered := make([]interface{}, len(data))
+		for i, item := range data {
+			filtered[i] = t.filter(item)
+		}
+		return filtered
+	}
+
+	return data
+}
+
+// RenameFields names the fields of structs of the object's type (or the type
+// it points to) in the public data the service marshals, by Go field name, so
+// that types that can't be tagged (such as generated or vendored types) are
+// named as an API requires.  Names given by the constants.OptionKeyFieldNames
+// option win over the service's.
+func (s *WebCodecService) RenameFields(object interface{}, names map[string]string) {
+	if s.fieldNames == nil {
+		s.fieldNames = codecs.FieldNames{}
+	}
+	s.fieldNames.Rename(object, names)
+}
+
+// withFieldNames gets the options with the service's field names added.
+func (s *WebCodecService) withFieldNames(options map[string]interface{}) map[string]interface{} {
+
+	if len(s.fieldNames) == 0 {
+		return options
+	}
+
+	fieldNames := s.fieldNames
+	if given, ok := options[constants.OptionKeyFieldNames].(codecs.FieldNames); ok {
+		fieldNames = s.fieldNames.Copy()
+		for objectType, names := range given {
+			fieldNames[objectType] = names
+		}
+	}
+
+	withNames := copyOptions(options)
+	withNames[constants.OptionKeyFieldNames] = fieldNames
+
+	return withNames
+}
+
+// unconvertKeys converts the keys of the data from the case given by the
+// constants.OptionKeyKeyCase option into codecs.PascalCase, so that they match
+// the names of Go fields, by unmarshalling it generically and marshalling it
+// again with the codec.  Data is returned as it is if there is no key case.
+func unconvertKeys(codec codecs.Codec, data []byte, options map[string]interface{}) ([]byte, error) {
+
+	if keyCase, _ := options[constants.OptionKeyKeyCase].(codecs.KeyCase); keyCase == codecs.KeyCaseAsIs {
+		return data, nil
+	}
+
+	var generic interface{}
+	if err := codec.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	return codec.Marshal(codecs.ConvertKeys(generic, codecs.PascalCase), nil)
+}
+
+// SetCodecOptions sets the default options for marshalling with the codec with
+// the content type (such as indenting JSON), which the options given to
+// MarshalWithCodec and the like are merged over.  Nil options remove the
+// defaults.
+func (s *WebCodecService) SetCodecOptions(contentType string, options map[string]interface{}) {
+
+	contentType = strings.ToLower(contentType)
+
+	if options == nil {
+		delete(s.codecOptionDefaults, contentType)
+		return
+	}
+
+	if s.codecOptionDefaults == nil {
+		s.codecOptionDefaults = map[string]map[string]interface{}{}
+	}
+	s.codecOptionDefaults[contentType] = options
+}
+
+// codecOptions gets the options merged over the codec's default options.
+func (s *WebCodecService) codecOptions(codec codecs.Codec, options map[string]interface{}) map[string]interface{} {
+
+	if len(s.codecOptionDefaults) == 0 {
+		return options
+	}
+
+	defaults, ok := s.codecOptionDefaults[strings.ToLower(codec.ContentType())]
+	if !ok {
+		return options
+	}
+
+	merged := make(map[string]interface{}, len(defaults)+len(options))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range options {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// MarshalWithCodecAndOptions marshals the object with the codec as
+// MarshalWithCodec does, taking typed options rather than an options map.
+// Output is transcoded into the Charset option, if it is set and the codec's
+// content type holds text.
+func (s *WebCodecService) MarshalWithCodecAndOptions(codec codecs.Codec, object interface{}, options codecs.Options) ([]byte, error) {
+
+	if len(options.Charset) == 0 {
+		return s.MarshalWithCodec(codec, object, options.Map())
+	}
+
+	data, _, err := s.MarshalWithCodecInCharset(codec, object, options.Map(), options.Charset)
+
+	return data, err
+}
+
+// UnmarshalWithCodecAndOptions unmarshals the data into the object as
+// UnmarshalWithCodec does, passing the options to codecs implementing
+// codecs.UnmarshalOptionsCodec (others ignore them).  The data is first
+// transcoded into UTF-8 from the Charset option, if it is set and the codec's
+// content type holds text, and its keys are converted from the KeyCase option
+// into codecs.PascalCase, if it is set, to match the names of Go fields.
+func (s *WebCodecService) UnmarshalWithCodecAndOptions(codec codecs.Codec, data []byte, object interface{}, options codecs.Options) error {
+
+	if len(options.Charset) > 0 && isTextual(codec.ContentType()) {
+
+		var err error
+		if data, err = DecodeCharset(data, options.Charset); err != nil {
+			return err
+		}
+	}
+
+	return s.unmarshalWithCodec(codec, data, object, options.Map())
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/stew/objects"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilterFields(t *testing.T) {
+
+	data := []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30, "password": "x"},
+		objects.Map{"name": "Tyler", "password": "y"},
+		"other",
+	}
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30},
+		objects.Map{"name": "Tyler"},
+		"other",
+	}, filterFields(data, []string{"name", "age"}))
+
+}
+
+func TestFilterFields_Nested(t *testing.T) {
+
+	data := map[string]interface{}{
+		"id":       1,
+		"password": "x",
+		"author":   map[string]interface{}{"name": "Mat", "email": "mat@example.com"},
+		"comments": []interface{}{
+			map[string]interface{}{"text": "Hi", "author": map[string]interface{}{"name": "Tyler", "email": "t@example.com"}},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"id":     1,
+		"author": map[string]interface{}{"name": "Mat"},
+		"comments": []interface{}{
+			map[string]interface{}{"author": map[string]interface{}{"name": "Tyler"}},
+		},
+	}, filterFields(data, []string{"id", "author.name", "comments.author.name", "missing.field"}))
+
+	// a whole field wins over paths into it
+	assert.Equal(t, map[string]interface{}{
+		"author": map[string]interface{}{"name": "Mat", "email": "mat@example.com"},
+	}, filterFields(data, []string{"author.name", "author"}))
+
+	assert.Equal(t, []string{"id", "author.name"}, optionFields(map[string]interface{}{constants.OptionKeyFields: "id, author.name,"}))
+
+}
+
+func TestMarshalWithCodecAndOptions(t *testing.T) {
+
+	service := NewWebCodecService()
+	object := map[string]interface{}{"name": "café", "password": "x"}
+
+	data, err := service.MarshalWithCodecAndOptions(new(json.JsonCodec), object, codecs.Options{Fields: []string{"name"}, Indent: " "})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n \"name\": \"café\"\n}", string(data))
+	}
+
+	data, err = service.MarshalWithCodecAndOptions(new(json.JsonCodec), object, codecs.Options{Fields: []string{"name"}, Charset: "ISO-8859-1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\"name\":\"caf\xe9\"}", string(data))
+	}
+
+}
+
+func TestUnmarshalWithCodecAndOptions(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var object struct {
+		Name string `json:"name"`
+	}
+
+	err := service.UnmarshalWithCodecAndOptions(new(json.JsonCodec), []byte(`{"name":"Mat","age":30}`), &object, codecs.Options{Strict: true})
+	assert.Error(t, err)
+
+	err = service.UnmarshalWithCodecAndOptions(new(json.JsonCodec), []byte("{\"name\":\"caf\xe9\"}"), &object, codecs.Options{Strict: true, Charset: "ISO-8859-1"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "café", object.Name)
+	}
+
+}
+
+func TestUnmarshalWithCodecAndOptions_KeyCase(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var object struct {
+		FirstName string
+		LastName  string
+	}
+
+	err := service.UnmarshalWithCodecAndOptions(new(json.JsonCodec), []byte(`{"first_name":"Mat","last_name":"Ryer"}`), &object, codecs.Options{KeyCase: codecs.SnakeCase})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Mat", object.FirstName)
+		assert.Equal(t, "Ryer", object.LastName)
+	}
+
+	data, err := service.MarshalWithCodecAndOptions(new(json.JsonCodec), object, codecs.Options{KeyCase: codecs.KebabCase})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"first-name":"Mat","last-name":"Ryer"}`, string(data))
+	}
+
+}
+
+func TestSetCodecOptions(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.SetCodecOptions(constants.ContentTypeJSON, map[string]interface{}{constants.OptionKeyIndent: "  ", constants.OptionKeyFields: []string{"name"}})
+
+	object := map[string]interface{}{"name": "Mat", "age": 30}
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n  \"name\": \"Mat\"\n}", string(data))
+	}
+
+	// per call options win
+	data, err = service.MarshalWithCodec(new(json.JsonCodec), object, map[string]interface{}{constants.OptionKeyIndent: ""})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+	}
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, object, nil)) {
+		assert.Equal(t, "{\n  \"name\": \"Mat\"\n}\n", buffer.String())
+	}
+
+	service.SetCodecOptions(constants.ContentTypeJSON, nil)
+
+	data, err = service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"age":30,"name":"Mat"}`, string(data))
+	}
+
+}
+
+// vendored stands for a type that can't be tagged.
+type vendored struct {
+	AccountID int
+	Name      string
+}
+
+func TestRenameFields(t *testing.T) {
+
+	service := NewWebCodecService(WithFieldNames(vendored{}, map[string]string{"AccountID": "account_id"}))
+	snapshot := service.Snapshot()
+	service.RenameFields(&vendored{}, map[string]string{"Name": "name"})
+
+	object := &vendored{AccountID: 1, Name: "Mat"}
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"account_id":1,"name":"Mat"}`, string(data))
+	}
+
+	data, err = snapshot.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"Name":"Mat","account_id":1}`, string(data))
+	}
+
+	// names given in the options win
+	names := codecs.FieldNames{}
+	names.Rename(vendored{}, map[string]string{"AccountID": "id"})
+	data, err = service.MarshalWithCodec(new(json.JsonCodec), object, map[string]interface{}{constants.OptionKeyFieldNames: names})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"Name":"Mat","id":1}`, string(data))
+	}
+
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs"
+	"io"
+	"time"
+)
+
+// encodeSequence writes the public data of the elements of the channel or
+// iterator function to w with the encoder as they come, if it is a
+// codecs.SequenceEncoder and there are no transformers needing the whole of
+// the data.  Otherwise the object is given to the encoder as it is, for codecs
+// that stream channels themselves.
+func (s *WebCodecService) encodeSequence(w io.Writer, encoder codecs.StreamEncoder, object interface{}, options map[string]interface{}) error {
+
+	encode := func(w io.Writer) error {
+		if sequenceEncoder, ok := encoder.(codecs.SequenceEncoder); ok && len(s.transformers) == 0 {
+			sequence, _ := codecs.PublicDataSequence(context.Background(), object, s.withFieldNames(options))
+			if fields := optionFields(options); len(fields) > 0 {
+				sequence = filteredSequence(sequence, fields)
+			}
+			return sequenceEncoder.EncodeSequence(w, sequence, options)
+		}
+		return encoder.Encode(w, object, options)
+	}
+
+	if !s.recording() {
+		return encode(w)
+	}
+
+	started, counter := time.Now(), &countingWriter{Writer: w}
+	err := encode(counter)
+	s.recordCoding(LogEventMarshal, encoder, started, counter.count, err)
+
+	return err
+}
+
+// filteredSequence keeps only the fields of the elements of the sequence (see
+// filterFields).
+func filteredSequence(sequence codecs.Sequence, fields []string) codecs.Sequence {
+	tree := newFieldTree(fields)
+	return codecs.SequenceFunc(func(fn func(element interface{}) error) error {
+		return sequence.Each(func(element interface{}) error {
+			return fn(tree.filter(element))
+		})
+	})
+}
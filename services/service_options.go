@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+)
+
+// Option configures a WebCodecService made by NewWebCodecService.  Options are
+// applied in the order they are given, so an option referring to a codec by
+// its content type (such as WithDefaultCodec) should follow the options
+// installing it.
+type Option func(s *WebCodecService) error
+
+// WithCodecs installs the codecs after those already installed.
+func WithCodecs(installing ...codecs.Codec) Option {
+	return func(s *WebCodecService) error {
+		installed := make([]codecs.Codec, 0, len(s.codecs)+len(installing))
+		s.codecs = append(append(installed, s.codecs...), installing...)
+		return nil
+	}
+}
+
+// WithoutDefaultCodecs leaves out DefaultCodecs, so that only the codecs
+// given by WithCodecs are installed.
+func WithoutDefaultCodecs() Option {
+	return func(s *WebCodecService) error {
+
+		var installed []codecs.Codec
+		for _, codec := range s.codecs {
+			if !isDefaultCodec(codec) {
+				installed = append(installed, codec)
+			}
+		}
+
+		s.codecs = installed
+		return nil
+	}
+}
+
+// WithDefaultCodec sets the codec to fall back to (see SetDefaultCodec).
+func WithDefaultCodec(contentType string) Option {
+	return func(s *WebCodecService) error {
+		return s.SetDefaultCodec(contentType)
+	}
+}
+
+// WithStrictNegotiation makes GetCodecForResponding return a
+// NotAcceptableError rather than fall back to a codec (see SetStrict).
+func WithStrictNegotiation() Option {
+	return func(s *WebCodecService) error {
+		s.SetStrict(true)
+		return nil
+	}
+}
+
+// WithCodecWeight sets the server's preference for responding with the codec
+// with the content type (see SetCodecWeight).
+func WithCodecWeight(contentType string, weight float64) Option {
+	return func(s *WebCodecService) error {
+		return s.SetCodecWeight(contentType, weight)
+	}
+}
+
+// WithNegotiator sets the Negotiator choosing the codec to respond with (see
+// SetNegotiator).
+func WithNegotiator(negotiator Negotiator) Option {
+	return func(s *WebCodecService) error {
+		s.SetNegotiator(negotiator)
+		return nil
+	}
+}
+
+// WithAcceptCacheSize sets the number of negotiations remembered (see
+// SetAcceptCacheSize).
+func WithAcceptCacheSize(size int) Option {
+	return func(s *WebCodecService) error {
+		s.SetAcceptCacheSize(size)
+		return nil
+	}
+}
+
+// WithSniffing makes GetCodecForData guess the content type of data given
+// without one (see SetSniffing).
+func WithSniffing() Option {
+	return func(s *WebCodecService) error {
+		s.SetSniffing(true)
+		return nil
+	}
+}
+
+// WithLogger sets the Logger recording what the service does (see SetLogger).
+func WithLogger(logger Logger) Option {
+	return func(s *WebCodecService) error {
+		s.SetLogger(logger)
+		return nil
+	}
+}
+
+// WithTracer sets the Tracer the context aware methods start spans with (see
+// SetTracer).
+func WithTracer(tracer Tracer) Option {
+	return func(s *WebCodecService) error {
+		s.SetTracer(tracer)
+		return nil
+	}
+}
+
+// WithMetrics sets the Metrics measuring marshalling and unmarshalling (see
+// SetMetrics).
+func WithMetrics(metrics Metrics) Option {
+	return func(s *WebCodecService) error {
+		s.SetMetrics(metrics)
+		return nil
+	}
+}
+
+// WithMaxPayloadSize sets the most bytes of data the service unmarshals (see
+// SetMaxPayloadSize).
+func WithMaxPayloadSize(size int64) Option {
+	return func(s *WebCodecService) error {
+		s.SetMaxPayloadSize(size)
+		return nil
+	}
+}
+
+// WithCodecMaxPayloadSize sets the most bytes of data the service unmarshals
+// with the codec with the content type (see SetCodecMaxPayloadSize).
+func WithCodecMaxPayloadSize(contentType string, size int64) Option {
+	return func(s *WebCodecService) error {
+		s.SetCodecMaxPayloadSize(contentType, size)
+		return nil
+	}
+}
+
+// WithDecodeLimits sets the limits on the structure of data unmarshalled (see
+// SetDecodeLimits).
+func WithDecodeLimits(limits codecs.DecodeLimits) Option {
+	return func(s *WebCodecService) error {
+		s.SetDecodeLimits(limits)
+		return nil
+	}
+}
+
+// WithConflictPolicy sets what TryAddCodec does with codecs conflicting with
+// installed codecs (see SetConflictPolicy).
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(s *WebCodecService) error {
+		s.SetConflictPolicy(policy)
+		return nil
+	}
+}
+
+// WithTransformers adds the transformers to the service's pipeline (see
+// AddTransformer).
+func WithTransformers(transformers ...Transformer) Option {
+	return func(s *WebCodecService) error {
+		for _, transformer := range transformers {
+			s.AddTransformer(transformer)
+		}
+		return nil
+	}
+}
+
+// WithFieldNames names the fields of structs of the object's type (see
+// RenameFields).
+func WithFieldNames(object interface{}, names map[string]string) Option {
+	return func(s *WebCodecService) error {
+		s.RenameFields(object, names)
+		return nil
+	}
+}
+
+// WithEmptyBodyPolicy sets what the service does when unmarshalling an empty
+// body (see SetEmptyBodyPolicy).
+func WithEmptyBodyPolicy(policy EmptyBodyPolicy) Option {
+	return func(s *WebCodecService) error {
+		s.SetEmptyBodyPolicy(policy)
+		return nil
+	}
+}
+
+// WithNilObjectPolicy sets what the service does when marshalling a nil object
+// (see SetNilObjectPolicy).
+func WithNilObjectPolicy(policy NilObjectPolicy) Option {
+	return func(s *WebCodecService) error {
+		s.SetNilObjectPolicy(policy)
+		return nil
+	}
+}
+
+// applyOptions applies the options to the service, panicking if one fails
+// since the service would be misconfigured.
+func (s *WebCodecService) applyOptions(options []Option) {
+	for _, option := range options {
+		if err := option(s); err != nil {
+			panic(fmt.Sprintf("codecs: Cannot configure the WebCodecService: %s", err))
+		}
+	}
+}
+
+// isDefaultCodec gets whether the codec is one of DefaultCodecs.
+func isDefaultCodec(codec codecs.Codec) bool {
+	for _, defaultCodec := range DefaultCodecs {
+		if codec == defaultCodec {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewWebCodecService_WithOptions(t *testing.T) {
+
+	jsonCodec, xmlCodec := new(json.JsonCodec), new(xml.SimpleXmlCodec)
+
+	service := NewWebCodecService(WithoutDefaultCodecs(), WithCodecs(jsonCodec, xmlCodec), WithDefaultCodec(constants.ContentTypeXML), WithStrictNegotiation())
+
+	assert.Equal(t, 2, len(service.Codecs()))
+	assert.Equal(t, jsonCodec, service.Codecs()[0])
+	assert.Equal(t, xmlCodec, service.Codecs()[1])
+	assert.Equal(t, 6, len(DefaultCodecs), "DefaultCodecs should be left as it is")
+
+	codec, err := service.GetCodec("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, xmlCodec, codec)
+	}
+
+	_, err = service.GetCodecForResponding("image/png", "", false)
+	assert.IsType(t, &NotAcceptableError{}, err)
+
+}
+
+func TestNewWebCodecService_WithCodecs(t *testing.T) {
+
+	jsonCodec := new(json.JsonCodec)
+	service := NewWebCodecService(WithCodecs(jsonCodec))
+
+	if assert.Equal(t, len(DefaultCodecs)+1, len(service.Codecs())) {
+		assert.Equal(t, jsonCodec, service.Codecs()[len(DefaultCodecs)])
+	}
+
+}
+
+func TestNewWebCodecService_WithInvalidOption(t *testing.T) {
+
+	assert.Panics(t, func() {
+		NewWebCodecService(WithDefaultCodec("image/png"))
+	})
+
+	assert.Panics(t, func() {
+		NewWebCodecService(WithCodecWeight(constants.ContentTypeJSON, 2))
+	})
+
+}
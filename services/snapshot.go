@@ -0,0 +1,138 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"io"
+)
+
+// Snapshot is a read-only copy of a WebCodecService's codecs and
+// configuration, taken by WebCodecService.Snapshot.  Changes made to the
+// service afterwards don't affect it, so requests handled with a snapshot
+// taken when the server starts never see codecs being added or removed
+// part way through.
+type Snapshot struct {
+	service *WebCodecService
+}
+
+// Snapshot takes a read-only copy of the service's codecs and configuration.
+// The snapshot remembers negotiations in its own cache, of the same size as
+// the service's.
+func (s *WebCodecService) Snapshot() *Snapshot {
+
+	copied := *s
+	copied.codecs = append([]codecs.Codec(nil), s.codecs...)
+	copied.languages = append([]string(nil), s.languages...)
+	copied.beforeMarshalHooks = append([]BeforeMarshalHook(nil), s.beforeMarshalHooks...)
+	copied.afterMarshalHooks = append([]AfterMarshalHook(nil), s.afterMarshalHooks...)
+	copied.beforeUnmarshalHooks = append([]BeforeUnmarshalHook(nil), s.beforeUnmarshalHooks...)
+	copied.transformers = append([]Transformer(nil), s.transformers...)
+	copied.fieldNames = s.fieldNames.Copy()
+
+	copied.extensions = make(map[string]string, len(s.extensions))
+	for extension, contentType := range s.extensions {
+		copied.extensions[extension] = contentType
+	}
+
+	copied.weights = make(map[string]float64, len(s.weights))
+	for contentType, weight := range s.weights {
+		copied.weights[contentType] = weight
+	}
+
+	copied.codecMaxPayloadSizes = make(map[string]int64, len(s.codecMaxPayloadSizes))
+	for contentType, size := range s.codecMaxPayloadSizes {
+		copied.codecMaxPayloadSizes[contentType] = size
+	}
+
+	copied.codecOptionDefaults = make(map[string]map[string]interface{}, len(s.codecOptionDefaults))
+	for contentType, options := range s.codecOptionDefaults {
+		copied.codecOptionDefaults[contentType] = copyOptions(options)
+	}
+
+	copied.cache = nil
+	if s.cache != nil {
+		copied.cache = newAcceptCache(s.cache.size)
+	}
+
+	return &Snapshot{service: &copied}
+}
+
+// copyOptions copies the options map.
+func copyOptions(options map[string]interface{}) map[string]interface{} {
+
+	copied := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Codecs gets the codecs installed when the snapshot was taken.
+func (s *Snapshot) Codecs() []codecs.Codec {
+	return append([]codecs.Codec(nil), s.service.codecs...)
+}
+
+// GetCodec gets the codec for the content type, as WebCodecService.GetCodec
+// does.
+func (s *Snapshot) GetCodec(contentType string) (codecs.Codec, error) {
+	return s.service.GetCodec(contentType)
+}
+
+// CodecForContentType gets the codec for exactly the content type, as
+// WebCodecService.CodecForContentType does.
+func (s *Snapshot) CodecForContentType(contentType string) (codecs.Codec, bool) {
+	return s.service.CodecForContentType(contentType)
+}
+
+// CodecByName gets the codec with the short name, as
+// WebCodecService.CodecByName does.
+func (s *Snapshot) CodecByName(name string) (codecs.Codec, bool) {
+	return s.service.CodecByName(name)
+}
+
+// GetCodecForData gets the codec for the content type or data, as
+// WebCodecService.GetCodecForData does.
+func (s *Snapshot) GetCodecForData(contentType string, data []byte) (codecs.Codec, error) {
+	return s.service.GetCodecForData(contentType, data)
+}
+
+// GetCodecForResponding gets the codec to respond with, as
+// WebCodecService.GetCodecForResponding does.
+func (s *Snapshot) GetCodecForResponding(accept, extension string, hasCallback bool) (codecs.Codec, error) {
+	return s.service.GetCodecForResponding(accept, extension, hasCallback)
+}
+
+// GetNegotiationForResponding negotiates how to respond, as
+// WebCodecService.GetNegotiationForResponding does.
+func (s *Snapshot) GetNegotiationForResponding(accept, extension string, hasCallback bool) (*Negotiation, error) {
+	return s.service.GetNegotiationForResponding(accept, extension, hasCallback)
+}
+
+// Capabilities gets the capabilities of the codec for the content type, as
+// WebCodecService.Capabilities does.
+func (s *Snapshot) Capabilities(contentType string) (codecs.Capabilities, error) {
+	return s.service.Capabilities(contentType)
+}
+
+// MarshalWithCodec marshals the object, as WebCodecService.MarshalWithCodec
+// does.
+func (s *Snapshot) MarshalWithCodec(codec codecs.Codec, object interface{}, options map[string]interface{}) ([]byte, error) {
+	return s.service.MarshalWithCodec(codec, object, options)
+}
+
+// MarshalWithCodecTo marshals the object to w, as
+// WebCodecService.MarshalWithCodecTo does.
+func (s *Snapshot) MarshalWithCodecTo(codec codecs.Codec, w io.Writer, object interface{}, options map[string]interface{}) error {
+	return s.service.MarshalWithCodecTo(codec, w, object, options)
+}
+
+// UnmarshalWithCodec unmarshals the data into the object, as
+// WebCodecService.UnmarshalWithCodec does.
+func (s *Snapshot) UnmarshalWithCodec(codec codecs.Codec, data []byte, object interface{}) error {
+	return s.service.UnmarshalWithCodec(codec, data, object)
+}
+
+// UnmarshalWithCodecFrom unmarshals the data read from r into the object, as
+// WebCodecService.UnmarshalWithCodecFrom does.
+func (s *Snapshot) UnmarshalWithCodecFrom(codec codecs.Codec, r io.Reader, object interface{}) error {
+	return s.service.UnmarshalWithCodecFrom(codec, r, object)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.RegisterExtension(".data", constants.ContentTypeXML)
+	snapshot := service.Snapshot()
+
+	// change the service after the snapshot
+	assert.NoError(t, service.RemoveCodec(constants.ContentTypeXML))
+	service.RegisterExtension(".data", constants.ContentTypeJSON)
+	service.AddCodec(new(bufferedCodec))
+
+	assert.Equal(t, 2, len(snapshot.Codecs()))
+
+	codec, err := snapshot.GetCodec(constants.ContentTypeXML)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	codec, err = snapshot.GetCodecForResponding("", ".data", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	_, err = snapshot.GetCodec("application/x-buffered")
+	assert.Error(t, err)
+
+	// changing what Codecs returns doesn't change the snapshot
+	snapshot.Codecs()[0] = new(bufferedCodec)
+	assert.Equal(t, constants.ContentTypeJSON, snapshot.Codecs()[0].ContentType())
+
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/codecs/constants"
+)
+
+// SniffContentType guesses the content type of the data from its first bytes,
+// returning "" if it can't tell.  BSON documents are recognised by their
+// length prefix, msgpack by a leading map or array, JSON by a leading { or [,
+// and XML by a leading <.
+func SniffContentType(data []byte) string {
+
+	// a BSON document starts with its own length, and ends with a zero byte
+	if len(data) >= 5 && int(binary.LittleEndian.Uint32(data)) == len(data) && data[len(data)-1] == 0 {
+		return constants.ContentTypeBSON
+	}
+
+	if len(data) > 0 {
+		switch b := data[0]; {
+		case b >= 0x80 && b <= 0x9f, b == 0xdc, b == 0xdd, b == 0xde, b == 0xdf:
+			return constants.ContentTypeMsgpack
+		}
+	}
+
+	text := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(text) == 0 {
+		return ""
+	}
+
+	switch text[0] {
+	case '{', '[':
+		return constants.ContentTypeJSON
+	case '<':
+		return constants.ContentTypeXML
+	}
+
+	return ""
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSniffContentType(t *testing.T) {
+
+	for data, expected := range map[string]string{
+		`{"name":"Mat"}`:            constants.ContentTypeJSON,
+		"\xef\xbb\xbf \n[1,2]":      constants.ContentTypeJSON,
+		`<?xml version="1.0"?><a/>`: constants.ContentTypeXML,
+		"\x81\xa4name\xa3Mat":       constants.ContentTypeMsgpack,
+		"\x05\x00\x00\x00\x00":      constants.ContentTypeBSON,
+		"name,age\nMat,30":          "",
+		"":                          "",
+	} {
+		assert.Equal(t, expected, SniffContentType([]byte(data)), data)
+	}
+
+}
+
+func TestGetCodecForData(t *testing.T) {
+
+	service := NewWebCodecService()
+	data := []byte(`<data><name>Mat</name></data>`)
+
+	codec, _ := service.GetCodecForData("", data)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	service.SetSniffing(true)
+
+	codec, _ = service.GetCodecForData("", data)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	codec, _ = service.GetCodecForData(constants.ContentTypeCSV, data)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	codec, _ = service.GetCodecForData("", []byte("name,age"))
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+}
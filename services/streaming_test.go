@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMarshalWithCodecTo(t *testing.T) {
+
+	service := NewWebCodecService()
+	var buffer bytes.Buffer
+
+	// streaming codecs encode the public data
+	object := new(test.TestObjectWithFacade)
+	object.On("PublicData", map[string]interface{}(nil)).Return(map[string]interface{}{"public": true}, nil)
+
+	if assert.NoError(t, service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, object, nil)) {
+		assert.Equal(t, "{\"public\":true}\n", buffer.String())
+	}
+
+	// others are marshalled and written
+	buffer.Reset()
+	if assert.NoError(t, service.MarshalWithCodecTo(new(bufferedCodec), &buffer, map[string]interface{}{"name": "Mat"}, nil)) {
+		assert.Equal(t, `{"name":"Mat"}`, buffer.String())
+	}
+
+}
+
+func TestUnmarshalWithCodecFrom(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var object map[string]interface{}
+	if assert.NoError(t, service.UnmarshalWithCodecFrom(new(json.JsonCodec), strings.NewReader(`{"name":"Mat"}`), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+
+	object = nil
+	if assert.NoError(t, service.UnmarshalWithCodecFrom(new(bufferedCodec), strings.NewReader(`{"name":"Tyler"}`), &object)) {
+		assert.Equal(t, "Tyler", object["name"])
+	}
+
+}
+
+// bufferedCodec is a JSON codec that can't stream.
+type bufferedCodec struct{}
+
+func (c *bufferedCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return jsonEncoding.Marshal(object)
+}
+
+func (c *bufferedCodec) Unmarshal(data []byte, obj interface{}) error {
+	return jsonEncoding.Unmarshal(data, obj)
+}
+
+func (c *bufferedCodec) ContentType() string {
+	return "application/x-buffered"
+}
+
+func (c *bufferedCodec) FileExtension() string {
+	return ".buffered"
+}
+
+func (c *bufferedCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func TestMarshalWithCodecTo_Sequence(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	rows := func(yield func(map[string]interface{}) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(map[string]interface{}{"id": i, "secret": "x"}) {
+				return
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	if assert.NoError(t, service.MarshalWithCodecTo(new(json.JsonCodec), &buffer, rows, map[string]interface{}{constants.OptionKeyFields: "id"})) {
+		assert.Equal(t, "[{\"id\":1},{\"id\":2},{\"id\":3}]\n", buffer.String())
+	}
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), rows, map[string]interface{}{constants.OptionKeyFields: "id"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `[{"id":1},{"id":2},{"id":3}]`, string(data))
+	}
+
+}
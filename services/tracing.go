@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/codecs"
+)
+
+// Span names and attribute keys used when tracing.
+const (
+	SpanNegotiate = "codecs.Negotiate"
+	SpanMarshal   = "codecs.Marshal"
+	SpanUnmarshal = "codecs.Unmarshal"
+
+	AttributeCodec       = "codecs.codec"
+	AttributeContentType = "codecs.content_type"
+	AttributeSize        = "codecs.size"
+	AttributeAccept      = "codecs.accept"
+)
+
+// Tracer starts spans, so that negotiating, marshalling and unmarshalling
+// show up in distributed traces.  The otelcodecs package adapts an
+// OpenTelemetry TracerProvider to it.
+type Tracer interface {
+
+	// Start starts a span with the name as a child of any span in the
+	// context, returning a context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+
+	// SetAttribute sets an attribute of the span.  Values are strings,
+	// int64s or bools.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records that the operation failed with the error.
+	RecordError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// SetTracer sets the Tracer GetCodecForRespondingContext,
+// MarshalWithCodecContext and UnmarshalWithCodecContext start spans with, or
+// stops tracing if it is nil.  Spans record the codec, content type and payload
+// size.
+func (s *WebCodecService) SetTracer(tracer Tracer) {
+	s.tracer = tracer
+}
+
+// startSpan starts a span for working with the codec, if there is a tracer.
+func (s *WebCodecService) startSpan(ctx context.Context, name string, codec codecs.Codec) (context.Context, Span) {
+
+	if s.tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, name)
+
+	if codec != nil {
+		setCodecAttributes(span, codec)
+	}
+
+	return ctx, span
+}
+
+// setCodecAttributes sets the attributes describing the codec.
+func setCodecAttributes(span Span, codec codecs.Codec) {
+	span.SetAttribute(AttributeCodec, fmt.Sprintf("%T", codec))
+	span.SetAttribute(AttributeContentType, codec.ContentType())
+}
+
+// endSpan ends the span, recording the payload size and any error.
+func endSpan(span Span, size int64, err error) {
+
+	if span == nil {
+		return
+	}
+
+	span.SetAttribute(AttributeSize, size)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
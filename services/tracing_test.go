@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// recordedSpan is a Span remembering what happened to it.
+type recordedSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordedSpan) RecordError(err error)                      { s.err = err }
+func (s *recordedSpan) End()                                       { s.ended = true }
+
+// recordingTracer is a Tracer remembering the spans it starts.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordedSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestSetTracer(t *testing.T) {
+
+	tracer := new(recordingTracer)
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetTracer(tracer)
+
+	_, err := service.GetCodecForRespondingContext(context.Background(), constants.ContentTypeXML, "", false)
+	assert.NoError(t, err)
+
+	data, err := service.MarshalWithCodecContext(context.Background(), new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	assert.NoError(t, err)
+
+	var object map[string]interface{}
+	err = service.UnmarshalWithCodecContext(context.Background(), new(json.JsonCodec), []byte(`{"name":`), &object)
+	assert.Error(t, err)
+
+	if assert.Equal(t, 3, len(tracer.spans)) {
+
+		assert.Equal(t, SpanNegotiate, tracer.spans[0].name)
+		assert.Equal(t, constants.ContentTypeXML, tracer.spans[0].attributes[AttributeAccept])
+		assert.Equal(t, constants.ContentTypeXML, tracer.spans[0].attributes[AttributeContentType])
+		assert.True(t, tracer.spans[0].ended)
+
+		assert.Equal(t, SpanMarshal, tracer.spans[1].name)
+		assert.Equal(t, "*json.JsonCodec", tracer.spans[1].attributes[AttributeCodec])
+		assert.Equal(t, constants.ContentTypeJSON, tracer.spans[1].attributes[AttributeContentType])
+		assert.Equal(t, int64(len(data)), tracer.spans[1].attributes[AttributeSize])
+		assert.NoError(t, tracer.spans[1].err)
+		assert.True(t, tracer.spans[1].ended)
+
+		assert.Equal(t, SpanUnmarshal, tracer.spans[2].name)
+		assert.Equal(t, int64(8), tracer.spans[2].attributes[AttributeSize])
+		assert.Equal(t, err, tracer.spans[2].err)
+		assert.True(t, tracer.spans[2].ended)
+	}
+
+	service.SetTracer(nil)
+	service.MarshalWithCodecContext(context.Background(), new(json.JsonCodec), map[string]interface{}{"name": "Mat"}, nil)
+	assert.Equal(t, 3, len(tracer.spans))
+
+}
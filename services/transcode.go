@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/stew/objects"
+	"unicode/utf8"
+)
+
+// Transcode converts the data from one content type to another, unmarshalling
+// it with the codec for fromContentType and marshalling the result with the
+// codec for toContentType and the options.  So that as much as possible
+// survives the trip, maps with non-string keys (as msgpack gives) get string
+// keys, and byte strings that are valid UTF-8 become strings unless the codec
+// for toContentType is binary.
+func (s *WebCodecService) Transcode(fromContentType, toContentType string, data []byte, options map[string]interface{}) ([]byte, error) {
+
+	fromCodec, err := s.GetCodec(fromContentType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toCodec, err := s.GetCodec(toContentType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var object interface{}
+	if err := s.UnmarshalWithCodec(fromCodec, data, &object); err != nil {
+		return nil, err
+	}
+
+	return s.MarshalWithCodec(toCodec, transcodable(object, !codecs.CapabilitiesOf(toCodec).Binary), options)
+}
+
+// transcodable gets the unmarshalled value in a form any codec can marshal.
+func transcodable(value interface{}, text bool) interface{} {
+
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(transcodable(key, true))] = transcodable(item, text)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[key] = transcodable(item, text)
+		}
+		return converted
+	case objects.Map:
+		return transcodable(map[string]interface{}(value), text)
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, item := range value {
+			converted[i] = transcodable(item, text)
+		}
+		return converted
+	case []map[string]interface{}:
+		converted := make([]interface{}, len(value))
+		for i, item := range value {
+			converted[i] = transcodable(item, text)
+		}
+		return converted
+	case []byte:
+		if text && utf8.Valid(value) {
+			return string(value)
+		}
+	}
+
+	return value
+}
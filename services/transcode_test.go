@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTranscode(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(rawMapCodec))
+
+	data, err := service.Transcode("application/x-raw-map", constants.ContentTypeJSON, []byte("ignored"), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"1":true,"name":"Mat","tags":["a","b"]}`, string(data))
+	}
+
+	data, err = service.Transcode(constants.ContentTypeJSON, constants.ContentTypeJSON, []byte(`{"name":"Mat"}`), map[string]interface{}{constants.OptionKeyIndent: " "})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "{\n \"name\": \"Mat\"\n}", string(data))
+	}
+
+	_, err = service.Transcode("image/png", constants.ContentTypeJSON, nil, nil)
+	assert.Error(t, err)
+
+	_, err = service.Transcode(constants.ContentTypeJSON, constants.ContentTypeJSON, []byte(`{`), nil)
+	assert.Error(t, err)
+
+}
+
+// rawMapCodec unmarshals maps with interface keys and byte string values, as
+// msgpack does.
+type rawMapCodec struct {
+	bufferedCodec
+}
+
+func (c *rawMapCodec) Unmarshal(data []byte, obj interface{}) error {
+	*obj.(*interface{}) = map[interface{}]interface{}{
+		"name": []byte("Mat"),
+		1:      true,
+		"tags": []interface{}{[]byte("a"), "b"},
+	}
+	return nil
+}
+
+func (c *rawMapCodec) ContentType() string {
+	return "application/x-raw-map"
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/stew/objects"
+	"path"
+)
+
+// Transformer is called with the public data of every object the service
+// marshals (after Facade objects are resolved and fields filtered), and
+// returns the data to marshal instead, such as the data with fields redacted
+// or wrapped in a standard envelope.  Transformers should copy rather than
+// change the data, since it may be the object being marshalled.  Returning an
+// error stops the marshalling with the error.
+type Transformer func(codec codecs.Codec, data interface{}, options map[string]interface{}) (interface{}, error)
+
+// AddTransformer adds the transformer to the end of the service's pipeline.
+// The data a transformer returns is the data passed to the next.
+func (s *WebCodecService) AddTransformer(transformer Transformer) {
+	s.transformers = append(s.transformers, transformer)
+}
+
+// transform runs the public data through the transformers.
+func (s *WebCodecService) transform(codec codecs.Codec, data interface{}, options map[string]interface{}) (interface{}, error) {
+
+	for _, transformer := range s.transformers {
+
+		var err error
+		data, err = transformer(codec, data, options)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// RedactFields makes a Transformer leaving out the fields of maps (including
+// nested maps, and maps in slices) whose names match any of the patterns, as
+// matched by path.Match (such as "*_token").
+func RedactFields(patterns ...string) Transformer {
+	return func(codec codecs.Codec, data interface{}, options map[string]interface{}) (interface{}, error) {
+		return redact(data, patterns), nil
+	}
+}
+
+// redact copies the data leaving out the fields matching the patterns.
+func redact(data interface{}, patterns []string) interface{} {
+
+	switch data := data.(type) {
+	case objects.Map:
+		return objects.Map(redact(map[string]interface{}(data), patterns).(map[string]interface{}))
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(data))
+		for field, value := range data {
+			if !matchesAny(field, patterns) {
+				redacted[field] = redact(value, patterns)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(data))
+		for i, item := range data {
+			redacted[i] = redact(item, patterns)
+		}
+		return redacted
+	}
+
+	return data
+}
+
+// matchesAny gets whether the name matches any of the patterns.
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// envelope wraps the public data with standard metadata.
+func envelope(codec codecs.Codec, data interface{}, options map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"meta": map[string]interface{}{"version": 1}, "data": data}, nil
+}
+
+func TestAddTransformer(t *testing.T) {
+
+	service := NewWebCodecService(WithTransformers(RedactFields("*_token", "password")))
+	service.AddTransformer(envelope)
+
+	object := map[string]interface{}{
+		"name":     "Mat",
+		"password": "secret",
+		"accounts": []interface{}{map[string]interface{}{"id": 1, "access_token": "x"}},
+	}
+
+	data, err := service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"data":{"accounts":[{"id":1}],"name":"Mat"},"meta":{"version":1}}`, string(data))
+	}
+
+	// the object itself is left alone
+	assert.Equal(t, "secret", object["password"])
+
+	data, err = service.MarshalWithCodecContext(context.Background(), new(json.JsonCodec), object, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"data":{"accounts":[{"id":1}],"name":"Mat"},"meta":{"version":1}}`, string(data))
+	}
+
+	transformErr := errors.New("refused")
+	service.AddTransformer(func(codec codecs.Codec, data interface{}, options map[string]interface{}) (interface{}, error) {
+		return nil, transformErr
+	})
+
+	_, err = service.MarshalWithCodec(new(json.JsonCodec), object, nil)
+	assert.Equal(t, transformErr, err)
+
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"strings"
+)
+
+// Validatable is the interface objects can implement to check themselves
+// after they are unmarshalled by BindWithCodec.
+type Validatable interface {
+
+	// Validate returns an error if the object is invalid, ideally a FieldError
+	// or *ValidationError saying which fields are at fault.
+	Validate() error
+}
+
+// Validator checks objects after they are unmarshalled by BindWithCodec,
+// returning an error if the object is invalid, ideally a FieldError or
+// *ValidationError saying which fields are at fault.
+type Validator func(object interface{}) error
+
+// FieldError describes what is wrong with a field of an object.
+type FieldError struct {
+
+	// Field is the name of the field at fault, or empty if the error isn't
+	// about a particular field.
+	Field string
+
+	// Message says what is wrong with the field.
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if len(e.Field) == 0 {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError is the error BindWithCodec returns when an object is
+// invalid.  It implements codecs.Facade, so it can be marshalled with the codec
+// negotiated for responding, as {"errors": [{"field": ..., "message": ...}]}.
+type ValidationError struct {
+
+	// Errors describe what is wrong with the object.
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+
+	messages := make([]string, len(e.Errors))
+	for i, fieldError := range e.Errors {
+		messages[i] = fieldError.Error()
+	}
+
+	return "Validation failed: " + strings.Join(messages, "; ")
+}
+
+// PublicData gets the errors as a map, for marshalling.
+func (e *ValidationError) PublicData(options map[string]interface{}) (interface{}, error) {
+
+	errors := make([]interface{}, len(e.Errors))
+	for i, fieldError := range e.Errors {
+		errors[i] = map[string]interface{}{"field": fieldError.Field, "message": fieldError.Message}
+	}
+
+	return map[string]interface{}{"errors": errors}, nil
+}
+
+// SetValidator sets the Validator BindWithCodec checks objects with, after
+// any Validate method of their own, or removes it if it is nil.
+func (s *WebCodecService) SetValidator(validator Validator) {
+	s.validator = validator
+}
+
+// BindWithCodec unmarshals the data into the object as UnmarshalWithCodec
+// does, and then validates it (see Validate).
+func (s *WebCodecService) BindWithCodec(codec codecs.Codec, data []byte, object interface{}) error {
+
+	if err := s.UnmarshalWithCodec(codec, data, object); err != nil {
+		return err
+	}
+
+	return s.Validate(object)
+}
+
+// Validate checks the object with its own Validate method if it is
+// Validatable, and then with the service's Validator if there is one.  The
+// errors they return are turned into a *ValidationError.
+func (s *WebCodecService) Validate(object interface{}) error {
+
+	if validatable, ok := object.(Validatable); ok {
+		if err := validatable.Validate(); err != nil {
+			return validationError(err)
+		}
+	}
+
+	if s.validator != nil {
+		if err := s.validator(object); err != nil {
+			return validationError(err)
+		}
+	}
+
+	return nil
+}
+
+// validationError turns the error into a *ValidationError.
+func validationError(err error) *ValidationError {
+
+	switch err := err.(type) {
+	case *ValidationError:
+		return err
+	case FieldError:
+		return &ValidationError{Errors: []FieldError{err}}
+	}
+
+	return &ValidationError{Errors: []FieldError{{Message: err.Error()}}}
+}
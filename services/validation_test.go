@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type validatedPerson struct {
+	Name string
+	Age  int
+}
+
+func (p *validatedPerson) Validate() error {
+	if len(p.Name) == 0 {
+		return FieldError{Field: "Name", Message: "is required"}
+	}
+	return nil
+}
+
+func TestBindWithCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	var person validatedPerson
+	assert.NoError(t, service.BindWithCodec(new(json.JsonCodec), []byte(`{"Name":"Mat","Age":30}`), &person))
+	assert.Equal(t, "Mat", person.Name)
+
+	err := service.BindWithCodec(new(json.JsonCodec), []byte(`{"Age":30}`), new(validatedPerson))
+	if assert.IsType(t, &ValidationError{}, err) {
+		assert.Equal(t, []FieldError{{Field: "Name", Message: "is required"}}, err.(*ValidationError).Errors)
+		assert.Equal(t, "Validation failed: Name: is required", err.Error())
+	}
+
+	// unmarshalling errors aren't validation errors
+	err = service.BindWithCodec(new(json.JsonCodec), []byte(`{`), new(validatedPerson))
+	assert.Error(t, err)
+	assert.False(t, isValidationError(err))
+
+}
+
+func TestSetValidator(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.SetValidator(func(object interface{}) error {
+		if object.(*validatedPerson).Age < 0 {
+			return errors.New("Age cannot be negative")
+		}
+		return nil
+	})
+
+	err := service.BindWithCodec(new(json.JsonCodec), []byte(`{"Name":"Mat","Age":-1}`), new(validatedPerson))
+	if assert.IsType(t, &ValidationError{}, err) {
+		assert.Equal(t, []FieldError{{Message: "Age cannot be negative"}}, err.(*ValidationError).Errors)
+	}
+
+	service.SetValidator(nil)
+	assert.NoError(t, service.BindWithCodec(new(json.JsonCodec), []byte(`{"Name":"Mat","Age":-1}`), new(validatedPerson)))
+
+}
+
+func TestValidationError_Marshal(t *testing.T) {
+
+	service := NewWebCodecService()
+	err := &ValidationError{Errors: []FieldError{{Field: "Name", Message: "is required"}}}
+
+	data, marshalErr := service.MarshalWithCodec(new(json.JsonCodec), err, nil)
+	if assert.NoError(t, marshalErr) {
+		assert.Equal(t, `{"errors":[{"field":"Name","message":"is required"}]}`, string(data))
+	}
+
+}
+
+// isValidationError gets whether the error is a *ValidationError.
+func isValidationError(err error) bool {
+	_, ok := err.(*ValidationError)
+	return ok
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/codecs"
@@ -11,14 +12,81 @@ import (
 	"github.com/stretchr/codecs/jsonp"
 	"github.com/stretchr/codecs/msgpack"
 	"github.com/stretchr/codecs/xml"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
 	"strings"
+	"time"
 )
 
-// ErrorContentTypeNotSupported is the error for when a content type is requested that is not supported by the system
+// ErrorContentTypeNotSupported is the error for when a content type is requested that is not supported by the system.
+// Configuring codecs that aren't installed returns it, and an UnsupportedMediaTypeError matches it (see errors.Is).
 var ErrorContentTypeNotSupported = errors.New("Content type is not supported.")
 
+// ErrorInvalidWeight is the error for when a codec weight is given that isn't
+// between 0 and 1.
+var ErrorInvalidWeight = errors.New("Codec weight must be between 0 and 1.")
+
+// NotAcceptableError is the error GetCodecForResponding returns in strict mode
+// when no installed codec is acceptable, for responding with 406 Not
+// Acceptable.
+type NotAcceptableError struct {
+	// Accept is the accept string that no codec matched.
+	Accept string
+
+	// Supported are the content types of the installed codecs.
+	Supported []string
+}
+
+func (e *NotAcceptableError) Error() string {
+	return fmt.Sprintf("No codec is acceptable for \"%s\".", e.Accept)
+}
+
+// StatusCode gets the HTTP status to respond with, 406 Not Acceptable.
+func (e *NotAcceptableError) StatusCode() int {
+	return http.StatusNotAcceptable
+}
+
+// UnsupportedMediaTypeError is the error GetCodec returns when no installed
+// codec handles the content type, for responding with 415 Unsupported Media
+// Type.
+type UnsupportedMediaTypeError struct {
+	// ContentType is the content type that no codec handles.
+	ContentType string
+
+	// Supported are the content types of the installed codecs.
+	Supported []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("Content type \"%s\" is not supported.", e.ContentType)
+}
+
+// StatusCode gets the HTTP status to respond with, 415 Unsupported Media
+// Type.
+func (e *UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// Is gets whether the target is ErrorContentTypeNotSupported, which the error
+// replaces.
+func (e *UnsupportedMediaTypeError) Is(target error) bool {
+	return target == ErrorContentTypeNotSupported
+}
+
+// supportedContentTypes gets the content types of the codecs.
+func supportedContentTypes(installed []codecs.Codec) []string {
+	contentTypes := make([]string, 0, len(installed))
+	for _, codec := range installed {
+		contentTypes = append(contentTypes, codec.ContentType())
+	}
+	return contentTypes
+}
+
 // DefaultCodecs represents the list of Codecs that get added automatically by
-// a call to NewWebCodecService.
+// a call to NewWebCodecService.  Services copy it when they are made, so
+// changing it only affects services made afterwards.
 var DefaultCodecs = []codecs.Codec{new(json.JsonCodec), new(jsonp.JsonPCodec), new(msgpack.MsgpackCodec), new(bson.BsonCodec), new(csv.CsvCodec), new(xml.SimpleXmlCodec)}
 
 // WebCodecService represents the default implementation for providing access to the
@@ -26,13 +94,112 @@ var DefaultCodecs = []codecs.Codec{new(json.JsonCodec), new(jsonp.JsonPCodec), n
 type WebCodecService struct {
 	// codecs holds the installed codecs for this service.
 	codecs []codecs.Codec
+
+	// strict is whether to return a NotAcceptableError rather than falling
+	// back to a codec the accept string doesn't match.
+	strict bool
+
+	// defaultCodec is the codec to fall back to, or nil for the first
+	// installed codec.
+	defaultCodec codecs.Codec
+
+	// sniffing is whether GetCodecForData guesses the content type of data
+	// given without one.
+	sniffing bool
+
+	// cache remembers the results of GetCodecAndParametersForResponding, or is
+	// nil if they aren't remembered.
+	cache *acceptCache
+
+	// extensions maps the extensions registered with RegisterExtension to
+	// content types.
+	extensions map[string]string
+
+	// negotiator chooses the codec to respond with, or is nil for the
+	// service's own negotiation.
+	negotiator Negotiator
+
+	// weights maps lower case content types to the server's preference for
+	// responding with them (see SetCodecWeight).
+	weights map[string]float64
+
+	// languages are the language tags responses are available in.
+	languages []string
+
+	// codecOptionDefaults maps lower case content types to the default
+	// options for marshalling with their codecs.
+	codecOptionDefaults map[string]map[string]interface{}
+
+	// beforeMarshalHooks, afterMarshalHooks and beforeUnmarshalHooks are
+	// the hooks added with OnBeforeMarshal, OnAfterMarshal and
+	// OnBeforeUnmarshal.
+	beforeMarshalHooks   []BeforeMarshalHook
+	afterMarshalHooks    []AfterMarshalHook
+	beforeUnmarshalHooks []BeforeUnmarshalHook
+
+	// transformers are the transformers added with AddTransformer.
+	transformers []Transformer
+
+	// fieldNames are the names given to struct fields with RenameFields.
+	fieldNames codecs.FieldNames
+
+	// validator checks objects bound by BindWithCodec, or is nil.
+	validator Validator
+
+	// logger records what the service does, or is nil.
+	logger Logger
+
+	// tracer starts spans for the context aware methods, or is nil.
+	tracer Tracer
+
+	// metrics measures marshalling and unmarshalling, or is nil.
+	metrics Metrics
+
+	// maxPayloadSize is the most bytes of data unmarshalled, or 0 for no
+	// limit, and codecMaxPayloadSizes maps lower case content types to
+	// limits in its place (see SetCodecMaxPayloadSize).
+	maxPayloadSize       int64
+	codecMaxPayloadSizes map[string]int64
+
+	// conflictPolicy says what TryAddCodec does with codecs conflicting with
+	// installed codecs.
+	conflictPolicy ConflictPolicy
+
+	// decodeLimits bound the structure of data unmarshalled with codecs
+	// implementing codecs.LimitedCodec.
+	decodeLimits codecs.DecodeLimits
+
+	// emptyBodyPolicy and nilObjectPolicy say what the service does with
+	// empty bodies and nil objects, rather than leaving them to the codecs.
+	emptyBodyPolicy EmptyBodyPolicy
+	nilObjectPolicy NilObjectPolicy
 }
 
 // NewWebCodecService makes a new WebCodecService with the default codecs
-// added.
-func NewWebCodecService() *WebCodecService {
+// added, configured by the options.  It panics if an option can't be applied,
+// such as WithDefaultCodec for a content type that isn't installed.
+func NewWebCodecService(options ...Option) *WebCodecService {
+	s := NewWebCodecServiceWith(DefaultCodecs...)
+	s.applyOptions(options)
+	return s
+}
+
+// NewWebCodecServiceWith makes a new WebCodecService with only the given codecs
+// added, in the order given.  The service keeps its own list of codecs, so
+// changing the slice passed in (or DefaultCodecs) doesn't affect it.
+func NewWebCodecServiceWith(installed ...codecs.Codec) *WebCodecService {
 	s := new(WebCodecService)
-	s.codecs = DefaultCodecs
+	s.codecs = append([]codecs.Codec(nil), installed...)
+	s.cache = newAcceptCache(DefaultAcceptCacheSize)
+	return s
+}
+
+// NewWebCodecServiceFromRegistry makes a new WebCodecService with the codecs
+// registered with codecs.Register added, in the order they were registered in,
+// configured by the options.  Importing a codec package registers its codecs.
+func NewWebCodecServiceFromRegistry(options ...Option) *WebCodecService {
+	s := NewWebCodecServiceWith(codecs.Registered()...)
+	s.applyOptions(options)
 	return s
 }
 
@@ -41,9 +208,295 @@ func (s *WebCodecService) Codecs() []codecs.Codec {
 	return s.codecs
 }
 
-// AddCodec adds the specified codec to the installed codecs list.
+// CodecForContentType gets the installed codec whose content type (or one of
+// whose aliases) is exactly the content type, ignoring any parameters.  Unlike
+// GetCodec, it doesn't fall back to a default codec or match structured syntax
+// suffixes.
+func (s *WebCodecService) CodecForContentType(contentType string) (codecs.Codec, bool) {
+
+	mediaType, subtype := parseMediaType(contentType)
+
+	for _, codec := range s.codecs {
+		for _, codecContentType := range codecContentTypes(codec) {
+			if codecType, codecSubtype := parseMediaType(codecContentType); codecType == mediaType && codecSubtype == subtype {
+				return codec, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// CodecByName gets the installed codec with the short name (such as "json"),
+// which is its file extension without the leading dot, or an extension
+// registered with RegisterExtension.
+func (s *WebCodecService) CodecByName(name string) (codecs.Codec, bool) {
+
+	if len(name) == 0 {
+		return nil, false
+	}
+
+	if matches := s.codecsForExtension(name, s.codecs); len(matches) > 0 {
+		return matches[0], true
+	}
+
+	return nil, false
+}
+
+// AddCodec adds the specified codec to the installed codecs list.  If it has
+// the same content type or file extension as an installed codec, the
+// service's ConflictPolicy decides what happens (see SetConflictPolicy); by
+// default AddCodec panics, as codecs.Register does (see TryAddCodec to get
+// the conflict as an error instead).
 func (s *WebCodecService) AddCodec(codec codecs.Codec) {
+	if err := s.TryAddCodec(codec); err != nil {
+		panic(fmt.Sprintf("codecs: Cannot add the codec: %s", err))
+	}
+}
+
+// TryAddCodec adds the specified codec to the installed codecs list as
+// AddCodec does, except that under ConflictError a *CodecConflictError is
+// returned, and the codec isn't installed, if it has the same content type or
+// file extension as an installed codec.
+func (s *WebCodecService) TryAddCodec(codec codecs.Codec) error {
+
+	if s.conflictPolicy != ConflictShadow {
+		if conflict := s.conflict(codec); conflict != nil {
+			if s.conflictPolicy == ConflictError {
+				return conflict
+			}
+			s.replaceConflicting(codec)
+			s.cache.clear()
+			return nil
+		}
+	}
+
 	s.codecs = append(s.codecs, codec)
+	s.cache.clear()
+
+	return nil
+}
+
+// InsertCodecAt installs the codec at the index in the installed codecs list,
+// ahead of the codec that was there, so that it wins ties with the codecs
+// after it.  Indexes beyond the end of the list add the codec to the end.
+// DefaultCodecs is left as it is.
+func (s *WebCodecService) InsertCodecAt(index int, codec codecs.Codec) {
+
+	if index < 0 {
+		index = 0
+	}
+	if index > len(s.codecs) {
+		index = len(s.codecs)
+	}
+
+	inserted := make([]codecs.Codec, 0, len(s.codecs)+1)
+	inserted = append(inserted, s.codecs[:index]...)
+	inserted = append(inserted, codec)
+	s.codecs = append(inserted, s.codecs[index:]...)
+
+	s.cache.clear()
+}
+
+// SetCodecOrder moves the installed codecs with the content types to the
+// start of the installed codecs list, in the order given, with the rest
+// following in their current order.  ErrorContentTypeNotSupported is returned
+// (and the order left as it is) if a content type isn't installed.
+// DefaultCodecs is left as it is.
+func (s *WebCodecService) SetCodecOrder(contentTypes ...string) error {
+
+	ordered := make([]codecs.Codec, 0, len(s.codecs))
+	moved := map[int]bool{}
+
+	for _, contentType := range contentTypes {
+
+		found := false
+		for i, codec := range s.codecs {
+			if !moved[i] && strings.ToLower(codec.ContentType()) == strings.ToLower(contentType) {
+				ordered = append(ordered, codec)
+				moved[i], found = true, true
+				break
+			}
+		}
+
+		if !found {
+			return ErrorContentTypeNotSupported
+		}
+	}
+
+	for i, codec := range s.codecs {
+		if !moved[i] {
+			ordered = append(ordered, codec)
+		}
+	}
+
+	s.codecs = ordered
+	s.cache.clear()
+
+	return nil
+}
+
+// RemoveCodec removes the installed codec with the content type, returning
+// ErrorContentTypeNotSupported if there isn't one.  DefaultCodecs is left as
+// it is.
+func (s *WebCodecService) RemoveCodec(contentType string) error {
+
+	for i, codec := range s.codecs {
+		if strings.ToLower(codec.ContentType()) == strings.ToLower(contentType) {
+
+			remaining := make([]codecs.Codec, 0, len(s.codecs)-1)
+			s.codecs = append(append(remaining, s.codecs[:i]...), s.codecs[i+1:]...)
+
+			if s.defaultCodec == codec {
+				s.defaultCodec = nil
+			}
+			s.cache.clear()
+			return nil
+		}
+	}
+
+	return ErrorContentTypeNotSupported
+}
+
+// ReplaceCodec installs the codec in place of the installed codec with the
+// same content type, keeping its position, and returns
+// ErrorContentTypeNotSupported if there isn't one.  DefaultCodecs is left as
+// it is.
+func (s *WebCodecService) ReplaceCodec(codec codecs.Codec) error {
+
+	for i, installed := range s.codecs {
+		if strings.ToLower(installed.ContentType()) == strings.ToLower(codec.ContentType()) {
+
+			replaced := make([]codecs.Codec, len(s.codecs))
+			copy(replaced, s.codecs)
+			replaced[i] = codec
+			s.codecs = replaced
+
+			if s.defaultCodec == installed {
+				s.defaultCodec = codec
+			}
+			s.cache.clear()
+			return nil
+		}
+	}
+
+	return ErrorContentTypeNotSupported
+}
+
+// SetAcceptCacheSize sets how many of the most recently used combinations of
+// accept string, extension and callback GetCodecForResponding remembers the
+// codec for, so that it needn't negotiate again.  A size of 0 turns the cache
+// off.
+func (s *WebCodecService) SetAcceptCacheSize(size int) {
+	if size <= 0 {
+		s.cache = nil
+		return
+	}
+	s.cache = newAcceptCache(size)
+}
+
+// SetStrict sets whether GetCodecForResponding returns a NotAcceptableError
+// when the accept string matches none of the installed codecs, rather than
+// falling back to one of them.  Accept strings without any valid media ranges
+// match every codec.
+func (s *WebCodecService) SetStrict(strict bool) {
+	s.strict = strict
+	s.cache.clear()
+}
+
+// SetDefaultCodec sets the installed codec with the content type as the one
+// to fall back to when responding to accept strings that match no codec, and
+// to use for interpreting requests without a content type.  An empty content
+// type restores the defaults, falling back to the first installed codec and
+// interpreting requests as JSON.  ErrorContentTypeNotSupported is returned if
+// no installed codec has the content type.
+func (s *WebCodecService) SetDefaultCodec(contentType string) error {
+
+	if len(contentType) == 0 {
+		s.defaultCodec = nil
+		s.cache.clear()
+		return nil
+	}
+
+	for _, codec := range s.codecs {
+		for _, codecContentType := range codecContentTypes(codec) {
+			if strings.ToLower(codecContentType) == strings.ToLower(contentType) {
+				s.defaultCodec = codec
+				s.cache.clear()
+				return nil
+			}
+		}
+	}
+
+	return ErrorContentTypeNotSupported
+}
+
+// SetCodecWeight sets the server's preference (between 0 and 1, and 1 unless
+// set) for responding with the codec with the content type, like the qs
+// parameter of RFC 2296.  GetCodecForResponding weighs the quality the accept
+// string gives each codec by its weight, so that when the accept string gives
+// codecs the same quality (as with */*) the server's preferred codec is used.
+func (s *WebCodecService) SetCodecWeight(contentType string, weight float64) error {
+
+	if weight < 0 || weight > 1 {
+		return ErrorInvalidWeight
+	}
+
+	if s.weights == nil {
+		s.weights = map[string]float64{}
+	}
+	s.weights[strings.ToLower(contentType)] = weight
+	s.cache.clear()
+
+	return nil
+}
+
+// weight gets the server's preference for responding with the codec.
+func (s *WebCodecService) weight(codec codecs.Codec) float64 {
+	if weight, ok := s.weights[strings.ToLower(codec.ContentType())]; ok {
+		return weight
+	}
+	return 1
+}
+
+// SetLanguages sets the language tags (such as "en-GB") responses are
+// available in, for GetLanguageForResponding to choose between.
+func (s *WebCodecService) SetLanguages(languages ...string) {
+	s.languages = languages
+}
+
+// GetLanguageForResponding gets the language tag to respond in based on the
+// given Accept-Language string and the service's languages (see
+// NegotiateLanguage).  Pass it to codecs in the options under
+// constants.OptionKeyClientLanguage so that they can localize their output.
+func (s *WebCodecService) GetLanguageForResponding(acceptLanguage string) string {
+	return NegotiateLanguage(acceptLanguage, s.languages)
+}
+
+// SetSniffing sets whether GetCodecForData guesses the content type of data
+// given without one (see SniffContentType), rather than interpreting it as
+// GetCodec does.
+func (s *WebCodecService) SetSniffing(sniffing bool) {
+	s.sniffing = sniffing
+}
+
+// fallbackCodec gets the codec to fall back to when nothing else matches.
+func (s *WebCodecService) fallbackCodec(installed []codecs.Codec) codecs.Codec {
+	for _, codec := range installed {
+		if s.defaultCodec != nil && codec == s.defaultCodec {
+			return codec
+		}
+	}
+	return installed[0]
+}
+
+// SetNegotiator sets the Negotiator GetCodecForResponding uses to choose the
+// codec to respond with, or restores the service's own negotiation if it is
+// nil.  Negotiations are cached by accept string, extension and callback as
+// usual, so the negotiator should choose based on those alone.
+func (s *WebCodecService) SetNegotiator(negotiator Negotiator) {
+	s.negotiator = negotiator
+	s.cache.clear()
 }
 
 func (s *WebCodecService) assertCodecs() {
@@ -58,41 +511,215 @@ func (s *WebCodecService) assertCodecs() {
 //
 // As of now, if hasCallback is true, the JSONP codec will be returned.
 // This may be changed if additional callback capable codecs are added.
+//
+// Otherwise a codec matching the extension (see RegisterExtension) is used,
+// unless the accept string rules it out with a quality of 0.  Failing that,
+// the codec the accept string gives the highest quality is used (see
+// ParseAccept), where each codec takes its quality from the most specific
+// media range matching its content type, including */* and type/* ranges,
+// weighed by the codec's weight (see SetCodecWeight).  Media types with a
+// structured syntax suffix (such as application/vnd.example+json) also match
+// codecs that declare the suffix by implementing codecs.SuffixCodec.  Codecs
+// tied on quality are ordered by the specificity of their media range (so
+// type/subtype beats type/*, which beats */*), then by whether they matched it
+// exactly rather than by suffix, and then by the order they were installed in;
+// the order of the accept string itself makes no difference.
+//
+// Codecs declaring the media type parameters they understand (by implementing
+// codecs.ParameterCodec) only match media ranges giving their required
+// parameters and supported values.  Use GetCodecAndParametersForResponding to
+// also get the parameters that were asked for.
+//
+// If nothing matches, a codec capable of callbacks is used when hasCallback is
+// true, and otherwise the default codec (see SetDefaultCodec) is, unless the
+// service is in strict mode (see SetStrict).  Codecs the accept string forbids
+// by giving them a quality of 0 are never used; a NotAcceptableError is
+// returned if every installed codec is forbidden.
 func (s *WebCodecService) GetCodecForResponding(accept, extension string, hasCallback bool) (codecs.Codec, error) {
+	codec, _, err := s.GetCodecAndParametersForResponding(accept, extension, hasCallback)
+	return codec, err
+}
+
+// GetCodecAndParametersForResponding gets the codec to use to respond in the
+// same way as GetCodecForResponding, along with the media type parameters
+// (such as version) asked for by the matching media range.  For codecs that
+// implement codecs.ParameterCodec these are the parameters the codec declares,
+// with defaults filled in, and for others they are all of the range's
+// parameters.
+func (s *WebCodecService) GetCodecAndParametersForResponding(accept, extension string, hasCallback bool) (codecs.Codec, map[string]string, error) {
+
+	negotiation, err := s.GetNegotiationForResponding(accept, extension, hasCallback)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return negotiation.Codec, negotiation.Params, nil
+}
+
+// GetNegotiationForResponding negotiates how to respond in the same way as
+// GetCodecForResponding, getting the codec along with the content type to
+// respond with and the media range that was matched.
+func (s *WebCodecService) GetNegotiationForResponding(accept, extension string, hasCallback bool) (*Negotiation, error) {
 
 	// make sure we have at least one codec
 	s.assertCodecs()
 
+	key := acceptCacheKey(accept, extension, hasCallback)
+	if negotiation, err, ok := s.cache.get(key); ok {
+		s.logNegotiation(accept, extension, hasCallback, negotiation, err, "", true)
+		return negotiation, err
+	}
+
+	var negotiation *Negotiation
+	var err error
+	var reason string
+	if s.negotiator != nil {
+		negotiation, err = s.negotiator.Negotiate(accept, extension, hasCallback, s.codecs)
+		negotiation, err = completeNegotiation(accept, negotiation, err, s.codecs)
+		reason = "the codec was chosen by the service's Negotiator"
+	} else {
+		negotiation, err = s.negotiate(accept, extension, hasCallback, s.codecs, &reason)
+	}
+	s.cache.put(key, negotiation, err)
+	s.logNegotiation(accept, extension, hasCallback, negotiation, err, reason, false)
+
+	return negotiation, err
+}
+
+// Negotiate chooses which of the codecs to respond with in the way described
+// by GetCodecForResponding, using the service's configuration (such as its
+// strict mode, default codec and extensions).  This makes the service the
+// Negotiator used when no other is set, and lets other Negotiators fall back to
+// it.
+func (s *WebCodecService) Negotiate(accept, extension string, hasCallback bool, installed []codecs.Codec) (*Negotiation, error) {
+	return s.negotiate(accept, extension, hasCallback, installed, nil)
+}
+
+// negotiate does the work of Negotiate, describing why the codec was chosen
+// in the reason, if it isn't nil.
+func (s *WebCodecService) negotiate(accept, extension string, hasCallback bool, installed []codecs.Codec, reason *string) (*Negotiation, error) {
+
+	// because gives the reason for the outcome
+	because := func(why string) {
+		if reason != nil {
+			*reason = why
+		}
+	}
+
+	if len(installed) == 0 {
+		because("no codecs are installed")
+		return nil, &NotAcceptableError{Accept: accept}
+	}
+
+	ranges := ParseAccept(accept)
+
 	// is there a callback?  If so, look for JSONP
 	if hasCallback {
-		for _, codec := range s.codecs {
-			if codec.ContentType() == constants.ContentTypeJSONP {
-				return codec, nil
+		for _, codec := range installed {
+			if isJSONP(codec) && !forbids(ranges, codec) {
+				because("there is a callback, and the JSONP codec is installed")
+				return newNegotiation(codec, ranges, -1), nil
 			}
 		}
 	}
 
-	for _, codec := range s.codecs {
-		if strings.Contains(strings.ToLower(accept), strings.ToLower(codec.ContentType())) {
-			return codec, nil
-		} else if strings.ToLower(codec.FileExtension()) == strings.ToLower(extension) {
-			return codec, nil
-		} else if hasCallback && codec.CanMarshalWithCallback() {
-			return codec, nil
+	if len(extension) > 0 {
+		for _, codec := range s.codecsForExtension(extension, installed) {
+			if quality, index, _ := acceptance(ranges, codec); index < 0 || quality > 0 {
+				because("it matches the extension " + extension)
+				return newNegotiation(codec, ranges, index), nil
+			}
+		}
+	}
+
+	var best codecs.Codec
+	bestQuality, bestIndex, bestExact := 0.0, -1, false
+	for _, codec := range installed {
+
+		quality, index, exact := acceptance(ranges, codec)
+		quality *= s.weight(codec)
+		if index < 0 || quality == 0 {
+			continue
 		}
+
+		if best == nil || quality > bestQuality {
+			best, bestQuality, bestIndex, bestExact = codec, quality, index, exact
+			continue
+		}
+
+		// ties go to the more specific range, then the exact match, and then
+		// the codec installed first
+		specificity, bestSpecificity := ranges[index].specificity(), ranges[bestIndex].specificity()
+		if quality == bestQuality && (specificity > bestSpecificity || (specificity == bestSpecificity && exact && !bestExact)) {
+			best, bestQuality, bestIndex, bestExact = codec, quality, index, exact
+		}
+	}
+
+	if best != nil {
+		because(fmt.Sprintf("it has the highest weighed quality (%g) for the media range %s", bestQuality, ranges[bestIndex]))
+		return newNegotiation(best, ranges, bestIndex), nil
 	}
 
-	// return the first installed codec by default
-	return s.codecs[0], nil
+	if s.strict && len(ranges) > 0 {
+		because("nothing matches the accept string, and the service is strict")
+		return nil, &NotAcceptableError{Accept: accept, Supported: supportedContentTypes(installed)}
+	}
+
+	if hasCallback {
+		for _, codec := range installed {
+			if codec.CanMarshalWithCallback() && !forbids(ranges, codec) {
+				because("nothing matches the accept string, and it can marshal with a callback")
+				return newNegotiation(codec, ranges, -1), nil
+			}
+		}
+	}
+
+	// return the default codec (or the first installed one), unless the
+	// accept string forbids it
+	fallback := s.fallbackCodec(installed)
+	if forbids(ranges, fallback) {
+		fallback = nil
+		for _, codec := range installed {
+			if !forbids(ranges, codec) {
+				fallback = codec
+				break
+			}
+		}
+	}
+
+	if fallback == nil {
+		because("the accept string forbids every codec")
+		return nil, &NotAcceptableError{Accept: accept, Supported: supportedContentTypes(installed)}
+	}
+
+	because("nothing matches the accept string, so the default codec is used")
+	return newNegotiation(fallback, ranges, -1), nil
 }
 
 // GetCodec gets the codec to use to interpret the request based on the
-// content type.
+// content type, which may be one of the aliases of codecs implementing
+// codecs.AliasCodec.  Content types no codec handles directly that have a
+// structured syntax suffix (such as application/vnd.example+json) get a codec
+// declaring the suffix, if there is one.  Codecs implementing
+// codecs.ParameterCodec (such as codecs.VersionedCodec) only handle content
+// types whose parameters they support, so a version parameter picks the codec
+// for that version.
 func (s *WebCodecService) GetCodec(contentType string) (codecs.Codec, error) {
 
 	// make sure we have at least one codec
 	s.assertCodecs()
 
+	if len(contentType) == 0 && s.defaultCodec != nil {
+		return s.defaultCodec, nil
+	}
+
+	// the parameters given, such as version
+	var given MediaRange
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		given.Params = params
+	}
+
 	for _, codec := range s.codecs {
 
 		// default codec
@@ -100,6 +727,11 @@ func (s *WebCodecService) GetCodec(contentType string) (codecs.Codec, error) {
 			return codec, nil
 		}
 
+		// skip codecs that don't support the parameters
+		if parameterCodec, ok := codec.(codecs.ParameterCodec); ok && !given.satisfies(parameterCodec.Parameters()) {
+			continue
+		}
+
 		// match the content type
 		if strings.Contains(strings.ToLower(contentType), strings.ToLower(codec.ContentType())) {
 			return codec, nil
@@ -107,8 +739,63 @@ func (s *WebCodecService) GetCodec(contentType string) (codecs.Codec, error) {
 
 	}
 
-	return nil, errors.New(fmt.Sprintf("Content type \"%s\" is not supported.", contentType))
+	// match an alias, such as application/xml
+	mediaType, subtype := parseMediaType(contentType)
+	for _, codec := range s.codecs {
+		if aliasCodec, ok := codec.(codecs.AliasCodec); ok {
+			for _, alias := range aliasCodec.Aliases() {
+				if aliasType, aliasSubtype := parseMediaType(alias); aliasType == mediaType && aliasSubtype == subtype {
+					return codec, nil
+				}
+			}
+		}
+	}
+
+	// match a structured syntax suffix, such as application/vnd.example+json
+	if plus := strings.LastIndex(subtype, "+"); plus >= 0 {
+		for _, codec := range s.codecs {
+			if suffixCodec, ok := codec.(codecs.SuffixCodec); ok {
+				for _, suffix := range suffixCodec.Suffixes() {
+					if strings.ToLower(suffix) == subtype[plus+1:] {
+						return codec, nil
+					}
+				}
+			}
+		}
+	}
+
+	return nil, &UnsupportedMediaTypeError{ContentType: contentType, Supported: supportedContentTypes(s.codecs)}
+
+}
+
+// Capabilities gets the capabilities of the codec GetCodec gets for the content
+// type (see codecs.CapabilitiesOf).
+func (s *WebCodecService) Capabilities(contentType string) (codecs.Capabilities, error) {
+
+	codec, err := s.GetCodec(contentType)
+
+	if err != nil {
+		return codecs.Capabilities{}, err
+	}
+
+	return codecs.CapabilitiesOf(codec), nil
+}
+
+// GetCodecForData gets the codec to use to interpret the request data based on
+// the content type, in the same way as GetCodec.  If the content type is empty
+// and sniffing is on (see SetSniffing), the codec for the content type guessed
+// from the data is used, if one is installed.
+func (s *WebCodecService) GetCodecForData(contentType string, data []byte) (codecs.Codec, error) {
+
+	if len(contentType) == 0 && s.sniffing {
+		if sniffed := SniffContentType(data); len(sniffed) > 0 {
+			if codec, err := s.GetCodec(sniffed); err == nil {
+				return codec, nil
+			}
+		}
+	}
 
+	return s.GetCodec(contentType)
 }
 
 // MarshalWithCodec marshals the specified object with the specified codec and options.
@@ -116,11 +803,31 @@ func (s *WebCodecService) GetCodec(contentType string) (codecs.Codec, error) {
 // marshalled instead.
 func (s *WebCodecService) MarshalWithCodec(codec codecs.Codec, object interface{}, options map[string]interface{}) ([]byte, error) {
 
+	started := time.Now()
+	data, err := s.marshalWithCodec(codec, object, options)
+	s.recordCoding(LogEventMarshal, codec, started, int64(len(data)), err)
+
+	return data, err
+}
+
+// marshalWithCodec does the work of MarshalWithCodec.
+func (s *WebCodecService) marshalWithCodec(codec codecs.Codec, object interface{}, options map[string]interface{}) ([]byte, error) {
+
 	// make sure we have at least one codec
 	s.assertCodecs()
 
+	// use the codec's default options
+	options = s.codecOptions(codec, options)
+
+	// run the hooks
+	object, err := s.beforeMarshal(codec, object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
 	// get the public data
-	publicData, err := codecs.PublicData(object, options)
+	publicData, err := s.publicData(context.Background(), codec, object, options)
 
 	// if there was an error - return it
 	if err != nil {
@@ -128,14 +835,222 @@ func (s *WebCodecService) MarshalWithCodec(codec codecs.Codec, object interface{
 	}
 
 	// let the codec do its work
-	return codec.Marshal(publicData, options)
+	data := []byte{}
+	if !s.marshalsEmpty(publicData) {
+		data, err = codec.Marshal(publicData, options)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.afterMarshal(codec, object, options, data)
 }
 
 // UnmarshalWithCodec unmarshals the specified data into the object with the specified codec.
 func (s *WebCodecService) UnmarshalWithCodec(codec codecs.Codec, data []byte, object interface{}) error {
+	return s.unmarshalWithCodec(codec, data, object, nil)
+}
+
+// unmarshalWithCodec unmarshals the data into the object with the codec,
+// passing the options to codecs taking them.
+func (s *WebCodecService) unmarshalWithCodec(codec codecs.Codec, data []byte, object interface{}, options map[string]interface{}) error {
 
 	// make sure we have at least one codec
 	s.assertCodecs()
 
-	return codec.Unmarshal(data, object)
+	started, size := time.Now(), int64(len(data))
+
+	// refuse data over the limit
+	err := s.checkPayloadSize(codec, size)
+
+	// run the hooks
+	if err == nil {
+		data, err = s.beforeUnmarshal(codec, data, object)
+	}
+
+	// refuse data exceeding the decode limits
+	if err == nil {
+		err = codecs.CheckLimits(codec, data, s.decodeLimits)
+	}
+
+	// deal with empty bodies as the policy says
+	empty := false
+	if err == nil {
+		empty, err = s.emptyBody(data)
+	}
+
+	// convert keys from the client's case
+	if err == nil && !empty {
+		data, err = unconvertKeys(codec, data, options)
+	}
+
+	if err == nil && !empty {
+		if options == nil {
+			err = codec.Unmarshal(data, object)
+		} else {
+			err = codecs.UnmarshalWithOptions(codec, data, object, options)
+		}
+	}
+
+	s.recordCoding(LogEventUnmarshal, codec, started, size, err)
+
+	return err
+}
+
+// MarshalWithCodecTo marshals the object with the codec and options as
+// MarshalWithCodec does, writing the result to w.  Codecs implementing
+// codecs.StreamEncoder write to w as they go, so the whole result needn't be
+// held in memory.  Channels and iterator functions (see codecs.IsSequence) are
+// written element by element by codecs implementing codecs.SequenceEncoder,
+// and given to other streaming codecs as they are.
+func (s *WebCodecService) MarshalWithCodecTo(codec codecs.Codec, w io.Writer, object interface{}, options map[string]interface{}) error {
+
+	// make sure we have at least one codec
+	s.assertCodecs()
+
+	// after marshal hooks need the whole of the data
+	encoder, ok := codec.(codecs.StreamEncoder)
+	if !ok || len(s.afterMarshalHooks) > 0 {
+		data, err := s.MarshalWithCodec(codec, object, options)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	// use the codec's default options
+	options = s.codecOptions(codec, options)
+
+	// run the hooks
+	object, err := s.beforeMarshal(codec, object, options)
+
+	if err != nil {
+		return err
+	}
+
+	// channels and iterator functions are written as they come
+	if codecs.IsSequence(object) {
+		return s.encodeSequence(w, encoder, object, options)
+	}
+
+	// get the public data
+	publicData, err := s.publicData(context.Background(), codec, object, options)
+
+	// if there was an error - return it
+	if err != nil {
+		return err
+	}
+
+	if s.marshalsEmpty(publicData) {
+		return nil
+	}
+
+	if !s.recording() {
+		return encoder.Encode(w, publicData, options)
+	}
+
+	started, counter := time.Now(), &countingWriter{Writer: w}
+	err = encoder.Encode(counter, publicData, options)
+	s.recordCoding(LogEventMarshal, codec, started, counter.count, err)
+
+	return err
+}
+
+// UnmarshalWithCodecFrom unmarshals the data read from r into the object with
+// the codec.  Codecs implementing codecs.StreamDecoder read from r as they go,
+// so the data needn't be held in memory.
+func (s *WebCodecService) UnmarshalWithCodecFrom(codec codecs.Codec, r io.Reader, object interface{}) error {
+
+	// make sure we have at least one codec
+	s.assertCodecs()
+
+	// before unmarshal hooks and decode limits need the whole of the data
+	if decoder, ok := codec.(codecs.StreamDecoder); ok && len(s.beforeUnmarshalHooks) == 0 && !s.limitsDecoding(codec) {
+
+		// deal with empty bodies as the policy says
+		empty, r := s.emptyReader(r)
+		if empty {
+			_, err := s.emptyBody(nil)
+			return err
+		}
+
+		limited := s.LimitReader(r, codec)
+
+		if !s.recording() {
+			return limitedError(limited, decoder.Decode(limited, object))
+		}
+
+		started, counter := time.Now(), &countingReader{Reader: limited}
+		err := limitedError(limited, decoder.Decode(counter, object))
+		s.recordCoding(LogEventUnmarshal, codec, started, counter.count, err)
+
+		return err
+	}
+
+	data, err := ioutil.ReadAll(s.LimitReader(r, codec))
+	if err != nil {
+		return err
+	}
+
+	return s.UnmarshalWithCodec(codec, data, object)
+}
+
+// MarshalWithCodecInCharset marshals the object as MarshalWithCodec does, and
+// then transcodes the output into the charset (such as the charset parameter
+// resolved by GetCodecAndParametersForResponding) if the codec's content type
+// holds text.  The charset the output ends up in is returned for use in the
+// Content-Type header; it is empty for binary content types.
+func (s *WebCodecService) MarshalWithCodecInCharset(codec codecs.Codec, object interface{}, options map[string]interface{}, charset string) ([]byte, string, error) {
+
+	data, err := s.MarshalWithCodec(codec, object, options)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isTextual(codec.ContentType()) {
+		return data, "", nil
+	}
+
+	return EncodeCharset(data, charset)
+}
+
+// UnmarshalWithCodecAndContentType unmarshals the data into the object as
+// UnmarshalWithCodec does, first transcoding it into UTF-8 from the charset
+// named by the content type's charset parameter, if it has one.
+func (s *WebCodecService) UnmarshalWithCodecAndContentType(codec codecs.Codec, data []byte, contentType string, object interface{}) error {
+
+	if charset := contentTypeCharset(contentType); len(charset) > 0 && isTextual(contentType) {
+
+		var err error
+		if data, err = DecodeCharset(data, charset); err != nil {
+			return err
+		}
+	}
+
+	return s.UnmarshalWithCodec(codec, data, object)
+}
+
+// GetContentEncodingForResponding gets the content coding to respond in based
+// on the given Accept-Encoding string (see NegotiateContentEncoding), for use
+// in the Content-Encoding header and with MarshalWithCodecAndContentEncoding.
+// An empty content coding means the response should not be compressed.
+func (s *WebCodecService) GetContentEncodingForResponding(acceptEncoding string) (string, error) {
+	return NegotiateContentEncoding(acceptEncoding)
+}
+
+// MarshalWithCodecAndContentEncoding marshals the object as MarshalWithCodec
+// does, and then compresses the output in the content coding (such as the one
+// from GetContentEncodingForResponding).
+func (s *WebCodecService) MarshalWithCodecAndContentEncoding(codec codecs.Codec, object interface{}, options map[string]interface{}, contentEncoding string) ([]byte, error) {
+
+	data, err := s.MarshalWithCodec(codec, object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Compress(data, contentEncoding)
 }
@@ -1,14 +1,20 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/csv"
 	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/jsonp"
+	"github.com/stretchr/codecs/msgpack"
 	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/codecs/xml"
 	"github.com/stretchr/stew/objects"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"net/http"
 	"strings"
 	"testing"
 )
@@ -26,6 +32,53 @@ func TestNewWebCodecService_DefaultCodecs(t *testing.T) {
 	assert.Equal(t, len(DefaultCodecs), len(n.codecs))
 }
 
+func TestNewWebCodecService_CopiesDefaultCodecs(t *testing.T) {
+
+	one, two := NewWebCodecService(), NewWebCodecService()
+	testCodec := new(test.TestCodec)
+	testCodec.On("ContentType").Return("application/x-test")
+	testCodec.On("FileExtension").Return(".test")
+	one.AddCodec(testCodec)
+
+	assert.Equal(t, len(DefaultCodecs)+1, len(one.Codecs()))
+	assert.Equal(t, len(DefaultCodecs), len(two.Codecs()))
+
+	original := DefaultCodecs[0]
+	DefaultCodecs[0] = new(test.TestCodec)
+	defer func() { DefaultCodecs[0] = original }()
+
+	assert.Equal(t, original, two.Codecs()[0], "changing DefaultCodecs shouldn't affect existing services")
+
+}
+
+func TestNewWebCodecServiceWith(t *testing.T) {
+
+	jsonCodec, xmlCodec := new(json.JsonCodec), new(xml.SimpleXmlCodec)
+	installed := []codecs.Codec{jsonCodec, xmlCodec}
+
+	service := NewWebCodecServiceWith(installed...)
+	installed[0] = xmlCodec
+
+	assert.Equal(t, []codecs.Codec{jsonCodec, xmlCodec}, service.Codecs())
+
+}
+
+func TestNewWebCodecServiceFromRegistry(t *testing.T) {
+
+	service := NewWebCodecServiceFromRegistry()
+
+	// importing the default codecs' packages registered them
+	for _, codec := range DefaultCodecs {
+		registered, err := service.GetCodec(codec.ContentType())
+		if assert.NoError(t, err, codec.ContentType()) {
+			assert.Equal(t, codec.ContentType(), registered.ContentType())
+		}
+	}
+
+	assert.Equal(t, codecs.Registered(), service.Codecs())
+
+}
+
 func TestAddCodec(t *testing.T) {
 
 	service := NewWebCodecService()
@@ -41,6 +94,210 @@ func TestAddCodec(t *testing.T) {
 
 }
 
+func TestTryAddCodec(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	jsonCodec := new(json.JsonCodec)
+
+	err := service.TryAddCodec(jsonCodec)
+	if conflict, ok := err.(*CodecConflictError); assert.True(t, ok) {
+		assert.Equal(t, jsonCodec, conflict.Codec)
+		assert.Equal(t, service.Codecs()[0], conflict.Installed)
+		assert.Equal(t, constants.ContentTypeJSON, conflict.ContentType)
+	}
+	assert.Equal(t, 2, len(service.Codecs()))
+
+	// aliases and file extensions conflict too
+	err = service.TryAddCodec(&codecs.VersionedCodec{Codec: new(csv.CsvCodec), Type: constants.ContentTypeXMLAlias})
+	assert.Equal(t, constants.ContentTypeXMLAlias, err.(*CodecConflictError).ContentType)
+	err = service.TryAddCodec(&codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.example+json"})
+	assert.Equal(t, constants.FileExtensionJSON, err.(*CodecConflictError).FileExtension)
+
+	// versions tell codecs apart
+	service = NewWebCodecServiceWith(&codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.example+json", Version: "1"})
+	assert.NoError(t, service.TryAddCodec(&codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.example+json", Version: "2"}))
+	assert.Error(t, service.TryAddCodec(&codecs.VersionedCodec{Codec: new(json.JsonCodec), Type: "application/vnd.example+json", Version: "2"}))
+
+	// replacing
+	service = NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetConflictPolicy(ConflictReplace)
+	if assert.NoError(t, service.TryAddCodec(jsonCodec)) {
+		assert.Equal(t, []codecs.Codec{jsonCodec, service.Codecs()[1]}, service.Codecs())
+	}
+
+	// shadowing
+	service = NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetConflictPolicy(ConflictShadow)
+	if assert.NoError(t, service.TryAddCodec(jsonCodec)) {
+		assert.Equal(t, 3, len(service.Codecs()))
+	}
+
+}
+
+func TestAddCodec_Conflicts(t *testing.T) {
+
+	// conflicting codecs are refused
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	jsonCodec := new(json.JsonCodec)
+
+	assert.Panics(t, func() {
+		service.AddCodec(jsonCodec)
+	})
+	assert.Equal(t, 2, len(service.Codecs()))
+
+	// unless they shadow them
+	service.SetConflictPolicy(ConflictShadow)
+
+	service.AddCodec(jsonCodec)
+	if assert.Equal(t, 3, len(service.Codecs())) {
+		assert.Equal(t, jsonCodec, service.Codecs()[2])
+	}
+
+	// or replace them
+	service = NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetConflictPolicy(ConflictReplace)
+
+	service.AddCodec(jsonCodec)
+	assert.Equal(t, []codecs.Codec{jsonCodec, service.Codecs()[1]}, service.Codecs())
+
+}
+
+func TestRemoveCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	if assert.NoError(t, service.RemoveCodec(constants.ContentTypeBSON)) {
+		assert.Equal(t, len(DefaultCodecs)-1, len(service.Codecs()))
+
+		_, err := service.GetCodec(constants.ContentTypeBSON)
+		assert.Error(t, err)
+
+		// the defaults are untouched
+		_, err = NewWebCodecService().GetCodec(constants.ContentTypeBSON)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, ErrorContentTypeNotSupported, service.RemoveCodec(constants.ContentTypeBSON))
+
+	if assert.NoError(t, service.SetDefaultCodec(constants.ContentTypeCSV)) && assert.NoError(t, service.RemoveCodec(constants.ContentTypeCSV)) {
+		codec, _ := service.GetCodecForResponding("", "", false)
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+}
+
+func TestReplaceCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	// the replacement keeps the position of the codec it replaces
+	replacement := new(test.TestCodec)
+	replacement.On("ContentType").Return(constants.ContentTypeJSON)
+
+	if assert.NoError(t, service.ReplaceCodec(replacement)) {
+		assert.Equal(t, replacement, service.Codecs()[0])
+		assert.Equal(t, len(DefaultCodecs), len(service.Codecs()))
+		assert.NotEqual(t, replacement, DefaultCodecs[0])
+	}
+
+	unknown := new(test.TestCodec)
+	unknown.On("ContentType").Return("image/png")
+	assert.Equal(t, ErrorContentTypeNotSupported, service.ReplaceCodec(unknown))
+
+}
+
+func TestInsertCodecAt(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec)}}
+	xmlCodec := new(xml.SimpleXmlCodec)
+	csvCodec := new(csv.CsvCodec)
+
+	service.InsertCodecAt(0, xmlCodec)
+	service.InsertCodecAt(10, csvCodec)
+
+	if assert.Equal(t, 3, len(service.Codecs())) {
+		assert.Equal(t, xmlCodec, service.Codecs()[0])
+		assert.Equal(t, csvCodec, service.Codecs()[2])
+	}
+
+	codec, _ := service.GetCodecForResponding("*/*", "", false)
+	assert.Equal(t, xmlCodec, codec)
+
+}
+
+func TestSetCodecOrder(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	if assert.NoError(t, service.SetCodecOrder(constants.ContentTypeXML, constants.ContentTypeCSV)) {
+		assert.Equal(t, constants.ContentTypeXML, service.Codecs()[0].ContentType())
+		assert.Equal(t, constants.ContentTypeCSV, service.Codecs()[1].ContentType())
+		assert.Equal(t, constants.ContentTypeJSON, service.Codecs()[2].ContentType())
+		assert.Equal(t, len(DefaultCodecs), len(service.Codecs()))
+
+		codec, _ := service.GetCodecForResponding("", "", false)
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+		// the defaults are untouched
+		assert.Equal(t, constants.ContentTypeJSON, DefaultCodecs[0].ContentType())
+	}
+
+	assert.Equal(t, ErrorContentTypeNotSupported, service.SetCodecOrder(constants.ContentTypeJSON, "image/png"))
+	assert.Equal(t, constants.ContentTypeXML, service.Codecs()[0].ContentType())
+
+}
+
+func TestCodecForContentType(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, ok := service.CodecForContentType(constants.ContentTypeJSON + "; charset=UTF-8")
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	// aliases
+	codec, ok = service.CodecForContentType(strings.ToUpper(constants.ContentTypeXMLAlias))
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	// no defaults or suffixes
+	_, ok = service.CodecForContentType("")
+	assert.False(t, ok)
+	_, ok = service.CodecForContentType("application/vnd.example+json")
+	assert.False(t, ok)
+
+}
+
+func TestCodecByName(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, ok := service.CodecByName("json")
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	codec, ok = service.CodecByName(".MSGPACK")
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeMsgpack, codec.ContentType())
+	}
+
+	// registered extensions
+	service.RegisterExtension("rss", constants.ContentTypeXML)
+	codec, ok = service.CodecByName("rss")
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	_, ok = service.CodecByName("yaml")
+	assert.False(t, ok)
+	_, ok = service.CodecByName("")
+	assert.False(t, ok)
+
+}
+
 func TestGetCodec(t *testing.T) {
 
 	service := NewWebCodecService()
@@ -74,6 +331,33 @@ func TestGetCodec(t *testing.T) {
 
 }
 
+func TestCapabilities(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	capabilities, err := service.Capabilities(constants.ContentTypeJSON)
+	if assert.NoError(t, err) {
+		assert.Equal(t, codecs.Capabilities{StreamEncode: true, StreamDecode: true, Unmarshal: true}, capabilities)
+	}
+
+	capabilities, err = service.Capabilities(constants.ContentTypeMsgpack)
+	if assert.NoError(t, err) {
+		assert.True(t, capabilities.Binary)
+		assert.True(t, capabilities.Unmarshal)
+	}
+
+	capabilities, err = service.Capabilities(constants.ContentTypeJSONP)
+	if assert.NoError(t, err) {
+		assert.False(t, capabilities.Binary)
+		assert.False(t, capabilities.Unmarshal)
+		assert.True(t, capabilities.Callback)
+	}
+
+	_, err = service.Capabilities("image/png")
+	assert.Error(t, err)
+
+}
+
 func TestGetCodecForResponding_DefaultCodec(t *testing.T) {
 
 	service := NewWebCodecService()
@@ -124,6 +408,15 @@ func TestGetCodecForResponding(t *testing.T) {
 		assert.Equal(t, constants.ContentTypeJSONP, codec.ContentType(), "ContentTypeJavaScript")
 	}
 
+	// JSONP - has callback, served as application/javascript
+
+	javaScriptService := NewWebCodecServiceWith(new(json.JsonCodec), &jsonp.JsonPCodec{ApplicationJavaScript: true})
+	codec, _ = javaScriptService.GetCodecForResponding("application/json", "", true)
+
+	if assert.NotNil(t, codec, "Should return the JSONP codec under its alias") {
+		assert.Equal(t, constants.ContentTypeJSONPAlias, codec.ContentType(), "ContentTypeJSONPAlias")
+	}
+
 	// JSONP - file extension
 
 	codec, _ = service.GetCodecForResponding("", constants.FileExtensionJSONP, false)
@@ -150,6 +443,315 @@ func TestGetCodecForResponding(t *testing.T) {
 
 }
 
+func TestGetCodecForResponding_Wildcards(t *testing.T) {
+
+	service := NewWebCodecService()
+	var codec codecs.Codec
+
+	// any type goes to the first installed codec
+	codec, _ = service.GetCodecForResponding("*/*", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	// type ranges match codecs of that type
+	codec, _ = service.GetCodecForResponding("image/png, text/*;q=0.8, application/json;q=0.5", "", false)
+	assert.Equal(t, constants.ContentTypeJSONP, codec.ContentType())
+
+	// more specific ranges override the quality of wildcards
+	codec, _ = service.GetCodecForResponding("text/*, text/javascript;q=0, text/csv;q=0.1", "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("*/*;q=0.1, text/xml;q=0.2", "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	// a browser asking for anything still gets the extension
+	codec, _ = service.GetCodecForResponding("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	// unless it is ruled out
+	codec, _ = service.GetCodecForResponding("text/csv;q=0, text/xml", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+}
+
+func TestGetCodecForResponding_Suffixes(t *testing.T) {
+
+	service := NewWebCodecService()
+	var codec codecs.Codec
+
+	codec, _ = service.GetCodecForResponding("application/vnd.myco.order+json", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("application/vnd.myco.order+xml, application/json;q=0.5", "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	// a codec for the vendor type itself is preferred
+	vendorCodec := new(test.TestCodec)
+	vendorCodec.On("ContentType").Return("application/vnd.myco.order+json")
+	service = &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), vendorCodec}}
+
+	codec, _ = service.GetCodecForResponding("application/vnd.myco.order+json", "", false)
+	assert.Equal(t, vendorCodec, codec)
+
+}
+
+func TestGetCodec_Suffixes(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec("application/vnd.myco.order+json; charset=UTF-8")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	_, err = service.GetCodec("application/vnd.myco.order+cbor")
+	assert.Error(t, err)
+
+}
+
+func TestAliases(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec("application/xml; charset=utf-8")
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+	codec, err = service.GetCodec(constants.ContentTypeMsgpackAlias)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeMsgpack, codec.ContentType())
+	}
+
+	codec, _ = service.GetCodecForResponding("application/xml, application/json;q=0.5", "", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	// aliases are exact matches
+	codec, _ = service.GetCodecForResponding("application/vnd.myco.order+json, application/msgpack", "", false)
+	assert.Equal(t, constants.ContentTypeMsgpack, codec.ContentType())
+
+	if assert.NoError(t, service.SetDefaultCodec(constants.ContentTypeXMLAlias)) {
+		codec, _ = service.GetCodecForResponding("", "", false)
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+	}
+
+}
+
+func TestRegisterExtension(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.RegisterExtension(".jsonl", constants.ContentTypeJSON)
+	service.RegisterExtension("XHTML", constants.ContentTypeXMLAlias)
+
+	codec, _ := service.GetCodecForResponding("", ".JSONL", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("", ".xhtml", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+	// codecs keep their own extensions
+	codec, _ = service.GetCodecForResponding("", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	// registered extensions take precedence
+	service.RegisterExtension(constants.FileExtensionCSV, constants.ContentTypeJSON)
+	codec, _ = service.GetCodecForResponding("", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	// unless they are ruled out
+	codec, _ = service.GetCodecForResponding("application/json;q=0, */*", constants.FileExtensionCSV, false)
+	assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+
+	service.RegisterExtension(".jsonl", "")
+	codec, _ = service.GetCodecForResponding("text/xml", ".jsonl", false)
+	assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+}
+
+func TestSetCodecWeight(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), new(msgpack.MsgpackCodec)}}
+
+	if assert.NoError(t, service.SetCodecWeight(constants.ContentTypeMsgpack, 1)) &&
+		assert.NoError(t, service.SetCodecWeight(constants.ContentTypeJSON, 0.5)) {
+
+		codec, _ := service.GetCodecForResponding("*/*", "", false)
+		assert.Equal(t, constants.ContentTypeMsgpack, codec.ContentType())
+
+		codec, _ = service.GetCodecForResponding("application/json, application/x-msgpack", "", false)
+		assert.Equal(t, constants.ContentTypeMsgpack, codec.ContentType())
+
+		// the client's quality still counts: 1 * 0.5 beats 0.4 * 1
+		codec, _ = service.GetCodecForResponding("application/json, application/x-msgpack;q=0.4", "", false)
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	assert.Equal(t, ErrorInvalidWeight, service.SetCodecWeight(constants.ContentTypeJSON, 1.5))
+	assert.Equal(t, ErrorInvalidWeight, service.SetCodecWeight(constants.ContentTypeJSON, -1))
+
+}
+
+// versionedCodec is a JSON codec for a vendor media type with a version
+// parameter.
+type versionedCodec struct {
+	json.JsonCodec
+}
+
+func (c *versionedCodec) ContentType() string {
+	return "application/vnd.myco.order+json"
+}
+
+func (c *versionedCodec) Parameters() []codecs.Parameter {
+	return []codecs.Parameter{
+		{Name: "version", Values: []string{"1", "2"}, Default: "2"},
+		{Name: "profile"},
+	}
+}
+
+func TestGetCodecAndParametersForResponding(t *testing.T) {
+
+	versioned := new(versionedCodec)
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec), versioned}}
+
+	codec, params, _ := service.GetCodecAndParametersForResponding("application/vnd.myco.order+json; version=1; profile=full; other=x", "", false)
+	assert.Equal(t, versioned, codec)
+	assert.Equal(t, map[string]string{"version": "1", "profile": "full"}, params)
+
+	codec, params, _ = service.GetCodecAndParametersForResponding("application/vnd.myco.order+json", "", false)
+	assert.Equal(t, versioned, codec)
+	assert.Equal(t, map[string]string{"version": "2"}, params)
+
+	// unsupported versions fall back on other codecs
+	codec, params, _ = service.GetCodecAndParametersForResponding("application/vnd.myco.order+json;version=3", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	assert.Equal(t, map[string]string{"version": "3"}, params)
+
+	codec, params, _ = service.GetCodecAndParametersForResponding("", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	assert.Equal(t, map[string]string{}, params)
+
+}
+
+func TestGetCodecForResponding_RequiredParameters(t *testing.T) {
+
+	versioned := new(versionedCodec)
+	service := &WebCodecService{codecs: []codecs.Codec{versioned, new(json.JsonCodec)}}
+
+	codec, _ := service.GetCodecForResponding("*/*", "", false)
+	assert.Equal(t, versioned, codec)
+
+	// required parameters can't be given by wildcards
+	versionRequired := &requiredVersionCodec{}
+	service = &WebCodecService{codecs: []codecs.Codec{versionRequired, new(json.JsonCodec)}}
+
+	codec, _ = service.GetCodecForResponding("*/*", "", false)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+	codec, _ = service.GetCodecForResponding("application/vnd.myco.order+json;version=1, */*;q=0.5", "", false)
+	assert.Equal(t, versionRequired, codec)
+
+}
+
+// requiredVersionCodec is a versionedCodec requiring the version parameter.
+type requiredVersionCodec struct {
+	versionedCodec
+}
+
+func (c *requiredVersionCodec) Parameters() []codecs.Parameter {
+	return []codecs.Parameter{{Name: "version", Required: true}}
+}
+
+func TestGetCodecForResponding_Strict(t *testing.T) {
+
+	service := &WebCodecService{codecs: []codecs.Codec{new(json.JsonCodec)}}
+
+	// falls back to the first codec by default
+	codec, err := service.GetCodecForResponding("application/xml", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	service.SetStrict(true)
+
+	codec, err = service.GetCodecForResponding("application/xml", "", false)
+	assert.Nil(t, codec)
+	if notAcceptable, ok := err.(*NotAcceptableError); assert.True(t, ok) {
+		assert.Equal(t, "application/xml", notAcceptable.Accept)
+	}
+
+	_, err = service.GetCodecForResponding("application/json;q=0, text/*", "", false)
+	assert.IsType(t, &NotAcceptableError{}, err)
+
+	// no accept string accepts anything
+	codec, err = service.GetCodecForResponding("", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+	codec, err = service.GetCodecForResponding("application/xml", constants.FileExtensionJSON, false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+}
+
+func TestSetDefaultCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	if assert.NoError(t, service.SetDefaultCodec(constants.ContentTypeXML)) {
+
+		codec, _ := service.GetCodecForResponding("", "", false)
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+		codec, _ = service.GetCodecForResponding("image/png", "", false)
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+		codec, _ = service.GetCodec("")
+		assert.Equal(t, constants.ContentTypeXML, codec.ContentType())
+
+		// matches still win
+		codec, _ = service.GetCodecForResponding(constants.ContentTypeCSV, "", false)
+		assert.Equal(t, constants.ContentTypeCSV, codec.ContentType())
+	}
+
+	assert.Equal(t, ErrorContentTypeNotSupported, service.SetDefaultCodec("image/png"))
+
+	if assert.NoError(t, service.SetDefaultCodec("")) {
+		codec, _ := service.GetCodecForResponding("", "", false)
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	}
+
+}
+
+func TestGetCodec_UnsupportedMediaTypeError(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+
+	_, err := service.GetCodec("image/png")
+	if unsupported, ok := err.(*UnsupportedMediaTypeError); assert.True(t, ok) {
+		assert.Equal(t, "image/png", unsupported.ContentType)
+		assert.Equal(t, []string{constants.ContentTypeJSON, constants.ContentTypeXML}, unsupported.Supported)
+		assert.Equal(t, http.StatusUnsupportedMediaType, unsupported.StatusCode())
+		assert.Equal(t, "Content type \"image/png\" is not supported.", unsupported.Error())
+	}
+	assert.True(t, errors.Is(err, ErrorContentTypeNotSupported))
+
+}
+
+func TestGetCodecForResponding_NotAcceptableError(t *testing.T) {
+
+	service := NewWebCodecServiceWith(new(json.JsonCodec), new(xml.SimpleXmlCodec))
+	service.SetStrict(true)
+
+	_, err := service.GetCodecForResponding("image/png", "", false)
+	if notAcceptable, ok := err.(*NotAcceptableError); assert.True(t, ok) {
+		assert.Equal(t, []string{constants.ContentTypeJSON, constants.ContentTypeXML}, notAcceptable.Supported)
+		assert.Equal(t, http.StatusNotAcceptable, notAcceptable.StatusCode())
+	}
+
+}
+
 func TestMarshalWithCodec(t *testing.T) {
 
 	testCodec := new(test.TestCodec)
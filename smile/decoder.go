@@ -0,0 +1,423 @@
+package smile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var (
+	// ErrorMissingHeader is returned when data does not start with the Smile header.
+	ErrorMissingHeader = errors.New("codecs: smile: Data does not start with the Smile header")
+
+	// ErrorUnexpectedEnd is returned when the data ends part way through a value.
+	ErrorUnexpectedEnd = errors.New("codecs: smile: Unexpected end of data")
+
+	// ErrorScaleOutOfRange is returned when a BigDecimal has a scale beyond
+	// maxDecimalScale.
+	ErrorScaleOutOfRange = errors.New("codecs: smile: BigDecimal scale out of range")
+)
+
+// maxDecimalScale is the largest scale (positive or negative) of a BigDecimal
+// that will be decoded.  Anything beyond it is far outside the range of a
+// float64, and raising 10 to it would take a long time.
+const maxDecimalScale = 10000
+
+// decoder reads Smile data into generic values.
+type decoder struct {
+	data  []byte
+	pos   int
+	flags byte
+
+	sharedNames  []string
+	sharedValues []string
+}
+
+// unmarshal decodes a complete Smile document.
+func unmarshal(data []byte) (interface{}, error) {
+
+	if len(data) < 4 || data[0] != header[0] || data[1] != header[1] || data[2] != header[2] {
+		return nil, ErrorMissingHeader
+	}
+
+	d := &decoder{data: data, pos: 4, flags: data[3]}
+
+	// empty documents are null
+	if d.pos >= len(d.data) || d.data[d.pos] == tokenEndContent {
+		return nil, nil
+	}
+
+	return d.value()
+}
+
+func (d *decoder) next() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, ErrorUnexpectedEnd
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *decoder) take(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrorUnexpectedEnd
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) value() (interface{}, error) {
+
+	token, err := d.next()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case token >= 0x01 && token <= 0x1F:
+		return d.sharedValue(int(token) - 1)
+	case token == tokenEmptyString:
+		return "", nil
+	case token == tokenNull:
+		return nil, nil
+	case token == tokenFalse:
+		return false, nil
+	case token == tokenTrue:
+		return true, nil
+	case token == tokenInt32, token == tokenInt64:
+		u, err := d.vint()
+		if err != nil {
+			return nil, err
+		}
+		return unzigzag(u), nil
+	case token == tokenBigInteger:
+		raw, err := d.binary7Bit()
+		if err != nil {
+			return nil, err
+		}
+		return bigIntFromBytes(raw), nil
+	case token == tokenFloat32:
+		u, err := d.read7BitGroups(5)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(u))), nil
+	case token == tokenFloat64:
+		u, err := d.read7BitGroups(10)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(u), nil
+	case token == tokenBigDecimal:
+		u, err := d.vint()
+		if err != nil {
+			return nil, err
+		}
+		scale := unzigzag(u)
+		if scale > maxDecimalScale || scale < -maxDecimalScale {
+			return nil, ErrorScaleOutOfRange
+		}
+		raw, err := d.binary7Bit()
+		if err != nil {
+			return nil, err
+		}
+		// value = unscaled * 10^-scale
+		r := new(big.Rat).SetInt(bigIntFromBytes(raw))
+		exponent := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(scale)), nil))
+		if scale > 0 {
+			r.Quo(r, exponent)
+		} else {
+			r.Mul(r, exponent)
+		}
+		f, _ := r.Float64()
+		return f, nil
+	case token >= tokenTinyASCII && token < tokenSmallASCII:
+		return d.shortString(int(token-tokenTinyASCII) + 1)
+	case token >= tokenSmallASCII && token < tokenTinyUnicode:
+		return d.shortString(int(token-tokenSmallASCII) + 33)
+	case token >= tokenTinyUnicode && token < tokenShortUnicode:
+		return d.shortString(int(token-tokenTinyUnicode) + 2)
+	case token >= tokenShortUnicode && token < tokenSmallInt:
+		return d.shortString(int(token-tokenShortUnicode) + 34)
+	case token >= tokenSmallInt && token < tokenLongASCII:
+		return unzigzag(uint64(token - tokenSmallInt)), nil
+	case token == tokenLongASCII, token == tokenLongUnicode:
+		return d.terminatedString()
+	case token == tokenBinary7Bit:
+		return d.binary7Bit()
+	case token >= tokenLongSharedValue && token <= tokenLongSharedValue+3:
+		b, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		return d.sharedValue(int(token&0x03)<<8 | int(b))
+	case token == tokenStartArray:
+		return d.array()
+	case token == tokenStartObject:
+		return d.object()
+	case token == tokenRawBinary:
+		length, err := d.vint()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := d.take(int(length))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), raw...), nil
+	}
+
+	return nil, fmt.Errorf("codecs: smile: Unexpected token 0x%02X at offset %d", token, d.pos-1)
+}
+
+func (d *decoder) array() (interface{}, error) {
+
+	values := []interface{}{}
+	for {
+
+		if d.pos < len(d.data) && d.data[d.pos] == tokenEndArray {
+			d.pos++
+			return values, nil
+		}
+
+		value, err := d.value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+}
+
+func (d *decoder) object() (interface{}, error) {
+
+	m := map[string]interface{}{}
+	for {
+
+		token, err := d.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		switch {
+		case token == tokenEndObject:
+			return m, nil
+		case token == keyEmptyString:
+			key = ""
+		case token >= keyLongShared && token <= keyLongShared+3:
+			b, err := d.next()
+			if err != nil {
+				return nil, err
+			}
+			if key, err = d.sharedName(int(token&0x03)<<8 | int(b)); err != nil {
+				return nil, err
+			}
+		case token == keyLongUnicode:
+			if key, err = d.terminatedString(); err != nil {
+				return nil, err
+			}
+			d.addSharedName(key)
+		case token >= keyShortShared && token < keyShortASCII:
+			if key, err = d.sharedName(int(token - keyShortShared)); err != nil {
+				return nil, err
+			}
+		case token >= keyShortASCII && token < keyShortUnicode:
+			if key, err = d.name(int(token-keyShortASCII) + 1); err != nil {
+				return nil, err
+			}
+		case token >= keyShortUnicode && token <= 0xF7:
+			if key, err = d.name(int(token-keyShortUnicode) + 2); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("codecs: smile: Unexpected key token 0x%02X at offset %d", token, d.pos-1)
+		}
+
+		value, err := d.value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = value
+	}
+}
+
+func (d *decoder) name(length int) (string, error) {
+
+	raw, err := d.take(length)
+
+	if err != nil {
+		return "", err
+	}
+
+	name := string(raw)
+	d.addSharedName(name)
+	return name, nil
+}
+
+func (d *decoder) shortString(length int) (interface{}, error) {
+
+	raw, err := d.take(length)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := string(raw)
+	if d.flags&flagSharedValues != 0 && length <= maxSharedLength {
+		if len(d.sharedValues) >= maxShared {
+			d.sharedValues = d.sharedValues[:0]
+		}
+		d.sharedValues = append(d.sharedValues, s)
+	}
+
+	return s, nil
+}
+
+func (d *decoder) terminatedString() (string, error) {
+
+	for i := d.pos; i < len(d.data); i++ {
+		if d.data[i] == tokenEndString {
+			s := string(d.data[d.pos:i])
+			d.pos = i + 1
+			return s, nil
+		}
+	}
+
+	return "", ErrorUnexpectedEnd
+}
+
+func (d *decoder) addSharedName(name string) {
+	if d.flags&flagSharedNames != 0 && len(name) <= maxSharedLength {
+		if len(d.sharedNames) >= maxShared {
+			d.sharedNames = d.sharedNames[:0]
+		}
+		d.sharedNames = append(d.sharedNames, name)
+	}
+}
+
+func (d *decoder) sharedName(index int) (string, error) {
+	if index >= len(d.sharedNames) {
+		return "", fmt.Errorf("codecs: smile: Invalid shared name reference %d", index)
+	}
+	return d.sharedNames[index], nil
+}
+
+func (d *decoder) sharedValue(index int) (interface{}, error) {
+	if index >= len(d.sharedValues) {
+		return nil, fmt.Errorf("codecs: smile: Invalid shared value reference %d", index)
+	}
+	return d.sharedValues[index], nil
+}
+
+func (d *decoder) vint() (uint64, error) {
+
+	var u uint64
+	for {
+
+		b, err := d.next()
+
+		if err != nil {
+			return 0, err
+		}
+
+		if b&0x80 != 0 {
+			return u<<6 | uint64(b&0x3F), nil
+		}
+
+		u = u<<7 | uint64(b)
+	}
+}
+
+func (d *decoder) read7BitGroups(count int) (uint64, error) {
+
+	raw, err := d.take(count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var u uint64
+	for _, b := range raw {
+		u = u<<7 | uint64(b&0x7F)
+	}
+
+	return u, nil
+}
+
+// binary7Bit reads a length prefixed, 7 bit encoded block of binary data.
+func (d *decoder) binary7Bit() ([]byte, error) {
+
+	length, err := d.vint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// every 7 bytes take 8, so there must be at least as many bytes left
+	if length > uint64(len(d.data)-d.pos) {
+		return nil, ErrorUnexpectedEnd
+	}
+
+	out := make([]byte, 0, length)
+	for uint64(len(out))+7 <= length {
+
+		chunk, err := d.read7BitGroups(8)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for shift := 48; shift >= 0; shift -= 8 {
+			out = append(out, byte(chunk>>uint(shift)))
+		}
+	}
+
+	if remaining := int(length) - len(out); remaining > 0 {
+
+		chunk, err := d.read7BitGroups(remaining)
+
+		if err != nil {
+			return nil, err
+		}
+
+		last, err := d.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		chunk = chunk<<uint(remaining) | uint64(last&(1<<uint(remaining)-1))
+		for shift := 8 * (remaining - 1); shift >= 0; shift -= 8 {
+			out = append(out, byte(chunk>>uint(shift)))
+		}
+	}
+
+	return out, nil
+}
+
+// bigIntFromBytes converts big-endian two's complement bytes into a big.Int.
+func bigIntFromBytes(raw []byte) *big.Int {
+
+	i := new(big.Int).SetBytes(raw)
+	if len(raw) > 0 && raw[0]&0x80 != 0 {
+		i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(raw)*8)))
+	}
+
+	return i
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,16 @@
+// A codec for handling Smile (binary JSON) encoding and decoding.
+//
+// Smile is the binary JSON format used by Jackson based services.  The codec
+// writes the standard ":)\n" header without shared name or value references
+// (so the output can be read by any Smile decoder), and reads documents with
+// or without shared references.
+//
+// Marshalling follows the same rules as encoding/json (including `json`
+// struct tags and json.Marshaler) except that []byte values are written as
+// Smile binary rather than base64 strings.
+//
+// When unmarshalling into an interface{}, objects become map[string]interface{},
+// arrays become []interface{}, integers become int64, floating point numbers
+// become float64 and binary becomes []byte.  Unmarshalling into any other type
+// uses encoding/json's rules.
+package smile
@@ -0,0 +1,385 @@
+package smile
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	numberType        = reflect.TypeOf(json.Number(""))
+)
+
+// encoder writes Smile data.
+type encoder struct {
+	buffer bytes.Buffer
+}
+
+// marshal encodes the object as a complete Smile document.
+func marshal(object interface{}) ([]byte, error) {
+
+	e := new(encoder)
+	e.buffer.Write(header)
+	e.buffer.WriteByte(0x00)
+
+	if err := e.encode(reflect.ValueOf(object)); err != nil {
+		return nil, err
+	}
+
+	return e.buffer.Bytes(), nil
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+
+	if !v.IsValid() {
+		e.buffer.WriteByte(tokenNull)
+		return nil
+	}
+
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Type().Implements(jsonMarshalerType) {
+		return e.encodeJSONMarshaler(v.Interface().(json.Marshaler))
+	}
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.writeString(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buffer.WriteByte(tokenNull)
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && v.Type().Implements(jsonMarshalerType) {
+			return e.encodeJSONMarshaler(v.Interface().(json.Marshaler))
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buffer.WriteByte(tokenTrue)
+		} else {
+			e.buffer.WriteByte(tokenFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return fmt.Errorf("codecs: smile: %d is too big to encode", v.Uint())
+		}
+		e.writeInt(int64(v.Uint()))
+	case reflect.Float32:
+		e.writeFloat32(float32(v.Float()))
+	case reflect.Float64:
+		e.writeFloat64(v.Float())
+	case reflect.String:
+		if v.Type() == numberType {
+			return e.encode(reflect.ValueOf(numberValue(json.Number(v.String()))))
+		}
+		e.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buffer.WriteByte(tokenNull)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.writeBinary(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return fmt.Errorf("codecs: smile: Cannot encode %s", v.Type())
+	}
+
+	return nil
+}
+
+// encodeJSONMarshaler encodes the JSON produced by the json.Marshaler.
+func (e *encoder) encodeJSONMarshaler(m json.Marshaler) error {
+
+	data, err := m.MarshalJSON()
+
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return e.encode(reflect.ValueOf(value))
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+
+	e.buffer.WriteByte(tokenStartArray)
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	e.buffer.WriteByte(tokenEndArray)
+
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+
+	if v.IsNil() {
+		e.buffer.WriteByte(tokenNull)
+		return nil
+	}
+
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("codecs: smile: Map keys must be strings, not %s", v.Type().Key())
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	e.buffer.WriteByte(tokenStartObject)
+	for _, key := range keys {
+		e.writeKey(key.String())
+		if err := e.encode(v.MapIndex(key)); err != nil {
+			return err
+		}
+	}
+	e.buffer.WriteByte(tokenEndObject)
+
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+
+	e.buffer.WriteByte(tokenStartObject)
+	for i := 0; i < v.NumField(); i++ {
+
+		field := v.Type().Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag := field.Tag.Get("json"); len(tag) > 0 {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if len(parts[0]) > 0 {
+				name = parts[0]
+			}
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+
+		value := v.Field(i)
+		if omitEmpty && isEmptyValue(value) {
+			continue
+		}
+
+		e.writeKey(name)
+		if err := e.encode(value); err != nil {
+			return err
+		}
+	}
+	e.buffer.WriteByte(tokenEndObject)
+
+	return nil
+}
+
+func (e *encoder) writeInt(n int64) {
+
+	switch {
+	case n >= -16 && n <= 15:
+		e.buffer.WriteByte(tokenSmallInt + byte(zigzag(n)))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		e.buffer.WriteByte(tokenInt32)
+		e.writeVInt(zigzag(n))
+	default:
+		e.buffer.WriteByte(tokenInt64)
+		e.writeVInt(zigzag(n))
+	}
+
+}
+
+// writeVInt writes the variable length int, where all bytes but the last
+// hold 7 bits and the last (marked by its high bit) holds 6.
+func (e *encoder) writeVInt(u uint64) {
+
+	var groups []byte
+	groups = append(groups, byte(u&0x3F)|0x80)
+	u >>= 6
+	for u > 0 {
+		groups = append(groups, byte(u&0x7F))
+		u >>= 7
+	}
+
+	for i := len(groups) - 1; i >= 0; i-- {
+		e.buffer.WriteByte(groups[i])
+	}
+
+}
+
+func (e *encoder) writeFloat32(f float32) {
+	e.buffer.WriteByte(tokenFloat32)
+	e.write7BitGroups(uint64(math.Float32bits(f)), 5)
+}
+
+func (e *encoder) writeFloat64(f float64) {
+	e.buffer.WriteByte(tokenFloat64)
+	e.write7BitGroups(math.Float64bits(f), 10)
+}
+
+// write7BitGroups writes the bits of u as count big-endian 7 bit groups.
+func (e *encoder) write7BitGroups(u uint64, count int) {
+	for i := count - 1; i >= 0; i-- {
+		e.buffer.WriteByte(byte(u>>(7*uint(i))) & 0x7F)
+	}
+}
+
+func (e *encoder) writeString(s string) {
+
+	length := len(s)
+	if length == 0 {
+		e.buffer.WriteByte(tokenEmptyString)
+		return
+	}
+
+	ascii := isASCII(s)
+	switch {
+	case ascii && length <= 32:
+		e.buffer.WriteByte(tokenTinyASCII + byte(length-1))
+	case ascii && length <= 64:
+		e.buffer.WriteByte(tokenSmallASCII + byte(length-33))
+	case !ascii && length <= 33:
+		e.buffer.WriteByte(tokenTinyUnicode + byte(length-2))
+	case !ascii && length <= 65:
+		e.buffer.WriteByte(tokenShortUnicode + byte(length-34))
+	default:
+		if ascii {
+			e.buffer.WriteByte(tokenLongASCII)
+		} else {
+			e.buffer.WriteByte(tokenLongUnicode)
+		}
+		e.buffer.WriteString(s)
+		e.buffer.WriteByte(tokenEndString)
+		return
+	}
+
+	e.buffer.WriteString(s)
+}
+
+func (e *encoder) writeKey(key string) {
+
+	length := len(key)
+	ascii := isASCII(key)
+
+	switch {
+	case length == 0:
+		e.buffer.WriteByte(keyEmptyString)
+		return
+	case ascii && length <= 64:
+		e.buffer.WriteByte(keyShortASCII + byte(length-1))
+	case !ascii && length <= 57:
+		e.buffer.WriteByte(keyShortUnicode + byte(length-2))
+	default:
+		e.buffer.WriteByte(keyLongUnicode)
+		e.buffer.WriteString(key)
+		e.buffer.WriteByte(tokenEndString)
+		return
+	}
+
+	e.buffer.WriteString(key)
+}
+
+// writeBinary writes the data using the 7 bit safe binary encoding.
+func (e *encoder) writeBinary(data []byte) {
+
+	e.buffer.WriteByte(tokenBinary7Bit)
+	e.writeVInt(uint64(len(data)))
+
+	i := 0
+	for ; i+7 <= len(data); i += 7 {
+		var chunk uint64
+		for _, b := range data[i : i+7] {
+			chunk = chunk<<8 | uint64(b)
+		}
+		e.write7BitGroups(chunk, 8)
+	}
+
+	// the remaining bytes are written as 7 bit groups, with the final
+	// group right aligned
+	if remaining := len(data) - i; remaining > 0 {
+		var chunk uint64
+		for _, b := range data[i:] {
+			chunk = chunk<<8 | uint64(b)
+		}
+		last := byte(chunk & (1<<uint(remaining) - 1))
+		e.write7BitGroups(chunk>>uint(remaining), remaining)
+		e.buffer.WriteByte(last)
+	}
+
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// numberValue converts a json.Number (from a json.Marshaler) into an int64 or
+// float64.
+func numberValue(n json.Number) interface{} {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i
+	}
+	f, _ := strconv.ParseFloat(string(n), 64)
+	return f
+}
@@ -0,0 +1,70 @@
+package smile
+
+// header is the first three bytes of every Smile document, followed by a
+// version and flags byte.
+var header = []byte{':', ')', '\n'}
+
+// Header flags.
+const (
+	flagSharedNames  byte = 0x01
+	flagSharedValues byte = 0x02
+	flagRawBinary    byte = 0x04
+)
+
+// Value mode tokens.
+const (
+	tokenEmptyString byte = 0x20
+	tokenNull        byte = 0x21
+	tokenFalse       byte = 0x22
+	tokenTrue        byte = 0x23
+	tokenInt32       byte = 0x24
+	tokenInt64       byte = 0x25
+	tokenBigInteger  byte = 0x26
+	tokenFloat32     byte = 0x28
+	tokenFloat64     byte = 0x29
+	tokenBigDecimal  byte = 0x2A
+
+	tokenTinyASCII    byte = 0x40 // 1-32 bytes
+	tokenSmallASCII   byte = 0x60 // 33-64 bytes
+	tokenTinyUnicode  byte = 0x80 // 2-33 bytes
+	tokenShortUnicode byte = 0xA0 // 34-65 bytes
+	tokenSmallInt     byte = 0xC0 // -16 to 15
+
+	tokenLongASCII       byte = 0xE0
+	tokenLongUnicode     byte = 0xE4
+	tokenBinary7Bit      byte = 0xE8
+	tokenLongSharedValue byte = 0xEC
+
+	tokenStartArray  byte = 0xF8
+	tokenEndArray    byte = 0xF9
+	tokenStartObject byte = 0xFA
+	tokenEndObject   byte = 0xFB
+	tokenEndString   byte = 0xFC
+	tokenRawBinary   byte = 0xFD
+	tokenEndContent  byte = 0xFF
+)
+
+// Key mode tokens.
+const (
+	keyEmptyString  byte = 0x20
+	keyLongShared   byte = 0x30 // 0x30-0x33
+	keyLongUnicode  byte = 0x34
+	keyShortShared  byte = 0x40 // 0x40-0x7F
+	keyShortASCII   byte = 0x80 // 1-64 bytes
+	keyShortUnicode byte = 0xC0 // 2-57 bytes
+)
+
+// maxSharedLength is the longest (in bytes) string that is kept for shared
+// references, and maxShared is how many are kept before starting again.
+const (
+	maxSharedLength int = 64
+	maxShared       int = 1024
+)
+
+func zigzag(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
+
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
@@ -0,0 +1,70 @@
+package smile
+
+import (
+	"encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// SmileCodec converts objects to and from Smile.
+type SmileCodec struct{}
+
+func init() {
+	codecs.Register(new(SmileCodec))
+}
+
+// Marshal converts an object to Smile.
+func (c *SmileCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return marshal(object)
+}
+
+// Unmarshal converts Smile into an object.
+func (c *SmileCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	value, err := unmarshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	// generic values can be set directly
+	if target, ok := obj.(*interface{}); ok {
+		*target = value
+		return nil
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *SmileCodec) ContentType() string {
+	return constants.ContentTypeSmile
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *SmileCodec) FileExtension() string {
+	return constants.FileExtensionSmile
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *SmileCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// Binary returns whether this codec's representation is binary.
+func (c *SmileCodec) Binary() bool {
+	return true
+}
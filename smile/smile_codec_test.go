@@ -0,0 +1,153 @@
+package smile
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+var smileCodec SmileCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(SmileCodec), "SmileCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeSmile, smileCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionSmile, smileCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, smileCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	bytes, err := smileCodec.Marshal(map[string]interface{}{"name": "Mat", "age": 1}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x3A, 0x29, 0x0A, 0x00, 0xFA, 0x82, 'a', 'g', 'e', 0xC2, 0x83, 'n', 'a', 'm', 'e', 0x42, 'M', 'a', 't', 0xFB}, bytes)
+	}
+
+}
+
+func TestUnmarshal_SharedNames(t *testing.T) {
+
+	// [{"a":1},{"a":-2}] as written by Jackson, with the second "a" as a
+	// shared name reference
+	data := []byte{0x3A, 0x29, 0x0A, 0x01, 0xF8, 0xFA, 0x80, 'a', 0xC2, 0xFB, 0xFA, 0x40, 0xC3, 0xFB, 0xF9}
+
+	var obj interface{}
+	if assert.NoError(t, smileCodec.Unmarshal(data, &obj)) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"a": int64(1)}, map[string]interface{}{"a": int64(-2)}}, obj)
+	}
+
+}
+
+func TestUnmarshal_SharedValues(t *testing.T) {
+
+	// ["abc","abc"] with the second value as a shared value reference
+	data := []byte{0x3A, 0x29, 0x0A, 0x03, 0xF8, 0x42, 'a', 'b', 'c', 0x01, 0xF9}
+
+	var obj interface{}
+	if assert.NoError(t, smileCodec.Unmarshal(data, &obj)) {
+		assert.Equal(t, []interface{}{"abc", "abc"}, obj)
+	}
+
+}
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+
+	binary := make([]byte, 20)
+	for i := range binary {
+		binary[i] = byte(i * 13)
+	}
+
+	values := []interface{}{
+		nil, true, false, "", "short", strings.Repeat("x", 40), strings.Repeat("y", 100),
+		"héllo", strings.Repeat("é", 20), strings.Repeat("é", 100),
+		int64(0), int64(15), int64(-16), int64(1000), int64(-70000), int64(math.MaxInt64), int64(math.MinInt64),
+		1.5, -0.001, math.MaxFloat64,
+		[]interface{}{int64(1), "two", []interface{}{}},
+		map[string]interface{}{"": "empty key", strings.Repeat("k", 70): "long key", "ünicode": int64(3)},
+	}
+
+	for _, value := range values {
+
+		bytes, err := smileCodec.Marshal(value, nil)
+
+		if assert.NoError(t, err) {
+			var obj interface{}
+			if assert.NoError(t, smileCodec.Unmarshal(bytes, &obj)) {
+				assert.Equal(t, value, obj)
+			}
+		}
+
+	}
+
+	for length := 0; length <= len(binary); length++ {
+
+		bytes, _ := smileCodec.Marshal(binary[:length], nil)
+
+		var obj interface{}
+		if assert.NoError(t, smileCodec.Unmarshal(bytes, &obj)) {
+			assert.Equal(t, binary[:length], obj, "binary of length %d", length)
+		}
+
+	}
+
+}
+
+func TestMarshalAndUnmarshal_Struct(t *testing.T) {
+
+	type person struct {
+		Name    string  `json:"name"`
+		Age     int     `json:"age"`
+		Email   string  `json:"email,omitempty"`
+		Secret  string  `json:"-"`
+		Ratio   float32 `json:"ratio"`
+		Picture []byte  `json:"picture"`
+	}
+
+	in := person{Name: "Tyler", Age: 28, Secret: "shh", Ratio: 0.5, Picture: []byte{1, 2, 3}}
+	bytes, err := smileCodec.Marshal(in, nil)
+
+	if assert.NoError(t, err) {
+
+		var generic interface{}
+		smileCodec.Unmarshal(bytes, &generic)
+		_, hasEmail := generic.(map[string]interface{})["email"]
+		assert.False(t, hasEmail, "omitempty should be respected")
+
+		var out person
+		if assert.NoError(t, smileCodec.Unmarshal(bytes, &out)) {
+			in.Secret = ""
+			assert.Equal(t, in, out)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorMissingHeader, smileCodec.Unmarshal([]byte(`{"a":1}`), &obj))
+	assert.Equal(t, ErrorUnexpectedEnd, smileCodec.Unmarshal([]byte{0x3A, 0x29, 0x0A, 0x00, 0xFA, 0x80}, &obj))
+
+	_, isInvalid := smileCodec.Unmarshal([]byte{0x3A, 0x29, 0x0A, 0x00, 0x21}, obj).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+	// a huge scale would take minutes to raise 10 to
+	assert.Equal(t, ErrorScaleOutOfRange, smileCodec.Unmarshal([]byte(":)\n\x00\x2a\x01\x7f\x7f\x7f\xbf\x81\x01\x01"), &obj))
+
+	// a binary length longer than the data is refused before allocating
+	assert.Equal(t, ErrorUnexpectedEnd, smileCodec.Unmarshal([]byte{0x3A, 0x29, 0x0A, 0x00, 0xE8, 0x3F, 0x7F, 0x7F, 0x7F, 0xBF}, &obj))
+
+}
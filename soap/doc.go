@@ -0,0 +1,11 @@
+// A codec for wrapping and unwrapping SOAP 1.1 and 1.2 envelopes.
+//
+// The body of the envelope is handled by the simple XML codec (see the
+// codecs/xml package), so the SOAP codec sees the same data as every other
+// codec.  Marshalling an error (or a *Fault) generates a SOAP Fault.
+//
+// SOAP 1.2 (application/soap+xml) is used by default; to speak SOAP 1.1
+// (text/xml) install the codec with the version set:
+//
+//	codecService.AddCodec(&soap.SoapCodec{Version: soap.Version11})
+package soap
@@ -0,0 +1,62 @@
+package soap
+
+import (
+	"fmt"
+)
+
+// Fault codes.  The SOAP 1.1 names are used; they are translated to their
+// SOAP 1.2 equivalents (Sender and Receiver) when necessary.
+const (
+	FaultCodeClient string = "Client"
+	FaultCodeServer string = "Server"
+)
+
+// Fault is a SOAP Fault.  Faults are errors so they can be returned from
+// Unmarshal, and marshalled like any other error.
+type Fault struct {
+
+	// Code is the fault code, e.g. FaultCodeServer.
+	Code string
+
+	// String is the human readable explanation of the fault.
+	String string
+
+	// Detail is optional application specific data, marshalled with the
+	// simple XML codec.  When unmarshalling it holds the raw XML.
+	Detail interface{}
+}
+
+// Error makes Fault an error.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("codecs: soap: %s fault: %s", f.Code, f.String)
+}
+
+// faultFrom gets a Fault for the error.
+func faultFrom(err error) *Fault {
+	if fault, ok := err.(*Fault); ok {
+		return fault
+	}
+	return &Fault{Code: FaultCodeServer, String: err.Error()}
+}
+
+// code12 translates a SOAP 1.1 fault code into SOAP 1.2.
+func code12(code string) string {
+	switch code {
+	case FaultCodeClient:
+		return "Sender"
+	case FaultCodeServer, "":
+		return "Receiver"
+	}
+	return code
+}
+
+// code11 translates a SOAP 1.2 fault code into SOAP 1.1.
+func code11(code string) string {
+	switch code {
+	case "Sender":
+		return FaultCodeClient
+	case "Receiver":
+		return FaultCodeServer
+	}
+	return code
+}
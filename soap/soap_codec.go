@@ -0,0 +1,227 @@
+package soap
+
+import (
+	"bytes"
+	xmlEncoding "encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/xml"
+	"strings"
+)
+
+// Version is a version of SOAP.
+type Version int
+
+const (
+	// Version12 is SOAP 1.2, and is the default.
+	Version12 Version = iota
+
+	// Version11 is SOAP 1.1.
+	Version11
+)
+
+const (
+	// Namespace11 is the envelope namespace for SOAP 1.1.
+	Namespace11 string = "http://schemas.xmlsoap.org/soap/envelope/"
+
+	// Namespace12 is the envelope namespace for SOAP 1.2.
+	Namespace12 string = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// The formats of the envelope and of the faults and their details in each
+// version, which are given escaped text and marshalled XML.
+const (
+	envelopeFormat string = "<soap:Envelope xmlns:soap=\"%s\"><soap:Body>%s</soap:Body></soap:Envelope>"
+	fault11Format  string = "<soap:Fault><faultcode>soap:%s</faultcode><faultstring>%s</faultstring>%s</soap:Fault>"
+	detail11Format string = "<detail>%s</detail>"
+	fault12Format  string = "<soap:Fault><soap:Code><soap:Value>soap:%s</soap:Value></soap:Code><soap:Reason><soap:Text xml:lang=\"en\">%s</soap:Text></soap:Reason>%s</soap:Fault>"
+	detail12Format string = "<soap:Detail>%s</soap:Detail>"
+)
+
+// ErrorMissingBody is returned by Unmarshal when the data is not a SOAP envelope
+// with a Body.
+var ErrorMissingBody = errors.New("codecs: soap: Data is not a SOAP envelope containing a Body")
+
+// SoapCodec converts objects to and from SOAP envelopes.
+type SoapCodec struct {
+
+	// Version is the version of SOAP to speak.
+	Version Version
+
+	// bodyCodec handles the contents of the body.
+	bodyCodec xml.SimpleXmlCodec
+}
+
+func init() {
+	codecs.Register(new(SoapCodec))
+}
+
+type envelope struct {
+	XMLName xmlEncoding.Name
+	Body    *struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+type faultBody struct {
+	Fault *struct {
+		// SOAP 1.1
+		FaultCode   string  `xml:"faultcode"`
+		FaultString string  `xml:"faultstring"`
+		Detail11    *rawXML `xml:"detail"`
+
+		// SOAP 1.2
+		Code   string  `xml:"Code>Value"`
+		Reason string  `xml:"Reason>Text"`
+		Detail *rawXML `xml:"Detail"`
+	} `xml:"Fault"`
+}
+
+type rawXML struct {
+	Inner string `xml:",innerxml"`
+}
+
+// Marshal wraps the XML representation of the object in a SOAP envelope.  If the
+// object is an error, a SOAP Fault is generated instead.
+func (c *SoapCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var body string
+	var err error
+
+	if objectErr, ok := object.(error); ok {
+		body, err = c.fault(faultFrom(objectErr), options)
+	} else {
+		body, err = c.marshalBody(object, options)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(xml.XMLDeclaration + fmt.Sprintf(envelopeFormat, c.namespace(), body)), nil
+}
+
+// Unmarshal unwraps the SOAP envelope and unmarshals the contents of the body
+// into obj.  If the body contains a Fault, it is returned as a *Fault error.
+func (c *SoapCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	var env envelope
+	if err := xmlEncoding.Unmarshal(data, &env); err != nil {
+		return err
+	}
+
+	if env.Body == nil {
+		return ErrorMissingBody
+	}
+
+	// is it a fault?
+	var faultXML faultBody
+	if err := xmlEncoding.Unmarshal(append(append([]byte("<Body>"), env.Body.Inner...), []byte("</Body>")...), &faultXML); err == nil && faultXML.Fault != nil {
+
+		f := faultXML.Fault
+		fault := &Fault{Code: trimPrefix(f.FaultCode), String: f.FaultString}
+		if f.Detail11 != nil {
+			fault.Detail = f.Detail11.Inner
+		}
+		if len(f.Code) > 0 {
+			fault.Code = code11(trimPrefix(f.Code))
+			fault.String = f.Reason
+			if f.Detail != nil {
+				fault.Detail = f.Detail.Inner
+			}
+		}
+
+		return fault
+	}
+
+	return c.bodyCodec.Unmarshal(bytes.TrimSpace(env.Body.Inner), obj)
+}
+
+// ContentType returns the content type for this codec, which depends on the
+// version of SOAP.
+func (c *SoapCodec) ContentType() string {
+	if c.Version == Version11 {
+		return constants.ContentTypeXML
+	}
+	return constants.ContentTypeSOAP
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *SoapCodec) FileExtension() string {
+	return constants.FileExtensionSOAP
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *SoapCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func (c *SoapCodec) namespace() string {
+	if c.Version == Version11 {
+		return Namespace11
+	}
+	return Namespace12
+}
+
+// marshalBody gets the simple XML for the object, without the XML declaration.
+func (c *SoapCodec) marshalBody(object interface{}, options map[string]interface{}) (string, error) {
+
+	if object == nil {
+		return "", nil
+	}
+
+	bytes, err := c.bodyCodec.Marshal(object, options)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(string(bytes), xml.XMLDeclaration), nil
+}
+
+// fault generates the Fault element for the current version.
+func (c *SoapCodec) fault(fault *Fault, options map[string]interface{}) (string, error) {
+
+	var detail string
+	if fault.Detail != nil {
+
+		detailXML, err := c.marshalBody(fault.Detail, options)
+
+		if err != nil {
+			return "", err
+		}
+
+		if c.Version == Version11 {
+			detail = fmt.Sprintf(detail11Format, detailXML)
+		} else {
+			detail = fmt.Sprintf(detail12Format, detailXML)
+		}
+	}
+
+	if c.Version == Version11 {
+		code := fault.Code
+		if len(code) == 0 {
+			code = FaultCodeServer
+		}
+		return fmt.Sprintf(fault11Format, escape(code), escape(fault.String), detail), nil
+	}
+
+	return fmt.Sprintf(fault12Format, escape(code12(fault.Code)), escape(fault.String), detail), nil
+}
+
+// escape gets the text escaped for use in XML.
+func escape(text string) string {
+	var escaped bytes.Buffer
+	xmlEncoding.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// trimPrefix removes the namespace prefix from a qualified name.
+func trimPrefix(name string) string {
+	if i := strings.Index(name, ":"); i > -1 {
+		return name[i+1:]
+	}
+	return name
+}
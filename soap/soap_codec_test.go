@@ -0,0 +1,109 @@
+package soap
+
+import (
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(SoapCodec), "SoapCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeSOAP, new(SoapCodec).ContentType())
+	assert.Equal(t, constants.ContentTypeXML, (&SoapCodec{Version: Version11}).ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionSOAP, new(SoapCodec).FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, new(SoapCodec).CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	codec := new(SoapCodec)
+	bytes, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.NoError(t, err) {
+		out := string(bytes)
+		assert.Contains(t, out, `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>`)
+		assert.Contains(t, out, `<name>`)
+		assert.Contains(t, out, `</soap:Body></soap:Envelope>`)
+	}
+
+}
+
+func TestMarshal_Fault11(t *testing.T) {
+
+	codec := &SoapCodec{Version: Version11}
+	bytes, err := codec.Marshal(&Fault{Code: FaultCodeClient, String: "Bad <input>"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>Bad &lt;input&gt;</faultstring></soap:Fault></soap:Body></soap:Envelope>`)
+	}
+
+	// the code is escaped too
+	bytes, err = codec.Marshal(&Fault{Code: "Client</faultcode><injected/><faultcode>", String: "Bad"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<faultcode>soap:Client&lt;/faultcode&gt;&lt;injected/&gt;&lt;faultcode&gt;</faultcode>`)
+	}
+
+}
+
+func TestMarshal_Fault12(t *testing.T) {
+
+	codec := new(SoapCodec)
+	bytes, err := codec.Marshal(errors.New("Something broke"), nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<soap:Fault><soap:Code><soap:Value>soap:Receiver</soap:Value></soap:Code><soap:Reason><soap:Text xml:lang="en">Something broke</soap:Text></soap:Reason></soap:Fault>`)
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	codec := new(SoapCodec)
+	data := `<?xml version="1.0"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Header/><soap:Body><object><name>Mat</name><age type="int">30</age></object></soap:Body></soap:Envelope>`
+
+	var obj interface{}
+	if assert.NoError(t, codec.Unmarshal([]byte(data), &obj)) {
+		m := obj.(map[string]interface{})
+		assert.Equal(t, "Mat", m["name"])
+		assert.Equal(t, 30, m["age"])
+	}
+
+}
+
+func TestUnmarshal_Fault(t *testing.T) {
+
+	for _, version := range []Version{Version11, Version12} {
+
+		codec := &SoapCodec{Version: version}
+		bytes, _ := codec.Marshal(&Fault{Code: FaultCodeClient, String: "Nope"}, nil)
+
+		var obj interface{}
+		err := codec.Unmarshal(bytes, &obj)
+
+		if fault, ok := err.(*Fault); assert.True(t, ok, "Should be a fault") {
+			assert.Equal(t, FaultCodeClient, fault.Code)
+			assert.Equal(t, "Nope", fault.String)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_MissingBody(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorMissingBody, new(SoapCodec).Unmarshal([]byte(`<Envelope></Envelope>`), &obj))
+
+}
@@ -0,0 +1,28 @@
+// A codec for Server-Sent Events (text/event-stream).
+//
+// Marshal writes an event for each item of a slice, array or channel (or for
+// the object itself if it is none of these), with the item encoded by the
+// codec's inner Codec (JSON unless set otherwise):
+//
+//	data: {"name":"Mat"}
+//
+//	data: {"name":"Tyler"}
+//
+// Items can be Event values to set the event type, id and retry time, and the
+// OptionKeyEvent option sets the event type of every other item.  Items are
+// passed through codecs.PublicData first, so channels of Facade objects are
+// written as their public data.
+//
+// Event streams are open ended, so Encode writes to an io.Writer as items are
+// received from a channel, flushing after each event if the writer has a
+// Flush method (as http.ResponseWriters do):
+//
+//	events := make(chan interface{})
+//	go watch(events)
+//	return codec.Encode(response, events, nil)
+//
+// Unmarshal reads the events' data using the inner Codec.  Unmarshalling into
+// a *[]Event keeps the type, id and retry time of each event, and into an
+// interface{} gives a []interface{} of the data; any other target uses
+// encoding/json's rules on the data.
+package sse
@@ -0,0 +1,27 @@
+package sse
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorInvalidField is returned when marshalling an Event whose type or id
+// contains a line break.
+var ErrorInvalidField = errors.New("codecs: sse: Event types and ids cannot contain line breaks")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: sse: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: sse: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: sse: Unmarshal(nil " + e.Type.String() + ")"
+}
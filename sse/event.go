@@ -0,0 +1,154 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/codecs"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a server-sent event.  When marshalling, Data is encoded by the
+// inner codec; when unmarshalling into a []Event it holds the decoded data.
+type Event struct {
+
+	// Event is the event type, which is "message" when empty.
+	Event string
+
+	// ID is the event id, which clients send back in the Last-Event-ID header
+	// when reconnecting.
+	ID string
+
+	// Retry is the reconnection time to ask clients to use, if it is not zero.
+	Retry time.Duration
+
+	// Data is the event's payload.
+	Data interface{}
+}
+
+// EventWriter writes events to an event stream, one per call to Write.
+type EventWriter struct {
+	w       io.Writer
+	codec   codecs.Codec
+	options map[string]interface{}
+}
+
+// NewEventWriter makes a new EventWriter that writes to w, encoding data with
+// the codec and passing it the options.
+func NewEventWriter(w io.Writer, codec codecs.Codec, options map[string]interface{}) *EventWriter {
+	return &EventWriter{w: w, codec: codec, options: options}
+}
+
+// Write writes the item as an event, flushing the underlying writer if it has
+// a Flush method.
+func (e *EventWriter) Write(item interface{}) error {
+
+	event, ok := item.(Event)
+	if !ok {
+		if p, isPointer := item.(*Event); isPointer && p != nil {
+			event = *p
+		} else {
+			event = Event{Data: item}
+			event.Event, _ = e.options[OptionKeyEvent].(string)
+		}
+	}
+
+	if strings.ContainsAny(event.Event+event.ID, "\r\n") {
+		return ErrorInvalidField
+	}
+
+	public, err := codecs.PublicData(event.Data, e.options)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := e.codec.Marshal(public, e.options)
+
+	if err != nil {
+		return err
+	}
+
+	var frame bytes.Buffer
+	if len(event.Event) > 0 {
+		frame.WriteString("event: " + event.Event + "\n")
+	}
+	if len(event.ID) > 0 {
+		frame.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Retry > 0 {
+		frame.WriteString("retry: " + strconv.FormatInt(int64(event.Retry/time.Millisecond), 10) + "\n")
+	}
+
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	for _, line := range lines {
+		frame.WriteString("data: " + line + "\n")
+	}
+	frame.WriteString("\n")
+
+	if _, err := e.w.Write(frame.Bytes()); err != nil {
+		return err
+	}
+
+	if flusher, ok := e.w.(interface {
+		Flush()
+	}); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// readEvents reads the events in the stream, with the data of each left as
+// raw bytes.  Events without data are dispatched by browsers, so they are
+// skipped too.
+func readEvents(data []byte) ([]Event, error) {
+
+	events := []Event{}
+	var event Event
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	for scanner.Scan() {
+
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if len(line) == 0 {
+			if lines != nil {
+				event.Data = []byte(strings.Join(lines, "\n"))
+				events = append(events, event)
+			}
+			event, lines = Event{}, nil
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			// comment
+			continue
+		}
+
+		field, value := line, ""
+		if colon := strings.Index(line, ":"); colon >= 0 {
+			field, value = line[:colon], strings.TrimPrefix(line[colon+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "retry":
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		case "data":
+			lines = append(lines, value)
+		}
+	}
+
+	return events, scanner.Err()
+}
@@ -0,0 +1,146 @@
+package sse
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"io"
+	"reflect"
+)
+
+// OptionKeyEvent is the option key for the event type of items that aren't
+// Event values.
+const OptionKeyEvent = "sse.event"
+
+// SseCodec converts sequences of objects to and from event streams.
+type SseCodec struct {
+
+	// Codec encodes the data of each event, and is JSON when nil.
+	Codec codecs.Codec
+}
+
+func init() {
+	codecs.Register(new(SseCodec))
+}
+
+// inner gets the codec for the data of each event.
+func (c *SseCodec) inner() codecs.Codec {
+	if c.Codec == nil {
+		return new(json.JsonCodec)
+	}
+	return c.Codec
+}
+
+// Marshal converts the items of a slice, array or channel (or a single
+// object) to an event stream.
+func (c *SseCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	var buffer bytes.Buffer
+	if err := c.Encode(&buffer, object, options); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Encode writes an event to w for each item of the object, which can be a
+// slice, array or channel (in which case events are written as items are
+// received until the channel is closed).  Anything else is written as a
+// single event.
+func (c *SseCodec) Encode(w io.Writer, object interface{}, options map[string]interface{}) error {
+
+	writer := NewEventWriter(w, c.inner(), options)
+	v := reflect.ValueOf(object)
+
+	if !v.IsValid() {
+		return writer.Write(object)
+	}
+
+	switch v.Kind() {
+	case reflect.Chan:
+		for {
+			item, ok := v.Recv()
+			if !ok {
+				return nil
+			}
+			if err := writer.Write(item.Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte is a single item
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := writer.Write(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return writer.Write(object)
+}
+
+// Unmarshal converts an event stream into an object, decoding the data of each
+// event with the inner codec.
+func (c *SseCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	events, err := readEvents(data)
+
+	if err != nil {
+		return err
+	}
+
+	items := make([]interface{}, len(events))
+	for i := range events {
+		var item interface{}
+		if err := c.inner().Unmarshal(events[i].Data.([]byte), &item); err != nil {
+			return err
+		}
+		events[i].Data = item
+		items[i] = item
+	}
+
+	// generic values can be set directly
+	switch target := obj.(type) {
+	case *[]Event:
+		*target = events
+		return nil
+	case *interface{}:
+		*target = items
+		return nil
+	}
+
+	// anything else gets json's treatment
+	jsonData, err := jsonEncoding.Marshal(items)
+
+	if err != nil {
+		return err
+	}
+
+	return jsonEncoding.Unmarshal(jsonData, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *SseCodec) ContentType() string {
+	return constants.ContentTypeSSE
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *SseCodec) FileExtension() string {
+	return constants.FileExtensionSSE
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *SseCodec) CanMarshalWithCallback() bool {
+	return false
+}
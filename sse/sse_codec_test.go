@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var sseCodec SseCodec
+
+type person struct {
+	Name string `json:"name"`
+}
+
+// publicPerson implements codecs.Facade.
+type publicPerson struct {
+	name string
+}
+
+func (p publicPerson) PublicData(options map[string]interface{}) (interface{}, error) {
+	return map[string]interface{}{"name": p.name}, nil
+}
+
+// flushRecorder records writes and flushes.
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes++
+}
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(SseCodec), "SseCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeSSE, sseCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionSSE, sseCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, sseCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	items := []interface{}{
+		person{"Mat"},
+		Event{Event: "update", ID: "2", Retry: 3 * time.Second, Data: person{"Tyler"}},
+		"multi\nline",
+	}
+
+	bytes, err := sseCodec.Marshal(items, map[string]interface{}{OptionKeyEvent: "person"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, ""+
+			"event: person\n"+
+			"data: {\"name\":\"Mat\"}\n"+
+			"\n"+
+			"event: update\n"+
+			"id: 2\n"+
+			"retry: 3000\n"+
+			"data: {\"name\":\"Tyler\"}\n"+
+			"\n"+
+			"event: person\n"+
+			"data: \"multi\\nline\"\n"+
+			"\n", string(bytes))
+	}
+
+	_, err = sseCodec.Marshal(Event{ID: "1\n2"}, nil)
+	assert.Equal(t, ErrorInvalidField, err)
+
+}
+
+func TestEncode_Channel(t *testing.T) {
+
+	items := make(chan publicPerson)
+	go func() {
+		items <- publicPerson{"Mat"}
+		items <- publicPerson{"Tyler"}
+		close(items)
+	}()
+
+	recorder := new(flushRecorder)
+
+	if assert.NoError(t, sseCodec.Encode(recorder, items, nil)) {
+		assert.Equal(t, "data: {\"name\":\"Mat\"}\n\ndata: {\"name\":\"Tyler\"}\n\n", recorder.String())
+		assert.Equal(t, 2, recorder.flushes)
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	data := []byte(": keep alive\n" +
+		"event: update\r\n" +
+		"id: 7\n" +
+		"retry: 1500\n" +
+		"data: {\"name\":\n" +
+		"data: \"Mat\"}\n" +
+		"\n" +
+		"data:{\"name\":\"Tyler\"}\n" +
+		"\n" +
+		"event: empty\n" +
+		"\n" +
+		"data: {\"name\":\"incomplete\"}\n")
+
+	var events []Event
+	if assert.NoError(t, sseCodec.Unmarshal(data, &events)) {
+		assert.Equal(t, []Event{
+			{Event: "update", ID: "7", Retry: 1500 * time.Millisecond, Data: map[string]interface{}{"name": "Mat"}},
+			{Data: map[string]interface{}{"name": "Tyler"}},
+		}, events)
+	}
+
+	var people []person
+	if assert.NoError(t, sseCodec.Unmarshal(data, &people)) {
+		assert.Equal(t, []person{{"Mat"}, {"Tyler"}}, people)
+	}
+
+	var generic interface{}
+	if assert.NoError(t, sseCodec.Unmarshal(data, &generic)) {
+		assert.Equal(t, 2, len(generic.([]interface{})))
+	}
+
+	_, isInvalid := sseCodec.Unmarshal(data, people).(*InvalidUnmarshalError)
+	assert.True(t, isInvalid)
+
+}
@@ -0,0 +1,42 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"strings"
+)
+
+// structTags gets whether the constants.OptionKeyStructTags option asks for
+// public data to be built from codec struct tags.
+func structTags(options map[string]interface{}) bool {
+	enabled, _ := options[constants.OptionKeyStructTags].(bool)
+	return enabled
+}
+
+// codecFieldName gets the name of the struct field in public data built from
+// codec tags, such as `codec:"name,omitempty"`, and whether it is left out
+// when it is empty.  Fields without a codec tag are named as publicFieldName
+// names them, and the name is empty for fields tagged "-".
+func codecFieldName(field reflect.StructField) (string, bool) {
+
+	tag, tagged := field.Tag.Lookup("codec")
+	if !tagged {
+		return publicFieldName(field), false
+	}
+	if tag == "-" {
+		return "", false
+	}
+
+	name, omitEmpty := tag, false
+	if comma := strings.Index(tag, ","); comma >= 0 {
+		name = tag[:comma]
+		for _, option := range strings.Split(tag[comma+1:], ",") {
+			omitEmpty = omitEmpty || option == "omitempty"
+		}
+	}
+	if len(name) == 0 {
+		name = field.Name
+	}
+
+	return name, omitEmpty
+}
@@ -0,0 +1,48 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// tagged names its fields with codec tags.
+type tagged struct {
+	ID       int       `codec:"id"`
+	Name     string    `codec:"name,omitempty" json:"fullName"`
+	Nickname string    `json:"nick"`
+	Password string    `codec:"-"`
+	Joined   time.Time `codec:"joined"`
+	Author   *author   `codec:"author,omitempty"`
+}
+
+func TestPublicData_StructTags(t *testing.T) {
+
+	joined := time.Date(2014, 1, 2, 0, 0, 0, 0, time.UTC)
+	object := &tagged{ID: 1, Nickname: "matryer", Password: "x", Joined: joined}
+
+	// struct tags are opt-in
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(object))
+	}
+
+	public, err = PublicData(object, Options{StructTags: true}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{"id": 1, "nick": "matryer", "joined": joined}, public)
+	}
+
+	object.Name, object.Author = "Mat Ryer", &author{Name: "Mat"}
+	public, err = PublicData([]tagged{*object}, map[string]interface{}{constants.OptionKeyStructTags: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"id":     1,
+			"name":   "Mat Ryer",
+			"nick":   "matryer",
+			"joined": joined,
+			"author": map[string]interface{}{"name": "Mat"},
+		}}, public)
+	}
+
+}
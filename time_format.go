@@ -0,0 +1,67 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"time"
+)
+
+// UnixTime is a unit of time since the Unix epoch, which time.Time values in
+// public data can be given as.  Give it with the constants.OptionKeyTimeUnix
+// option.
+type UnixTime int
+
+const (
+	// UnixTimeNone leaves times to be formatted as text.  It is the default.
+	UnixTimeNone UnixTime = iota
+
+	// UnixSeconds gives times as whole seconds since the Unix epoch.
+	UnixSeconds
+
+	// UnixMillis gives times as whole milliseconds since the Unix epoch.
+	UnixMillis
+)
+
+// timeType is the type of time.Time.
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeFormat is how time.Time values in public data are given, as given by
+// the options.
+type timeFormat struct {
+	layout string
+	utc    bool
+	unix   UnixTime
+}
+
+// timeFormatFor gets the time format given by the
+// constants.OptionKeyTimeLayout, constants.OptionKeyTimeUTC and
+// constants.OptionKeyTimeUnix options.
+func timeFormatFor(options map[string]interface{}) timeFormat {
+	layout, _ := options[constants.OptionKeyTimeLayout].(string)
+	utc, _ := options[constants.OptionKeyTimeUTC].(bool)
+	unix, _ := options[constants.OptionKeyTimeUnix].(UnixTime)
+	return timeFormat{layout: layout, utc: utc, unix: unix}
+}
+
+// format gets the time as the format gives it: as a number if it has a Unix
+// unit, as text if it has a layout, or else as a time.Time (in UTC if it says
+// so) for the codec to marshal.
+func (f timeFormat) format(t time.Time) interface{} {
+
+	if f.utc {
+		t = t.UTC()
+	}
+
+	switch f.unix {
+	case UnixSeconds:
+		return t.Unix()
+	case UnixMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+
+	if len(f.layout) > 0 {
+		return t.Format(f.layout)
+	}
+
+	return t
+}
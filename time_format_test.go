@@ -0,0 +1,61 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// event holds times.
+type event struct {
+	Name    string     `json:"name"`
+	Starts  time.Time  `json:"starts"`
+	Ends    *time.Time `json:"ends"`
+	Reminds []time.Time
+}
+
+func TestPublicData_TimeFormat(t *testing.T) {
+
+	zone := time.FixedZone("MST", -7*60*60)
+	starts := time.Date(2014, 1, 2, 3, 4, 5, 6000000, zone)
+	object := &event{Name: "launch", Starts: starts, Reminds: []time.Time{starts}}
+
+	// times are left as they are by default
+	public, err := PublicData(object, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, public == interface{}(object))
+	}
+
+	public, err = PublicData(object, Options{TimeLayout: time.RFC3339}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"name":    "launch",
+			"starts":  "2014-01-02T03:04:05-07:00",
+			"ends":    nil,
+			"Reminds": []interface{}{"2014-01-02T03:04:05-07:00"},
+		}, public)
+	}
+
+	public, err = PublicData(object, Options{TimeLayout: time.RFC3339, TimeUTC: true}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2014-01-02T10:04:05Z", public.(map[string]interface{})["starts"])
+	}
+
+	// times in UTC are left to the codec to format without a layout
+	public, err = PublicData(starts, map[string]interface{}{constants.OptionKeyTimeUTC: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, starts.UTC(), public)
+	}
+
+	public, err = PublicData(&starts, Options{TimeUnix: UnixSeconds}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1388657045), public)
+	}
+
+	public, err = PublicData(starts, Options{TimeUnix: UnixMillis, TimeLayout: time.RFC3339}.Map())
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1388657045006), public)
+	}
+
+}
@@ -0,0 +1,86 @@
+package codecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeInfo is what PublicData needs to know about a type, worked out once per
+// type (see typeInfoFor) so that marshalling many values of the same types
+// doesn't repeat the reflection.
+type typeInfo struct {
+
+	// mayHoldFacade is whether values of the type may be, or hold, Facade
+	// objects (see mayHoldFacade).
+	mayHoldFacade bool
+
+	// fields are the exported fields of a struct type, including those
+	// promoted from embedded structs (see jsonFields), and fieldsMayHoldFacade
+	// whether any of them may be, or hold, Facade objects.
+	fields              []fieldInfo
+	fieldsMayHoldFacade bool
+
+	// publicTags and codecTags are whether any of the fields of a struct type
+	// have public or codec tags.
+	publicTags bool
+	codecTags  bool
+
+	// textMarshaler and binaryMarshaler are whether the type (or a pointer
+	// to it) implements encoding.TextMarshaler and encoding.BinaryMarshaler.
+	textMarshaler   bool
+	binaryMarshaler bool
+}
+
+// fieldInfo describes an exported struct field.
+type fieldInfo struct {
+	reflect.StructField
+
+	// name is the field's name in public data (see publicFieldName), and
+	// codecName and omitEmpty its name and whether it is left out when empty
+	// when built from codec tags (see codecFieldName).  Names are empty for
+	// fields left out.
+	name      string
+	codecName string
+	omitEmpty bool
+
+	// jsonOmitEmpty and jsonString are whether the field's json tag has the
+	// omitempty and string options (see jsonOptions).
+	jsonOmitEmpty bool
+	jsonString    bool
+}
+
+// typeInfos caches the *typeInfo of each reflect.Type.
+var typeInfos sync.Map
+
+// typeInfoFor gets the typeInfo of the type.
+func typeInfoFor(t reflect.Type) *typeInfo {
+
+	if info, ok := typeInfos.Load(t); ok {
+		return info.(*typeInfo)
+	}
+
+	info := &typeInfo{mayHoldFacade: computeMayHoldFacade(t)}
+	info.textMarshaler = t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+	info.binaryMarshaler = t.Implements(binaryMarshalerType) || reflect.PtrTo(t).Implements(binaryMarshalerType)
+
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			_, publicTag := t.Field(i).Tag.Lookup("public")
+			_, codecTag := t.Field(i).Tag.Lookup("codec")
+			info.publicTags = info.publicTags || publicTag
+			info.codecTags = info.codecTags || codecTag
+		}
+
+		info.fields = jsonFields(t)
+		for _, field := range info.fields {
+			_, publicTag := field.Tag.Lookup("public")
+			_, codecTag := field.Tag.Lookup("codec")
+			info.publicTags = info.publicTags || publicTag
+			info.codecTags = info.codecTags || codecTag
+			info.fieldsMayHoldFacade = info.fieldsMayHoldFacade || computeMayHoldFacade(field.Type)
+		}
+	}
+
+	actual, _ := typeInfos.LoadOrStore(t, info)
+	return actual.(*typeInfo)
+}
@@ -0,0 +1,34 @@
+package codecs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func TestTypeInfoFor(t *testing.T) {
+
+	info := typeInfoFor(reflect.TypeOf(post{}))
+	assert.True(t, info == typeInfoFor(reflect.TypeOf(post{})), "the info should be cached")
+	assert.True(t, info.fieldsMayHoldFacade)
+	assert.False(t, info.publicTags)
+
+	var names []string
+	for _, field := range info.fields {
+		names = append(names, field.name)
+	}
+	assert.Equal(t, []string{"title", "author", "tags", ""}, names)
+
+	info = typeInfoFor(reflect.TypeOf(tagged{}))
+	assert.True(t, info.codecTags)
+	assert.Equal(t, "name", info.fields[1].codecName)
+	assert.True(t, info.fields[1].omitEmpty)
+
+	// recursive types are fine
+	assert.True(t, typeInfoFor(reflect.TypeOf(cycle{})).fieldsMayHoldFacade)
+
+	// structs with only plain fields needn't be walked
+	assert.False(t, typeInfoFor(reflect.TypeOf(struct{ Name string }{})).fieldsMayHoldFacade)
+	assert.False(t, mayHoldFacade(reflect.TypeOf([]byte(nil))))
+
+}
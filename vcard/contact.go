@@ -0,0 +1,179 @@
+package vcard
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The keys used when reading contacts from maps.
+const (
+	FieldUID          string = "uid"
+	FieldName         string = "name"
+	FieldFamilyName   string = "family_name"
+	FieldGivenName    string = "given_name"
+	FieldNickname     string = "nickname"
+	FieldEmail        string = "email"
+	FieldPhone        string = "phone"
+	FieldOrganization string = "organization"
+	FieldTitle        string = "title"
+	FieldURL          string = "url"
+	FieldNote         string = "note"
+	FieldBirthday     string = "birthday"
+)
+
+// Contact is a single vCard.
+type Contact struct {
+	UID string
+
+	// FormattedName is the FN property - the name as it should be displayed.
+	// If it's empty when marshalling, it is built from the name parts.
+	FormattedName string
+
+	// The components of the structured N property.
+	FamilyName        string
+	GivenName         string
+	AdditionalNames   string
+	HonorificPrefixes string
+	HonorificSuffixes string
+
+	Nickname     string
+	Emails       []string
+	Phones       []string
+	Addresses    []Address
+	Organization string
+	Title        string
+	URL          string
+	Note         string
+
+	// Birthday is written as a date; the time of day is ignored.
+	Birthday time.Time
+}
+
+// Address is the structured ADR property.
+type Address struct {
+	// Type is the TYPE parameter, e.g. "home" or "work".
+	Type       string
+	Street     string
+	Locality   string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// ContactProvider is the interface objects should implement if they want to
+// be marshalled as vCards.
+type ContactProvider interface {
+	Contact(options map[string]interface{}) (*Contact, error)
+}
+
+// ContactsFrom converts the object (a contact, or an array or slice of them)
+// into contacts.
+func ContactsFrom(object interface{}, options map[string]interface{}) ([]*Contact, error) {
+
+	if object == nil {
+		return nil, nil
+	}
+
+	if contacts, ok := object.([]*Contact); ok {
+		return contacts, nil
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() == reflect.Array || value.Kind() == reflect.Slice {
+
+		contacts := make([]*Contact, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+
+			contact, err := contactFrom(value.Index(i).Interface(), options)
+
+			if err != nil {
+				return nil, err
+			}
+
+			contacts = append(contacts, contact)
+		}
+
+		return contacts, nil
+	}
+
+	contact, err := contactFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Contact{contact}, nil
+}
+
+func contactFrom(object interface{}, options map[string]interface{}) (*Contact, error) {
+
+	switch o := object.(type) {
+	case *Contact:
+		return o, nil
+	case Contact:
+		return &o, nil
+	case ContactProvider:
+		return o.Contact(options)
+	}
+
+	value := reflect.ValueOf(object)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, ErrorNotAContact
+	}
+
+	m := make(map[string]interface{}, value.Len())
+	for _, key := range value.MapKeys() {
+		m[key.String()] = value.MapIndex(key).Interface()
+	}
+
+	contact := &Contact{
+		UID:           stringValue(m[FieldUID]),
+		FormattedName: stringValue(m[FieldName]),
+		FamilyName:    stringValue(m[FieldFamilyName]),
+		GivenName:     stringValue(m[FieldGivenName]),
+		Nickname:      stringValue(m[FieldNickname]),
+		Emails:        stringsValue(m[FieldEmail]),
+		Phones:        stringsValue(m[FieldPhone]),
+		Organization:  stringValue(m[FieldOrganization]),
+		Title:         stringValue(m[FieldTitle]),
+		URL:           stringValue(m[FieldURL]),
+		Note:          stringValue(m[FieldNote]),
+	}
+
+	switch birthday := m[FieldBirthday].(type) {
+	case time.Time:
+		contact.Birthday = birthday
+	case string:
+		contact.Birthday, _ = time.Parse("2006-01-02", birthday)
+	}
+
+	return contact, nil
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// stringsValue gets a []string from a single value or a list of them.
+func stringsValue(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = stringValue(item)
+		}
+		return values
+	}
+	return []string{stringValue(value)}
+}
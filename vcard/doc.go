@@ -0,0 +1,10 @@
+// A codec for handling vCard 4.0 (text/vcard) encoding and decoding.
+//
+// The codec marshals Contact values (or objects implementing the
+// ContactProvider interface, or slices of them) into one VCARD per contact,
+// and parses vCards back into contacts.
+//
+// Maps are also understood, using the Field* keys (e.g. "name", "email")
+// so objects implementing the codecs.Facade interface can still be exported
+// as contacts.
+package vcard
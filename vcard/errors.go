@@ -0,0 +1,26 @@
+package vcard
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorNotAContact is returned when an object cannot be converted into a Contact.
+var ErrorNotAContact = errors.New("codecs: vcard: Object cannot be converted into a contact.  Implement ContactProvider, or use the Contact type.")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil *Contact, *[]*Contact or *interface{}.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: vcard: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: vcard: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: vcard: Unmarshal(unsupported " + e.Type.String() + ")"
+}
@@ -0,0 +1,248 @@
+package vcard
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/internal/contentline"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// birthdayFormat is the layout of BDAY values.
+const birthdayFormat string = "20060102"
+
+// VCardCodec converts objects to and from vCard 4.0 data.
+type VCardCodec struct{}
+
+func init() {
+	codecs.Register(new(VCardCodec))
+}
+
+// Marshal converts an object to one or more vCards.
+func (c *VCardCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	contacts, err := ContactsFrom(object, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var w contentline.Writer
+	for _, contact := range contacts {
+
+		w.Write("BEGIN", nil, "VCARD")
+		w.Write("VERSION", nil, "4.0")
+
+		writeText(&w, "UID", contact.UID)
+		w.Write("FN", nil, contentline.EscapeText(contact.formattedName()))
+
+		if contact.hasName() {
+			w.Write("N", nil, structured(contact.FamilyName, contact.GivenName, contact.AdditionalNames, contact.HonorificPrefixes, contact.HonorificSuffixes))
+		}
+
+		writeText(&w, "NICKNAME", contact.Nickname)
+		for _, email := range contact.Emails {
+			writeText(&w, "EMAIL", email)
+		}
+		for _, phone := range contact.Phones {
+			writeText(&w, "TEL", phone)
+		}
+		for _, address := range contact.Addresses {
+			var params map[string]string
+			if len(address.Type) > 0 {
+				params = map[string]string{"TYPE": address.Type}
+			}
+			w.Write("ADR", params, structured("", "", address.Street, address.Locality, address.Region, address.PostalCode, address.Country))
+		}
+		writeText(&w, "ORG", contact.Organization)
+		writeText(&w, "TITLE", contact.Title)
+		if len(contact.URL) > 0 {
+			w.Write("URL", nil, contact.URL)
+		}
+		writeText(&w, "NOTE", contact.Note)
+		if !contact.Birthday.IsZero() {
+			w.Write("BDAY", nil, contact.Birthday.Format(birthdayFormat))
+		}
+
+		w.Write("END", nil, "VCARD")
+	}
+
+	return w.Bytes(), nil
+}
+
+// Unmarshal converts vCard data into contacts.
+//
+// The obj may be a *Contact (which gets the first contact), a *[]*Contact,
+// or a *interface{}, which gets a *Contact if there is one contact or a
+// []*Contact if there are more.
+func (c *VCardCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	lines, err := contentline.Parse(data)
+
+	if err != nil {
+		return err
+	}
+
+	var contacts []*Contact
+	var contact *Contact
+	for _, line := range lines {
+
+		switch line.Name {
+		case "BEGIN":
+			if strings.ToUpper(line.Value) == "VCARD" {
+				contact = new(Contact)
+			}
+			continue
+		case "END":
+			if strings.ToUpper(line.Value) == "VCARD" && contact != nil {
+				contacts = append(contacts, contact)
+				contact = nil
+			}
+			continue
+		}
+
+		if contact != nil {
+			readProperty(contact, line)
+		}
+
+	}
+
+	switch target := obj.(type) {
+	case *Contact:
+		if target != nil {
+			if len(contacts) > 0 {
+				*target = *contacts[0]
+			}
+			return nil
+		}
+	case *[]*Contact:
+		if target != nil {
+			*target = contacts
+			return nil
+		}
+	case *interface{}:
+		if target != nil {
+			if len(contacts) == 1 {
+				*target = contacts[0]
+			} else {
+				*target = contacts
+			}
+			return nil
+		}
+	}
+
+	return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+}
+
+// ContentType returns the content type for this codec.
+func (c *VCardCodec) ContentType() string {
+	return constants.ContentTypeVCard
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *VCardCodec) FileExtension() string {
+	return constants.FileExtensionVCard
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *VCardCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func readProperty(contact *Contact, line contentline.Line) {
+
+	text := contentline.UnescapeText(line.Value)
+
+	switch line.Name {
+	case "UID":
+		contact.UID = text
+	case "FN":
+		contact.FormattedName = text
+	case "N":
+		parts := components(line.Value, 5)
+		contact.FamilyName = parts[0]
+		contact.GivenName = parts[1]
+		contact.AdditionalNames = parts[2]
+		contact.HonorificPrefixes = parts[3]
+		contact.HonorificSuffixes = parts[4]
+	case "NICKNAME":
+		contact.Nickname = text
+	case "EMAIL":
+		contact.Emails = append(contact.Emails, text)
+	case "TEL":
+		contact.Phones = append(contact.Phones, text)
+	case "ADR":
+		parts := components(line.Value, 7)
+		contact.Addresses = append(contact.Addresses, Address{
+			Type:       line.Param("TYPE"),
+			Street:     parts[2],
+			Locality:   parts[3],
+			Region:     parts[4],
+			PostalCode: parts[5],
+			Country:    parts[6],
+		})
+	case "ORG":
+		contact.Organization = text
+	case "TITLE":
+		contact.Title = text
+	case "URL":
+		contact.URL = line.Value
+	case "NOTE":
+		contact.Note = text
+	case "BDAY":
+		contact.Birthday, _ = time.Parse(birthdayFormat, strings.Replace(line.Value, "-", "", -1))
+	}
+
+}
+
+// formattedName gets the FN for the contact, building one from the name
+// parts if necessary.
+func (c *Contact) formattedName() string {
+
+	if len(c.FormattedName) > 0 {
+		return c.FormattedName
+	}
+
+	var parts []string
+	for _, part := range []string{c.HonorificPrefixes, c.GivenName, c.AdditionalNames, c.FamilyName, c.HonorificSuffixes} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (c *Contact) hasName() bool {
+	return len(c.FamilyName+c.GivenName+c.AdditionalNames+c.HonorificPrefixes+c.HonorificSuffixes) > 0
+}
+
+// structured builds a semicolon separated structured value.
+func structured(parts ...string) string {
+	for i, part := range parts {
+		parts[i] = contentline.EscapeText(part)
+	}
+	return strings.Join(parts, ";")
+}
+
+// components splits a structured value into (at least) count unescaped parts.
+func components(value string, count int) []string {
+
+	parts := contentline.SplitValues(value, ';')
+	for len(parts) < count {
+		parts = append(parts, "")
+	}
+
+	for i, part := range parts {
+		parts[i] = contentline.UnescapeText(part)
+	}
+
+	return parts
+}
+
+func writeText(w *contentline.Writer, name, value string) {
+	if len(value) > 0 {
+		w.Write(name, nil, contentline.EscapeText(value))
+	}
+}
@@ -0,0 +1,117 @@
+package vcard
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var vcardCodec VCardCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(VCardCodec), "VCardCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeVCard, vcardCodec.ContentType())
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionVCard, vcardCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, vcardCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal(t *testing.T) {
+
+	contact := &Contact{
+		GivenName:  "Mat",
+		FamilyName: "Ryer",
+		Emails:     []string{"mat@example.com"},
+		Addresses:  []Address{{Type: "work", Street: "Pearl Street", Locality: "Boulder", Region: "CO"}},
+		Birthday:   time.Date(1983, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	bytes, err := vcardCodec.Marshal(contact, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "BEGIN:VCARD\r\n"+
+			"VERSION:4.0\r\n"+
+			"FN:Mat Ryer\r\n"+
+			"N:Ryer;Mat;;;\r\n"+
+			"EMAIL:mat@example.com\r\n"+
+			"ADR;TYPE=work:;;Pearl Street;Boulder;CO;;\r\n"+
+			"BDAY:19830102\r\n"+
+			"END:VCARD\r\n", string(bytes))
+	}
+
+}
+
+func TestMarshal_Maps(t *testing.T) {
+
+	contacts := []interface{}{
+		map[string]interface{}{FieldName: "Mat", FieldEmail: []interface{}{"a@example.com", "b@example.com"}},
+		map[string]interface{}{FieldName: "Tyler", FieldPhone: "+1 555 0100"},
+	}
+
+	bytes, err := vcardCodec.Marshal(contacts, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), "FN:Mat\r\nEMAIL:a@example.com\r\nEMAIL:b@example.com\r\n")
+		assert.Contains(t, string(bytes), "FN:Tyler\r\nTEL:+1 555 0100\r\n")
+	}
+
+}
+
+func TestMarshal_NotAContact(t *testing.T) {
+
+	_, err := vcardCodec.Marshal("Mat", nil)
+	assert.Equal(t, ErrorNotAContact, err)
+
+}
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+
+	contact := Contact{
+		UID:          "urn:uuid:1",
+		GivenName:    "Tyler",
+		FamilyName:   "Bunnell",
+		Organization: "Stretchr, Inc.",
+		Note:         "Likes Go;\nand codecs",
+		Addresses:    []Address{{Street: "1 Main St", Country: "USA"}},
+	}
+
+	bytes, _ := vcardCodec.Marshal(contact, nil)
+
+	var result Contact
+	if assert.NoError(t, vcardCodec.Unmarshal(bytes, &result)) {
+		assert.Equal(t, contact.UID, result.UID)
+		assert.Equal(t, "Tyler Bunnell", result.FormattedName)
+		assert.Equal(t, contact.GivenName, result.GivenName)
+		assert.Equal(t, contact.FamilyName, result.FamilyName)
+		assert.Equal(t, contact.Organization, result.Organization)
+		assert.Equal(t, contact.Note, result.Note)
+		assert.Equal(t, contact.Addresses, result.Addresses)
+	}
+
+}
+
+func TestUnmarshal_Multiple(t *testing.T) {
+
+	data := "BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Mat\r\nEND:VCARD\r\nBEGIN:VCARD\r\nVERSION:4.0\r\nFN:Ryan\r\nBDAY:1985-06-07\r\nEND:VCARD\r\n"
+
+	var obj interface{}
+	if assert.NoError(t, vcardCodec.Unmarshal([]byte(data), &obj)) {
+		contacts := obj.([]*Contact)
+		if assert.Equal(t, 2, len(contacts)) {
+			assert.Equal(t, "Mat", contacts[0].FormattedName)
+			assert.Equal(t, "Ryan", contacts[1].FormattedName)
+			assert.Equal(t, time.Date(1985, 6, 7, 0, 0, 0, 0, time.UTC), contacts[1].Birthday)
+		}
+	}
+
+}
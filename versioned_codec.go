@@ -0,0 +1,71 @@
+package codecs
+
+import (
+	"strings"
+)
+
+// ParameterVersion is the name of the media type parameter giving the version
+// of a media type, as in application/vnd.example+json; version=2.
+const ParameterVersion string = "version"
+
+// ParameterPretty is the name of the media type parameter asking for indented
+// output, as in application/json; pretty=true.
+const ParameterPretty string = "pretty"
+
+// VersionedCodec serves one version of a media type with another codec, so
+// that several versions of the same media type can be installed side by side
+// and negotiated between.  Versions can be told apart by the version parameter
+// (with the same Type for every version) or by the vendor tree (with a Type
+// such as application/vnd.example.v2+json for each version).
+type VersionedCodec struct {
+
+	// Codec does the marshalling and unmarshalling.
+	Codec
+
+	// Type is the content type the codec handles, or empty for the content
+	// type of Codec.
+	Type string
+
+	// Version is the version of the media type the codec handles.
+	Version string
+}
+
+// ContentType gets the content type that the codec handles.
+func (c *VersionedCodec) ContentType() string {
+	if len(c.Type) > 0 {
+		return c.Type
+	}
+	return c.Codec.ContentType()
+}
+
+// Parameters gets the media type parameters the codec understands, which
+// are those of Codec along with the version parameter, accepting only the
+// codec's Version (and defaulting to it).
+func (c *VersionedCodec) Parameters() []Parameter {
+
+	var parameters []Parameter
+	if parameterCodec, ok := c.Codec.(ParameterCodec); ok {
+		for _, parameter := range parameterCodec.Parameters() {
+			if strings.ToLower(parameter.Name) != ParameterVersion {
+				parameters = append(parameters, parameter)
+			}
+		}
+	}
+
+	return append(parameters, Parameter{Name: ParameterVersion, Values: []string{c.Version}, Default: c.Version})
+}
+
+// Suffixes gets the structured syntax suffixes of Codec, or the suffix of
+// Type if Codec declares none.
+func (c *VersionedCodec) Suffixes() []string {
+
+	if suffixCodec, ok := c.Codec.(SuffixCodec); ok {
+		return suffixCodec.Suffixes()
+	}
+
+	if plus := strings.LastIndex(c.Type, "+"); plus >= 0 {
+		return []string{strings.ToLower(strings.TrimSpace(strings.Split(c.Type[plus+1:], ";")[0]))}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersionedCodec(t *testing.T) {
+
+	inner := new(test.TestCodec)
+	inner.On("ContentType").Return(constants.ContentTypeJSON)
+
+	codec := &VersionedCodec{Codec: inner, Version: "2"}
+
+	assert.Implements(t, (*ParameterCodec)(nil), codec)
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	assert.Equal(t, []Parameter{{Name: ParameterVersion, Values: []string{"2"}, Default: "2"}}, codec.Parameters())
+	assert.Nil(t, codec.Suffixes())
+
+	codec = &VersionedCodec{Codec: inner, Type: "application/vnd.example.v1+json", Version: "1"}
+
+	assert.Equal(t, "application/vnd.example.v1+json", codec.ContentType())
+	assert.Equal(t, []string{"json"}, codec.Suffixes())
+
+}
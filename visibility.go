@@ -0,0 +1,93 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// VisibilityPolicy decides whether the struct field appears in public data
+// marshalled for the roles (given by the constants.OptionKeyRoles option).
+type VisibilityPolicy func(field reflect.StructField, roles []string) bool
+
+var (
+	visibilityPolicies     = map[reflect.Type]VisibilityPolicy{}
+	visibilityPoliciesLock sync.RWMutex
+)
+
+// RegisterVisibilityPolicy makes the policy decide which fields of structs of
+// the object's type (or the type it points to) appear in public data, instead
+// of their public tags.  A nil policy removes the type's policy.
+func RegisterVisibilityPolicy(object interface{}, policy VisibilityPolicy) {
+
+	if object == nil {
+		panic("codecs: RegisterVisibilityPolicy object is nil.")
+	}
+
+	objectType := reflect.TypeOf(object)
+	for objectType.Kind() == reflect.Ptr {
+		objectType = objectType.Elem()
+	}
+
+	visibilityPoliciesLock.Lock()
+	defer visibilityPoliciesLock.Unlock()
+
+	if policy == nil {
+		delete(visibilityPolicies, objectType)
+		return
+	}
+	visibilityPolicies[objectType] = policy
+}
+
+// TagVisibility is the VisibilityPolicy of structs without a registered
+// policy.  Fields tagged with the roles that may see them, such as
+// `public:"admin,owner"`, appear only for those roles; untagged fields appear
+// for everyone.
+func TagVisibility(field reflect.StructField, roles []string) bool {
+
+	tag, ok := field.Tag.Lookup("public")
+	if !ok {
+		return true
+	}
+
+	for _, allowed := range strings.Split(tag, ",") {
+		for _, role := range roles {
+			if strings.TrimSpace(allowed) == role {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// visibilityPolicy gets the policy deciding which fields of structs of the type
+// appear in public data, or nil if they all do.
+func visibilityPolicy(structType reflect.Type) VisibilityPolicy {
+
+	visibilityPoliciesLock.RLock()
+	policy, ok := visibilityPolicies[structType]
+	visibilityPoliciesLock.RUnlock()
+	if ok {
+		return policy
+	}
+
+	if typeInfoFor(structType).publicTags {
+		return TagVisibility
+	}
+
+	return nil
+}
+
+// roles gets the roles given by the constants.OptionKeyRoles option, which may
+// be a []string or a single role as a string.
+func roles(options map[string]interface{}) []string {
+	switch value := options[constants.OptionKeyRoles].(type) {
+	case []string:
+		return value
+	case string:
+		return []string{value}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package xml
+
+import (
+	"bytes"
+	xmlEncoding "encoding/xml"
+	"github.com/stretchr/codecs"
+)
+
+// CheckLimits checks that the XML doesn't exceed the limits, scanning it
+// without decoding it into an object.  Elements count towards the depth and
+// elements limits, and attribute values and text towards the string length
+// limit.
+func (c *SimpleXmlCodec) CheckLimits(data []byte, limits codecs.DecodeLimits) error {
+
+	decoder := xmlEncoding.NewDecoder(bytes.NewReader(data))
+	checker := codecs.LimitChecker{Limits: limits}
+
+	for {
+
+		token, err := decoder.RawToken()
+
+		// malformed data is left for Unmarshal to report
+		if err != nil {
+			return nil
+		}
+
+		switch token := token.(type) {
+		case xmlEncoding.StartElement:
+			if err = checker.Enter(); err == nil {
+				err = checker.Element()
+			}
+			for _, attr := range token.Attr {
+				if err == nil {
+					err = checker.String(len(attr.Value))
+				}
+			}
+		case xmlEncoding.EndElement:
+			checker.Leave()
+		case xmlEncoding.CharData:
+			err = checker.String(len(token))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
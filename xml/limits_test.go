@@ -0,0 +1,18 @@
+package xml
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+
+	data := []byte(`<?xml version="1.0"?><object><name type="string">Mat</name><tags><tag/></tags></object>`)
+
+	assert.NoError(t, xmlCodec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 3, MaxElements: 4, MaxStringLength: 6}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitDepth, Max: 2}, xmlCodec.CheckLimits(data, codecs.DecodeLimits{MaxDepth: 2}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitElements, Max: 3}, xmlCodec.CheckLimits(data, codecs.DecodeLimits{MaxElements: 3}))
+	assert.Equal(t, &codecs.DecodeLimitError{Limit: codecs.LimitStringLength, Max: 5}, xmlCodec.CheckLimits(data, codecs.DecodeLimits{MaxStringLength: 5}))
+
+}
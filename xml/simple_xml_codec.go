@@ -3,9 +3,11 @@ package xml
 import (
 	"fmt"
 	xml "github.com/clbanning/x2j"
+	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/stew/objects"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -30,8 +32,14 @@ var (
 // SimpleXmlCodec converts objects to and from simple XML.
 type SimpleXmlCodec struct{}
 
+func init() {
+	codecs.Register(new(SimpleXmlCodec))
+}
+
 // Marshal converts an object to a []byte representation.
-// You can optionally pass additional arguments to further customize this call.
+// You can optionally pass additional arguments to further customize this call,
+// such as the constants.OptionKeySortKeys option to write the fields of objects
+// in the order of their names.
 func (c *SimpleXmlCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 
 	var output []string
@@ -85,6 +93,16 @@ func (c *SimpleXmlCodec) FileExtension() string {
 	return constants.FileExtensionXML
 }
 
+// Suffixes returns the structured syntax suffixes this codec can handle.
+func (c *SimpleXmlCodec) Suffixes() []string {
+	return []string{"xml"}
+}
+
+// Aliases returns the other content types this codec handles.
+func (c *SimpleXmlCodec) Aliases() []string {
+	return []string{constants.ContentTypeXMLAlias}
+}
+
 // CanMarshalWithCallback indicates whether this codec is capable of marshalling a response with
 // a callback parameter.
 func (c *SimpleXmlCodec) CanMarshalWithCallback() bool {
@@ -213,9 +231,19 @@ func marshal(object interface{}, doIndent bool, indentLevel int, options objects
 	switch object.(type) {
 	case map[string]interface{}:
 
+		values := object.(map[string]interface{})
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		if sortKeys, _ := options[constants.OptionKeySortKeys].(bool); sortKeys {
+			sort.Strings(keys)
+		}
+
 		var objects []string
-		for k, v := range object.(map[string]interface{}) {
+		for _, k := range keys {
 
+			v := values[k]
 			valueBytes, valueMarshalErr := marshal(v, doIndent, nextIndent, options)
 
 			// handle errors
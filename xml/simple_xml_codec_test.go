@@ -28,6 +28,11 @@ func TestExtension(t *testing.T) {
 	assert.Equal(t, constants.FileExtensionXML, xmlCodec.FileExtension())
 }
 
+func TestAliases(t *testing.T) {
+	assert.Implements(t, (*codecs.AliasCodec)(nil), new(SimpleXmlCodec))
+	assert.Equal(t, []string{constants.ContentTypeXMLAlias}, xmlCodec.Aliases())
+}
+
 func TestMarshalAndUnmarshal(t *testing.T) {
 
 	// make a big object
@@ -71,6 +76,20 @@ func TestMarshal_map(t *testing.T) {
 
 }
 
+func TestMarshal_mapWithSortedKeys(t *testing.T) {
+
+	data := map[string]interface{}{"name": "Mat", "age": 30, "address": map[string]interface{}{"state": "CO", "city": "Boulder"}}
+	options := objects.NewMap(constants.OptionKeySortKeys, true)
+
+	for i := 0; i < 10; i++ {
+		bytes, marshalErr := marshal(data, false, 0, options)
+		if assert.NoError(t, marshalErr) {
+			assert.Equal(t, "<object><address><object><city>Boulder</city><state>CO</state></object></address><age>30</age><name>Mat</name></object>", string(bytes), "Output")
+		}
+	}
+
+}
+
 func TestMarshal_mapWithTypes(t *testing.T) {
 
 	data := map[string]interface{}{"name": "Mat", "age": 30, "yesOrNo": true}
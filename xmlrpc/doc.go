@@ -0,0 +1,21 @@
+// A codec for handling XML-RPC methodCall and methodResponse documents.
+//
+// Go values are mapped to XML-RPC types as follows:
+//
+//	int types       <int> (or <i8> if the value doesn't fit in 32 bits)
+//	bool            <boolean>
+//	string          <string>
+//	float types     <double>
+//	time.Time       <dateTime.iso8601>
+//	[]byte          <base64>
+//	maps, structs   <struct>
+//	arrays, slices  <array>
+//	nil             <nil/>
+//
+// When unmarshalling, ints become int64, doubles become float64, structs
+// become map[string]interface{} and arrays become []interface{}.
+//
+// Marshalling a *MethodCall produces a methodCall, marshalling an error (or a
+// *Fault) produces a fault response, and marshalling anything else produces a
+// methodResponse with the object as its only parameter.
+package xmlrpc
@@ -0,0 +1,351 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	xmlEncoding "encoding/xml"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeFormat is the layout of dateTime.iso8601 values.
+const dateTimeFormat string = "20060102T15:04:05"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// writeValue writes the <value> element for the object.
+func writeValue(buffer *bytes.Buffer, object interface{}) error {
+
+	buffer.WriteString("<value>")
+	if err := writeTypedValue(buffer, reflect.ValueOf(object)); err != nil {
+		return err
+	}
+	buffer.WriteString("</value>")
+
+	return nil
+}
+
+func writeTypedValue(buffer *bytes.Buffer, value reflect.Value) error {
+
+	if !value.IsValid() {
+		buffer.WriteString("<nil/>")
+		return nil
+	}
+
+	if value.Type() == timeType {
+		fmt.Fprintf(buffer, "<dateTime.iso8601>%s</dateTime.iso8601>", value.Interface().(time.Time).Format(dateTimeFormat))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			buffer.WriteString("<nil/>")
+			return nil
+		}
+		return writeTypedValue(buffer, value.Elem())
+	case reflect.Bool:
+		if value.Bool() {
+			buffer.WriteString("<boolean>1</boolean>")
+		} else {
+			buffer.WriteString("<boolean>0</boolean>")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeInt(buffer, value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return fmt.Errorf("codecs: xmlrpc: %d is too big for an XML-RPC integer", value.Uint())
+		}
+		writeInt(buffer, int64(value.Uint()))
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprintf(buffer, "<double>%s</double>", strconv.FormatFloat(value.Float(), 'f', -1, 64))
+	case reflect.String:
+		buffer.WriteString("<string>")
+		xmlEncoding.EscapeText(buffer, []byte(value.String()))
+		buffer.WriteString("</string>")
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			fmt.Fprintf(buffer, "<base64>%s</base64>", base64.StdEncoding.EncodeToString(value.Bytes()))
+			return nil
+		}
+		buffer.WriteString("<array><data>")
+		for i := 0; i < value.Len(); i++ {
+			if err := writeValue(buffer, value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("</data></array>")
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("codecs: xmlrpc: Map keys must be strings, not %s", value.Type().Key())
+		}
+		keys := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		buffer.WriteString("<struct>")
+		for _, key := range keys {
+			if err := writeMember(buffer, key, value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())).Interface()); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("</struct>")
+	case reflect.Struct:
+		buffer.WriteString("<struct>")
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			name := field.Name
+			if tag := field.Tag.Get("xmlrpc"); len(tag) > 0 {
+				if tag == "-" {
+					continue
+				}
+				name = tag
+			}
+			if err := writeMember(buffer, name, value.Field(i).Interface()); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("</struct>")
+	default:
+		return fmt.Errorf("codecs: xmlrpc: Cannot marshal %s", value.Type())
+	}
+
+	return nil
+}
+
+func writeInt(buffer *bytes.Buffer, i int64) {
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		fmt.Fprintf(buffer, "<i8>%d</i8>", i)
+		return
+	}
+	fmt.Fprintf(buffer, "<int>%d</int>", i)
+}
+
+func writeMember(buffer *bytes.Buffer, name string, object interface{}) error {
+	buffer.WriteString("<member><name>")
+	xmlEncoding.EscapeText(buffer, []byte(name))
+	buffer.WriteString("</name>")
+	if err := writeValue(buffer, object); err != nil {
+		return err
+	}
+	buffer.WriteString("</member>")
+	return nil
+}
+
+// readValue reads the contents of a <value> element, whose start element has
+// already been consumed, up to and including its end element.
+func readValue(d *xmlEncoding.Decoder) (interface{}, error) {
+
+	var text bytes.Buffer
+	var result interface{}
+	typed := false
+
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.CharData:
+			text.Write(t)
+		case xmlEncoding.StartElement:
+			typed = true
+			if result, err = readTyped(d, t.Name.Local); err != nil {
+				return nil, err
+			}
+		case xmlEncoding.EndElement:
+			if !typed {
+				// untyped values are strings
+				return text.String(), nil
+			}
+			return result, nil
+		}
+
+	}
+
+}
+
+// readTyped reads the typed element (e.g. <int>) whose start element has already
+// been consumed.
+func readTyped(d *xmlEncoding.Decoder, typeName string) (interface{}, error) {
+
+	switch typeName {
+	case "struct":
+		return readStruct(d)
+	case "array":
+		return readArray(d)
+	case "nil":
+		return nil, d.Skip()
+	}
+
+	text, err := readText(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch typeName {
+	case "int", "i4", "i8":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "boolean":
+		switch strings.TrimSpace(text) {
+		case "1":
+			return true, nil
+		case "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("codecs: xmlrpc: Invalid boolean %q", text)
+	case "double":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "string":
+		return text, nil
+	case "dateTime.iso8601":
+		for _, layout := range []string{dateTimeFormat, "2006-01-02T15:04:05", time.RFC3339} {
+			if t, err := time.Parse(layout, strings.TrimSpace(text)); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("codecs: xmlrpc: Invalid dateTime.iso8601 %q", text)
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	}
+
+	return nil, fmt.Errorf("codecs: xmlrpc: Unknown value type <%s>", typeName)
+}
+
+func readStruct(d *xmlEncoding.Decoder) (interface{}, error) {
+
+	m := map[string]interface{}{}
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			if t.Name.Local != "member" {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			name, value, err := readMember(d)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = value
+		case xmlEncoding.EndElement:
+			return m, nil
+		}
+
+	}
+}
+
+func readMember(d *xmlEncoding.Decoder) (string, interface{}, error) {
+
+	var name string
+	var value interface{}
+
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			switch t.Name.Local {
+			case "name":
+				if name, err = readText(d); err != nil {
+					return "", nil, err
+				}
+			case "value":
+				if value, err = readValue(d); err != nil {
+					return "", nil, err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return "", nil, err
+				}
+			}
+		case xmlEncoding.EndElement:
+			return name, value, nil
+		}
+
+	}
+}
+
+func readArray(d *xmlEncoding.Decoder) (interface{}, error) {
+
+	values := []interface{}{}
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			// step into <data>
+			if t.Name.Local == "value" {
+				value, err := readValue(d)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+		case xmlEncoding.EndElement:
+			if t.Name.Local == "array" {
+				return values, nil
+			}
+		}
+
+	}
+}
+
+// readText reads the character data up to the end of the current element.
+func readText(d *xmlEncoding.Decoder) (string, error) {
+
+	var text bytes.Buffer
+	depth := 0
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.CharData:
+			text.Write(t)
+		case xmlEncoding.StartElement:
+			depth++
+		case xmlEncoding.EndElement:
+			if depth == 0 {
+				return text.String(), nil
+			}
+			depth--
+		}
+
+	}
+}
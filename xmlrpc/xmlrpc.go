@@ -0,0 +1,33 @@
+package xmlrpc
+
+import (
+	"fmt"
+)
+
+// Fault codes used when marshalling errors that are not already a *Fault.
+const (
+	FaultCodeServer int = -32500
+)
+
+// MethodCall is an XML-RPC methodCall.
+type MethodCall struct {
+	Method string
+	Params []interface{}
+}
+
+// MethodResponse is an XML-RPC methodResponse.
+type MethodResponse struct {
+	Params []interface{}
+}
+
+// Fault is an XML-RPC fault.  Faults are errors so they can be returned by
+// Unmarshal and marshalled like any other error.
+type Fault struct {
+	Code   int
+	String string
+}
+
+// Error makes Fault an error.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("codecs: xmlrpc: fault %d: %s", f.Code, f.String)
+}
@@ -0,0 +1,304 @@
+package xmlrpc
+
+import (
+	"bytes"
+	xmlEncoding "encoding/xml"
+	"errors"
+	"github.com/stretchr/codecs/constants"
+	"io"
+	"reflect"
+)
+
+// ErrorNotXmlRpc is returned by Unmarshal when the data is neither a methodCall
+// nor a methodResponse.
+var ErrorNotXmlRpc = errors.New("codecs: xmlrpc: Data is not an XML-RPC methodCall or methodResponse")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: xmlrpc: Unmarshal(nil)"
+	}
+	return "codecs: xmlrpc: Unmarshal(unsupported " + e.Type.String() + ")"
+}
+
+// XmlRpcCodec converts objects to and from XML-RPC.  It isn't registered (see
+// codecs.Register), since XML-RPC is served as text/xml, the content type of
+// xml.SimpleXmlCodec, so it must be installed in a service in place of the
+// XML codec.
+type XmlRpcCodec struct{}
+
+// Marshal converts an object to an XML-RPC methodCall, methodResponse or fault.
+func (c *XmlRpcCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	buffer := bytes.NewBufferString(xmlEncoding.Header)
+
+	switch o := object.(type) {
+	case *MethodCall:
+		buffer.WriteString("<methodCall><methodName>")
+		xmlEncoding.EscapeText(buffer, []byte(o.Method))
+		buffer.WriteString("</methodName>")
+		if err := writeParams(buffer, o.Params); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("</methodCall>")
+	case *MethodResponse:
+		buffer.WriteString("<methodResponse>")
+		if err := writeParams(buffer, o.Params); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("</methodResponse>")
+	case error:
+		fault, ok := o.(*Fault)
+		if !ok {
+			fault = &Fault{Code: FaultCodeServer, String: o.Error()}
+		}
+		buffer.WriteString("<methodResponse><fault>")
+		if err := writeValue(buffer, map[string]interface{}{"faultCode": fault.Code, "faultString": fault.String}); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("</fault></methodResponse>")
+	default:
+		buffer.WriteString("<methodResponse>")
+		if err := writeParams(buffer, []interface{}{object}); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("</methodResponse>")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal converts an XML-RPC document into an object.
+//
+// A methodCall can be unmarshalled into a *MethodCall (or a *interface{}, which
+// will hold a *MethodCall).  A methodResponse can be unmarshalled into a
+// *MethodResponse, or a *interface{} which will hold the first parameter.  If
+// the response is a fault, it is returned as a *Fault error.
+func (c *XmlRpcCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	d := xmlEncoding.NewDecoder(bytes.NewReader(data))
+
+	var call *MethodCall
+	var response *MethodResponse
+
+	for call == nil && response == nil {
+
+		token, err := d.Token()
+
+		if err == io.EOF {
+			return ErrorNotXmlRpc
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xmlEncoding.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "methodCall":
+			if call, err = readMethodCall(d); err != nil {
+				return err
+			}
+		case "methodResponse":
+			if response, err = readMethodResponse(d); err != nil {
+				return err
+			}
+		default:
+			return ErrorNotXmlRpc
+		}
+
+	}
+
+	switch target := obj.(type) {
+	case *MethodCall:
+		if call != nil {
+			*target = *call
+			return nil
+		}
+	case *MethodResponse:
+		if response != nil {
+			*target = *response
+			return nil
+		}
+	case *interface{}:
+		if call != nil {
+			*target = call
+		} else if len(response.Params) > 0 {
+			*target = response.Params[0]
+		} else {
+			*target = nil
+		}
+		return nil
+	}
+
+	return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+}
+
+// ContentType returns the content type for this codec.
+func (c *XmlRpcCodec) ContentType() string {
+	return constants.ContentTypeXML
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *XmlRpcCodec) FileExtension() string {
+	return constants.FileExtensionXMLRPC
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *XmlRpcCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func writeParams(buffer *bytes.Buffer, params []interface{}) error {
+
+	buffer.WriteString("<params>")
+	for _, param := range params {
+		buffer.WriteString("<param>")
+		if err := writeValue(buffer, param); err != nil {
+			return err
+		}
+		buffer.WriteString("</param>")
+	}
+	buffer.WriteString("</params>")
+
+	return nil
+}
+
+func readMethodCall(d *xmlEncoding.Decoder) (*MethodCall, error) {
+
+	call := new(MethodCall)
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			switch t.Name.Local {
+			case "methodName":
+				if call.Method, err = readText(d); err != nil {
+					return nil, err
+				}
+			case "params":
+				if call.Params, err = readParams(d); err != nil {
+					return nil, err
+				}
+			default:
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xmlEncoding.EndElement:
+			return call, nil
+		}
+
+	}
+}
+
+func readMethodResponse(d *xmlEncoding.Decoder) (*MethodResponse, error) {
+
+	response := new(MethodResponse)
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			switch t.Name.Local {
+			case "params":
+				if response.Params, err = readParams(d); err != nil {
+					return nil, err
+				}
+			case "fault":
+				return nil, readFault(d)
+			default:
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xmlEncoding.EndElement:
+			return response, nil
+		}
+
+	}
+}
+
+func readParams(d *xmlEncoding.Decoder) ([]interface{}, error) {
+
+	params := []interface{}{}
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			// step into <param>
+			if t.Name.Local == "value" {
+				value, err := readValue(d)
+				if err != nil {
+					return nil, err
+				}
+				params = append(params, value)
+			}
+		case xmlEncoding.EndElement:
+			if t.Name.Local == "params" {
+				return params, nil
+			}
+		}
+
+	}
+}
+
+// readFault reads the fault element and returns it as a *Fault, or returns
+// any error that occurs while reading it.
+func readFault(d *xmlEncoding.Decoder) error {
+
+	for {
+
+		token, err := d.Token()
+
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xmlEncoding.StartElement:
+			if t.Name.Local != "value" {
+				continue
+			}
+			value, err := readValue(d)
+			if err != nil {
+				return err
+			}
+			fault := new(Fault)
+			if m, ok := value.(map[string]interface{}); ok {
+				if code, ok := m["faultCode"].(int64); ok {
+					fault.Code = int(code)
+				}
+				fault.String, _ = m["faultString"].(string)
+			}
+			return fault
+		case xmlEncoding.EndElement:
+			return &Fault{}
+		}
+
+	}
+}
@@ -0,0 +1,123 @@
+package xmlrpc
+
+import (
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var xmlrpcCodec XmlRpcCodec
+
+func TestInterface(t *testing.T) {
+	assert.Implements(t, (*codecs.Codec)(nil), new(XmlRpcCodec), "XmlRpcCodec")
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, constants.ContentTypeXML, xmlrpcCodec.ContentType())
+}
+
+func TestNotRegistered(t *testing.T) {
+
+	// the XML codec is registered for text/xml without conflict
+	for _, codec := range codecs.Registered() {
+		if codec.ContentType() == constants.ContentTypeXML {
+			assert.IsType(t, new(xml.SimpleXmlCodec), codec)
+		}
+	}
+
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, constants.FileExtensionXMLRPC, xmlrpcCodec.FileExtension())
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+	assert.False(t, xmlrpcCodec.CanMarshalWithCallback())
+}
+
+func TestMarshal_Response(t *testing.T) {
+
+	bytes, err := xmlrpcCodec.Marshal(map[string]interface{}{"name": "Mat", "age": 30, "admin": true}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<methodResponse><params><param><value><struct><member><name>admin</name><value><boolean>1</boolean></value></member><member><name>age</name><value><int>30</int></value></member><member><name>name</name><value><string>Mat</string></value></member></struct></value></param></params></methodResponse>`, string(bytes))
+	}
+
+}
+
+func TestMarshal_Fault(t *testing.T) {
+
+	bytes, err := xmlrpcCodec.Marshal(errors.New("Oops"), nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<methodResponse><fault><value><struct><member><name>faultCode</name><value><int>-32500</int></value></member><member><name>faultString</name><value><string>Oops</string></value></member></struct></value></fault></methodResponse>`)
+	}
+
+}
+
+func TestMarshalAndUnmarshal_Call(t *testing.T) {
+
+	when := time.Date(2013, 1, 2, 3, 4, 5, 0, time.UTC)
+	call := &MethodCall{Method: "examples.echo", Params: []interface{}{
+		int64(1) << 40, 1.5, "<text>", []byte("hi"), when, []interface{}{int64(1), "two"}, nil,
+	}}
+
+	bytes, err := xmlrpcCodec.Marshal(call, nil)
+
+	if assert.NoError(t, err) {
+
+		var result MethodCall
+		if assert.NoError(t, xmlrpcCodec.Unmarshal(bytes, &result)) {
+			assert.Equal(t, call.Method, result.Method)
+			assert.Equal(t, call.Params, result.Params)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Response(t *testing.T) {
+
+	data := `<?xml version="1.0"?>
+<methodResponse>
+  <params>
+    <param>
+      <value><struct>
+        <member><name>name</name><value>Untyped</value></member>
+        <member><name>count</name><value><i4>42</i4></value></member>
+      </struct></value>
+    </param>
+  </params>
+</methodResponse>`
+
+	var obj interface{}
+	if assert.NoError(t, xmlrpcCodec.Unmarshal([]byte(data), &obj)) {
+		m := obj.(map[string]interface{})
+		assert.Equal(t, "Untyped", m["name"])
+		assert.Equal(t, int64(42), m["count"])
+	}
+
+}
+
+func TestUnmarshal_Fault(t *testing.T) {
+
+	bytes, _ := xmlrpcCodec.Marshal(&Fault{Code: 4, String: "Too many params"}, nil)
+
+	var obj interface{}
+	err := xmlrpcCodec.Unmarshal(bytes, &obj)
+
+	assert.Equal(t, &Fault{Code: 4, String: "Too many params"}, err)
+
+}
+
+func TestUnmarshal_NotXmlRpc(t *testing.T) {
+
+	var obj interface{}
+	assert.Equal(t, ErrorNotXmlRpc, xmlrpcCodec.Unmarshal([]byte(`<object></object>`), &obj))
+
+}